@@ -4,14 +4,18 @@ API Client for the Complyance SDK matching Python SDK exactly.
 package complyancesdk
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
+	"math"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIClient API Client matching Python SDK
@@ -21,10 +25,31 @@ type APIClient struct {
 	retryStrategy  *RetryStrategy
 	circuitBreaker *CircuitBreaker
 	httpClient     *http.Client
+	// injectedHTTPClient is set when the HTTP client came from the caller, who
+	// then owns its connections
+	injectedHTTPClient bool
+	logger         Logger
+	redaction      *RedactionConfig
+	tracer         trace.Tracer
+	signingEnabled bool
+	signingSecret  string
+
+	compressionThreshold int
+	forceCompression     bool
+	maxResponseBytes     int64
+	maxPayloadBytes      int64
+	maxBulkPayloadBytes  int64
+	recordRequestJSON    bool
+	clock                Clock
+	rateLimiter          *RateLimiter
+	serverRateLimit      rateLimitTracker
 }
 
 const DefaultTimeout = 30 * time.Second
 
+// RedactedValue replaces secrets in requests exported for inspection
+const RedactedValue = "[REDACTED]"
+
 // NewAPIClient creates a new API client
 func NewAPIClient(apiKey string, environment Environment, retryConfig *RetryConfig) *APIClient {
 	return &APIClient{
@@ -32,9 +57,44 @@ func NewAPIClient(apiKey string, environment Environment, retryConfig *RetryConf
 		baseURL:        environment.GetBaseURL(),
 		retryStrategy:  NewRetryStrategy(retryConfig),
 		circuitBreaker: NewCircuitBreaker(retryConfig.GetCircuitBreakerConfig()),
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
-		},
+		httpClient:     newDefaultHTTPClient(),
+		logger:    noopLogger{},
+		redaction: NewDefaultRedactionConfig(),
+		tracer:    newTracer(nil),
+		clock:     SystemClock,
+	}
+}
+
+// newDefaultHTTPClient HTTP client used when none is injected
+// with a transport of its own, so closing its connections leaves other
+// clients alone
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   DefaultTimeout,
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
+// GetHTTPClient Get the HTTP client used for API requests
+func (a *APIClient) GetHTTPClient() *http.Client {
+	return a.httpClient
+}
+
+// SetHTTPClient Use client for API requests, e.g. to route through a proxy or an
+// instrumented transport. nil restores the default client with DefaultTimeout.
+func (a *APIClient) SetHTTPClient(client *http.Client) {
+	a.injectedHTTPClient = client != nil
+	if client == nil {
+		client = newDefaultHTTPClient()
+	}
+	a.httpClient = client
+}
+
+// CloseIdleConnections Close idle connections of the client's own HTTP client.
+// An injected HTTP client is left to its owner.
+func (a *APIClient) CloseIdleConnections() {
+	if !a.injectedHTTPClient {
+		a.httpClient.CloseIdleConnections()
 	}
 }
 
@@ -43,6 +103,73 @@ func (a *APIClient) GetCircuitBreaker() *CircuitBreaker {
 	return a.circuitBreaker
 }
 
+// GetBaseURL Get the Unify endpoint the client sends requests to
+func (a *APIClient) GetBaseURL() string {
+	return a.baseURL
+}
+
+// SetBaseURL Set the Unify endpoint the client sends requests to
+func (a *APIClient) SetBaseURL(baseURL string) {
+	a.baseURL = baseURL
+}
+
+// GetLogger Get the logger
+func (a *APIClient) GetLogger() Logger {
+	return a.logger
+}
+
+// SetLogger Set the logger used by the client, its retry strategy and circuit breaker.
+// Everything logged passes through the client's RedactionConfig. A nil logger
+// silences the client.
+func (a *APIClient) SetLogger(logger Logger) {
+	a.logger = NewRedactingLogger(logger, a.redaction)
+	a.retryStrategy.SetLogger(a.logger)
+	a.circuitBreaker.SetLogger(a.logger)
+}
+
+// GetRedactionConfig Get the redaction config applied to log output
+func (a *APIClient) GetRedactionConfig() *RedactionConfig {
+	return a.redaction
+}
+
+// SetRedactionConfig Set the redaction config applied to log output; nil restores the defaults
+func (a *APIClient) SetRedactionConfig(config *RedactionConfig) {
+	if config == nil {
+		config = NewDefaultRedactionConfig()
+	}
+	a.redaction = config
+	a.SetLogger(a.logger)
+}
+
+// SetMetricsSink Set the sink for retry and circuit breaker metrics; nil disables them
+func (a *APIClient) SetMetricsSink(sink MetricsSink) {
+	a.retryStrategy.SetMetricsSink(sink)
+	a.circuitBreaker.SetMetricsSink(sink)
+}
+
+// SetClock Set the clock used by the retry strategy, circuit breaker and
+// Retry-After parsing; nil restores SystemClock
+func (a *APIClient) SetClock(clock Clock) {
+	a.clock = clockOrSystem(clock)
+	a.retryStrategy.SetClock(clock)
+	a.circuitBreaker.SetClock(clock)
+	if a.rateLimiter != nil {
+		a.rateLimiter.SetClock(clock)
+	}
+}
+
+// SetTracerProvider Set the OpenTelemetry provider used for request spans; nil disables tracing
+func (a *APIClient) SetTracerProvider(provider trace.TracerProvider) {
+	a.tracer = newTracer(provider)
+}
+
+// SetRequestSigning Enable or disable HMAC-SHA256 signing of Unify request bodies
+// with secret. Signed requests carry the X-Signature and X-Signature-Timestamp headers.
+func (a *APIClient) SetRequestSigning(enabled bool, secret string) {
+	a.signingEnabled = enabled
+	a.signingSecret = secret
+}
+
 // GetDocumentStatus gets retrieval status by document ID.
 // Calls GET /api/v3/documents/{documentId}/status.
 func (a *APIClient) GetDocumentStatus(documentID string) (map[string]interface{}, error) {
@@ -54,8 +181,7 @@ func (a *APIClient) GetDocumentStatus(documentID string) (map[string]interface{}
 		).WithSuggestion("Provide a valid documentId to fetch retrieval status."))
 	}
 
-	path := fmt.Sprintf("/api/v3/documents/%s/status", url.PathEscape(normalized))
-	fullURL := strings.TrimSuffix(a.baseURL, "/unify") + path
+	fullURL := a.serviceURL(fmt.Sprintf("/api/v3/documents/%s/status", url.PathEscape(normalized)))
 
 	req, err := http.NewRequest("GET", fullURL, nil)
 	if err != nil {
@@ -69,7 +195,7 @@ func (a *APIClient) GetDocumentStatus(documentID string) (map[string]interface{}
 	req.Header.Set("Authorization", "Bearer "+a.apiKey)
 	req.Header.Set("X-API-Key", a.apiKey)
 
-	resp, err := a.httpClient.Do(req)
+	resp, err := a.doRequest(req)
 	if err != nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
@@ -78,12 +204,9 @@ func (a *APIClient) GetDocumentStatus(documentID string) (map[string]interface{}
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := a.readResponseBody(resp.Body)
 	if err != nil {
-		return nil, NewSDKError(NewErrorDetailWithCode(
-			ErrorCodeAPIError,
-			fmt.Sprintf("Failed to read response body: %v", err),
-		))
+		return nil, err
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
@@ -93,7 +216,7 @@ func (a *APIClient) GetDocumentStatus(documentID string) (map[string]interface{}
 		).WithSuggestion("Check your API key, base URL, and documentId.")
 		errorDetail.AddContextValue("httpStatus", resp.StatusCode)
 		errorDetail.AddContextValue("responseBody", string(body))
-		return nil, NewSDKError(errorDetail)
+		return nil, newResponseSDKError(errorDetail, resp.StatusCode)
 	}
 
 	if len(body) == 0 {
@@ -111,6 +234,100 @@ func (a *APIClient) GetDocumentStatus(documentID string) (map[string]interface{}
 	return parsed, nil
 }
 
+// serviceURL Build a URL for a non-unify endpoint off the environment base URL
+func (a *APIClient) serviceURL(path string) string {
+	return strings.TrimSuffix(a.baseURL, "/unify") + path
+}
+
+// GetStatus gets the current submission status by submission ID.
+// Calls GET /api/v3/submissions/{submissionId}/status through the retry strategy.
+func (a *APIClient) GetStatus(ctx context.Context, submissionID string) (*SubmissionResponse, error) {
+	normalized := strings.TrimSpace(submissionID)
+	if normalized == "" {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			"Submission ID is required",
+		).WithSuggestion("Provide the submissionId returned by PushToUnify."))
+	}
+
+	result, err := a.retryStrategy.ExecuteContext(
+		ctx,
+		func() (interface{}, error) {
+			return a.getStatusInternal(ctx, normalized)
+		},
+		fmt.Sprintf("submission-status-%s", normalized),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*SubmissionResponse), nil
+}
+
+// getStatusInternal Internal method to fetch submission status
+func (a *APIClient) getStatusInternal(ctx context.Context, submissionID string) (*SubmissionResponse, error) {
+	fullURL := a.serviceURL(fmt.Sprintf("/api/v3/submissions/%s/status", url.PathEscape(submissionID)))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeNetworkError,
+			fmt.Sprintf("Failed to create HTTP request: %v", err),
+		))
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	req.Header.Set("X-API-Key", a.apiKey)
+
+	resp, err := a.doRequest(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, newContextSDKError(ctx.Err())
+		}
+		errorDetail := NewErrorDetailWithCode(
+			ErrorCodeNetworkError,
+			fmt.Sprintf("Network error: %v", err),
+		).WithSuggestion("Check your network connection and try again")
+		errorDetail.Retryable = true
+		return nil, NewSDKError(errorDetail)
+	}
+	defer resp.Body.Close()
+
+	body, err := a.readResponseBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, err := a.handleErrorResponse(resp.StatusCode, string(body), resp)
+		return nil, err
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeAPIError,
+			fmt.Sprintf("Failed to parse submission status response: %v", err),
+		))
+	}
+
+	// The status may be wrapped as data.submission, data, or returned bare
+	section := parsed
+	if data, ok := parsed["data"].(map[string]interface{}); ok {
+		section = data
+		if submission, ok := data["submission"].(map[string]interface{}); ok {
+			section = submission
+		}
+	}
+
+	submission := &SubmissionResponse{}
+	a.decodeResponseSection(section, &submission)
+	if submission.SubmissionID == nil {
+		submission.SubmissionID = &submissionID
+	}
+	return submission, nil
+}
+
 // GetSubmissionStatus is deprecated and intentionally blocked.
 func (a *APIClient) GetSubmissionStatus(submissionID string) (map[string]interface{}, error) {
 	_ = submissionID
@@ -121,14 +338,16 @@ func (a *APIClient) GetSubmissionStatus(submissionID string) (map[string]interfa
 }
 
 // SendPayload Send payload matching Python SDK
+//
+// Deprecated: SendPayload does not contact the API and always reports success.
+// Use GETSUnifySDK.SubmitPayloadContext or SendUnifyRequest instead.
 func (a *APIClient) SendPayload(payload string, source *Source, country Country, documentType DocumentType) (*SubmissionResponseOld, error) {
-	log.Println("🔥 SENDING PAYLOAD FROM QUEUE 🔥")
-	log.Printf("Source: %s", source.GetID())
-	log.Printf("Country: %s", country)
-	log.Printf("Document Type: %s", documentType)
-	log.Println("Payload JSON:")
-	log.Println(payload)
-	log.Println("🔥 END PAYLOAD 🔥")
+	a.logger.Debug("Sending payload from queue", map[string]interface{}{
+		"source":        source.GetID(),
+		"country":       string(country),
+		"documentType":  string(documentType),
+		"payloadLength": len(payload),
+	})
 
 	// Mocked: Always return a successful response
 	response := &SubmissionResponseOld{
@@ -136,19 +355,34 @@ func (a *APIClient) SendPayload(payload string, source *Source, country Country,
 		Status:       SubmissionStatusSubmitted,
 		Error:        nil,
 	}
-	log.Printf("Payload submitted successfully with ID: %s", response.GetSubmissionID())
+	a.logger.Info("Payload submitted successfully", map[string]interface{}{
+		"submissionId": response.GetSubmissionID(),
+	})
 	return response, nil
 }
 
 // SendUnifyRequest Send UnifyRequest matching Python SDK
 func (a *APIClient) SendUnifyRequest(request *UnifyRequest) (*UnifyResponse, error) {
+	return a.SendUnifyRequestContext(context.Background(), request)
+}
+
+// SendUnifyRequestContext Send UnifyRequest, tracing it as a child of any span in ctx
+// and giving up between retries once ctx is done
+func (a *APIClient) SendUnifyRequestContext(ctx context.Context, request *UnifyRequest) (*UnifyResponse, error) {
+	// Fix the idempotency key before the first attempt so every retry reuses it
+	request.EnsureIdempotencyKey()
+
+	ctx, span := a.startSpan(ctx, SpanUnifyRequest, unifyRequestAttributes(request)...)
+
 	// Execute the request with retry logic
-	result, err := a.retryStrategy.Execute(
+	result, err := a.retryStrategy.ExecuteContext(
+		ctx,
 		func() (interface{}, error) {
-			return a.sendUnifyRequestInternal(request)
+			return a.sendUnifyRequestInternal(ctx, request)
 		},
 		fmt.Sprintf("unify-request-%s", request.GetSource().GetID()),
 	)
+	endSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -156,18 +390,28 @@ func (a *APIClient) SendUnifyRequest(request *UnifyRequest) (*UnifyResponse, err
 }
 
 // sendUnifyRequestInternal Internal method to send UnifyRequest
-func (a *APIClient) sendUnifyRequestInternal(request *UnifyRequest) (*UnifyResponse, error) {
+func (a *APIClient) sendUnifyRequestInternal(ctx context.Context, request *UnifyRequest) (*UnifyResponse, error) {
+	if request.GetTimeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, request.GetTimeout())
+		defer cancel()
+	}
+
+	_, serializeSpan := a.startSpan(ctx, SpanUnifySerialize)
 	requestData := a.serializeRequest(request)
 	jsonPayload, err := json.Marshal(requestData)
 	if err != nil {
-		return nil, NewSDKError(NewErrorDetailWithCode(
+		serializeErr := NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeAPIError,
 			fmt.Sprintf("Failed to serialize request: %v", err),
 		))
+		endSpan(serializeSpan, serializeErr)
+		return nil, serializeErr
+	}
+	endSpan(serializeSpan, nil)
+	if err := a.checkPayloadSize(request, jsonPayload); err != nil {
+		return nil, err
 	}
-
-	// Essential request info
-	log.Printf("📤 API Request URL: %s", a.baseURL)
 
 	headers := map[string]string{
 		"Content-Type":  "application/json",
@@ -181,27 +425,70 @@ func (a *APIClient) sendUnifyRequestInternal(request *UnifyRequest) (*UnifyRespo
 		headers["X-Correlation-ID"] = *request.GetCorrelationID()
 	}
 
-	// Log the request headers
-	log.Println("📤 API REQUEST HEADERS:")
-	for key, value := range headers {
-		if key == "Authorization" {
-			log.Printf("   %s: Bearer %s", key, value[7:]) // Hide the actual token
-		} else {
-			log.Printf("   %s: %s", key, value)
+	if request.GetIdempotencyKey() != nil {
+		headers["Idempotency-Key"] = *request.GetIdempotencyKey()
+	}
+
+	if tenantID := tenantIDFromContext(ctx); tenantID != "" {
+		headers[tenantIDHeader] = tenantID
+	}
+
+	a.signRequest(headers, jsonPayload)
+	body, err := a.compressRequestBody(headers, jsonPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Sending unify request", map[string]interface{}{
+		"url":       a.baseURL,
+		"requestId": *request.GetRequestID(),
+	})
+	a.logger.Debug("Unify request details", map[string]interface{}{
+		"headers": RedactHeaders(headers),
+		"request": requestData,
+	})
+
+	resp, responseBody, err := a.postUnifyRequest(ctx, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	responseCode := resp.StatusCode
+	responseBodyStr := string(responseBody)
+
+	a.logger.Info("Received unify response", map[string]interface{}{"httpStatus": responseCode})
+	a.logger.Debug("Unify response body", map[string]interface{}{"body": responseBodyStr})
+
+	_, deserializeSpan := a.startSpan(ctx, SpanUnifyDeserialize)
+	response, err := a.handleResponse(responseCode, responseBodyStr, resp)
+	if err == nil {
+		a.populateCorrelationMetadata(response, resp.Header, request.GetCorrelationID())
+		if operation := request.GetOperation(); operation != nil && *operation == OperationBulk {
+			a.populateBulkSubmission(response, responseBodyStr, request)
+		}
+		if a.recordRequestJSON {
+			if audit, auditErr := auditRequestJSON(requestData); auditErr == nil {
+				response.Metadata[MetadataKeyRequestJSON] = string(audit)
+			}
 		}
 	}
+	endSpan(deserializeSpan, err)
+	return response, err
+}
 
-	// Log the complete request payload
-	log.Println("📤 API REQUEST PAYLOAD:")
-	var prettyPayload map[string]interface{}
-	json.Unmarshal(jsonPayload, &prettyPayload)
-	prettyJSON, _ := json.MarshalIndent(prettyPayload, "", "  ")
-	log.Println(string(prettyJSON))
+// postUnifyRequest POST the serialized (and possibly compressed) request and read
+// the whole response body inside the HTTP span
+func (a *APIClient) postUnifyRequest(ctx context.Context, body []byte, headers map[string]string) (resp *http.Response, responseBody []byte, err error) {
+	parent := trace.SpanFromContext(ctx)
+	ctx, span := a.startSpan(ctx, SpanUnifyHTTP)
+	defer func() {
+		endSpan(span, err)
+	}()
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", a.baseURL, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, NewSDKError(NewErrorDetailWithCode(
+		return nil, nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
 			fmt.Sprintf("Failed to create HTTP request: %v", err),
 		))
@@ -213,35 +500,56 @@ func (a *APIClient) sendUnifyRequestInternal(request *UnifyRequest) (*UnifyRespo
 	}
 
 	// Send request
-	resp, err := a.httpClient.Do(req)
+	resp, err = a.doRequest(req)
+	if err != nil && ctx.Err() != nil {
+		a.logger.Error("API request cancelled", map[string]interface{}{"error": err.Error()})
+		contextErr := newContextSDKError(ctx.Err())
+		// A per-request timeout only ends this attempt; the retry strategy
+		// still stops once the caller's own context is done
+		contextErr.ErrorDetail.Retryable = ctx.Err() == context.DeadlineExceeded
+		return nil, nil, contextErr
+	}
 	if err != nil {
-		log.Printf("Network error during API request: %v", err)
+		a.logger.Error("Network error during API request", map[string]interface{}{"error": err.Error()})
 		errorDetail := NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
 			fmt.Sprintf("Network error: %v", err),
 		)
 		errorDetail.Suggestion = &[]string{"Check your network connection and try again"}[0]
 		errorDetail.Retryable = true
-		return nil, NewSDKError(errorDetail)
+		return nil, nil, NewSDKError(errorDetail)
 	}
 	defer resp.Body.Close()
 
-	responseBody, err := io.ReadAll(resp.Body)
+	statusAttribute := AttributeHTTPStatusCode.Int(resp.StatusCode)
+	span.SetAttributes(statusAttribute)
+	parent.SetAttributes(statusAttribute)
+
+	responseBody, err = a.readResponseBody(resp.Body)
 	if err != nil {
-		return nil, NewSDKError(NewErrorDetailWithCode(
-			ErrorCodeAPIError,
-			fmt.Sprintf("Failed to read response body: %v", err),
-		))
+		return nil, nil, err
 	}
+	return resp, responseBody, nil
+}
 
-	responseCode := resp.StatusCode
-	responseBodyStr := string(responseBody)
-
-	log.Printf("📥 API Response: %d %s", responseCode, resp.Status)
-	log.Println("📥 RAW API RESPONSE:")
-	log.Println(responseBodyStr)
+// SetRecordRequestJSON Attach the serialized request to each UnifyResponse's
+// metadata (see UnifyResponse.GetRequestJSON) so it can be archived
+func (a *APIClient) SetRecordRequestJSON(enabled bool) {
+	a.recordRequestJSON = enabled
+}
 
-	return a.handleResponse(responseCode, responseBodyStr, resp)
+// auditRequestJSON Serialized request with the API key redacted. Map keys are
+// marshalled in sorted order, so apart from the key this is byte-for-byte the
+// body that is sent.
+func auditRequestJSON(requestData map[string]interface{}) ([]byte, error) {
+	redacted := make(map[string]interface{}, len(requestData))
+	for key, value := range requestData {
+		redacted[key] = value
+	}
+	if _, ok := redacted["apiKey"]; ok {
+		redacted["apiKey"] = RedactedValue
+	}
+	return json.Marshal(redacted)
 }
 
 // serializeRequest Serialize UnifyRequest to dictionary
@@ -365,6 +673,27 @@ func (a *APIClient) serializeDestinationDetails(details *DestinationDetails) map
 	if details.ProcessID != nil {
 		result["processId"] = *details.ProcessID
 	}
+	if details.URL != nil {
+		result["url"] = *details.URL
+	}
+	if details.Method != nil {
+		result["method"] = *details.Method
+	}
+	if len(details.Headers) > 0 {
+		result["headers"] = details.Headers
+	}
+	if details.RetentionYears != nil {
+		result["retentionYears"] = *details.RetentionYears
+	}
+	if details.StorageClass != nil {
+		result["storageClass"] = strings.ToUpper(strings.TrimSpace(*details.StorageClass))
+	}
+	if details.Bucket != nil {
+		result["bucket"] = *details.Bucket
+	}
+	if details.Prefix != nil {
+		result["prefix"] = *details.Prefix
+	}
 
 	return result
 }
@@ -372,23 +701,31 @@ func (a *APIClient) serializeDestinationDetails(details *DestinationDetails) map
 // handleResponse Handle HTTP response
 func (a *APIClient) handleResponse(responseCode int, responseBody string, resp *http.Response) (*UnifyResponse, error) {
 	if responseCode >= 200 && responseCode < 300 {
-		return a.handleSuccessResponse(responseBody)
+		return a.handleSuccessResponse(responseBody, responseContentType(resp))
 	} else {
 		return a.handleErrorResponse(responseCode, responseBody, resp)
 	}
 }
 
-// handleSuccessResponse Handle successful response
-func (a *APIClient) handleSuccessResponse(responseBody string) (*UnifyResponse, error) {
-	// Log the complete raw response
-	log.Println("📥 API RAW RESPONSE:")
-	log.Println(responseBody)
-
+// handleSuccessResponse Handle successful response. A body that is not JSON
+// and was not sent with a JSON Content-Type, such as a PDF or a plain "OK", is
+// not an error: the response has status "success" and the raw body and content
+// type in its metadata.
+func (a *APIClient) handleSuccessResponse(responseBody string, contentType string) (*UnifyResponse, error) {
 	var responseData map[string]interface{}
 	err := json.Unmarshal([]byte(responseBody), &responseData)
+	if err != nil && contentType != "" && !isJSONContentType(contentType) {
+		a.logger.Info("API request completed successfully with a non-JSON response", map[string]interface{}{"contentType": contentType})
+		return &UnifyResponse{
+			Status: "success",
+			Metadata: map[string]interface{}{
+				MetadataKeyRawBody:     responseBody,
+				MetadataKeyContentType: contentType,
+			},
+		}, nil
+	}
 	if err != nil {
-		log.Printf("Failed to parse successful API response: %v", err)
-		log.Printf("Raw response body: %s", responseBody)
+		a.logger.Error("Failed to parse successful API response", map[string]interface{}{"error": err.Error()})
 
 		errorDetail := NewErrorDetailWithCode(
 			ErrorCodeAPIError,
@@ -402,11 +739,11 @@ func (a *APIClient) handleSuccessResponse(responseBody string) (*UnifyResponse,
 
 	// Convert dict to UnifyResponse object
 	unifyResponse := a.deserializeUnifyResponse(responseData)
-	log.Printf("API request completed successfully with status: %s", unifyResponse.GetStatus())
+	a.logger.Info("API request completed successfully", map[string]interface{}{"status": unifyResponse.GetStatus()})
 
 	// Validate response structure
 	if unifyResponse.GetData() == nil {
-		log.Println("Response data is null, this might indicate an issue")
+		a.logger.Warn("Response data is null, this might indicate an issue", nil)
 	}
 
 	return unifyResponse, nil
@@ -430,6 +767,17 @@ func (a *APIClient) deserializeUnifyResponse(data map[string]interface{}) *Unify
 		response.Metadata = metadata
 	}
 
+	// Correlation identifiers may also be echoed at the top level of the body
+	for _, source := range correlationSources {
+		for _, key := range source.bodyKeys {
+			if _, exists := response.Metadata[key]; !exists {
+				if value, ok := data[key].(string); ok {
+					response.Metadata[key] = value
+				}
+			}
+		}
+	}
+
 	// Handle error
 	if errorData, ok := data["error"].(map[string]interface{}); ok {
 		errorDetail := NewErrorDetail()
@@ -477,18 +825,141 @@ func (a *APIClient) deserializeUnifyResponse(data map[string]interface{}) *Unify
 			responseData.Source = sourceResp
 		}
 
-		// Add other response handlers here as needed...
+		// Remaining sections map directly onto their response models
+		a.decodeResponseSection(dataDict["payload"], &responseData.Payload)
+		a.decodeResponseSection(dataDict["template"], &responseData.Template)
+		a.decodeResponseSection(dataDict["logical_document_type"], &responseData.LogicalDocumentType)
+		a.decodeResponseSection(dataDict["conversion"], &responseData.Conversion)
+		a.decodeResponseSection(dataDict["document"], &responseData.Document)
+		a.decodeResponseSection(dataDict["validation"], &responseData.Validation)
+		a.decodeResponseSection(dataDict["submission"], &responseData.Submission)
+		a.decodeResponseSection(dataDict["processing"], &responseData.Processing)
+		a.decodeResponseSection(dataDict["destinations"], &responseData.Destinations)
+
 		response.Data = responseData
 	}
 
 	return response
 }
 
+// correlationSources Body keys and response headers each correlation metadata key is read from
+var correlationSources = []struct {
+	metadataKey string
+	bodyKeys    []string
+	headers     []string
+}{
+	{MetadataKeyCorrelationID, []string{"correlationId", "correlation_id"}, []string{"X-Correlation-ID"}},
+	{MetadataKeyTraceID, []string{"traceId", "trace_id"}, []string{"X-Trace-ID", "traceparent"}},
+	{MetadataKeyRequestID, []string{"requestId", "request_id"}, []string{"X-Request-ID"}},
+}
+
+// populateCorrelationMetadata Record the correlation, trace and request IDs the server
+// echoed back in the response metadata. Values from the body take precedence over
+// headers; the correlation ID falls back to the one that was sent.
+func (a *APIClient) populateCorrelationMetadata(response *UnifyResponse, headers http.Header, sentCorrelationID *string) {
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	for _, source := range correlationSources {
+		if value := correlationBodyValue(response.Metadata, source.bodyKeys); value != "" {
+			response.Metadata[source.metadataKey] = value
+			continue
+		}
+		for _, header := range source.headers {
+			value := strings.TrimSpace(headers.Get(header))
+			if header == "traceparent" {
+				value = traceIDFromTraceparent(value)
+			}
+			if value != "" {
+				response.Metadata[source.metadataKey] = value
+				break
+			}
+		}
+	}
+	if _, ok := response.Metadata[MetadataKeyCorrelationID]; !ok && sentCorrelationID != nil && *sentCorrelationID != "" {
+		response.Metadata[MetadataKeyCorrelationID] = *sentCorrelationID
+	}
+}
+
+// correlationBodyValue First non-empty string among keys in the response metadata
+func correlationBodyValue(metadata map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if value, ok := metadata[key].(string); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// traceIDFromTraceparent Extract the trace ID from a W3C traceparent header
+func traceIDFromTraceparent(value string) string {
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
+
+// decodeResponseSection Decode a generic response section into its typed model.
+// Sections that are absent or do not match the model are left nil.
+func (a *APIClient) decodeResponseSection(section interface{}, target interface{}) {
+	sectionDict, ok := section.(map[string]interface{})
+	if !ok {
+		return
+	}
+	raw, err := json.Marshal(sectionDict)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		a.logger.Warn("Failed to decode response section", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// parseRetryAfter Parse a Retry-After header value given either as delta-seconds
+// or as an HTTP-date. Dates in the past yield zero.
+func parseRetryAfter(value string, now time.Time) (int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return seconds, true
+	}
+	retryAt, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := retryAt.Sub(now)
+	if wait <= 0 {
+		return 0, true
+	}
+	return int(math.Ceil(wait.Seconds())), true
+}
+
+// responseContentType Content-Type header of resp, empty when there is none
+func responseContentType(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	return strings.TrimSpace(resp.Header.Get("Content-Type"))
+}
+
+// isJSONContentType Check if a Content-Type is application/json or a +json type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
 // handleErrorResponse Handle error response
 func (a *APIClient) handleErrorResponse(responseCode int, responseBody string, resp *http.Response) (*UnifyResponse, error) {
-	log.Printf("❌ API request failed with HTTP %d", responseCode)
-	log.Println("📥 API ERROR RESPONSE:")
-	log.Println(responseBody)
+	a.logger.Error("API request failed", map[string]interface{}{"httpStatus": responseCode})
 
 	// Try to parse error response as JSON first
 	errorDetail := a.parseErrorResponse(responseCode, responseBody)
@@ -514,8 +985,11 @@ func (a *APIClient) handleErrorResponse(responseCode int, responseBody string, r
 		errorDetail.Code = &[]ErrorCode{ErrorCodeRateLimitExceeded}[0]
 		errorDetail.Suggestion = &[]string{"Too many requests. Please wait before retrying"}[0]
 		errorDetail.Retryable = true
-		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-			// Parse retry after header if needed
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), a.clock.Now()); ok {
+				errorDetail.RetryAfterSeconds = &retryAfter
+				errorDetail.AddContextValue("retryAfterSeconds", retryAfter)
+			}
 		}
 	case 500:
 		errorDetail.Code = &[]ErrorCode{ErrorCodeInternalServerError}[0]
@@ -532,7 +1006,7 @@ func (a *APIClient) handleErrorResponse(responseCode int, responseBody string, r
 		}
 	}
 
-	return nil, NewSDKError(errorDetail)
+	return nil, newResponseSDKError(errorDetail, responseCode)
 }
 
 // parseErrorResponse Parse error response
@@ -565,24 +1039,18 @@ func (a *APIClient) parseErrorResponse(responseCode int, responseBody string) *E
 				errorDetail.Retryable = retryable
 			}
 
-			// Parse validation errors if present
-			if validationErrors, ok := errorNode["validationErrors"].([]interface{}); ok {
-				for _, validationError := range validationErrors {
-					if ve, ok := validationError.(map[string]interface{}); ok {
-						field := ""
-						message := ""
-						code := ""
-						if f, ok := ve["field"].(string); ok {
-							field = f
-						}
-						if m, ok := ve["message"].(string); ok {
-							message = m
-						}
-						if c, ok := ve["code"].(string); ok {
-							code = c
-						}
-						errorDetail.AddValidationError(field, message, code)
-					}
+			// Parse validation errors if present, into both the raw and typed forms
+			validationErrors, ok := errorNode["validationErrors"].([]interface{})
+			if !ok {
+				validationErrors, _ = errorNode["validation_errors"].([]interface{})
+			}
+			for _, validationError := range validationErrors {
+				if ve, ok := validationError.(map[string]interface{}); ok {
+					field, _ := ve["field"].(string)
+					message, _ := ve["message"].(string)
+					code, _ := ve["code"].(string)
+					errorDetail.AddValidationError(field, message, code)
+					errorDetail.AddValidationErrorModel(parseValidationErrorModel(ve))
 				}
 			}
 		}
@@ -591,11 +1059,39 @@ func (a *APIClient) parseErrorResponse(responseCode int, responseBody string) *E
 	return errorDetail
 }
 
+// parseValidationErrorModel Build a typed validation error from a server
+// validation error entry. The path comes from "path" when the server sends
+// one, otherwise from the dotted "field".
+func parseValidationErrorModel(ve map[string]interface{}) *ValidationErrorModel {
+	model := &ValidationErrorModel{}
+	if m, ok := ve["method"].(string); ok {
+		model.Method = &m
+	}
+	if m, ok := ve["message"].(string); ok {
+		model.Message = &m
+	}
+	if c, ok := ve["code"].(string); ok {
+		model.Code = &c
+	}
+	if path, ok := ve["path"].([]interface{}); ok {
+		for _, segment := range path {
+			switch v := segment.(type) {
+			case string:
+				model.Path = append(model.Path, v)
+			case float64:
+				model.Path = append(model.Path, strconv.FormatFloat(v, 'f', -1, 64))
+			}
+		}
+	} else if f, ok := ve["field"].(string); ok && f != "" {
+		model.Path = strings.Split(f, ".")
+	}
+	return model
+}
+
 // SendRawJSONRequest Send raw JSON request directly without deserialization
 func (a *APIClient) SendRawJSONRequest(jsonPayload string) (*UnifyResponse, error) {
-	log.Println("🔥 RAW JSON: Sending raw JSON request")
-	log.Printf("🔥 RAW JSON: JSON length: %d", len(jsonPayload))
-	log.Printf("🔥 RAW JSON: JSON preview: %s", jsonPayload[:min(200, len(jsonPayload))])
+	a.logger.Info("Sending raw JSON request", map[string]interface{}{"length": len(jsonPayload)})
+	a.logger.Debug("Raw JSON request body", map[string]interface{}{"request": jsonPayload})
 
 	result, err := a.retryStrategy.Execute(
 		func() (interface{}, error) {
@@ -615,8 +1111,13 @@ func (a *APIClient) sendRawJSONRequestInternal(jsonPayload string) (*UnifyRespon
 		"Content-Type": "application/json",
 		"Accept":       "application/json",
 	}
+	a.signRequest(headers, []byte(jsonPayload))
+	body, err := a.compressRequestBody(headers, []byte(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
 
-	req, err := http.NewRequest("POST", a.baseURL, strings.NewReader(jsonPayload))
+	req, err := http.NewRequest("POST", a.baseURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
@@ -628,9 +1129,9 @@ func (a *APIClient) sendRawJSONRequestInternal(jsonPayload string) (*UnifyRespon
 		req.Header.Set(key, value)
 	}
 
-	resp, err := a.httpClient.Do(req)
+	resp, err := a.doRequest(req)
 	if err != nil {
-		log.Printf("Network error during raw JSON API request: %v", err)
+		a.logger.Error("Network error during raw JSON API request", map[string]interface{}{"error": err.Error()})
 		errorDetail := NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
 			fmt.Sprintf("Network error: %v", err),
@@ -641,25 +1142,26 @@ func (a *APIClient) sendRawJSONRequestInternal(jsonPayload string) (*UnifyRespon
 	}
 	defer resp.Body.Close()
 
-	responseBody, err := io.ReadAll(resp.Body)
+	responseBody, err := a.readResponseBody(resp.Body)
 	if err != nil {
-		return nil, NewSDKError(NewErrorDetailWithCode(
-			ErrorCodeAPIError,
-			fmt.Sprintf("Failed to read response body: %v", err),
-		))
+		return nil, err
 	}
 
 	responseCode := resp.StatusCode
 	responseBodyStr := string(responseBody)
 
-	log.Printf("🔥 RAW JSON: API Response Code: %d", responseCode)
-	log.Printf("🔥 RAW JSON: API Response Body: %s", responseBodyStr)
+	a.logger.Info("Received raw JSON response", map[string]interface{}{"httpStatus": responseCode})
+	a.logger.Debug("Raw JSON response body", map[string]interface{}{"body": responseBodyStr})
 
 	if responseCode >= 200 && responseCode < 300 {
-		return a.handleSuccessResponse(responseBodyStr)
+		response, err := a.handleSuccessResponse(responseBodyStr, responseContentType(resp))
+		if err == nil {
+			a.populateCorrelationMetadata(response, resp.Header, nil)
+		}
+		return response, err
 	} else {
 		errorDetail := a.parseErrorResponse(responseCode, responseBodyStr)
-		return nil, NewSDKError(errorDetail)
+		return nil, newResponseSDKError(errorDetail, responseCode)
 	}
 }
 
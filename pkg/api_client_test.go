@@ -0,0 +1,336 @@
+package complyancesdk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestUnifyRequest(invoiceNumber string) *UnifyRequest {
+	return NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload(invoiceNumber)).
+		APIKey("test-key").
+		Build()
+}
+
+func TestIdempotencyKeyIsStableAcrossRetries(t *testing.T) {
+	var keys []string
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	retryConfig := NewDefaultRetryConfig()
+	retryConfig.BaseDelayMs = 1
+	client.retryStrategy = NewRetryStrategy(retryConfig)
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-100")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Fatalf("expected the same idempotency key on every attempt, got %v", keys)
+	}
+}
+
+func TestIdempotencyKeyGeneration(t *testing.T) {
+	first := newTestUnifyRequest("INV-200").EnsureIdempotencyKey()
+	second := newTestUnifyRequest("INV-200").EnsureIdempotencyKey()
+	other := newTestUnifyRequest("INV-201").EnsureIdempotencyKey()
+	if first != second {
+		t.Fatalf("expected the same document to produce the same key, got %s and %s", first, second)
+	}
+	if first == other {
+		t.Fatalf("expected different documents to produce different keys")
+	}
+
+	validation := newTestUnifyRequest("INV-200")
+	validation.SetPurpose(PurposeMapping)
+	creditNote := newTestUnifyRequest("INV-200")
+	creditNote.SetDocumentTypeString("credit_note")
+	if validation.EnsureIdempotencyKey() == first || creditNote.EnsureIdempotencyKey() == first {
+		t.Fatalf("expected another purpose or document type of the same number to produce another key")
+	}
+
+	explicit := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		Payload(testInvoicePayload("INV-200")).
+		IdempotencyKey("my-key").
+		Build()
+	if explicit.EnsureIdempotencyKey() != "my-key" {
+		t.Fatalf("expected explicit idempotency key to be kept, got %s", *explicit.GetIdempotencyKey())
+	}
+
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil)
+	cfg.SetInvoiceDataPath("document.header")
+	keyFor := func(number string) string {
+		request := NewUnifyRequestBuilder().
+			Source(NewSource("src", "1", nil)).
+			Country("SA").
+			Payload(map[string]interface{}{"document": map[string]interface{}{"header": map[string]interface{}{"invoice_number": number}}}).
+			Build()
+		return request.ensureIdempotencyKey(cfg.documentIDPathsFor(DocumentTypeTaxInvoice))
+	}
+	if keyFor("A-1") != keyFor("A-1") || keyFor("A-1") == keyFor("A-2") {
+		t.Fatalf("expected the document number to be read below the configured invoice data path")
+	}
+}
+
+func rateLimitedResponse(retryAfter string) *http.Response {
+	header := http.Header{}
+	header.Set("Retry-After", retryAfter)
+	return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}
+}
+
+func TestRateLimitParsesRetryAfterSeconds(t *testing.T) {
+	client := NewAPIClient("test-key", EnvironmentSandbox, NewNoRetryConfig())
+	_, err := client.handleErrorResponse(429, `{}`, rateLimitedResponse("7"))
+
+	detail := err.(*SDKError).GetErrorDetail()
+	if detail.RetryAfterSeconds == nil || *detail.RetryAfterSeconds != 7 {
+		t.Fatalf("expected RetryAfterSeconds=7, got %v", detail.RetryAfterSeconds)
+	}
+}
+
+func TestRateLimitParsesRetryAfterHTTPDate(t *testing.T) {
+	client := NewAPIClient("test-key", EnvironmentSandbox, NewNoRetryConfig())
+	retryAt := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	_, err := client.handleErrorResponse(429, `{}`, rateLimitedResponse(retryAt))
+
+	detail := err.(*SDKError).GetErrorDetail()
+	if detail.RetryAfterSeconds == nil || *detail.RetryAfterSeconds < 88 || *detail.RetryAfterSeconds > 90 {
+		t.Fatalf("expected RetryAfterSeconds close to 90, got %v", detail.RetryAfterSeconds)
+	}
+
+	if seconds, ok := parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT", time.Now()); !ok || seconds != 0 {
+		t.Fatalf("expected a past date to yield 0, got %d (%t)", seconds, ok)
+	}
+	if _, ok := parseRetryAfter("soon", time.Now()); ok {
+		t.Fatalf("expected an invalid value to be ignored")
+	}
+}
+
+func TestCorrelationIdentifiersArePopulatedFromResponse(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Correlation-ID", r.Header.Get("X-Correlation-ID"))
+		w.Header().Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, _ = w.Write([]byte(`{"status":"success","request_id":"srv-req-9","metadata":{"correlation_id":"corr-from-body"}}`))
+	})
+	request := newTestUnifyRequest("INV-CORR")
+	request.SetCorrelationID("corr-from-client")
+
+	response, err := client.SendUnifyRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := response.GetCorrelationID(); got == nil || *got != "corr-from-body" {
+		t.Fatalf("expected body correlation ID to win, got %v", got)
+	}
+	if got := response.GetTraceID(); got == nil || *got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected trace ID from traceparent, got %v", got)
+	}
+	if got := response.GetRequestID(); got == nil || *got != "srv-req-9" {
+		t.Fatalf("expected request ID from body, got %v", got)
+	}
+}
+
+func TestCorrelationIDFallsBackToHeaderThenRequest(t *testing.T) {
+	echo := true
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if echo {
+			w.Header().Set("X-Correlation-ID", r.Header.Get("X-Correlation-ID"))
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	request := newTestUnifyRequest("INV-CORR-2")
+	request.SetCorrelationID("corr-echoed")
+
+	response, err := client.SendUnifyRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := response.GetCorrelationID(); got == nil || *got != "corr-echoed" {
+		t.Fatalf("expected echoed header correlation ID, got %v", got)
+	}
+
+	echo = false
+	request.SetCorrelationID("corr-sent")
+	response, err = client.SendUnifyRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := response.GetMetadata()[MetadataKeyCorrelationID]; got != "corr-sent" {
+		t.Fatalf("expected sent correlation ID as fallback, got %v", got)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.requests++
+	r.Header.Set("X-Injected-Transport", "yes")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestConfigureUsesInjectedHTTPClient(t *testing.T) {
+	transport := &countingTransport{}
+	var injectedHeader string
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetHTTPClient(&http.Client{Transport: transport})
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		injectedHeader = r.Header.Get("X-Injected-Transport")
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-CLIENT"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transport.requests != 1 || injectedHeader != "yes" {
+		t.Fatalf("expected the request to go through the injected transport, got %d requests", transport.requests)
+	}
+	if globalSDK.apiClient.GetHTTPClient() != cfg.HTTPClient {
+		t.Fatalf("expected the API client to keep the injected client")
+	}
+
+	client := NewAPIClient("test-key", EnvironmentSandbox, NewNoRetryConfig())
+	client.SetHTTPClient(nil)
+	if client.GetHTTPClient().Timeout != DefaultTimeout {
+		t.Fatalf("expected nil to restore the default client")
+	}
+}
+
+func TestPerRequestTimeoutFiresBeforeClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	t.Cleanup(func() { close(release) })
+
+	request := newTestUnifyRequest("INV-TIMEOUT")
+	request.SetTimeout(20 * time.Millisecond)
+	started := time.Now()
+	_, err := client.SendUnifyRequest(request)
+	sdkErr, ok := err.(*SDKError)
+	if !ok {
+		t.Fatalf("expected SDKError, got %v", err)
+	}
+	if original, _ := sdkErr.ErrorDetail.GetContextValue("originalError").(string); !strings.Contains(original, string(ErrorCodeTimeoutError)) {
+		t.Fatalf("expected the attempt to fail with a timeout, got %v", sdkErr.ErrorDetail.Context)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("per-request timeout did not fire, took %s", elapsed)
+	}
+
+	// The shorter of the client and request timeouts wins
+	client.SetHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})
+	request = newTestUnifyRequest("INV-TIMEOUT-2")
+	request.SetTimeout(time.Minute)
+	started = time.Now()
+	if _, err := client.SendUnifyRequest(request); err == nil {
+		t.Fatalf("expected the client timeout to fire")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("client timeout did not win, took %s", elapsed)
+	}
+}
+
+func TestUnprocessableEntityExposesTypedValidationErrors(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{
+			"status": "error",
+			"error": {
+				"code": "VALIDATION_FAILED",
+				"message": "Invoice failed validation",
+				"validationErrors": [
+					{"field": "invoice_data.seller_vat", "message": "VAT number must be 15 digits", "code": "INVALID_FORMAT", "method": "schema"},
+					{"path": ["line_items", 0, "quantity"], "message": "Quantity must be positive", "code": "OUT_OF_RANGE", "method": "business_rules"}
+				]
+			}
+		}`))
+	})
+
+	_, err := client.SendUnifyRequest(newTestUnifyRequest("INV-422"))
+	sdkErr, ok := err.(*SDKError)
+	if !ok {
+		t.Fatalf("expected SDKError, got %v", err)
+	}
+
+	typed := sdkErr.ValidationErrors()
+	if len(typed) != 2 {
+		t.Fatalf("expected 2 typed validation errors, got %d", len(typed))
+	}
+	if got := strings.Join(typed[0].GetPath(), "."); got != "invoice_data.seller_vat" {
+		t.Fatalf("unexpected path from field: %q", got)
+	}
+	if got := typed[0].GetMethod(); got == nil || *got != "schema" {
+		t.Fatalf("unexpected method: %v", got)
+	}
+	if got := strings.Join(typed[1].GetPath(), "."); got != "line_items.0.quantity" {
+		t.Fatalf("unexpected path from path array: %q", got)
+	}
+	if got := typed[1].GetCode(); got == nil || *got != "OUT_OF_RANGE" {
+		t.Fatalf("unexpected code: %v", got)
+	}
+
+	var inner *SDKError
+	if !errors.As(sdkErr.Unwrap(), &inner) {
+		t.Fatalf("expected the retry error to wrap the 422 error")
+	}
+	raw := inner.ErrorDetail.ValidationErrors
+	if len(raw) != 2 || raw[0]["field"] != "invoice_data.seller_vat" || raw[0]["code"] != "INVALID_FORMAT" || raw[1]["message"] != "Quantity must be positive" {
+		t.Fatalf("raw validation errors not preserved: %v", raw)
+	}
+	if methods := inner.ErrorDetail.Validation.GetMethods(); len(methods) != 2 {
+		t.Fatalf("expected both validation methods, got %v", methods)
+	}
+}
+
+func TestNonJSONSuccessResponseKeepsRawBody(t *testing.T) {
+	bodies := map[string]string{
+		"text/plain; charset=utf-8": "OK",
+		"application/pdf":           "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n",
+	}
+	for contentType, body := range bodies {
+		client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write([]byte(body))
+		})
+
+		response, err := client.SendUnifyRequest(newTestUnifyRequest("INV-RAW"))
+		if err != nil {
+			t.Fatalf("%s: expected no parse error, got %v", contentType, err)
+		}
+		if !response.IsSuccess() || string(response.GetRawBody()) != body || response.GetMetadata()[MetadataKeyContentType] != contentType {
+			t.Fatalf("%s: unexpected response %+v", contentType, response)
+		}
+	}
+}
+
+func TestInvalidJSONSuccessResponseIsStillAnError(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("OK"))
+	})
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-BAD")); err == nil {
+		t.Fatalf("expected a parse error for an invalid JSON body")
+	}
+}
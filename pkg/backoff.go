@@ -0,0 +1,199 @@
+/*
+Backoff strategies for the delay between retry attempts.
+*/
+package complyancesdk
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the built-in backoff strategies, used by RetryConfig.BackoffStrategyName
+const (
+	BackoffExponential        = "exponential"
+	BackoffLinear             = "linear"
+	BackoffFullJitter         = "full_jitter"
+	BackoffDecorrelatedJitter = "decorrelated_jitter"
+)
+
+// BackoffStrategy Delay before a retry attempt
+type BackoffStrategy interface {
+	// Name identifies the strategy
+	Name() string
+	// NextDelayMs returns the delay in milliseconds before attempt (1 for the
+	// first retry). previousDelayMs is the delay before the previous attempt,
+	// 0 before the first retry, and random returns values in [0, 1).
+	NextDelayMs(config *RetryConfig, attempt int, previousDelayMs float64, random func() float64) float64
+}
+
+// ExponentialBackoff BaseDelayMs multiplied by BackoffMultiplier for every
+// further attempt, capped at MaxDelayMs and varied by up to ±JitterFactor
+type ExponentialBackoff struct{}
+
+// Name of the strategy
+func (ExponentialBackoff) Name() string { return BackoffExponential }
+
+// NextDelayMs Exponential delay before attempt
+func (ExponentialBackoff) NextDelayMs(config *RetryConfig, attempt int, previousDelayMs float64, random func() float64) float64 {
+	delay := math.Min(
+		float64(config.MaxDelayMs),
+		float64(config.BaseDelayMs)*math.Pow(config.BackoffMultiplier, float64(attempt-1)),
+	)
+	return applyJitterFactor(config, delay, random)
+}
+
+// LinearBackoff BaseDelayMs times the attempt number, capped at MaxDelayMs
+// and varied by up to ±JitterFactor
+type LinearBackoff struct{}
+
+// Name of the strategy
+func (LinearBackoff) Name() string { return BackoffLinear }
+
+// NextDelayMs Linear delay before attempt
+func (LinearBackoff) NextDelayMs(config *RetryConfig, attempt int, previousDelayMs float64, random func() float64) float64 {
+	delay := math.Min(float64(config.MaxDelayMs), float64(config.BaseDelayMs)*float64(attempt))
+	return applyJitterFactor(config, delay, random)
+}
+
+// FullJitterBackoff Random delay between 0 and the exponential delay.
+// JitterFactor is not used.
+type FullJitterBackoff struct{}
+
+// Name of the strategy
+func (FullJitterBackoff) Name() string { return BackoffFullJitter }
+
+// NextDelayMs Fully jittered delay before attempt
+func (FullJitterBackoff) NextDelayMs(config *RetryConfig, attempt int, previousDelayMs float64, random func() float64) float64 {
+	ceiling := math.Min(
+		float64(config.MaxDelayMs),
+		float64(config.BaseDelayMs)*math.Pow(config.BackoffMultiplier, float64(attempt-1)),
+	)
+	return random() * ceiling
+}
+
+// DecorrelatedJitterBackoff Random delay between BaseDelayMs and three times
+// the previous delay, capped at MaxDelayMs. Spreads out clients retrying at
+// the same time better than the other strategies. JitterFactor and
+// BackoffMultiplier are not used.
+type DecorrelatedJitterBackoff struct{}
+
+// Name of the strategy
+func (DecorrelatedJitterBackoff) Name() string { return BackoffDecorrelatedJitter }
+
+// NextDelayMs Decorrelated delay before attempt
+func (DecorrelatedJitterBackoff) NextDelayMs(config *RetryConfig, attempt int, previousDelayMs float64, random func() float64) float64 {
+	base := float64(config.BaseDelayMs)
+	upper := math.Max(base, 3*math.Max(previousDelayMs, base))
+	return math.Min(float64(config.MaxDelayMs), base+random()*(upper-base))
+}
+
+// backoffStrategies Built-in strategies by name
+var backoffStrategies = map[string]BackoffStrategy{
+	BackoffExponential:        ExponentialBackoff{},
+	BackoffLinear:             LinearBackoff{},
+	BackoffFullJitter:         FullJitterBackoff{},
+	BackoffDecorrelatedJitter: DecorrelatedJitterBackoff{},
+}
+
+// BackoffStrategyByName Built-in strategy called name, ignoring case, nil when
+// there is none
+func BackoffStrategyByName(name string) BackoffStrategy {
+	return backoffStrategies[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// applyJitterFactor delay varied by a random amount of up to ±JitterFactor
+func applyJitterFactor(config *RetryConfig, delay float64, random func() float64) float64 {
+	if config.JitterFactor > 0 {
+		jitter := (random()*2 - 1) * config.JitterFactor // Random between -jitterFactor and +jitterFactor
+		delay = delay * (1 + jitter)
+	}
+	return delay
+}
+
+// backoffDelayMs Delay in milliseconds before an attempt using the configured
+// backoff strategy
+func backoffDelayMs(config *RetryConfig, attempt int, previousDelayMs float64) float64 {
+	if attempt <= 0 {
+		return 0
+	}
+	return math.Max(0, config.GetBackoffStrategy().NextDelayMs(config, attempt, previousDelayMs, config.jitterFloat64))
+}
+
+// GetBackoffStrategy Strategy used between attempts: Backoff when set, then
+// the built-in strategy named BackoffStrategyName, then ExponentialBackoff
+func (r *RetryConfig) GetBackoffStrategy() BackoffStrategy {
+	if r.Backoff != nil {
+		return r.Backoff
+	}
+	if strategy := BackoffStrategyByName(r.BackoffStrategyName); strategy != nil {
+		return strategy
+	}
+	return ExponentialBackoff{}
+}
+
+// SetBackoffStrategy Use strategy between attempts; nil restores the strategy
+// named by BackoffStrategyName
+func (r *RetryConfig) SetBackoffStrategy(strategy BackoffStrategy) {
+	r.Backoff = strategy
+	if strategy != nil {
+		r.BackoffStrategyName = strategy.Name()
+	}
+}
+
+// SetJitterSeed Seed the random numbers used for jitter, restarting the sequence
+func (r *RetryConfig) SetJitterSeed(seed int64) {
+	r.JitterSeed = &seed
+	r.RandomSource = nil
+	jitterRandomMu.Lock()
+	r.jitter = nil
+	jitterRandomMu.Unlock()
+}
+
+// jitterRandom Random numbers shared by every user of a RetryConfig, safe for
+// concurrent use
+type jitterRandom struct {
+	mu     sync.Mutex
+	random *rand.Rand
+	seed   *int64
+	source rand.Source
+}
+
+// jitterRandomMu Guards creating the jitterRandom of a RetryConfig
+var jitterRandomMu sync.Mutex
+
+// jitterFloat64 Next random number in [0, 1) from RandomSource, JitterSeed or
+// a time-seeded source. Changing either field starts a new sequence.
+func (r *RetryConfig) jitterFloat64() float64 {
+	jitterRandomMu.Lock()
+	current := r.jitter
+	if current == nil || !sameJitterSeed(current.seed, r.JitterSeed) || !sameInjectedValue(current.source, r.RandomSource) {
+		current = &jitterRandom{source: r.RandomSource}
+		switch {
+		case r.RandomSource != nil:
+			current.random = rand.New(r.RandomSource)
+		case r.JitterSeed != nil:
+			seed := *r.JitterSeed
+			current.seed = &seed
+			current.random = rand.New(rand.NewSource(seed))
+		default:
+			current.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		r.jitter = current
+	}
+	jitterRandomMu.Unlock()
+
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	return current.random.Float64()
+}
+
+// sameJitterSeed Whether both seeds are unset or equal
+func sameJitterSeed(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
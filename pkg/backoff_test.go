@@ -0,0 +1,104 @@
+package complyancesdk
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBackoffStrategiesStayWithinBounds(t *testing.T) {
+	config := NewDefaultRetryConfig()
+	config.BaseDelayMs = 100
+	config.MaxDelayMs = 2000
+	config.BackoffMultiplier = 2
+	config.JitterFactor = 0.1
+
+	cases := []struct {
+		strategy BackoffStrategy
+		// bounds returns the inclusive range of the delay before attempt
+		bounds func(attempt int, previous float64) (float64, float64)
+	}{
+		{ExponentialBackoff{}, func(attempt int, previous float64) (float64, float64) {
+			delay := math.Min(2000, 100*math.Pow(2, float64(attempt-1)))
+			return delay * 0.9, delay * 1.1
+		}},
+		{LinearBackoff{}, func(attempt int, previous float64) (float64, float64) {
+			delay := math.Min(2000, 100*float64(attempt))
+			return delay * 0.9, delay * 1.1
+		}},
+		{FullJitterBackoff{}, func(attempt int, previous float64) (float64, float64) {
+			return 0, math.Min(2000, 100*math.Pow(2, float64(attempt-1)))
+		}},
+		{DecorrelatedJitterBackoff{}, func(attempt int, previous float64) (float64, float64) {
+			return 100, math.Min(2000, 3*math.Max(previous, 100))
+		}},
+	}
+
+	for _, tc := range cases {
+		config.SetBackoffStrategy(tc.strategy)
+		random := rand.New(rand.NewSource(42))
+		previous := 0.0
+		for attempt := 1; attempt <= 8; attempt++ {
+			delay := config.GetBackoffStrategy().NextDelayMs(config, attempt, previous, random.Float64)
+			low, high := tc.bounds(attempt, previous)
+			if delay < low || delay > high {
+				t.Fatalf("%s attempt %d: delay %.2fms outside [%.2f, %.2f]", tc.strategy.Name(), attempt, delay, low, high)
+			}
+			previous = delay
+		}
+	}
+}
+
+func TestBackoffStrategyIsSelectedByName(t *testing.T) {
+	config := NewDefaultRetryConfig()
+	if got := config.GetBackoffStrategy().Name(); got != BackoffExponential {
+		t.Fatalf("expected exponential backoff by default, got %s", got)
+	}
+	for _, name := range []string{BackoffExponential, BackoffLinear, BackoffFullJitter, BackoffDecorrelatedJitter} {
+		config.BackoffStrategyName = name
+		if got := config.GetBackoffStrategy().Name(); got != name {
+			t.Fatalf("expected %s, got %s", name, got)
+		}
+	}
+
+	config.BackoffStrategyName = "LINEAR"
+	config.BaseDelayMs = 300
+	config.JitterFactor = 0
+	if delay := NewRetryStrategy(config).calculateDelay(3, 0); delay != 900 {
+		t.Fatalf("expected the retry strategy to use linear backoff, got %fms", delay)
+	}
+}
+
+func TestJitterSeedMakesDelaysReproducible(t *testing.T) {
+	config := NewDefaultRetryConfig()
+	config.BaseDelayMs = 100
+	config.MaxDelayMs = 10000
+	config.BackoffMultiplier = 2
+	config.JitterFactor = 0.5
+	config.SetJitterSeed(7)
+
+	expected := rand.New(rand.NewSource(7))
+	strategy := NewRetryStrategy(config)
+	delays := make([]float64, 3)
+	for attempt := 1; attempt <= 3; attempt++ {
+		want := 100 * math.Pow(2, float64(attempt-1)) * (1 + (expected.Float64()*2-1)*0.5)
+		delays[attempt-1] = strategy.calculateDelay(attempt, 0)
+		if delays[attempt-1] != want {
+			t.Fatalf("attempt %d: expected %vms, got %vms", attempt, want, delays[attempt-1])
+		}
+	}
+
+	config.SetJitterSeed(7)
+	for attempt := 1; attempt <= 3; attempt++ {
+		if delay := strategy.calculateDelay(attempt, 0); delay != delays[attempt-1] {
+			t.Fatalf("attempt %d: expected reseeding to repeat %vms, got %vms", attempt, delays[attempt-1], delay)
+		}
+	}
+
+	config.RandomSource = rand.NewSource(7)
+	for attempt := 1; attempt <= 3; attempt++ {
+		if delay := strategy.calculateDelay(attempt, 0); delay != delays[attempt-1] {
+			t.Fatalf("attempt %d: expected RandomSource to repeat %vms, got %vms", attempt, delays[attempt-1], delay)
+		}
+	}
+}
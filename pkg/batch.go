@@ -0,0 +1,418 @@
+/*
+Batch submission support for the Unify API.
+*/
+package complyancesdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// BatchPushRequest A single document in a BatchPushToUnify call; the fields mirror the PushToUnify arguments
+type BatchPushRequest struct {
+	SourceName    string
+	SourceVersion string
+	LogicalType   LogicalDocType
+	Country       Country
+	Operation     Operation
+	Mode          Mode
+	Purpose       Purpose
+	Payload       map[string]interface{}
+	Destinations  []*Destination
+}
+
+// BatchPushResult Outcome of one BatchPushRequest
+type BatchPushResult struct {
+	Response *UnifyResponse
+	Err      error
+}
+
+// GetResponse getter for response
+func (b *BatchPushResult) GetResponse() *UnifyResponse {
+	return b.Response
+}
+
+// GetError getter for error
+func (b *BatchPushResult) GetError() error {
+	return b.Err
+}
+
+// BatchPushToUnify Push several documents with at most concurrency requests in
+// flight. Results are aligned with requests by index; a failing item does not
+// stop the others. Items that fail with a retryable error are queued together
+// with EnqueueBatch once all items are done. Cancelling ctx aborts the items in
+// flight, and items not yet started fail with a cancellation error.
+// Uses the SDK set up by Configure.
+func BatchPushToUnify(ctx context.Context, requests []*BatchPushRequest, concurrency int) []*BatchPushResult {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.BatchPushToUnify(ctx, requests, concurrency)
+}
+
+// BatchPushToUnify Push several documents with at most concurrency requests in
+// flight. Results are aligned with requests by index; a failing item does not
+// stop the others. Items that fail with a retryable error are queued together
+// with EnqueueBatch once all items are done, and get a queued response; if
+// that fails they keep their send error. Cancelling ctx aborts the items in
+// flight, and items not yet started fail with a cancellation error.
+func (s *GETSUnifySDK) BatchPushToUnify(ctx context.Context, requests []*BatchPushRequest, concurrency int) []*BatchPushResult {
+	results := make([]*BatchPushResult, len(requests))
+	retries := make([]*queuedRetry, len(requests))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(requests) {
+		concurrency = len(requests)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index], retries[index] = s.pushBatchItem(ctx, requests[index])
+			}
+		}()
+	}
+
+	for index := range requests {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	s.queueBatchRetries(results, retries)
+	return results
+}
+
+// queueBatchRetries Enqueue the batch items that failed with a retryable error
+// in one EnqueueBatch and mark them queued
+func (s *GETSUnifySDK) queueBatchRetries(results []*BatchPushResult, retries []*queuedRetry) {
+	var queued []*queuedRetry
+	for _, retry := range retries {
+		if retry != nil {
+			queued = append(queued, retry)
+		}
+	}
+	if len(queued) == 0 {
+		return
+	}
+	if err := s.queueManager.enqueueRetryBatch(queued); err != nil {
+		s.apiClient.GetLogger().Warn("Failed to queue batch items for retry", map[string]interface{}{
+			"count": len(queued),
+			"error": err.Error(),
+		})
+		return
+	}
+	for index, retry := range retries {
+		if retry != nil {
+			results[index] = &BatchPushResult{Response: queuedUnifyResponse(retry.request)}
+		}
+	}
+}
+
+// pushBatchItem Push a single batch item with ctx, so cancelling the batch also
+// aborts requests and retry backoffs in flight. A retryable failure is
+// returned for the batch to queue instead of being queued here.
+func (s *GETSUnifySDK) pushBatchItem(ctx context.Context, request *BatchPushRequest) (*BatchPushResult, *queuedRetry) {
+	if ctx.Err() != nil {
+		return &BatchPushResult{Err: newContextSDKError(ctx.Err())}, nil
+	}
+	if request == nil {
+		return &BatchPushResult{Err: NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Batch request is required",
+		))}, nil
+	}
+	if s == nil || s.config == nil {
+		return &BatchPushResult{Err: NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		))}, nil
+	}
+
+	unifyRequest, err := s.buildLogicalRequest(
+		request.SourceName,
+		request.SourceVersion,
+		request.LogicalType,
+		request.Country,
+		request.Operation,
+		request.Mode,
+		request.Purpose,
+		request.Payload,
+		request.Destinations,
+	)
+	if err != nil {
+		return &BatchPushResult{Err: err}, nil
+	}
+
+	// Process queued submissions first before handling new requests
+	s.ProcessQueuedSubmissionsFirst()
+
+	response, retry, err := s.sendUnifyRequestOnce(ctx, unifyRequest)
+	if err != nil {
+		return &BatchPushResult{Err: err}, retry
+	}
+	response, err = s.resubmitCorrectedRejection(ctx, unifyRequest, response)
+	return &BatchPushResult{Response: response, Err: err}, nil
+}
+
+// BulkNDJSONChunkSize Number of NDJSON records sent per bulk request
+const BulkNDJSONChunkSize = 100
+
+// maxNDJSONLineBytes Largest single NDJSON record accepted from the reader
+const maxNDJSONLineBytes = 10 * 1024 * 1024
+
+// BulkUploadSummary Outcome of a PushBulkNDJSON call. Rejected counts records
+// refused by the SDK or the server; Failed counts records of chunks that never
+// got an answer, e.g. on network errors or 5xx responses, and may be sent again.
+type BulkUploadSummary struct {
+	TotalRecords int     `json:"total_records"`
+	Accepted     int     `json:"accepted"`
+	Rejected     int     `json:"rejected"`
+	Failed       int     `json:"failed"`
+	InvalidLines int     `json:"invalid_lines"`
+	Chunks       int     `json:"chunks"`
+	Errors       []error `json:"-"`
+}
+
+// GetTotalRecords getter for total records
+func (b *BulkUploadSummary) GetTotalRecords() int {
+	return b.TotalRecords
+}
+
+// GetAccepted getter for accepted count
+func (b *BulkUploadSummary) GetAccepted() int {
+	return b.Accepted
+}
+
+// GetRejected getter for rejected count
+func (b *BulkUploadSummary) GetRejected() int {
+	return b.Rejected
+}
+
+// GetFailed getter for the count of records whose chunk failed to be delivered
+func (b *BulkUploadSummary) GetFailed() int {
+	return b.Failed
+}
+
+// PushBulkNDJSON Stream newline-delimited JSON payloads to the Unify API as bulk
+// requests of BulkNDJSONChunkSize records. Records are read from reader only as
+// chunks are sent, so memory use is bounded by one chunk. Each record is
+// prepared as PushToUnify prepares a single document. Lines that are not JSON
+// objects, and records that cannot be prepared, e.g. adjustment notes without
+// an original invoice reference, are counted as rejected without being sent. A
+// chunk the server refuses counts its records as rejected, one that fails to
+// be delivered counts them as failed, and processing continues with the next
+// chunk. Cancelling ctx aborts the chunk in flight.
+// Uses the SDK set up by Configure.
+func PushBulkNDJSON(ctx context.Context, source *Source, country Country, docType LogicalDocType, reader io.Reader) (*BulkUploadSummary, error) {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.PushBulkNDJSON(ctx, source, country, docType, reader)
+}
+
+// PushBulkNDJSON Stream newline-delimited JSON payloads to the Unify API as bulk
+// requests of BulkNDJSONChunkSize records. Records are read from reader only as
+// chunks are sent, so memory use is bounded by one chunk. Each record is
+// prepared as PushToUnify prepares a single document. Lines that are not JSON
+// objects, and records that cannot be prepared, e.g. adjustment notes without
+// an original invoice reference, are counted as rejected without being sent. A
+// chunk the server refuses counts its records as rejected, one that fails to
+// be delivered counts them as failed, and processing continues with the next
+// chunk. Cancelling ctx aborts the chunk in flight.
+func (s *GETSUnifySDK) PushBulkNDJSON(ctx context.Context, source *Source, country Country, docType LogicalDocType, reader io.Reader) (*BulkUploadSummary, error) {
+	if s == nil || s.config == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		))
+	}
+	if source == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Source is required",
+		))
+	}
+	if reader == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"NDJSON reader is required",
+		))
+	}
+	if err := validateLogicalDocType(docType, country); err != nil {
+		return nil, err
+	}
+	if err := validateCountryForEnvironment(country, s.config); err != nil {
+		return nil, err
+	}
+
+	summary := &BulkUploadSummary{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineBytes)
+
+	chunk := make([]interface{}, 0, BulkNDJSONChunkSize)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return newContextSDKError(ctx.Err())
+		}
+		s.sendBulkChunk(ctx, source, country, docType, chunk, summary)
+		chunk = make([]interface{}, 0, BulkNDJSONChunkSize)
+		if ctx.Err() != nil {
+			return newContextSDKError(ctx.Err())
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		summary.TotalRecords++
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			summary.InvalidLines++
+			summary.Rejected++
+			summary.Errors = append(summary.Errors, fmt.Errorf("record %d: %v", summary.TotalRecords, err))
+			continue
+		}
+		prepared, err := s.prepareBulkRecord(docType, country, record)
+		if err != nil {
+			summary.Rejected++
+			summary.Errors = append(summary.Errors, fmt.Errorf("record %d: %w", summary.TotalRecords, err))
+			continue
+		}
+
+		chunk = append(chunk, prepared)
+		if len(chunk) == BulkNDJSONChunkSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidPayloadFormat,
+			fmt.Sprintf("Failed to read NDJSON stream: %v", err),
+		))
+	}
+	if err := flush(); err != nil {
+		return summary, err
+	}
+
+	return summary, nil
+}
+
+// prepareBulkRecord Check a bulk record's original reference, merge the
+// country policy and prepare it as buildLogicalRequest prepares the payload of
+// a single document
+func (s *GETSUnifySDK) prepareBulkRecord(docType LogicalDocType, country Country, record map[string]interface{}) (map[string]interface{}, error) {
+	if err := s.config.validateOriginalReference(docType, record); err != nil {
+		return nil, err
+	}
+	mergedRecord, documentTypeV2 := applyCountryPolicy(docType, country, record)
+	normalizedDocumentTypeV2, err := normalizeAndValidateDocumentTypeV2(documentTypeV2)
+	if err != nil {
+		return nil, err
+	}
+	return s.prepareRequestPayload(mergedRecord, country, PurposeInvoicing, normalizedDocumentTypeV2)
+}
+
+// buildBulkRequest Bulk request for a chunk of prepared records, keyed for
+// idempotency on the document numbers of the records
+func (s *GETSUnifySDK) buildBulkRequest(source *Source, country Country, docType LogicalDocType, records []interface{}) (*UnifyRequest, error) {
+	_, documentTypeV2 := applyCountryPolicy(docType, country, map[string]interface{}{})
+	normalizedDocumentTypeV2, err := normalizeAndValidateDocumentTypeV2(documentTypeV2)
+	if err != nil {
+		return nil, err
+	}
+	baseDocumentType := resolveBaseDocumentTypeFromV2(normalizedDocumentTypeV2.Base)
+	request := s.buildUnifyRequest(
+		NewSourceRef(source.GetName(), source.GetVersion()),
+		baseDocumentType,
+		normalizedDocumentTypeV2.Base,
+		country, OperationBulk, ModeDocuments, PurposeInvoicing,
+		map[string]interface{}{"documents": records},
+		[]*Destination{},
+		normalizedDocumentTypeV2,
+	)
+	request.ensureBulkIdempotencyKey(s.config.documentIDPathsFor(baseDocumentType))
+	return request, nil
+}
+
+// sendBulkChunk Send one chunk of records as a bulk request with ctx, after the
+// BeforeSend hook, and tally the result
+func (s *GETSUnifySDK) sendBulkChunk(ctx context.Context, source *Source, country Country, docType LogicalDocType, records []interface{}, summary *BulkUploadSummary) {
+	summary.Chunks++
+
+	request, err := s.buildBulkRequest(source, country, docType, records)
+	if err == nil {
+		err = s.runBeforeSend(request)
+	}
+	if err != nil {
+		summary.Rejected += len(records)
+		summary.Errors = append(summary.Errors, err)
+		return
+	}
+	response, err := s.apiClient.SendUnifyRequestContext(ctx, request)
+	if err != nil {
+		if s.isDeliveryFailure(err) {
+			summary.Failed += len(records)
+		} else {
+			summary.Rejected += len(records)
+		}
+		summary.Errors = append(summary.Errors, err)
+		return
+	}
+
+	if data := response.GetData(); data != nil && data.GetBulkSubmission() != nil {
+		bulk := data.GetBulkSubmission()
+		accepted := len(bulk.Accepted())
+		summary.Accepted += accepted
+		summary.Rejected += len(records) - accepted
+		return
+	}
+
+	accepted, rejected := len(records), 0
+	if counted, ok := metadataCount(response.GetMetadata(), "accepted"); ok {
+		accepted = counted
+		rejected = len(records) - counted
+	}
+	if counted, ok := metadataCount(response.GetMetadata(), "rejected"); ok {
+		rejected = counted
+		accepted = len(records) - counted
+	}
+	summary.Accepted += accepted
+	summary.Rejected += rejected
+}
+
+// isDeliveryFailure Whether a send failed without the server deciding on the
+// records: network errors, timeouts, cancellation and the statuses that are
+// queued for retry, such as 5xx
+func (s *GETSUnifySDK) isDeliveryFailure(err error) bool {
+	cause := rootSDKError(err)
+	return cause == nil || s.shouldEnqueueForRetry(cause)
+}
+
+// metadataCount Read a numeric count from response metadata
+func metadataCount(metadata map[string]interface{}, key string) (int, bool) {
+	switch value := metadata[key].(type) {
+	case float64:
+		return int(value), true
+	case int:
+		return value, true
+	}
+	return 0, false
+}
@@ -0,0 +1,386 @@
+package complyancesdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newBatchRequests(count int) []*BatchPushRequest {
+	requests := make([]*BatchPushRequest, count)
+	for i := range requests {
+		requests[i] = &BatchPushRequest{
+			SourceName:    "src",
+			SourceVersion: "1",
+			LogicalType:   LogicalDocTypeTaxInvoice,
+			Country:       CountrySA,
+			Operation:     OperationSingle,
+			Mode:          ModeDocuments,
+			Purpose:       PurposeInvoicing,
+			Payload:       testInvoicePayload(fmt.Sprintf("INV-B%d", i)),
+		}
+	}
+	return requests
+}
+
+// configureConcurrencyTracker configures the SDK against a server that records
+// the peak number of requests in flight.
+func configureConcurrencyTracker(t *testing.T) func() int {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), handler)
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return peak
+	}
+}
+
+func TestBatchPushToUnifyReportsPartialFailuresByIndex(t *testing.T) {
+	configureConcurrencyTracker(t)
+
+	requests := newBatchRequests(5)
+	requests[2].Country = CountrySG
+	requests[4].Country = ""
+
+	results := BatchPushToUnify(context.Background(), requests, 3)
+	if len(results) != len(requests) {
+		t.Fatalf("expected %d results, got %d", len(requests), len(results))
+	}
+	for i, result := range results {
+		failed := i == 2 || i == 4
+		if failed && result.GetError() == nil {
+			t.Fatalf("expected item %d to fail", i)
+		}
+		if !failed && (result.GetError() != nil || !result.GetResponse().IsSuccess()) {
+			t.Fatalf("expected item %d to succeed, got %v", i, result.GetError())
+		}
+	}
+}
+
+func TestBatchPushToUnifyRespectsConcurrencyCap(t *testing.T) {
+	peak := configureConcurrencyTracker(t)
+	BatchPushToUnify(context.Background(), newBatchRequests(6), 1)
+	if peak() != 1 {
+		t.Fatalf("expected at most 1 request in flight, saw %d", peak())
+	}
+
+	peak = configureConcurrencyTracker(t)
+	BatchPushToUnify(context.Background(), newBatchRequests(12), 4)
+	if peak() < 2 || peak() > 4 {
+		t.Fatalf("expected between 2 and 4 requests in flight, saw %d", peak())
+	}
+}
+
+func TestBatchPushToUnifyStopsOnCancelledContext(t *testing.T) {
+	configureConcurrencyTracker(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, result := range BatchPushToUnify(ctx, newBatchRequests(3), 2) {
+		if !errors.Is(result.GetError(), context.Canceled) {
+			t.Fatalf("expected item %d to be cancelled, got %v", i, result.GetError())
+		}
+	}
+}
+
+func TestBatchPushToUnifyCancelsRequestsInFlight(t *testing.T) {
+	started := make(chan struct{}, 2)
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		<-started
+		cancel()
+	}()
+	begin := time.Now()
+	results := BatchPushToUnify(ctx, newBatchRequests(2), 2)
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Fatalf("expected cancellation to abort the slow requests, took %s", elapsed)
+	}
+	for i, result := range results {
+		if !errors.Is(result.GetError(), context.Canceled) {
+			t.Fatalf("expected item %d to be cancelled, got %v", i, result.GetError())
+		}
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 0 {
+		t.Fatalf("expected cancelled items not to be queued, got %d", pending)
+	}
+}
+
+// countingBatchStore MemoryQueueStore recording how items reach it
+type countingBatchStore struct {
+	*MemoryQueueStore
+	mu      sync.Mutex
+	single  int
+	batches [][]string
+}
+
+func (c *countingBatchStore) Enqueue(id string, record []byte) error {
+	c.mu.Lock()
+	c.single++
+	c.mu.Unlock()
+	return c.MemoryQueueStore.Enqueue(id, record)
+}
+
+func (c *countingBatchStore) EnqueueBatch(ids []string, records [][]byte) ([]string, error) {
+	c.mu.Lock()
+	c.batches = append(c.batches, ids)
+	c.mu.Unlock()
+	return c.MemoryQueueStore.EnqueueBatch(ids, records)
+}
+
+func TestBatchPushToUnifyQueuesRetryableFailuresInOneBatch(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		invoiceData := body["payload"].(map[string]interface{})["invoice_data"].(map[string]interface{})
+		if invoiceData["invoice_number"] == "INV-B1" {
+			_, _ = w.Write([]byte(`{"status":"success"}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	store := &countingBatchStore{MemoryQueueStore: NewMemoryQueueStore()}
+	previousStore := globalSDK.queueManager.store
+	globalSDK.queueManager.store = store
+	t.Cleanup(func() { globalSDK.queueManager.store = previousStore })
+
+	results := BatchPushToUnify(context.Background(), newBatchRequests(4), 2)
+	for i, result := range results {
+		want := "queued"
+		if i == 1 {
+			want = "success"
+		}
+		if result.GetError() != nil || result.GetResponse().GetStatus() != want {
+			t.Fatalf("expected item %d to be %s, got %+v", i, want, result)
+		}
+	}
+	if store.single != 0 || len(store.batches) != 1 || len(store.batches[0]) != 3 {
+		t.Fatalf("expected the three failures in one batch, got %d single enqueues and batches %v", store.single, store.batches)
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 3 {
+		t.Fatalf("expected 3 pending items, got %d", pending)
+	}
+}
+
+func TestEnqueueBatchUsesConfiguredQueue(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {})
+	previousStore := globalSDK.queueManager.store
+	globalSDK.queueManager.store = NewMemoryQueueStore()
+	t.Cleanup(func() { globalSDK.queueManager.store = previousStore })
+
+	if err := EnqueueBatch(testBatchSubmissions(t, 3)); err != nil {
+		t.Fatalf("EnqueueBatch failed: %v", err)
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 3 {
+		t.Fatalf("expected 3 pending items, got %d", pending)
+	}
+	var unconfigured *GETSUnifySDK
+	if code, ok := GetErrorCode(unconfigured.EnqueueBatch(testBatchSubmissions(t, 1))); !ok || code != ErrorCodeQueueError {
+		t.Fatalf("expected a queue error without a configured SDK, got %v", code)
+	}
+}
+
+func TestPushBulkNDJSONStreamsRecordsInChunks(t *testing.T) {
+	var mu sync.Mutex
+	received, requests := 0, 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		payload, _ := body["payload"].(map[string]interface{})
+		documents, _ := payload["documents"].([]interface{})
+
+		mu.Lock()
+		received += len(documents)
+		requests++
+		mu.Unlock()
+
+		if body["operation"] != "BULK" {
+			t.Errorf("expected BULK operation, got %v", body["operation"])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":   "success",
+			"metadata": map[string]interface{}{"accepted": len(documents)},
+		})
+	}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), handler)
+
+	reader, writer := io.Pipe()
+	go func() {
+		for i := 0; i < 1000; i++ {
+			fmt.Fprintf(writer, "{\"invoice_data\":{\"invoice_number\":\"INV-%04d\"}}\n", i)
+		}
+		writer.Close()
+	}()
+
+	summary, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocTypeTaxInvoice, reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received != 1000 || requests != 1000/BulkNDJSONChunkSize {
+		t.Fatalf("expected 1000 records in %d requests, got %d in %d", 1000/BulkNDJSONChunkSize, received, requests)
+	}
+	if summary.GetTotalRecords() != 1000 || summary.GetAccepted() != 1000 || summary.GetRejected() != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestPushBulkNDJSONPreparesRecordsLikeSingleDocuments(t *testing.T) {
+	var mu sync.Mutex
+	var documents []interface{}
+	var keys []string
+	serverDown := false
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetTextNormalizationCountries(CountrySA)
+	cfg.SetMonetaryFields(2, "invoice_data.total_amount")
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		if serverDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		payload, _ := body["payload"].(map[string]interface{})
+		documents, _ = payload["documents"].([]interface{})
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	stream := "{\"invoice_data\":{\"invoice_number\":\"INV-1\",\"total_amount\":115.005,\"seller_name\":\"Cafe\u0301\"}}\n"
+	for i := 0; i < 2; i++ {
+		summary, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocTypeTaxInvoice, strings.NewReader(stream))
+		if err != nil || summary.GetAccepted() != 1 {
+			t.Fatalf("unexpected result: %+v, %v", summary, err)
+		}
+	}
+	invoiceData := documents[0].(map[string]interface{})["invoice_data"].(map[string]interface{})
+	if invoiceData["total_amount"] != "115.01" || invoiceData["document_type"] != "tax_invoice" || invoiceData["seller_name"] != "Caf\u00E9" {
+		t.Fatalf("expected the record to be prepared like a single document, got %v", invoiceData)
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected resending the same records to reuse the idempotency key, got %v", keys)
+	}
+
+	mu.Lock()
+	serverDown = true
+	mu.Unlock()
+	summary, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocTypeTaxInvoice, strings.NewReader(stream+"not json\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.GetFailed() != 1 || summary.GetRejected() != 1 || summary.GetAccepted() != 0 {
+		t.Fatalf("expected the undelivered chunk to count as failed, not rejected, got %+v", summary)
+	}
+}
+
+func TestPushBulkNDJSONCountsInvalidLinesAndRejections(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","metadata":{"rejected":1}}`))
+	}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), handler)
+
+	stream := strings.NewReader("{\"invoice_data\":{}}\nnot json\n\n{\"invoice_data\":{}}\n{\"invoice_data\":{}}\n")
+	summary, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocTypeTaxInvoice, stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.TotalRecords != 4 || summary.InvalidLines != 1 || summary.Accepted != 2 || summary.Rejected != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestPushBulkNDJSONCancelsChunkInFlight(t *testing.T) {
+	started := make(chan struct{}, 1)
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	begin := time.Now()
+	summary, err := PushBulkNDJSON(ctx, NewSource("src", "1", nil), CountrySA, LogicalDocTypeTaxInvoice, strings.NewReader("{\"invoice_data\":{}}\n"))
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Fatalf("expected cancellation to abort the chunk in flight, took %s", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) || summary.GetFailed() != 1 || summary.GetRejected() != 0 {
+		t.Fatalf("expected a cancellation error with the chunk failed, got %v (%+v)", err, summary)
+	}
+}
+
+func TestPushBulkNDJSONRunsPreSendChecks(t *testing.T) {
+	var documents []interface{}
+	hookCalls := 0
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetBeforeSend(func(request *UnifyRequest) error {
+		hookCalls++
+		return nil
+	})
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		payload, _ := body["payload"].(map[string]interface{})
+		documents, _ = payload["documents"].([]interface{})
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	stream := strings.NewReader(`{"invoice_data":{"invoice_number":"CN-1","original_invoice_number":"INV-1"}}` + "\n" +
+		`{"invoice_data":{"invoice_number":"CN-2"}}` + "\n")
+	summary, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocTypeCreditNote, stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(documents) != 1 || summary.GetAccepted() != 1 || summary.GetRejected() != 1 || hookCalls != 1 {
+		t.Fatalf("expected the unreferenced credit note to be rejected before sending, got %d sent, %+v, %d hook calls", len(documents), summary, hookCalls)
+	}
+
+	if _, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocType("NOT_A_TYPE"), strings.NewReader("{}\n")); err == nil {
+		t.Fatalf("expected an unknown document type to be rejected")
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Per-document results of bulk submissions.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// BulkItemResult Outcome of one document of a bulk submission. Index is the
+// position of the document in the payload "documents" list that was sent.
+type BulkItemResult struct {
+	Index              int                    `json:"index"`
+	DocumentID         string                 `json:"document_id,omitempty"`
+	Status             string                 `json:"status"`
+	SubmissionID       *string                `json:"submission_id,omitempty"`
+	Errors             []*SubmissionError     `json:"errors,omitempty"`
+	GovernmentResponse map[string]interface{} `json:"government_response,omitempty"`
+}
+
+// IsAccepted Check if the document was accepted
+func (b *BulkItemResult) IsAccepted() bool {
+	return strings.EqualFold(b.Status, "accepted")
+}
+
+// IsRejected Check if the document was rejected
+func (b *BulkItemResult) IsRejected() bool {
+	return strings.EqualFold(b.Status, "rejected")
+}
+
+// BulkSubmissionResponse Per-document results of an OperationBulk request
+type BulkSubmissionResponse struct {
+	BatchID *string           `json:"batch_id,omitempty"`
+	Status  *string           `json:"status,omitempty"`
+	Items   []*BulkItemResult `json:"items"`
+}
+
+// GetItems getter for the per-document results, in the order the server returned them
+func (b *BulkSubmissionResponse) GetItems() []*BulkItemResult {
+	return b.Items
+}
+
+// ItemByIndex Result of the document sent at index, nil when there is none
+func (b *BulkSubmissionResponse) ItemByIndex(index int) *BulkItemResult {
+	for _, item := range b.Items {
+		if item.Index == index {
+			return item
+		}
+	}
+	return nil
+}
+
+// ItemByDocumentID Result of the document with documentID, nil when there is none
+func (b *BulkSubmissionResponse) ItemByDocumentID(documentID string) *BulkItemResult {
+	for _, item := range b.Items {
+		if item.DocumentID != "" && item.DocumentID == documentID {
+			return item
+		}
+	}
+	return nil
+}
+
+// Accepted Results of the accepted documents
+func (b *BulkSubmissionResponse) Accepted() []*BulkItemResult {
+	accepted := []*BulkItemResult{}
+	for _, item := range b.Items {
+		if item.IsAccepted() {
+			accepted = append(accepted, item)
+		}
+	}
+	return accepted
+}
+
+// Failed Results of every document that was not accepted
+func (b *BulkSubmissionResponse) Failed() []*BulkItemResult {
+	failed := []*BulkItemResult{}
+	for _, item := range b.Items {
+		if !item.IsAccepted() {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// bulkItemKeys Keys the per-document list is read from, in order
+var bulkItemKeys = []string{"items", "results", "documents"}
+
+// bulkDocumentIDPaths Paths tried for the ID of a sent document when the server
+// does not echo it, relative to the document
+var bulkDocumentIDPaths = []string{
+	"invoice_data.invoice_number",
+	"invoice_data.document_number",
+	"invoice_data.id",
+}
+
+// populateBulkSubmission Set Data.BulkSubmission from the per-document results
+// of a bulk response. The results are read from data.bulk or data.submission,
+// under items, results or documents. Items without a document ID get the ID of
+// the document sent at their index.
+func (a *APIClient) populateBulkSubmission(response *UnifyResponse, responseBody string, request *UnifyRequest) {
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(responseBody), &body); err != nil || body.Data == nil {
+		return
+	}
+
+	var section map[string]interface{}
+	var entries []interface{}
+	for _, key := range []string{"bulk", "submission"} {
+		candidate, ok := body.Data[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, itemsKey := range bulkItemKeys {
+			if list, ok := candidate[itemsKey].([]interface{}); ok {
+				section, entries = candidate, list
+				break
+			}
+		}
+		if section != nil {
+			break
+		}
+	}
+	if section == nil {
+		return
+	}
+
+	sent := asList(request.GetPayload()["documents"])
+	bulk := &BulkSubmissionResponse{Items: make([]*BulkItemResult, 0, len(entries))}
+	if batchID := bulkString(section, "batchId", "batch_id", "submissionId", "submission_id"); batchID != "" {
+		bulk.BatchID = &batchID
+	}
+	if status := bulkString(section, "status"); status != "" {
+		bulk.Status = &status
+	}
+	for position, entry := range entries {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		bulk.Items = append(bulk.Items, newBulkItemResult(entryMap, position, sent))
+	}
+
+	if response.Data == nil {
+		response.Data = &UnifyResponseData{}
+	}
+	response.Data.BulkSubmission = bulk
+}
+
+// newBulkItemResult Result of one entry of the per-document list
+func newBulkItemResult(entry map[string]interface{}, position int, sent []interface{}) *BulkItemResult {
+	item := &BulkItemResult{
+		Index:      position,
+		DocumentID: bulkString(entry, "documentId", "document_id", "invoiceCodeNumber", "invoice_number"),
+		Status:     bulkString(entry, "status"),
+	}
+	if index, ok := entry["index"].(float64); ok {
+		item.Index = int(index)
+	}
+	if submissionID := bulkString(entry, "submissionId", "submission_id", "uuid"); submissionID != "" {
+		item.SubmissionID = &submissionID
+	}
+	if government, ok := entry["governmentResponse"].(map[string]interface{}); ok {
+		item.GovernmentResponse = government
+	} else if government, ok := entry["government_response"].(map[string]interface{}); ok {
+		item.GovernmentResponse = government
+	}
+
+	errorEntries := asList(entry["errors"])
+	if errorMap, ok := entry["error"].(map[string]interface{}); ok {
+		errorEntries = append(errorEntries, errorMap)
+	}
+	for _, errorEntry := range errorEntries {
+		if submissionError := newBulkItemError(errorEntry); submissionError != nil {
+			item.Errors = append(item.Errors, submissionError)
+		}
+	}
+
+	if item.DocumentID == "" && item.Index >= 0 && item.Index < len(sent) {
+		if document, ok := sent[item.Index].(map[string]interface{}); ok {
+			for _, path := range bulkDocumentIDPaths {
+				if documentID, ok := lookupDocumentID(document, path); ok {
+					item.DocumentID = documentID
+					break
+				}
+			}
+		}
+	}
+	return item
+}
+
+// newBulkItemError Error given as a plain message or as an object with a
+// message and an optional code or errorCode, nil when there is no message
+func newBulkItemError(entry interface{}) *SubmissionError {
+	message, code := "", ""
+	switch value := entry.(type) {
+	case string:
+		message = strings.TrimSpace(value)
+	case map[string]interface{}:
+		message = bulkString(value, "message", "error")
+		code = bulkString(value, "code", "errorCode")
+	}
+	if message == "" {
+		return nil
+	}
+	submissionError := &SubmissionError{Message: &message}
+	if code != "" {
+		submissionError.Code = &code
+	}
+	return submissionError
+}
+
+// bulkString First non-empty string or number among keys
+func bulkString(entry map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := entry[key].(type) {
+		case string:
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+	return ""
+}
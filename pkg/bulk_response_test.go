@@ -0,0 +1,102 @@
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// mixedBulkResponse Bulk response with accepted and rejected documents; the
+// rejected document at index 2 is not echoed back by ID
+const mixedBulkResponse = `{
+	"status": "success",
+	"data": {
+		"submission": {
+			"batchId": "BATCH-1",
+			"status": "PARTIAL",
+			"items": [
+				{"index": 0, "documentId": "INV-1", "status": "ACCEPTED", "submissionId": "SUB-1"},
+				{"index": 1, "documentId": "INV-2", "status": "REJECTED", "errors": [
+					{"code": "BR-KSA-37", "message": "The seller address building number must contain 4 digits."},
+					"Buyer VAT number is missing"
+				]},
+				{"index": 2, "status": "REJECTED", "error": {"errorCode": "CF321", "error": "Issuance date is too old"}},
+				{"index": 3, "documentId": "INV-4", "status": "ACCEPTED", "submissionId": "SUB-4"}
+			]
+		}
+	}
+}`
+
+func TestBulkResponseReportsEachDocument(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mixedBulkResponse))
+	})
+
+	documents := []interface{}{}
+	for _, invoiceNumber := range []string{"INV-1", "INV-2", "INV-3", "INV-4"} {
+		documents = append(documents, testInvoicePayload(invoiceNumber))
+	}
+	documentType := MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice)
+	sdk := currentSDK()
+	request := sdk.buildUnifyRequest(NewSourceRef("src", "1"), resolveBaseDocumentTypeFromV2(documentType.Base), documentType.Base,
+		CountrySA, OperationBulk, ModeDocuments, PurposeInvoicing, map[string]interface{}{"documents": documents}, []*Destination{}, documentType)
+	response, err := sdk.apiClient.SendUnifyRequest(request)
+	if err != nil {
+		t.Fatalf("SendUnifyRequest failed: %v", err)
+	}
+
+	bulk := response.GetData().GetBulkSubmission()
+	if bulk == nil || len(bulk.GetItems()) != 4 || *bulk.BatchID != "BATCH-1" || *bulk.Status != "PARTIAL" {
+		t.Fatalf("unexpected bulk response: %+v", bulk)
+	}
+	if len(bulk.Accepted()) != 2 || len(bulk.Failed()) != 2 {
+		t.Fatalf("expected 2 accepted and 2 failed documents, got %d and %d", len(bulk.Accepted()), len(bulk.Failed()))
+	}
+
+	rejected := bulk.ItemByDocumentID("INV-2")
+	if rejected == nil || !rejected.IsRejected() || rejected.Index != 1 || len(rejected.Errors) != 2 {
+		t.Fatalf("unexpected result for INV-2: %+v", rejected)
+	}
+	if *rejected.Errors[0].Code != "BR-KSA-37" || rejected.Errors[1].Code != nil || *rejected.Errors[1].Message != "Buyer VAT number is missing" {
+		t.Fatalf("unexpected errors for INV-2: %+v %+v", rejected.Errors[0], rejected.Errors[1])
+	}
+
+	unnamed := bulk.ItemByIndex(2)
+	if unnamed == nil || unnamed.DocumentID != "INV-3" || *unnamed.Errors[0].Code != "CF321" {
+		t.Fatalf("expected the document ID sent at index 2, got %+v", unnamed)
+	}
+	if accepted := bulk.ItemByDocumentID("INV-4"); accepted == nil || !accepted.IsAccepted() || *accepted.SubmissionID != "SUB-4" {
+		t.Fatalf("unexpected result for INV-4: %+v", accepted)
+	}
+}
+
+func TestBulkResponseIsOnlyReadForBulkOperations(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mixedBulkResponse))
+	})
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+		PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{})
+	if err != nil {
+		t.Fatalf("PushToUnify failed: %v", err)
+	}
+	if response.GetData().GetBulkSubmission() != nil {
+		t.Fatalf("expected no bulk results for a single submission")
+	}
+}
+
+func TestPushBulkNDJSONCountsPerDocumentResults(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mixedBulkResponse))
+	})
+
+	stream := strings.NewReader(strings.Repeat("{\"invoice_data\":{}}\n", 4))
+	summary, err := PushBulkNDJSON(context.Background(), NewSource("src", "1", nil), CountrySA, LogicalDocTypeTaxInvoice, stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Accepted != 2 || summary.Rejected != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
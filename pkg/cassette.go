@@ -0,0 +1,305 @@
+/*
+Recording of API interactions to cassette files and offline replay.
+*/
+package complyancesdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cassetteRedactedHeaders Request headers masked in cassettes
+var cassetteRedactedHeaders = []string{"Authorization", "X-API-Key", HeaderSignature}
+
+// Cassette Recorded request and response pairs, stored as JSON
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction One recorded request and the response it got
+type Interaction struct {
+	Request    CassetteRequest  `json:"request"`
+	Response   CassetteResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// CassetteRequest Recorded request. Credentials are masked and gzip bodies
+// are stored decompressed.
+type CassetteRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// CassetteResponse Recorded response
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette Read a cassette file
+func LoadCassette(path string) (*Cassette, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newCassetteError(path, fmt.Sprintf("Failed to read cassette: %v", err))
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(content, cassette); err != nil {
+		return nil, newCassetteError(path, fmt.Sprintf("Invalid cassette: %v", err))
+	}
+	return cassette, nil
+}
+
+// Save Write the cassette to path, replacing the file
+func (c *Cassette) Save(path string) error {
+	encoded, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return newCassetteError(path, fmt.Sprintf("Failed to encode cassette: %v", err))
+	}
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		return newCassetteError(path, fmt.Sprintf("Failed to write cassette: %v", err))
+	}
+	return nil
+}
+
+// RecordingTransport http.RoundTripper that sends requests through another
+// transport and appends every request and response to a cassette file, which
+// is rewritten after each interaction. Secrets in headers and bodies are
+// masked. A cassette that cannot be written is logged and the response is
+// still returned. Use it through SDKConfig.SetHTTPClient:
+//
+//	cfg.SetHTTPClient(&http.Client{Transport: complyancesdk.NewRecordingTransport("cassette.json", nil)})
+type RecordingTransport struct {
+	path      string
+	next      http.RoundTripper
+	redaction *RedactionConfig
+	logger    Logger
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecordingTransport Record to the cassette at path, sending requests
+// through next (http.DefaultTransport when nil)
+func NewRecordingTransport(path string, next http.RoundTripper) *RecordingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RecordingTransport{
+		path:      path,
+		next:      next,
+		redaction: NewDefaultRedactionConfig(),
+		logger:    NewStdLogger(nil, false),
+		cassette:  &Cassette{Interactions: []*Interaction{}},
+	}
+}
+
+// SetRedactionConfig Mask these field paths in recorded request bodies in
+// addition to the API key; nil restores DefaultRedactionFieldPaths
+func (t *RecordingTransport) SetRedactionConfig(config *RedactionConfig) {
+	if config == nil {
+		config = NewDefaultRedactionConfig()
+	}
+	t.redaction = config
+}
+
+// SetLogger Set the logger for cassette write failures, the standard logger by
+// default; nil disables it
+func (t *RecordingTransport) SetLogger(logger Logger) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.logger = loggerOrNoop(logger)
+}
+
+// Cassette Copy of the interactions recorded so far
+func (t *RecordingTransport) Cassette() *Cassette {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &Cassette{Interactions: append([]*Interaction(nil), t.cassette.Interactions...)}
+}
+
+// RoundTrip Send req and record it with its response. Requests that fail
+// without a response are not recorded.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		requestBody = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := &Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactCassetteHeader(req.Header),
+			Body:   t.redactBody(decodeRecordedBody(req.Header, requestBody)),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(responseBody),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	if err := t.cassette.Save(t.path); err != nil {
+		t.logger.Warn("Failed to save cassette", map[string]interface{}{
+			"path":  t.path,
+			"error": err.Error(),
+		})
+	}
+	return resp, nil
+}
+
+// redactBody Body with secrets masked, left as is when it is not JSON
+func (t *RecordingTransport) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var document interface{}
+	if json.Unmarshal(body, &document) != nil {
+		return string(body)
+	}
+	return t.redaction.RedactJSON(string(body))
+}
+
+// redactCassetteHeader Copy of header with credentials masked
+func redactCassetteHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range cassetteRedactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, RedactedValue)
+		}
+	}
+	return redacted
+}
+
+// decodeRecordedBody body decompressed when it was sent gzip encoded
+func decodeRecordedBody(header http.Header, body []byte) []byte {
+	if !strings.EqualFold(header.Get(HeaderContentEncoding), "gzip") || len(body) == 0 {
+		return body
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+// ReplayTransport http.RoundTripper that answers requests from a cassette
+// without any network access. A request gets the first recorded response for
+// the same method, path and query that has not been replayed yet; the host is
+// ignored so cassettes recorded against one environment replay against any.
+type ReplayTransport struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayTransport Replay the cassette at path
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayTransportFromCassette(cassette), nil
+}
+
+// NewReplayTransportFromCassette Replay cassette
+func NewReplayTransportFromCassette(cassette *Cassette) *ReplayTransport {
+	return &ReplayTransport{cassette: cassette, used: make([]bool, len(cassette.Interactions))}
+}
+
+// Remaining Number of recorded interactions not replayed yet
+func (t *ReplayTransport) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	remaining := 0
+	for _, used := range t.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// RoundTrip Answer req with its recorded response
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, interaction := range t.cassette.Interactions {
+		if t.used[i] || !replayMatches(interaction.Request, req) {
+			continue
+		}
+		t.used[i] = true
+		recorded := interaction.Response
+		return &http.Response{
+			StatusCode:    recorded.StatusCode,
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction left for %s %s", req.Method, req.URL.RequestURI())
+}
+
+// replayMatches Whether recorded has the method, path and query of req
+func replayMatches(recorded CassetteRequest, req *http.Request) bool {
+	if !strings.EqualFold(recorded.Method, req.Method) {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	return err == nil && recordedURL.RequestURI() == req.URL.RequestURI()
+}
+
+// newCassetteError CONFIGURATION_ERROR for a cassette file that cannot be used
+func newCassetteError(path, message string) *SDKError {
+	detail := NewErrorDetailWithCode(ErrorCodeConfigurationError, message).
+		WithSuggestion("Check that the cassette path is writable, or was written by RecordingTransport.")
+	detail.AddContextValue("path", path)
+	return NewSDKError(detail)
+}
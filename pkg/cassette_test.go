@@ -0,0 +1,100 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordedInteractionsReplayWithServerDown(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+	push := func() (*UnifyResponse, error) {
+		return PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), nil)
+	}
+
+	recordConfig := NewSDKConfig("ak_test_secret", EnvironmentSandbox, sources, NewNoRetryConfig())
+	recordConfig.SetHTTPClient(&http.Client{Transport: NewRecordingTransport(cassettePath, nil)})
+	server := configureTestSDK(t, recordConfig, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"SUB-REC","status":"ACCEPTED"}}}`))
+	})
+	recorded, err := push()
+	if err != nil {
+		t.Fatalf("recording push failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatalf("expected a cassette file: %v", err)
+	}
+	if strings.Contains(string(content), "ak_test_secret") {
+		t.Fatalf("expected the API key to be masked in the cassette")
+	}
+	cassette, err := LoadCassette(cassettePath)
+	if err != nil || len(cassette.Interactions) != 1 || cassette.Interactions[0].Request.Method != http.MethodPost {
+		t.Fatalf("expected one recorded POST, got %+v, %v", cassette, err)
+	}
+
+	serverURL := server.URL
+	server.Close()
+
+	replay, err := NewReplayTransport(cassettePath)
+	if err != nil {
+		t.Fatalf("NewReplayTransport failed: %v", err)
+	}
+	replayConfig := NewSDKConfig("ak_test_secret", EnvironmentSandbox, sources, NewNoRetryConfig())
+	replayConfig.SetHTTPClient(&http.Client{Transport: replay})
+	if err := Configure(replayConfig); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	globalSDK.apiClient.baseURL = serverURL
+
+	replayed, err := push()
+	if err != nil {
+		t.Fatalf("replayed push failed with the server down: %v", err)
+	}
+	if got, want := *replayed.GetData().GetSubmission().SubmissionID, *recorded.GetData().GetSubmission().SubmissionID; got != want {
+		t.Fatalf("expected the recorded submission %s, got %s", want, got)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("expected the recorded interaction to be used")
+	}
+	request, _ := http.NewRequest(http.MethodPost, serverURL+"/unify", nil)
+	if _, err := replay.RoundTrip(request); err == nil {
+		t.Fatalf("expected an error once the cassette is exhausted")
+	}
+}
+
+func TestRecordingTransportReturnsResponseWhenCassetteCannotBeSaved(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	transport := NewRecordingTransport(filepath.Join(t.TempDir(), "missing", "cassette.json"), nil)
+	transport.SetLogger(logger)
+	body := `{"payload":{"invoice_data":{"buyer":{"tax_id":"300000000000003"}}}}`
+	request, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+
+	resp, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("expected the response despite the failed save, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(logger.text(), "Failed to save cassette") {
+		t.Fatalf("expected the failed save to be logged")
+	}
+	recorded := transport.Cassette().Interactions
+	if len(recorded) != 1 || strings.Contains(recorded[0].Request.Body, "300000000000003") {
+		t.Fatalf("expected the tax ID to be masked by default, got %+v", recorded)
+	}
+}
@@ -4,9 +4,11 @@ Circuit Breaker implementation matching Python SDK exactly.
 package complyancesdk
 
 import (
-	"log"
 	"strconv"
+	"sync"
 	"time"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/retry"
 )
 
 // CircuitState Circuit breaker states
@@ -18,12 +20,23 @@ const (
 	CircuitStateHalfOpen CircuitState = "HALF_OPEN"
 )
 
-// CircuitBreaker Circuit breaker implementation matching Python SDK
+// CircuitBreaker Circuit breaker implementation matching Python SDK. It is safe
+// for concurrent use; each SDK instance owns one.
 type CircuitBreaker struct {
+	mu              sync.Mutex
 	config          *CircuitBreakerConfig
 	state           CircuitState
 	failureCount    int
 	lastFailureTime int64
+	// probesInFlight and probeSuccesses count the calls let through while half-open
+	probesInFlight int
+	probeSuccesses int
+	// failureTimes holds the times of the failures still inside the failure
+	// window; it is only used when the config sets one
+	failureTimes []int64
+	logger       Logger
+	metrics      MetricsSink
+	clock        Clock
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -33,96 +46,230 @@ func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
 		state:           CircuitStateClosed,
 		failureCount:    0,
 		lastFailureTime: 0,
+		logger:          noopLogger{},
+		metrics:         metricsSinkOrNoop(nil),
+		clock:           SystemClock,
+	}
+}
+
+// SetClock Set the clock used to time the open state; nil restores SystemClock
+func (c *CircuitBreaker) SetClock(clock Clock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clockOrSystem(clock)
+}
+
+// nowMillis Current time in milliseconds from the breaker's clock
+func (c *CircuitBreaker) nowMillis() int64 {
+	return c.clock.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// SetMetricsSink Set the sink notified of state transitions
+func (c *CircuitBreaker) SetMetricsSink(sink MetricsSink) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = metricsSinkOrNoop(sink)
+}
+
+// transition Move to a new state, reporting the change to the metrics sink
+func (c *CircuitBreaker) transition(to CircuitState) {
+	from := c.state
+	c.state = to
+	if from == to {
+		return
 	}
+	c.metrics.IncrCounter(retry.MetricCircuitTransitions, map[string]string{
+		retry.LabelFrom: circuitStateMetricLabel(from),
+		retry.LabelTo:   circuitStateMetricLabel(to),
+	})
+	c.metrics.Gauge(retry.MetricCircuitState, circuitStateMetricValue(to), nil)
+}
+
+// SetLogger Set the logger for state transitions
+func (c *CircuitBreaker) SetLogger(logger Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logger = loggerOrNoop(logger)
 }
 
-// Execute operation with circuit breaker
+// Execute operation with circuit breaker. Once the open timeout has passed the
+// breaker is half-open and lets at most GetHalfOpenMaxProbes calls through: it
+// closes when all of them succeed and opens again as soon as one fails. Other
+// calls are rejected with CIRCUIT_BREAKER_OPEN meanwhile.
 func (c *CircuitBreaker) Execute(operation func() (interface{}, error)) (interface{}, error) {
+	probe, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := operation()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if probe {
+		c.probesInFlight--
+	}
+	if err != nil {
+		c.onFailure()
+		return nil, err
+	} else {
+		c.onSuccess(probe)
+		return result, nil
+	}
+}
+
+// acquire Permission for one call, reporting whether it is a half-open probe
+func (c *CircuitBreaker) acquire() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.state == CircuitStateOpen {
-		currentTime := time.Now().UnixNano() / int64(time.Millisecond) // Convert to milliseconds
+		currentTime := c.nowMillis()
 		timeSinceLastFailure := currentTime - c.lastFailureTime
-		remainingTime := 60000 - timeSinceLastFailure // 1 minute timeout
+		remainingTime := int64(c.GetTimeout()) - timeSinceLastFailure
 
 		if c.shouldAttemptReset() {
-			c.state = CircuitStateHalfOpen
+			c.transition(CircuitStateHalfOpen)
+			c.probesInFlight, c.probeSuccesses = 0, 0
 		} else {
-			return nil, NewSDKError(NewErrorDetailWithCode(
+			return false, NewSDKError(NewErrorDetailWithCode(
 				ErrorCodeCircuitBreakerOpen,
 				"Circuit breaker is open - "+strconv.FormatInt(remainingTime/1000, 10)+" seconds remaining",
 			))
 		}
 	}
 
-	result, err := operation()
-	if err != nil {
-		c.onFailure()
-		return nil, err
-	} else {
-		c.onSuccess()
-		return result, nil
+	if c.state != CircuitStateHalfOpen {
+		return false, nil
+	}
+	if c.probesInFlight+c.probeSuccesses >= c.config.GetHalfOpenMaxProbes() {
+		return false, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeCircuitBreakerOpen,
+			"Circuit breaker is half-open - waiting for "+strconv.Itoa(c.probesInFlight)+" probe requests",
+		))
 	}
+	c.probesInFlight++
+	return true, nil
 }
 
-// onSuccess Handle successful operation
-func (c *CircuitBreaker) onSuccess() {
-	if c.state == CircuitStateHalfOpen {
-		c.state = CircuitStateClosed
+// onSuccess Handle successful operation; the breaker closes once every
+// half-open probe has succeeded
+func (c *CircuitBreaker) onSuccess(probe bool) {
+	if c.state != CircuitStateHalfOpen || !probe {
+		return
+	}
+	c.probeSuccesses++
+	if c.probeSuccesses >= c.config.GetHalfOpenMaxProbes() {
+		c.transition(CircuitStateClosed)
 		c.failureCount = 0
+		c.failureTimes = nil
 	}
 }
 
-// onFailure Handle failed operation
+// onFailure Handle failed operation; any failure while half-open opens the
+// breaker again
 func (c *CircuitBreaker) onFailure() {
-	c.failureCount++
-	c.lastFailureTime = time.Now().UnixNano() / int64(time.Millisecond) // Convert to milliseconds
+	c.lastFailureTime = c.nowMillis()
+	if c.config.GetFailureWindowMs() > 0 {
+		c.failureTimes = append(c.failureTimes, c.lastFailureTime)
+		c.expireFailures()
+	} else {
+		c.failureCount++
+	}
+
+	if c.state == CircuitStateHalfOpen || c.failureCount >= c.config.GetFailureThreshold() {
+		c.transition(CircuitStateOpen)
+	}
+}
 
-	if c.failureCount >= c.config.GetFailureThreshold() {
-		c.state = CircuitStateOpen
+// expireFailures Drop failures older than the failure window from the count
+func (c *CircuitBreaker) expireFailures() {
+	window := int64(c.config.GetFailureWindowMs())
+	if window <= 0 {
+		return
+	}
+	cutoff := c.nowMillis() - window
+	expired := 0
+	for expired < len(c.failureTimes) && c.failureTimes[expired] <= cutoff {
+		expired++
 	}
+	c.failureTimes = c.failureTimes[expired:]
+	c.failureCount = len(c.failureTimes)
 }
 
 // shouldAttemptReset Check if circuit breaker should attempt reset
 func (c *CircuitBreaker) shouldAttemptReset() bool {
-	currentTime := time.Now().UnixNano() / int64(time.Millisecond) // Convert to milliseconds
+	currentTime := c.nowMillis()
 	timeSinceLastFailure := currentTime - c.lastFailureTime
 	timeoutMillis := int64(c.config.GetTimeout())
 
 	if timeSinceLastFailure >= timeoutMillis {
-		log.Printf("Circuit breaker timeout expired (%d ms) - attempting reset", timeSinceLastFailure)
+		c.logger.Info("Circuit breaker timeout expired - attempting reset", map[string]interface{}{
+			"timeSinceLastFailureMs": timeSinceLastFailure,
+		})
 		return true
 	} else {
 		remainingTime := timeoutMillis - timeSinceLastFailure
-		log.Printf("Circuit breaker timeout not expired - %d seconds remaining", remainingTime/1000)
+		c.logger.Debug("Circuit breaker timeout not expired", map[string]interface{}{
+			"remainingSeconds": remainingTime / 1000,
+		})
 		return false
 	}
 }
 
+// Reset Close the breaker and clear its failures, e.g. once an operator has
+// fixed an outage, instead of waiting out the open timeout
+func (c *CircuitBreaker) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != CircuitStateClosed {
+		c.logger.Info("Circuit breaker reset", map[string]interface{}{"from": string(c.state), "failureCount": c.failureCount})
+	}
+	c.transition(CircuitStateClosed)
+	c.failureCount = 0
+	c.failureTimes = nil
+	c.lastFailureTime = 0
+	c.probesInFlight, c.probeSuccesses = 0, 0
+}
+
 // GetState Get circuit breaker state
 func (c *CircuitBreaker) GetState() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.state
 }
 
-// GetFailureCount Get failure count
+// GetTimeout Milliseconds the breaker stays open before letting a call through
+func (c *CircuitBreaker) GetTimeout() int {
+	return c.config.GetTimeout()
+}
+
+// GetFailureCount Get failure count, only counting failures inside the
+// failure window when one is configured
 func (c *CircuitBreaker) GetFailureCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireFailures()
 	return c.failureCount
 }
 
 // GetLastFailureTime Get last failure time
 func (c *CircuitBreaker) GetLastFailureTime() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.lastFailureTime
 }
 
 // IsOpen Check if circuit breaker is open
 func (c *CircuitBreaker) IsOpen() bool {
-	return c.state == CircuitStateOpen
+	return c.GetState() == CircuitStateOpen
 }
 
 // IsClosed Check if circuit breaker is closed
 func (c *CircuitBreaker) IsClosed() bool {
-	return c.state == CircuitStateClosed
+	return c.GetState() == CircuitStateClosed
 }
 
 // IsHalfOpen Check if circuit breaker is half open
 func (c *CircuitBreaker) IsHalfOpen() bool {
-	return c.state == CircuitStateHalfOpen
+	return c.GetState() == CircuitStateHalfOpen
 }
@@ -0,0 +1,215 @@
+package complyancesdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/retry"
+)
+
+func TestCircuitBreakerRecoversWhenFakeClockAdvances(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := NewCircuitBreaker(NewCircuitBreakerConfig(2, 60000))
+	breaker.SetClock(clock)
+
+	fail := func() (interface{}, error) { return nil, errors.New("boom") }
+	succeed := func() (interface{}, error) { return "ok", nil }
+
+	_, _ = breaker.Execute(fail)
+	_, _ = breaker.Execute(fail)
+	if !breaker.IsOpen() {
+		t.Fatalf("expected the breaker to open after 2 failures, got %s", breaker.GetState())
+	}
+
+	clock.Advance(59 * time.Second)
+	calls := 0
+	_, err := breaker.Execute(func() (interface{}, error) { calls++; return succeed() })
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeCircuitBreakerOpen || calls != 0 {
+		t.Fatalf("expected the open breaker to reject calls before the timeout, got %v after %d calls", err, calls)
+	}
+
+	clock.Advance(time.Second)
+	result, err := breaker.Execute(succeed)
+	if err != nil || result != "ok" {
+		t.Fatalf("expected the breaker to let a call through after the timeout, got %v, %v", result, err)
+	}
+	if !breaker.IsClosed() || breaker.GetFailureCount() != 0 {
+		t.Fatalf("expected a successful half-open call to close the breaker, got %s with %d failures", breaker.GetState(), breaker.GetFailureCount())
+	}
+}
+
+// openBreaker Breaker tripped by threshold failures whose open timeout has passed
+func openBreaker(t *testing.T, clock *FakeClock, config *CircuitBreakerConfig) *CircuitBreaker {
+	t.Helper()
+	breaker := NewCircuitBreaker(config)
+	breaker.SetClock(clock)
+	for i := 0; i < config.GetFailureThreshold(); i++ {
+		_, _ = breaker.Execute(func() (interface{}, error) { return nil, errors.New("boom") })
+	}
+	if !breaker.IsOpen() {
+		t.Fatalf("expected the breaker to open, got %s", breaker.GetState())
+	}
+	clock.Advance(time.Duration(config.GetTimeout()) * time.Millisecond)
+	return breaker
+}
+
+func TestHalfOpenBreakerLimitsProbes(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	config := NewCircuitBreakerConfig(1, 1000)
+	config.HalfOpenMaxProbes = 2
+	breaker := openBreaker(t, clock, config)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := breaker.Execute(func() (interface{}, error) {
+				started <- struct{}{}
+				<-release
+				return "ok", nil
+			})
+			done <- err
+		}()
+	}
+	<-started
+	<-started
+
+	calls := 0
+	_, err := breaker.Execute(func() (interface{}, error) { calls++; return "ok", nil })
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeCircuitBreakerOpen || calls != 0 {
+		t.Fatalf("expected a third call to be rejected while 2 probes are in flight, got %v after %d calls", err, calls)
+	}
+	if !breaker.IsHalfOpen() {
+		t.Fatalf("expected the breaker to stay half-open, got %s", breaker.GetState())
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("probe failed: %v", err)
+		}
+	}
+	if !breaker.IsClosed() || breaker.GetFailureCount() != 0 {
+		t.Fatalf("expected the breaker to close after both probes succeeded, got %s with %d failures", breaker.GetState(), breaker.GetFailureCount())
+	}
+}
+
+func TestHalfOpenBreakerReopensOnProbeFailure(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	config := NewCircuitBreakerConfig(3, 1000)
+	config.HalfOpenMaxProbes = 2
+	breaker := openBreaker(t, clock, config)
+
+	if _, err := breaker.Execute(func() (interface{}, error) { return "ok", nil }); err != nil {
+		t.Fatalf("expected the first probe to go through, got %v", err)
+	}
+	if !breaker.IsHalfOpen() {
+		t.Fatalf("expected one successful probe of two to keep the breaker half-open, got %s", breaker.GetState())
+	}
+	if _, err := breaker.Execute(func() (interface{}, error) { return nil, errors.New("still down") }); err == nil {
+		t.Fatalf("expected the failing probe's error")
+	}
+	if !breaker.IsOpen() {
+		t.Fatalf("expected a failed probe to reopen the breaker at once, got %s", breaker.GetState())
+	}
+
+	calls := 0
+	_, err := breaker.Execute(func() (interface{}, error) { calls++; return "ok", nil })
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeCircuitBreakerOpen || calls != 0 {
+		t.Fatalf("expected the reopened breaker to wait out a new timeout, got %v after %d calls", err, calls)
+	}
+}
+
+func TestResetCircuitBreakerResumesQueuedSends(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sdk, tokens := newInstanceTestSDK(t, "key-reset")
+	breaker := sdk.apiClient.GetCircuitBreaker()
+	for !breaker.IsOpen() {
+		_, _ = breaker.Execute(func() (interface{}, error) { return nil, errors.New("outage") })
+	}
+
+	request := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload("INV-RESET")).
+		RequestID("req-reset").
+		APIKey("key-reset").
+		Build()
+	if err := sdk.queueManager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	sdk.ProcessPendingSubmissions()
+	if sent := tokens(); len(sent) != 0 {
+		t.Fatalf("expected the open breaker to hold the submission back, got %d requests", len(sent))
+	}
+
+	sdk.ResetCircuitBreaker()
+	if !breaker.IsClosed() || breaker.GetFailureCount() != 0 || breaker.GetLastFailureTime() != 0 {
+		t.Fatalf("expected a closed breaker without failures, got %s with %d failures", breaker.GetState(), breaker.GetFailureCount())
+	}
+	sdk.ProcessPendingSubmissions()
+	if sent := tokens(); len(sent) != 1 {
+		t.Fatalf("expected the queued submission to be sent after the reset, got %d requests", len(sent))
+	}
+	if status := sdk.GetDetailedQueueStatus(); status.PendingCount != 0 || status.SuccessCount != 1 {
+		t.Fatalf("expected the submission to succeed, got %s", status.String())
+	}
+}
+
+func TestFailuresOutsideWindowDoNotOpenBreaker(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	config := NewCircuitBreakerConfig(3, 60000)
+	config.FailureWindowMs = 10000
+	breaker := NewCircuitBreaker(config)
+	breaker.SetClock(clock)
+	fail := func() (interface{}, error) { return nil, errors.New("boom") }
+
+	for i := 0; i < 5; i++ {
+		_, _ = breaker.Execute(fail)
+		clock.Advance(6 * time.Second)
+	}
+	if !breaker.IsClosed() {
+		t.Fatalf("expected failures spread beyond the window to leave the breaker closed, got %s", breaker.GetState())
+	}
+	if count := breaker.GetFailureCount(); count != 1 {
+		t.Fatalf("expected only the latest failure to stay in the window, got %d", count)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _ = breaker.Execute(fail)
+		clock.Advance(time.Second)
+	}
+	if !breaker.IsOpen() {
+		t.Fatalf("expected 3 failures within the window to open the breaker, got %s", breaker.GetState())
+	}
+}
+
+func TestRetryCircuitBreakerFailureWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := retry.NewCircuitBreaker(3, time.Minute).
+		WithFailureWindow(10 * time.Second).
+		WithClock(func() time.Time { return now })
+
+	for i := 0; i < 5; i++ {
+		breaker.RecordFailure()
+		now = now.Add(6 * time.Second)
+	}
+	if breaker.GetState() != retry.CircuitClosed || breaker.GetFailureCount() != 1 {
+		t.Fatalf("expected old failures to age out, got %s with %d failures", breaker.GetState(), breaker.GetFailureCount())
+	}
+
+	for i := 0; i < 3; i++ {
+		breaker.RecordFailure()
+		now = now.Add(time.Second)
+	}
+	if !breaker.IsOpen() {
+		t.Fatalf("expected 3 failures within the window to open the circuit, got %s", breaker.GetState())
+	}
+	now = now.Add(time.Minute)
+	if breaker.IsOpen() {
+		t.Fatalf("expected the circuit to half-open once the fake clock passes the timeout")
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Clock abstraction so time-dependent behaviour can be driven by tests.
+*/
+package complyancesdk
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock Source of the current time and of timers for the circuit breaker,
+// retry strategy and persistent queue
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel that receives the time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the Clock used unless another is injected
+var SystemClock Clock = systemClock{}
+
+// clockOrSystem Return clock, or SystemClock when nil
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// FakeClock Clock that only moves when advanced, for deterministic tests
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+// fakeClockWaiter Pending After call on a FakeClock
+type fakeClockWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a FakeClock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake current time
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives once the clock is advanced by d
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeClockWaiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance Move the clock forward by d, firing any After channels that come due
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+
+	pending := f.waiters[:0]
+	for _, waiter := range f.waiters {
+		if waiter.deadline.After(f.now) {
+			pending = append(pending, waiter)
+			continue
+		}
+		waiter.ch <- f.now
+	}
+	f.waiters = pending
+}
+
+// PendingTimers Number of After channels that have not fired yet
+func (f *FakeClock) PendingTimers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
@@ -0,0 +1,83 @@
+package complyancetest
+
+import (
+	"net/http"
+
+	complyancesdk "github.com/complyance-io/complyance-go-sdk/v3/pkg"
+)
+
+// Name and version of the source built by NewSource
+const (
+	SourceName    = "complyancetest"
+	SourceVersion = "1.0"
+)
+
+// NewSource First-party source named SourceName, version SourceVersion
+func NewSource() *complyancesdk.Source {
+	sourceType := complyancesdk.SourceTypeFirstParty
+	return complyancesdk.NewSource(SourceName, SourceVersion, &sourceType)
+}
+
+// NewPayload Invoice payload with the fields the SDK requires, numbered invoiceNumber
+func NewPayload(invoiceNumber string) map[string]interface{} {
+	return map[string]interface{}{
+		"invoice_data": map[string]interface{}{
+			"invoice_number": invoiceNumber,
+			"issue_date":     "2024-01-15",
+			"currency":       "SAR",
+			"total_amount":   115.0,
+			"tax_amount":     15.0,
+		},
+		"seller_info": map[string]interface{}{
+			"name":       "Test Seller",
+			"vat_number": "300000000000003",
+		},
+		"buyer_info": map[string]interface{}{
+			"name":       "Test Buyer",
+			"vat_number": "300000000000004",
+		},
+		"line_items": []interface{}{
+			map[string]interface{}{
+				"description": "Test item",
+				"quantity":    1.0,
+				"unit_price":  100.0,
+				"tax_rate":    15.0,
+			},
+		},
+	}
+}
+
+// Accepted Successful response with an ACCEPTED submission
+func Accepted(submissionID string) Response {
+	status := "ACCEPTED"
+	return Response{
+		StatusCode: http.StatusOK,
+		Body: &complyancesdk.UnifyResponse{
+			Status: "success",
+			Data: &complyancesdk.UnifyResponseData{
+				Submission: &complyancesdk.SubmissionResponse{
+					SubmissionID: &submissionID,
+					Status:       &status,
+				},
+			},
+		},
+	}
+}
+
+// Rejected Validation failure with code and message, sent as HTTP 422
+func Rejected(code complyancesdk.ErrorCode, message string) Response {
+	return Response{StatusCode: http.StatusUnprocessableEntity, Body: errorBody(code, message)}
+}
+
+// ServerError Error response with HTTP status statusCode, e.g. 503
+func ServerError(statusCode int) Response {
+	return Response{StatusCode: statusCode, Body: errorBody(complyancesdk.ErrorCodeInternalServerError, http.StatusText(statusCode))}
+}
+
+// errorBody Error UnifyResponse with code and message
+func errorBody(code complyancesdk.ErrorCode, message string) *complyancesdk.UnifyResponse {
+	return &complyancesdk.UnifyResponse{
+		Status: "error",
+		Error:  &complyancesdk.ErrorDetail{Code: &code, Message: &message},
+	}
+}
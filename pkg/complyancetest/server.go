@@ -0,0 +1,158 @@
+/*
+Package complyancetest provides a mock Complyance API and fixtures for testing
+code that uses the SDK.
+
+	server := complyancetest.NewServer(t)
+	server.Respond(complyancesdk.CountrySA, complyancesdk.LogicalDocTypeTaxInvoice, complyancetest.Rejected(complyancesdk.ErrorCodeValidationFailed, "Missing VAT number"))
+	if err := complyancesdk.Configure(server.Config()); err != nil {
+		t.Fatal(err)
+	}
+*/
+package complyancetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	complyancesdk "github.com/complyance-io/complyance-go-sdk/v3/pkg"
+)
+
+// APIKey API key used by Server.Config
+const APIKey = "ak_test_complyancetest"
+
+// Response Canned response of the mock server
+type Response struct {
+	StatusCode int
+	// Body is encoded as JSON, usually a *complyancesdk.UnifyResponse
+	Body interface{}
+}
+
+// Request Unify request received by the mock server
+type Request struct {
+	Country      complyancesdk.Country
+	DocumentType string
+	Header       http.Header
+	Body         map[string]interface{}
+}
+
+// responseKey Country and GETS base document type a response is registered for
+type responseKey struct {
+	country      complyancesdk.Country
+	documentType string
+}
+
+// Server Mock Complyance API. Unify requests get the response registered for
+// their country and document type, or the default response, Accepted unless
+// changed with RespondDefault. The health endpoint used by Preflight always
+// succeeds.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[responseKey]Response
+	fallback  Response
+	requests  []Request
+}
+
+// NewServer Start a mock server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	server := &Server{
+		responses: map[responseKey]Response{},
+		fallback:  Accepted("SUB-TEST-1"),
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Respond Answer requests for country and logicalType with response. Requests
+// are matched on the GETS base document type logicalType maps to, so for
+// example TAX_INVOICE and SIMPLIFIED_TAX_INVOICE share a response.
+func (s *Server) Respond(country complyancesdk.Country, logicalType complyancesdk.LogicalDocType, response Response) {
+	key := responseKey{country: country, documentType: complyancesdk.MapLogicalDocTypeToGetsV2(logicalType).Base}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[key] = response
+}
+
+// RespondDefault Answer requests without a registered response with response
+func (s *Server) RespondDefault(response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = response
+}
+
+// Requests Unify requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Config SDK configuration for the DEV environment pointed at the server, with
+// the given sources (NewSource when none), no retries and an in-memory queue
+func (s *Server) Config(sources ...*complyancesdk.Source) *complyancesdk.SDKConfig {
+	if len(sources) == 0 {
+		sources = []*complyancesdk.Source{NewSource()}
+	}
+	config := complyancesdk.NewSDKConfig(APIKey, complyancesdk.EnvironmentDev, sources, complyancesdk.NewNoRetryConfig())
+	config.WithEnvironmentURLs(map[complyancesdk.Environment]string{complyancesdk.EnvironmentDev: s.URL})
+	config.SetQueueMode(complyancesdk.QueueModeMemory)
+	return config
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == complyancesdk.PreflightPath {
+		writeJSON(w, Response{StatusCode: http.StatusOK, Body: map[string]interface{}{"status": "ok", "data": map[string]interface{}{"environment": "dev"}}})
+		return
+	}
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/unify") {
+		writeJSON(w, Response{StatusCode: http.StatusNotFound, Body: errorBody(complyancesdk.ErrorCodeAPIError, "Not found: "+r.URL.Path)})
+		return
+	}
+
+	request := Request{Header: r.Header.Clone()}
+	raw, _ := io.ReadAll(r.Body)
+	if err := json.Unmarshal(raw, &request.Body); err != nil {
+		writeJSON(w, Response{StatusCode: http.StatusBadRequest, Body: errorBody(complyancesdk.ErrorCodeInvalidArgument, "Request body is not JSON")})
+		return
+	}
+	if country, ok := request.Body["country"].(string); ok {
+		request.Country = complyancesdk.Country(country)
+	}
+	if documentType, ok := request.Body["documentType"].(map[string]interface{}); ok {
+		request.DocumentType, _ = documentType["base"].(string)
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	response, ok := s.responses[responseKey{country: request.Country, documentType: request.DocumentType}]
+	if !ok {
+		response = s.fallback
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, response)
+}
+
+// writeJSON Write response as JSON, with status 200 when it has none
+func writeJSON(w http.ResponseWriter, response Response) {
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	body, err := json.Marshal(response.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
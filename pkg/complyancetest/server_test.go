@@ -0,0 +1,61 @@
+package complyancetest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	complyancesdk "github.com/complyance-io/complyance-go-sdk/v3/pkg"
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/complyancetest"
+)
+
+func TestServerAnswersPerCountryAndDocumentType(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := complyancetest.NewServer(t)
+	server.Respond(complyancesdk.CountryMY, complyancesdk.LogicalDocTypeCreditNote,
+		complyancetest.Rejected(complyancesdk.ErrorCodeValidationFailed, "Buyer VAT number is invalid"))
+	if err := complyancesdk.Configure(server.Config()); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	push := func(logicalType complyancesdk.LogicalDocType, country complyancesdk.Country, invoiceNumber string) (*complyancesdk.UnifyResponse, error) {
+		payload := complyancetest.NewPayload(invoiceNumber)
+		if complyancesdk.IsAdjustmentNote(logicalType) {
+			payload["invoice_data"].(map[string]interface{})["original_invoice_number"] = "INV-1"
+		}
+		return complyancesdk.PushToUnify(complyancetest.SourceName, complyancetest.SourceVersion, logicalType, country,
+			complyancesdk.OperationSingle, complyancesdk.ModeDocuments, complyancesdk.PurposeInvoicing,
+			payload, nil)
+	}
+
+	response, err := push(complyancesdk.LogicalDocTypeTaxInvoice, complyancesdk.CountrySA, "INV-1")
+	if err != nil {
+		t.Fatalf("expected the default accepted response, got %v", err)
+	}
+	if submission := response.GetData().GetSubmission(); submission == nil || !submission.IsAccepted() {
+		t.Fatalf("expected an accepted submission, got %+v", response.GetData())
+	}
+
+	_, err = push(complyancesdk.LogicalDocTypeCreditNote, complyancesdk.CountryMY, "CN-1")
+	if status, ok := complyancesdk.HTTPStatusCode(err); !ok || status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected the canned 422 rejection, got %v", err)
+	}
+	rejection, ok := errors.Unwrap(err).(*complyancesdk.SDKError)
+	if !ok || rejection.ErrorDetail.Message == nil || *rejection.ErrorDetail.Message != "Buyer VAT number is invalid" {
+		t.Fatalf("expected the canned rejection message, got %v", errors.Unwrap(err))
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 || requests[0].Country != complyancesdk.CountrySA || requests[1].DocumentType != "credit_note" {
+		t.Fatalf("expected the SA invoice and the MY credit note to be recorded, got %+v", requests)
+	}
+	if requests[0].Body["apiKey"] != complyancetest.APIKey {
+		t.Fatalf("expected requests to carry the test API key")
+	}
+
+	result, err := complyancesdk.Preflight(context.Background())
+	if err != nil || !result.OK() {
+		t.Fatalf("expected preflight to pass against the mock server, got %+v, %v", result, err)
+	}
+}
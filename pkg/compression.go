@@ -0,0 +1,53 @@
+/*
+Gzip compression of request bodies for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// HeaderContentEncoding is set to "gzip" on compressed request bodies
+const HeaderContentEncoding = "Content-Encoding"
+
+// SetCompression Gzip request bodies larger than thresholdBytes, or every body
+// when force is set. A threshold of 0 or less disables automatic compression.
+func (a *APIClient) SetCompression(thresholdBytes int, force bool) {
+	a.compressionThreshold = thresholdBytes
+	a.forceCompression = force
+}
+
+// compressRequestBody Gzip body when compression applies, setting the
+// Content-Encoding header. Signatures are computed over the uncompressed body,
+// so this must run after signRequest.
+func (a *APIClient) compressRequestBody(headers map[string]string, body []byte) ([]byte, error) {
+	if !a.forceCompression && (a.compressionThreshold <= 0 || len(body) <= a.compressionThreshold) {
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, newCompressionError(err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, newCompressionError(err)
+	}
+
+	headers[HeaderContentEncoding] = "gzip"
+	a.logger.Debug("Compressed request body", map[string]interface{}{
+		"originalBytes":   len(body),
+		"compressedBytes": buf.Len(),
+	})
+	return buf.Bytes(), nil
+}
+
+// newCompressionError Error for a request body that could not be compressed
+func newCompressionError(err error) *SDKError {
+	return NewSDKError(NewErrorDetailWithCode(
+		ErrorCodeAPIError,
+		fmt.Sprintf("Failed to compress request body: %v", err),
+	))
+}
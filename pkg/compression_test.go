@@ -0,0 +1,72 @@
+package complyancesdk
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type capturedBody struct {
+	encoding string
+	body     []byte
+}
+
+func newCompressionTestClient(t *testing.T, captured *capturedBody) *APIClient {
+	t.Helper()
+	return newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		captured.encoding = r.Header.Get(HeaderContentEncoding)
+		var reader io.Reader = r.Body
+		if captured.encoding == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("body is not gzipped: %v", err)
+				return
+			}
+			reader = gz
+		}
+		captured.body, _ = io.ReadAll(reader)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+}
+
+func TestRequestBodyIsGzippedAboveThreshold(t *testing.T) {
+	captured := &capturedBody{}
+	client := newCompressionTestClient(t, captured)
+	client.SetCompression(4096, false)
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-SMALL")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured.encoding != "" {
+		t.Fatalf("expected small body to be sent plain, got encoding %q", captured.encoding)
+	}
+
+	large := newTestUnifyRequest("INV-LARGE")
+	large.GetPayload()["notes"] = strings.Repeat("line item description ", 1000)
+	if _, err := client.SendUnifyRequest(large); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured.encoding != "gzip" {
+		t.Fatalf("expected large body to be gzipped")
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(captured.body, &body); err != nil || body["requestId"] != *large.GetRequestID() {
+		t.Fatalf("decompressed body is not the request: %v", err)
+	}
+}
+
+func TestForcedCompressionAppliesToRawJSON(t *testing.T) {
+	captured := &capturedBody{}
+	client := newCompressionTestClient(t, captured)
+	client.SetCompression(0, true)
+
+	if _, err := client.SendRawJSONRequest(`{"requestId":"raw-1"}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured.encoding != "gzip" || string(captured.body) != `{"requestId":"raw-1"}` {
+		t.Fatalf("expected gzipped raw body, got encoding %q body %q", captured.encoding, captured.body)
+	}
+}
@@ -2,31 +2,96 @@
 SDK Configuration for the Complyance SDK matching Python SDK exactly.
 */
 package complyancesdk
-//
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RejectionCorrector is invoked when a submission comes back REJECTED. It may
+// return a corrected copy of the payload together with retry=true to have the
+// SDK resubmit it once; returning retry=false leaves the rejection as-is.
+type RejectionCorrector func(resp *SubmissionResponse, payload map[string]interface{}) (corrected map[string]interface{}, retry bool)
+
+// BeforeSendHook is called with the fully built request, meta.config merged
+// and destinations generated, right before it is serialized and sent. It may
+// modify the request, e.g. to attach audit metadata to the payload, or return
+// an error to abort the submission. It also runs for ValidateDocument, Convert
+// and BuildSerializedRequest, so previews match what is sent.
+type BeforeSendHook func(request *UnifyRequest) error
+
 // SDKConfig model matching Python SDK
 type SDKConfig struct {
 	APIKey                    string       `json:"api_key"`
+	AllowAPIKeyEnvironmentMismatch bool    `json:"allow_api_key_environment_mismatch,omitempty"`
 	Environment               Environment  `json:"environment"`
 	Sources                   []*Source    `json:"sources"`
+	DefaultSource             *Source      `json:"default_source,omitempty"`
 	RetryConfig               *RetryConfig `json:"retry_config"`
+	RetryPreset               string       `json:"retry_preset,omitempty"`
 	AutoGenerateTaxDestination bool         `json:"auto_generate_tax_destination"`
+	MergeDestinations          bool         `json:"merge_destinations,omitempty"`
 	CorrelationID             *string      `json:"correlation_id,omitempty"`
+	RejectionCorrector        RejectionCorrector `json:"-"`
+	BeforeSend                BeforeSendHook     `json:"-"`
+	Logger                    Logger             `json:"-"`
+	Redaction                 *RedactionConfig   `json:"redaction,omitempty"`
+	TracerProvider            trace.TracerProvider `json:"-"`
+	MetricsSink               MetricsSink          `json:"-"`
+	EnvironmentURLs           map[Environment]string `json:"environment_urls,omitempty"`
+	AllowedCountries          map[Environment][]Country `json:"allowed_countries,omitempty"`
+	SigningEnabled            bool                   `json:"signing_enabled"`
+	SigningSecret             string                 `json:"signing_secret,omitempty"`
+	TLSConfig                 *tls.Config            `json:"-"`
+	ClientCertFile            string                 `json:"client_cert_file,omitempty"`
+	ClientKeyFile             string                 `json:"client_key_file,omitempty"`
+	CACertFile                string                 `json:"ca_cert_file,omitempty"`
+	HTTPClient                *http.Client           `json:"-"`
+	QueueEventHandler         QueueEventHandler      `json:"-"`
+	QueueMaxAttempts          int                    `json:"queue_max_attempts,omitempty"`
+	CompressionThresholdBytes int                    `json:"compression_threshold_bytes,omitempty"`
+	ForceCompression          bool                   `json:"force_compression,omitempty"`
+	MaxResponseBytes          int64                  `json:"max_response_bytes,omitempty"`
+	MaxPayloadBytes           int64                  `json:"max_payload_bytes,omitempty"`
+	MaxBulkPayloadBytes       int64                  `json:"max_bulk_payload_bytes,omitempty"`
+	RateLimitPerSecond        float64                `json:"rate_limit_per_second,omitempty"`
+	RateLimitBurst            int                    `json:"rate_limit_burst,omitempty"`
+	RateLimitThrottleThreshold int                   `json:"rate_limit_throttle_threshold,omitempty"`
+	RecordRequestJSON         bool                   `json:"record_request_json,omitempty"`
+	InvoiceDataPath           string                 `json:"invoice_data_path,omitempty"`
+	QueueMode                 QueueMode              `json:"queue_mode,omitempty"`
+	QueueBasePath             string                 `json:"queue_base_path,omitempty"`
+	InstanceName              string                 `json:"instance_name,omitempty"`
+	QueueEncryptionKey        []byte                 `json:"-"`
+	DestinationGenerator      DestinationGenerator   `json:"-"`
+	TextNormalizationCountries []Country             `json:"text_normalization_countries,omitempty"`
+	TextNormalizer            TextNormalizer         `json:"-"`
+	MonetaryFieldPaths        []string               `json:"monetary_field_paths,omitempty"`
+	MonetaryPrecision         int                    `json:"monetary_precision,omitempty"`
+	OriginalReferenceFields   []string               `json:"original_reference_fields,omitempty"`
+	DocumentIDPaths           map[DocumentType][]string `json:"document_id_paths,omitempty"`
+	Clock                     Clock                  `json:"-"`
+
+	// retryConfigDefaulted is set while RetryConfig is the environment's default
+	// rather than one the user supplied
+	retryConfigDefaulted bool
 }
 
-// NewSDKConfig creates a new SDK configuration
+// NewSDKConfig creates a new SDK configuration. A nil retryConfig selects the
+// environment's default, see DefaultRetryConfigForEnvironment.
 func NewSDKConfig(apiKey string, environment Environment, sources []*Source, retryConfig *RetryConfig) *SDKConfig {
-	if retryConfig == nil {
-		retryConfig = NewDefaultRetryConfig()
-	}
-	
-	return &SDKConfig{
+	config := &SDKConfig{
 		APIKey:                    apiKey,
 		Environment:               environment,
 		Sources:                   sources,
-		RetryConfig:               retryConfig,
 		AutoGenerateTaxDestination: true,
 		CorrelationID:             nil,
 	}
+	config.SetRetryConfig(retryConfig)
+	return config
 }
 
 // NewSDKConfigBuilder Create a builder for SDKConfig
@@ -45,6 +110,19 @@ func (s *SDKConfig) GetAPIKey() string {
 	return s.APIKey
 }
 
+// IsAllowAPIKeyEnvironmentMismatch getter for accepting a test key in
+// PRODUCTION or a live key elsewhere
+func (s *SDKConfig) IsAllowAPIKeyEnvironmentMismatch() bool {
+	return s.AllowAPIKeyEnvironmentMismatch
+}
+
+// SetAllowAPIKeyEnvironmentMismatch setter for accepting an API key whose
+// ak_test_/ak_live_ prefix does not match the environment; the mismatch is
+// then logged as a warning instead of failing Configure
+func (s *SDKConfig) SetAllowAPIKeyEnvironmentMismatch(allow bool) {
+	s.AllowAPIKeyEnvironmentMismatch = allow
+}
+
 // GetEnvironment getter for environment
 func (s *SDKConfig) GetEnvironment() Environment {
 	return s.Environment
@@ -55,11 +133,22 @@ func (s *SDKConfig) GetSources() []*Source {
 	return s.Sources
 }
 
+// GetSourceRegistry Registry over the configured sources, for resolving a source
+// by name with an optional version
+func (s *SDKConfig) GetSourceRegistry() *SourceRegistry {
+	return NewSourceRegistry(s.Sources)
+}
+
 // GetRetryConfig getter for retry config
 func (s *SDKConfig) GetRetryConfig() *RetryConfig {
 	return s.RetryConfig
 }
 
+// GetDestinationGenerator getter for the destination generation rules
+func (s *SDKConfig) GetDestinationGenerator() DestinationGenerator {
+	return s.DestinationGenerator
+}
+
 // IsAutoGenerateTaxDestination getter for auto generate tax destination
 func (s *SDKConfig) IsAutoGenerateTaxDestination() bool {
 	return s.AutoGenerateTaxDestination
@@ -70,13 +159,372 @@ func (s *SDKConfig) GetCorrelationID() *string {
 	return s.CorrelationID
 }
 
-// SetRetryConfig setter for retry config
+// GetRejectionCorrector getter for rejection corrector
+func (s *SDKConfig) GetRejectionCorrector() RejectionCorrector {
+	return s.RejectionCorrector
+}
+
+// GetLogger getter for logger
+func (s *SDKConfig) GetLogger() Logger {
+	return s.Logger
+}
+
+// GetClock getter for the clock; nil means SystemClock
+func (s *SDKConfig) GetClock() Clock {
+	return s.Clock
+}
+
+// GetRedaction getter for the log redaction config
+func (s *SDKConfig) GetRedaction() *RedactionConfig {
+	return s.Redaction
+}
+
+// GetTracerProvider getter for the OpenTelemetry tracer provider
+func (s *SDKConfig) GetTracerProvider() trace.TracerProvider {
+	return s.TracerProvider
+}
+
+// GetMetricsSink getter for the metrics sink
+func (s *SDKConfig) GetMetricsSink() MetricsSink {
+	return s.MetricsSink
+}
+
+// GetEnvironmentURLs getter for per-environment base URL overrides
+func (s *SDKConfig) GetEnvironmentURLs() map[Environment]string {
+	return s.EnvironmentURLs
+}
+
+// GetBaseURL Unify base URL for the configured environment, using the
+// EnvironmentURLs override when one is set
+func (s *SDKConfig) GetBaseURL() string {
+	if override := strings.TrimSpace(s.EnvironmentURLs[s.Environment]); override != "" {
+		return normalizeUnifyURL(override)
+	}
+	return s.Environment.GetBaseURL()
+}
+
+// WithEnvironmentURLs Override the base URL per environment, e.g. to point
+// DEV/TEST/STAGE at a dedicated tenant. Values may be a host URL such as
+// "https://acme.example.com" or the full ".../unify" endpoint.
+func (s *SDKConfig) WithEnvironmentURLs(urls map[Environment]string) *SDKConfig {
+	s.EnvironmentURLs = copyEnvironmentURLs(urls)
+	return s
+}
+
+// copyEnvironmentURLs Copy of the overrides so later changes by the caller have no effect
+func copyEnvironmentURLs(urls map[Environment]string) map[Environment]string {
+	if urls == nil {
+		return nil
+	}
+	copied := make(map[Environment]string, len(urls))
+	for environment, url := range urls {
+		copied[environment] = url
+	}
+	return copied
+}
+
+// GetAllowedCountries getter for per-environment country allow-list overrides
+func (s *SDKConfig) GetAllowedCountries() map[Environment][]Country {
+	return s.AllowedCountries
+}
+
+// WithAllowedCountries Replace the countries allowed in an environment, e.g. to
+// enable AE or SG in PRODUCTION once certified. Environments without an entry
+// keep the DefaultAllowedCountries restrictions.
+func (s *SDKConfig) WithAllowedCountries(environment Environment, countries ...Country) *SDKConfig {
+	if s.AllowedCountries == nil {
+		s.AllowedCountries = make(map[Environment][]Country)
+	}
+	s.AllowedCountries[environment] = append([]Country{}, countries...)
+	return s
+}
+
+// AllowedCountriesFor Countries allowed in an environment: the AllowedCountries
+// override if there is one, otherwise DefaultAllowedCountries. restricted is
+// false when every country is allowed.
+func (s *SDKConfig) AllowedCountriesFor(environment Environment) (countries []Country, restricted bool) {
+	if override, ok := s.AllowedCountries[environment]; ok {
+		return append([]Country{}, override...), true
+	}
+	return DefaultAllowedCountries(environment)
+}
+
+// copyAllowedCountries Copy of the allow-lists so later changes by the caller have no effect
+func copyAllowedCountries(allowed map[Environment][]Country) map[Environment][]Country {
+	if allowed == nil {
+		return nil
+	}
+	copied := make(map[Environment][]Country, len(allowed))
+	for environment, countries := range allowed {
+		copied[environment] = append([]Country{}, countries...)
+	}
+	return copied
+}
+
+// normalizeUnifyURL Ensure an override points at the /unify endpoint
+func normalizeUnifyURL(url string) string {
+	url = strings.TrimRight(strings.TrimSpace(url), "/")
+	if strings.HasSuffix(url, "/unify") {
+		return url
+	}
+	return url + "/unify"
+}
+
+// IsSigningEnabled getter for request signing
+func (s *SDKConfig) IsSigningEnabled() bool {
+	return s.SigningEnabled
+}
+
+// GetSigningSecret getter for the request signing secret
+func (s *SDKConfig) GetSigningSecret() string {
+	return s.SigningSecret
+}
+
+// SetRequestSigning Sign every Unify request body with an HMAC-SHA256 of secret
+func (s *SDKConfig) SetRequestSigning(enabled bool, secret string) {
+	s.SigningEnabled = enabled
+	s.SigningSecret = secret
+}
+
+// GetCompressionThresholdBytes getter for the body size above which requests are gzipped
+func (s *SDKConfig) GetCompressionThresholdBytes() int {
+	return s.CompressionThresholdBytes
+}
+
+// IsForceCompression getter for gzipping every request body
+func (s *SDKConfig) IsForceCompression() bool {
+	return s.ForceCompression
+}
+
+// SetCompression Gzip request bodies larger than thresholdBytes, or every body
+// when force is set; a threshold of 0 disables automatic compression
+func (s *SDKConfig) SetCompression(thresholdBytes int, force bool) {
+	s.CompressionThresholdBytes = thresholdBytes
+	s.ForceCompression = force
+}
+
+// GetMaxResponseBytes getter for the largest response body the client reads
+func (s *SDKConfig) GetMaxResponseBytes() int64 {
+	return s.MaxResponseBytes
+}
+
+// SetMaxResponseBytes setter for the largest response body the client reads;
+// 0 uses DefaultMaxResponseBytes
+func (s *SDKConfig) SetMaxResponseBytes(maxBytes int64) {
+	s.MaxResponseBytes = maxBytes
+}
+
+// GetMaxPayloadBytes getter for the largest serialized request the client sends
+func (s *SDKConfig) GetMaxPayloadBytes() int64 {
+	return s.MaxPayloadBytes
+}
+
+// GetMaxBulkPayloadBytes getter for the largest serialized bulk request the client sends
+func (s *SDKConfig) GetMaxBulkPayloadBytes() int64 {
+	return s.MaxBulkPayloadBytes
+}
+
+// SetMaxPayloadBytes setter for the largest serialized request the client
+// sends, with bulkMaxBytes for OperationBulk requests; 0 uses
+// DefaultMaxPayloadBytes or DefaultMaxBulkPayloadBytes
+func (s *SDKConfig) SetMaxPayloadBytes(maxBytes, bulkMaxBytes int64) {
+	s.MaxPayloadBytes = maxBytes
+	s.MaxBulkPayloadBytes = bulkMaxBytes
+}
+
+// GetRateLimitPerSecond getter for the average requests per second; 0 means unlimited
+func (s *SDKConfig) GetRateLimitPerSecond() float64 {
+	return s.RateLimitPerSecond
+}
+
+// GetRateLimitBurst getter for the number of requests allowed at once
+func (s *SDKConfig) GetRateLimitBurst() int {
+	return s.RateLimitBurst
+}
+
+// SetRateLimit setter for the client-side rate limit every request, including
+// queue retries, passes through: requestsPerSecond on average with bursts of up
+// to burst requests. A rate of 0 disables the limit.
+func (s *SDKConfig) SetRateLimit(requestsPerSecond float64, burst int) {
+	s.RateLimitPerSecond = requestsPerSecond
+	s.RateLimitBurst = burst
+}
+
+// GetRateLimitThrottleThreshold getter for the X-RateLimit-Remaining count
+// below which requests wait for the reset; 0 never waits
+func (s *SDKConfig) GetRateLimitThrottleThreshold() int {
+	return s.RateLimitThrottleThreshold
+}
+
+// SetRateLimitThrottleThreshold setter for delaying requests until
+// X-RateLimit-Reset once the API reports fewer than threshold remaining
+func (s *SDKConfig) SetRateLimitThrottleThreshold(threshold int) {
+	s.RateLimitThrottleThreshold = threshold
+}
+
+// IsRecordRequestJSON getter for attaching the sent request JSON to responses
+func (s *SDKConfig) IsRecordRequestJSON() bool {
+	return s.RecordRequestJSON
+}
+
+// SetRecordRequestJSON setter for attaching the sent request JSON, with the API
+// key redacted, to each UnifyResponse for audit archiving
+func (s *SDKConfig) SetRecordRequestJSON(enabled bool) {
+	s.RecordRequestJSON = enabled
+}
+
+// GetInvoiceDataPath getter for the dot-separated path of the invoice data object
+// in payloads, DefaultInvoiceDataPath unless overridden
+func (s *SDKConfig) GetInvoiceDataPath() string {
+	if strings.TrimSpace(s.InvoiceDataPath) == "" {
+		return DefaultInvoiceDataPath
+	}
+	return s.InvoiceDataPath
+}
+
+// SetInvoiceDataPath setter for the dot-separated path of the invoice data
+// object, e.g. "document.invoice_data"
+func (s *SDKConfig) SetInvoiceDataPath(path string) {
+	s.InvoiceDataPath = path
+}
+
+// GetQueueMode getter for the queue storage backend, QueueModeFile unless overridden
+func (s *SDKConfig) GetQueueMode() QueueMode {
+	if s.QueueMode == "" {
+		return QueueModeFile
+	}
+	return s.QueueMode
+}
+
+// SetQueueMode setter for the queue storage backend; use QueueModeMemory on
+// read-only or ephemeral filesystems
+func (s *SDKConfig) SetQueueMode(mode QueueMode) {
+	s.QueueMode = mode
+}
+
+// GetQueueBasePath getter for the file queue directory; empty means
+// ~/complyance-queue for Configure and a directory per instance below it for
+// NewSDK
+func (s *SDKConfig) GetQueueBasePath() string {
+	return s.QueueBasePath
+}
+
+// SetQueueBasePath setter for the file queue directory, so services running as
+// the same user can keep separate queues or use a dedicated volume
+func (s *SDKConfig) SetQueueBasePath(path string) {
+	s.QueueBasePath = path
+}
+
+// GetInstanceName getter for the name of an SDK created with NewSDK
+func (s *SDKConfig) GetInstanceName() string {
+	return s.InstanceName
+}
+
+// SetInstanceName setter for the name of an SDK created with NewSDK, e.g. "zatca"
+// or "lhdn". Without a QueueBasePath the instance queues under
+// ~/complyance-queue/instances/<name>; when empty the directory is derived from
+// the API key and environment.
+func (s *SDKConfig) SetInstanceName(name string) {
+	s.InstanceName = name
+}
+
+// GetQueueEncryptionKey getter for the key that encrypts file queue records at rest
+func (s *SDKConfig) GetQueueEncryptionKey() []byte {
+	return s.QueueEncryptionKey
+}
+
+// SetQueueEncryptionKey setter for the AES key (16, 24 or 32 bytes) that
+// encrypts file queue records at rest; nil stores them as plaintext JSON
+func (s *SDKConfig) SetQueueEncryptionKey(key []byte) {
+	s.QueueEncryptionKey = key
+}
+
+// GetDocumentIDPaths getter for the per-document-type paths used to find a
+// queued submission's document ID
+func (s *SDKConfig) GetDocumentIDPaths() map[DocumentType][]string {
+	return s.DocumentIDPaths
+}
+
+// WithDocumentIDPaths Set the paths tried, in order, to find the document ID of
+// a queued submission of documentType, e.g. "payload.invoice_data.credit_note_number".
+// Document types without paths use DefaultDocumentIDPaths.
+func (s *SDKConfig) WithDocumentIDPaths(documentType DocumentType, paths ...string) *SDKConfig {
+	if s.DocumentIDPaths == nil {
+		s.DocumentIDPaths = make(map[DocumentType][]string)
+	}
+	s.DocumentIDPaths[documentType] = append([]string(nil), paths...)
+	return s
+}
+
+// GetHTTPClient getter for the injected HTTP client
+func (s *SDKConfig) GetHTTPClient() *http.Client {
+	return s.HTTPClient
+}
+
+// SetHTTPClient setter for the HTTP client used for API requests; nil uses the
+// default client with DefaultTimeout
+func (s *SDKConfig) SetHTTPClient(client *http.Client) {
+	s.HTTPClient = client
+}
+
+// GetQueueEventHandler getter for the queue event handler
+func (s *SDKConfig) GetQueueEventHandler() QueueEventHandler {
+	return s.QueueEventHandler
+}
+
+// SetQueueEventHandler setter for the handler notified as submissions move
+// through the persistent queue; nil disables notifications
+func (s *SDKConfig) SetQueueEventHandler(handler QueueEventHandler) {
+	s.QueueEventHandler = handler
+}
+
+// GetQueueMaxAttempts getter for the number of attempts before a queued
+// submission is dead-lettered; 0 means DefaultQueueMaxAttempts
+func (s *SDKConfig) GetQueueMaxAttempts() int {
+	return s.QueueMaxAttempts
+}
+
+// SetQueueMaxAttempts setter for the number of attempts before a queued submission is dead-lettered
+func (s *SDKConfig) SetQueueMaxAttempts(maxAttempts int) {
+	s.QueueMaxAttempts = maxAttempts
+}
+
+// GetTLSConfig getter for the custom TLS config
+func (s *SDKConfig) GetTLSConfig() *tls.Config {
+	return s.TLSConfig
+}
+
+// SetTLSConfig setter for a custom TLS config used for API connections
+func (s *SDKConfig) SetTLSConfig(tlsConfig *tls.Config) {
+	s.TLSConfig = tlsConfig
+}
+
+// SetClientCertificate Present the PEM certificate and key in these files for mutual TLS
+func (s *SDKConfig) SetClientCertificate(certFile, keyFile string) {
+	s.ClientCertFile = certFile
+	s.ClientKeyFile = keyFile
+}
+
+// SetCACertFile Trust only the PEM CA certificates in this file for API connections
+func (s *SDKConfig) SetCACertFile(caCertFile string) {
+	s.CACertFile = caCertFile
+}
+
+// SetRetryConfig setter for retry config. A nil retryConfig selects the
+// environment's default, which then follows later SetEnvironment calls.
 func (s *SDKConfig) SetRetryConfig(retryConfig *RetryConfig) {
 	if retryConfig != nil {
 		s.RetryConfig = retryConfig
 	} else {
-		s.RetryConfig = NewDefaultRetryConfig()
+		s.RetryConfig = DefaultRetryConfigForEnvironment(s.Environment)
 	}
+	s.retryConfigDefaulted = retryConfig == nil
+}
+
+// GetRetryPreset getter for the name of the retry preset set by WithRetryPreset,
+// empty when none was used
+func (s *SDKConfig) GetRetryPreset() string {
+	return s.RetryPreset
 }
 
 // SetAPIKey setter for API key
@@ -87,6 +535,9 @@ func (s *SDKConfig) SetAPIKey(apiKey string) {
 // SetEnvironment setter for environment
 func (s *SDKConfig) SetEnvironment(environment Environment) {
 	s.Environment = environment
+	if s.retryConfigDefaulted {
+		s.RetryConfig = DefaultRetryConfigForEnvironment(environment)
+	}
 }
 
 // SetSources setter for sources
@@ -94,24 +545,201 @@ func (s *SDKConfig) SetSources(sources []*Source) {
 	s.Sources = sources
 }
 
+// GetDefaultSource getter for the source of calls that omit one
+func (s *SDKConfig) GetDefaultSource() *Source {
+	return s.DefaultSource
+}
+
+// SetDefaultSource setter for the source used when PushToUnify is called with
+// an empty source name and version, so single-source apps can omit it. Mapping
+// requests, which need no source, are sent without it.
+func (s *SDKConfig) SetDefaultSource(source *Source) {
+	s.DefaultSource = source
+}
+
+// SetDestinationGenerator setter for the rules that generate destinations for
+// requests sent without any; nil generates the tax authority destination only
+func (s *SDKConfig) SetDestinationGenerator(generator DestinationGenerator) {
+	s.DestinationGenerator = generator
+}
+
+// GetTextNormalizationCountries getter for the countries whose payload text
+// is checked for valid UTF-8 and normalized before sending
+func (s *SDKConfig) GetTextNormalizationCountries() []Country {
+	return s.TextNormalizationCountries
+}
+
+// SetTextNormalizationCountries setter for the countries whose payload text is
+// checked for valid UTF-8 and normalized to NFC before sending, e.g. CountrySA
+// for Arabic names. Invalid UTF-8 fails the request instead of reaching the
+// tax authority.
+func (s *SDKConfig) SetTextNormalizationCountries(countries ...Country) {
+	s.TextNormalizationCountries = append([]Country(nil), countries...)
+}
+
+// GetTextNormalizer getter for the custom text normalizer
+func (s *SDKConfig) GetTextNormalizer() TextNormalizer {
+	return s.TextNormalizer
+}
+
+// SetTextNormalizer setter for the function that normalizes payload text for
+// TextNormalizationCountries; nil uses NormalizeNFC
+func (s *SDKConfig) SetTextNormalizer(normalizer TextNormalizer) {
+	s.TextNormalizer = normalizer
+}
+
+// GetMonetaryFieldPaths getter for the payload paths of monetary amounts
+func (s *SDKConfig) GetMonetaryFieldPaths() []string {
+	return s.MonetaryFieldPaths
+}
+
+// GetMonetaryPrecision getter for the decimal places of monetary amounts,
+// DefaultMonetaryPrecision when not set
+func (s *SDKConfig) GetMonetaryPrecision() int {
+	if s.MonetaryPrecision <= 0 {
+		return DefaultMonetaryPrecision
+	}
+	return s.MonetaryPrecision
+}
+
+// SetMonetaryFields setter for the payload paths of monetary amounts, e.g.
+// "invoice_data.total_amount" or "line_items.unit_price", which are sent as
+// decimal strings with precision places instead of floats. A precision of 0
+// uses DefaultMonetaryPrecision.
+func (s *SDKConfig) SetMonetaryFields(precision int, paths ...string) {
+	s.MonetaryPrecision = precision
+	s.MonetaryFieldPaths = append([]string(nil), paths...)
+}
+
+// GetOriginalReferenceFields getter for the invoice data fields holding the
+// original invoice of credit and debit notes, DefaultOriginalReferenceFields
+// when not set
+func (s *SDKConfig) GetOriginalReferenceFields() []string {
+	if len(s.OriginalReferenceFields) == 0 {
+		return DefaultOriginalReferenceFields
+	}
+	return s.OriginalReferenceFields
+}
+
+// SetOriginalReferenceFields setter for the invoice data fields holding the
+// original invoice of credit and debit notes, dot separated and relative to
+// the invoice data path; any one of them must be set
+func (s *SDKConfig) SetOriginalReferenceFields(paths ...string) {
+	s.OriginalReferenceFields = append([]string(nil), paths...)
+}
+
 // SetAutoGenerateTaxDestination setter for auto generate tax destination
 func (s *SDKConfig) SetAutoGenerateTaxDestination(autoGenerateTaxDestination bool) {
 	s.AutoGenerateTaxDestination = autoGenerateTaxDestination
 }
 
+// IsMergeDestinations getter for merging explicit destinations with generated ones
+func (s *SDKConfig) IsMergeDestinations() bool {
+	return s.MergeDestinations
+}
+
+// SetMergeDestinations setter for merging the destinations passed to PushToUnify
+// with the generated ones, de-duplicated by type and key, instead of sending
+// only the passed ones. An empty list then sends the generated destinations.
+func (s *SDKConfig) SetMergeDestinations(merge bool) {
+	s.MergeDestinations = merge
+}
+
 // SetCorrelationID setter for correlation ID
 func (s *SDKConfig) SetCorrelationID(correlationID string) {
 	s.CorrelationID = &correlationID
 }
 
+// SetRejectionCorrector setter for rejection corrector
+func (s *SDKConfig) SetRejectionCorrector(corrector RejectionCorrector) {
+	s.RejectionCorrector = corrector
+}
+
+// GetBeforeSend getter for the hook called before each request is sent
+func (s *SDKConfig) GetBeforeSend() BeforeSendHook {
+	return s.BeforeSend
+}
+
+// SetBeforeSend setter for the hook called before each request is sent
+func (s *SDKConfig) SetBeforeSend(hook BeforeSendHook) {
+	s.BeforeSend = hook
+}
+
+// SetLogger setter for logger; nil disables SDK logging
+func (s *SDKConfig) SetLogger(logger Logger) {
+	s.Logger = logger
+}
+
+// SetClock setter for the clock used by retries, the circuit breaker and the queue; nil uses SystemClock
+func (s *SDKConfig) SetClock(clock Clock) {
+	s.Clock = clock
+}
+
+// SetRedaction setter for the log redaction config; nil uses the defaults
+func (s *SDKConfig) SetRedaction(redaction *RedactionConfig) {
+	s.Redaction = redaction
+}
+
+// SetTracerProvider setter for the OpenTelemetry tracer provider; nil disables tracing
+func (s *SDKConfig) SetTracerProvider(provider trace.TracerProvider) {
+	s.TracerProvider = provider
+}
+
+// SetMetricsSink setter for the metrics sink; nil disables metrics
+func (s *SDKConfig) SetMetricsSink(sink MetricsSink) {
+	s.MetricsSink = sink
+}
+
 // SDKConfigBuilder Builder for SDKConfig matching Python SDK
 type SDKConfigBuilder struct {
 	apiKey                    *string
 	environment               Environment
 	sources                   []*Source
+	defaultSource             *Source
 	retryConfig               *RetryConfig
 	autoGenerateTaxDestination bool
+	mergeDestinations          bool
 	correlationID             *string
+	rejectionCorrector        RejectionCorrector
+	beforeSend                BeforeSendHook
+	logger                    Logger
+	redaction                 *RedactionConfig
+	tracerProvider            trace.TracerProvider
+	metricsSink               MetricsSink
+	environmentURLs           map[Environment]string
+	allowedCountries          map[Environment][]Country
+	signingEnabled            bool
+	signingSecret             string
+	tlsConfig                 *tls.Config
+	clientCertFile            string
+	clientKeyFile             string
+	caCertFile                string
+	httpClient                *http.Client
+	queueEventHandler         QueueEventHandler
+	queueMaxAttempts          int
+	compressionThreshold      int
+	forceCompression          bool
+	maxResponseBytes          int64
+	maxPayloadBytes           int64
+	maxBulkPayloadBytes       int64
+	rateLimitPerSecond        float64
+	rateLimitBurst            int
+	rateLimitThrottleThreshold int
+	recordRequestJSON         bool
+	invoiceDataPath           string
+	queueMode                 QueueMode
+	queueBasePath             string
+	instanceName              string
+	queueEncryptionKey        []byte
+	destinationGenerator      DestinationGenerator
+	textNormalizationCountries []Country
+	textNormalizer            TextNormalizer
+	monetaryPrecision         int
+	monetaryFieldPaths        []string
+	originalReferenceFields   []string
+	documentIDPaths           map[DocumentType][]string
+	clock                     Clock
+	allowAPIKeyEnvironmentMismatch bool
 }
 
 // APIKey setter for API key
@@ -120,6 +748,12 @@ func (b *SDKConfigBuilder) APIKey(apiKey string) *SDKConfigBuilder {
 	return b
 }
 
+// AllowAPIKeyEnvironmentMismatch setter for accepting an API key whose prefix does not match the environment
+func (b *SDKConfigBuilder) AllowAPIKeyEnvironmentMismatch(allow bool) *SDKConfigBuilder {
+	b.allowAPIKeyEnvironmentMismatch = allow
+	return b
+}
+
 // Environment setter for environment
 func (b *SDKConfigBuilder) Environment(environment Environment) *SDKConfigBuilder {
 	b.environment = environment
@@ -136,24 +770,246 @@ func (b *SDKConfigBuilder) Sources(sources []*Source) *SDKConfigBuilder {
 	return b
 }
 
+// DefaultSource setter for the source of calls that omit one
+func (b *SDKConfigBuilder) DefaultSource(source *Source) *SDKConfigBuilder {
+	b.defaultSource = source
+	return b
+}
+
 // RetryConfig setter for retry config
 func (b *SDKConfigBuilder) RetryConfig(retryConfig *RetryConfig) *SDKConfigBuilder {
 	b.retryConfig = retryConfig
 	return b
 }
 
+// DestinationGenerator setter for the destination generation rules
+func (b *SDKConfigBuilder) DestinationGenerator(generator DestinationGenerator) *SDKConfigBuilder {
+	b.destinationGenerator = generator
+	return b
+}
+
+// TextNormalizationCountries setter for the countries whose payload text is validated and normalized
+func (b *SDKConfigBuilder) TextNormalizationCountries(countries ...Country) *SDKConfigBuilder {
+	b.textNormalizationCountries = countries
+	return b
+}
+
+// TextNormalizer setter for the custom text normalizer
+func (b *SDKConfigBuilder) TextNormalizer(normalizer TextNormalizer) *SDKConfigBuilder {
+	b.textNormalizer = normalizer
+	return b
+}
+
+// MonetaryFields setter for the payload paths of monetary amounts and their decimal places
+func (b *SDKConfigBuilder) MonetaryFields(precision int, paths ...string) *SDKConfigBuilder {
+	b.monetaryPrecision = precision
+	b.monetaryFieldPaths = paths
+	return b
+}
+
+// OriginalReferenceFields setter for the invoice data fields holding the original invoice of credit and debit notes
+func (b *SDKConfigBuilder) OriginalReferenceFields(paths ...string) *SDKConfigBuilder {
+	b.originalReferenceFields = paths
+	return b
+}
+
 // AutoGenerateTaxDestination setter for auto generate tax destination
 func (b *SDKConfigBuilder) AutoGenerateTaxDestination(autoGenerate bool) *SDKConfigBuilder {
 	b.autoGenerateTaxDestination = autoGenerate
 	return b
 }
 
+// MergeDestinations setter for merging explicit destinations with generated ones
+func (b *SDKConfigBuilder) MergeDestinations(merge bool) *SDKConfigBuilder {
+	b.mergeDestinations = merge
+	return b
+}
+
 // CorrelationID setter for correlation ID
 func (b *SDKConfigBuilder) CorrelationID(correlationID string) *SDKConfigBuilder {
 	b.correlationID = &correlationID
 	return b
 }
 
+// BeforeSend setter for the hook called before each request is sent
+func (b *SDKConfigBuilder) BeforeSend(hook BeforeSendHook) *SDKConfigBuilder {
+	b.beforeSend = hook
+	return b
+}
+
+// RejectionCorrector setter for rejection corrector
+func (b *SDKConfigBuilder) RejectionCorrector(corrector RejectionCorrector) *SDKConfigBuilder {
+	b.rejectionCorrector = corrector
+	return b
+}
+
+// Logger setter for logger
+func (b *SDKConfigBuilder) Logger(logger Logger) *SDKConfigBuilder {
+	b.logger = logger
+	return b
+}
+
+// Clock setter for the clock used by retries, the circuit breaker and the queue
+func (b *SDKConfigBuilder) Clock(clock Clock) *SDKConfigBuilder {
+	b.clock = clock
+	return b
+}
+
+// Redaction setter for the log redaction config
+func (b *SDKConfigBuilder) Redaction(redaction *RedactionConfig) *SDKConfigBuilder {
+	b.redaction = redaction
+	return b
+}
+
+// TracerProvider setter for the OpenTelemetry tracer provider
+func (b *SDKConfigBuilder) TracerProvider(provider trace.TracerProvider) *SDKConfigBuilder {
+	b.tracerProvider = provider
+	return b
+}
+
+// MetricsSink setter for the metrics sink
+func (b *SDKConfigBuilder) MetricsSink(sink MetricsSink) *SDKConfigBuilder {
+	b.metricsSink = sink
+	return b
+}
+
+// AllowedCountries setter for per-environment country allow-list overrides
+func (b *SDKConfigBuilder) AllowedCountries(allowed map[Environment][]Country) *SDKConfigBuilder {
+	b.allowedCountries = allowed
+	return b
+}
+
+// EnvironmentURLs setter for per-environment base URL overrides
+func (b *SDKConfigBuilder) EnvironmentURLs(urls map[Environment]string) *SDKConfigBuilder {
+	b.environmentURLs = urls
+	return b
+}
+
+// SigningSecret Enable HMAC-SHA256 request signing with secret
+func (b *SDKConfigBuilder) SigningSecret(secret string) *SDKConfigBuilder {
+	b.signingEnabled = true
+	b.signingSecret = secret
+	return b
+}
+
+// TLSConfig setter for a custom TLS config
+func (b *SDKConfigBuilder) TLSConfig(tlsConfig *tls.Config) *SDKConfigBuilder {
+	b.tlsConfig = tlsConfig
+	return b
+}
+
+// ClientCertificate setter for the mutual TLS certificate and key files
+func (b *SDKConfigBuilder) ClientCertificate(certFile, keyFile string) *SDKConfigBuilder {
+	b.clientCertFile = certFile
+	b.clientKeyFile = keyFile
+	return b
+}
+
+// CACertFile setter for the pinned CA certificate file
+func (b *SDKConfigBuilder) CACertFile(caCertFile string) *SDKConfigBuilder {
+	b.caCertFile = caCertFile
+	return b
+}
+
+// HTTPClient setter for the HTTP client used for API requests
+func (b *SDKConfigBuilder) HTTPClient(client *http.Client) *SDKConfigBuilder {
+	b.httpClient = client
+	return b
+}
+
+// QueueEventHandler setter for the queue event handler
+func (b *SDKConfigBuilder) QueueEventHandler(handler QueueEventHandler) *SDKConfigBuilder {
+	b.queueEventHandler = handler
+	return b
+}
+
+// CompressionThreshold setter for the body size in bytes above which requests are gzipped
+func (b *SDKConfigBuilder) CompressionThreshold(thresholdBytes int) *SDKConfigBuilder {
+	b.compressionThreshold = thresholdBytes
+	return b
+}
+
+// ForceCompression setter for gzipping every request body regardless of size
+func (b *SDKConfigBuilder) ForceCompression(force bool) *SDKConfigBuilder {
+	b.forceCompression = force
+	return b
+}
+
+// MaxResponseBytes setter for the largest response body the client reads
+func (b *SDKConfigBuilder) MaxResponseBytes(maxBytes int64) *SDKConfigBuilder {
+	b.maxResponseBytes = maxBytes
+	return b
+}
+
+// MaxPayloadBytes setter for the largest serialized request the client sends,
+// with bulkMaxBytes for bulk requests
+func (b *SDKConfigBuilder) MaxPayloadBytes(maxBytes, bulkMaxBytes int64) *SDKConfigBuilder {
+	b.maxPayloadBytes = maxBytes
+	b.maxBulkPayloadBytes = bulkMaxBytes
+	return b
+}
+
+// RateLimit setter for the client-side rate limit of requests per second and burst
+func (b *SDKConfigBuilder) RateLimit(requestsPerSecond float64, burst int) *SDKConfigBuilder {
+	b.rateLimitPerSecond = requestsPerSecond
+	b.rateLimitBurst = burst
+	return b
+}
+
+// RateLimitThrottleThreshold setter for the X-RateLimit-Remaining count below which requests wait for the reset
+func (b *SDKConfigBuilder) RateLimitThrottleThreshold(threshold int) *SDKConfigBuilder {
+	b.rateLimitThrottleThreshold = threshold
+	return b
+}
+
+// RecordRequestJSON setter for attaching the sent request JSON to responses
+func (b *SDKConfigBuilder) RecordRequestJSON(enabled bool) *SDKConfigBuilder {
+	b.recordRequestJSON = enabled
+	return b
+}
+
+// InvoiceDataPath setter for the dot-separated path of the invoice data object in payloads
+func (b *SDKConfigBuilder) InvoiceDataPath(path string) *SDKConfigBuilder {
+	b.invoiceDataPath = path
+	return b
+}
+
+// QueueMode setter for the queue storage backend
+func (b *SDKConfigBuilder) QueueMode(mode QueueMode) *SDKConfigBuilder {
+	b.queueMode = mode
+	return b
+}
+
+// QueueBasePath setter for the file queue directory
+func (b *SDKConfigBuilder) QueueBasePath(path string) *SDKConfigBuilder {
+	b.queueBasePath = path
+	return b
+}
+
+// InstanceName setter for the name of an SDK created with NewSDK
+func (b *SDKConfigBuilder) InstanceName(name string) *SDKConfigBuilder {
+	b.instanceName = name
+	return b
+}
+
+// QueueEncryptionKey setter for the key that encrypts file queue records at rest
+func (b *SDKConfigBuilder) QueueEncryptionKey(key []byte) *SDKConfigBuilder {
+	b.queueEncryptionKey = key
+	return b
+}
+
+// DocumentIDPaths setter for the per-document-type paths used to find a queued submission's document ID
+func (b *SDKConfigBuilder) DocumentIDPaths(paths map[DocumentType][]string) *SDKConfigBuilder {
+	b.documentIDPaths = paths
+	return b
+}
+
+// QueueMaxAttempts setter for the number of attempts before a queued submission is dead-lettered
+func (b *SDKConfigBuilder) QueueMaxAttempts(maxAttempts int) *SDKConfigBuilder {
+	b.queueMaxAttempts = maxAttempts
+	return b
+}
+
 // Build builds the SDKConfig
 func (b *SDKConfigBuilder) Build() *SDKConfig {
 	apiKey := ""
@@ -162,7 +1018,43 @@ func (b *SDKConfigBuilder) Build() *SDKConfig {
 	}
 	
 	config := NewSDKConfig(apiKey, b.environment, b.sources, b.retryConfig)
+	config.SetDefaultSource(b.defaultSource)
 	config.AutoGenerateTaxDestination = b.autoGenerateTaxDestination
+	config.MergeDestinations = b.mergeDestinations
 	config.CorrelationID = b.correlationID
+	config.RejectionCorrector = b.rejectionCorrector
+	config.SetBeforeSend(b.beforeSend)
+	config.Logger = b.logger
+	config.Redaction = b.redaction
+	config.TracerProvider = b.tracerProvider
+	config.MetricsSink = b.metricsSink
+	config.WithEnvironmentURLs(b.environmentURLs)
+	config.AllowedCountries = copyAllowedCountries(b.allowedCountries)
+	config.SetRequestSigning(b.signingEnabled, b.signingSecret)
+	config.TLSConfig = b.tlsConfig
+	config.SetClientCertificate(b.clientCertFile, b.clientKeyFile)
+	config.CACertFile = b.caCertFile
+	config.HTTPClient = b.httpClient
+	config.QueueEventHandler = b.queueEventHandler
+	config.QueueMaxAttempts = b.queueMaxAttempts
+	config.SetCompression(b.compressionThreshold, b.forceCompression)
+	config.SetMaxResponseBytes(b.maxResponseBytes)
+	config.SetMaxPayloadBytes(b.maxPayloadBytes, b.maxBulkPayloadBytes)
+	config.SetRateLimit(b.rateLimitPerSecond, b.rateLimitBurst)
+	config.SetRateLimitThrottleThreshold(b.rateLimitThrottleThreshold)
+	config.SetRecordRequestJSON(b.recordRequestJSON)
+	config.SetInvoiceDataPath(b.invoiceDataPath)
+	config.SetQueueMode(b.queueMode)
+	config.SetQueueBasePath(b.queueBasePath)
+	config.SetInstanceName(b.instanceName)
+	config.SetQueueEncryptionKey(b.queueEncryptionKey)
+	config.DestinationGenerator = b.destinationGenerator
+	config.SetTextNormalizationCountries(b.textNormalizationCountries...)
+	config.TextNormalizer = b.textNormalizer
+	config.SetMonetaryFields(b.monetaryPrecision, b.monetaryFieldPaths...)
+	config.SetOriginalReferenceFields(b.originalReferenceFields...)
+	config.DocumentIDPaths = copyDocumentIDPaths(b.documentIDPaths)
+	config.Clock = b.clock
+	config.SetAllowAPIKeyEnvironmentMismatch(b.allowAPIKeyEnvironmentMismatch)
 	return config
 }
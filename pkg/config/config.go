@@ -17,6 +17,9 @@ const (
 	DefaultBaseDelay    = 500 * time.Millisecond
 	DefaultMaxDelay     = 5 * time.Second
 	DefaultJitterFactor = 0.1
+
+	// DefaultMaxResponseBytes is the largest response body the client will read
+	DefaultMaxResponseBytes int64 = 16 << 20
 )
 
 // Environment variable names
@@ -47,6 +50,10 @@ type Config struct {
 
 	// RetryConfig holds the retry and circuit breaker configuration
 	RetryConfig *RetryConfig
+
+	// MaxResponseBytes limits how much of a response body is read; larger
+	// responses fail instead of being buffered in full
+	MaxResponseBytes int64
 }
 
 // RetryConfig holds retry and circuit breaker settings
@@ -72,6 +79,10 @@ type RetryConfig struct {
 	// CircuitBreakerTimeout is the duration to keep the circuit open
 	CircuitBreakerTimeout time.Duration
 
+	// FailureWindow only counts failures within this duration towards
+	// FailureThreshold; every consecutive failure counts when 0
+	FailureWindow time.Duration
+
 	// RetryableHTTPCodes is a list of HTTP status codes that should trigger a retry
 	RetryableHTTPCodes []int
 }
@@ -84,6 +95,7 @@ func New(options ...Option) *Config {
 	cfg := &Config{
 		Environment: models.EnvironmentSandbox,
 		Timeout:     DefaultTimeout,
+		MaxResponseBytes: DefaultMaxResponseBytes,
 		RetryConfig: &RetryConfig{
 			MaxRetries:           DefaultMaxRetries,
 			BaseDelay:            DefaultBaseDelay,
@@ -227,6 +239,13 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithMaxResponseBytes sets the largest response body the client will read
+func WithMaxResponseBytes(maxBytes int64) Option {
+	return func(c *Config) {
+		c.MaxResponseBytes = maxBytes
+	}
+}
+
 // WithSource adds a source to the configuration
 func WithSource(source *models.Source) Option {
 	return func(c *Config) {
@@ -0,0 +1,177 @@
+/*
+File-based configuration loading for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configEnvPattern Matches ${VAR} and ${VAR:-default} references in config files
+var configEnvPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// knownEnvironments Environments accepted in configuration files
+var knownEnvironments = map[Environment]bool{
+	EnvironmentDev:        true,
+	EnvironmentTest:       true,
+	EnvironmentStage:      true,
+	EnvironmentLocal:      true,
+	EnvironmentSandbox:    true,
+	EnvironmentSimulation: true,
+	EnvironmentProduction: true,
+}
+
+// LoadConfigFromFile Load an SDKConfig from a JSON (.json) or YAML (.yaml, .yml)
+// file. Keys match the SDKConfig JSON field names, e.g. api_key, environment,
+// sources and retry_config, and retry_preset names a preset for WithRetryPreset
+// whose values retry_config then overrides. References such as ${COMPLYANCE_API_KEY} or
+// ${COMPLYANCE_ENV:-sandbox} are replaced with environment variables before
+// parsing. Fields missing from the file keep the NewSDKConfig defaults, and the
+// result is validated before it is returned.
+func LoadConfigFromFile(path string) (*SDKConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newConfigFileError(path, fmt.Sprintf("Failed to read config file: %v", err), "Check that the file exists and is readable.")
+	}
+
+	expanded, missing := expandConfigEnv(string(content))
+	if len(missing) > 0 {
+		return nil, newConfigFileError(path,
+			fmt.Sprintf("Environment variables referenced in config are not set: %s", strings.Join(missing, ", ")),
+			"Export the referenced environment variables or give them a default with ${NAME:-default}.")
+	}
+
+	document, err := configDocumentJSON(path, []byte(expanded))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := NewSDKConfig("", "", nil, nil)
+	var preset struct {
+		RetryPreset string          `json:"retry_preset"`
+		Environment string          `json:"environment"`
+		RetryConfig json.RawMessage `json:"retry_config"`
+	}
+	_ = json.Unmarshal(document, &preset)
+	// retry_config fields override the preset, or the environment's default
+	cfg.SetEnvironment(Environment(strings.ToUpper(strings.TrimSpace(preset.Environment))))
+	if preset.RetryPreset != "" {
+		if cfg.WithRetryPreset(preset.RetryPreset) != nil {
+			return nil, newConfigFieldError("retry_preset", fmt.Sprintf("Unknown retry preset %q", preset.RetryPreset),
+				fmt.Sprintf("Set retry_preset to one of %s.", strings.Join(RetryPresetNames(), ", ")))
+		}
+	}
+	if err := json.Unmarshal(document, cfg); err != nil {
+		return nil, newConfigFileError(path, fmt.Sprintf("Invalid config file: %v", err), "Check the field names and value types against SDKConfig.")
+	}
+	cfg.Environment = Environment(strings.ToUpper(strings.TrimSpace(string(cfg.Environment))))
+	if len(cfg.EnvironmentURLs) > 0 {
+		urls := make(map[Environment]string, len(cfg.EnvironmentURLs))
+		for environment, url := range cfg.EnvironmentURLs {
+			urls[Environment(strings.ToUpper(strings.TrimSpace(string(environment))))] = url
+		}
+		cfg.EnvironmentURLs = urls
+	}
+	if cfg.RetryConfig == nil || preset.RetryConfig != nil {
+		cfg.SetRetryConfig(cfg.RetryConfig)
+	}
+
+	if err := validateFileConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// expandConfigEnv Replace environment variable references, returning the names of
+// unset variables that have no default
+func expandConfigEnv(content string) (string, []string) {
+	var missing []string
+	expanded := configEnvPattern.ReplaceAllStringFunc(content, func(reference string) string {
+		match := configEnvPattern.FindStringSubmatch(reference)
+		if value, ok := os.LookupEnv(match[1]); ok {
+			return value
+		}
+		if strings.Contains(reference, ":-") {
+			return match[2]
+		}
+		missing = append(missing, match[1])
+		return reference
+	})
+	return expanded, missing
+}
+
+// configDocumentJSON Convert the file content to JSON so SDKConfig's JSON field names apply to both formats
+func configDocumentJSON(path string, content []byte) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var probe interface{}
+		if err := json.Unmarshal(content, &probe); err != nil {
+			return nil, newConfigFileError(path, fmt.Sprintf("Malformed JSON config file: %v", err), "Fix the JSON syntax in the config file.")
+		}
+		return content, nil
+	case ".yaml", ".yml":
+		var document interface{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return nil, newConfigFileError(path, fmt.Sprintf("Malformed YAML config file: %v", err), "Fix the YAML syntax in the config file.")
+		}
+		if document == nil {
+			document = map[string]interface{}{}
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, newConfigFileError(path, fmt.Sprintf("Unsupported YAML config file: %v", err), "Use string keys and plain scalar values in the config file.")
+		}
+		return converted, nil
+	default:
+		return nil, newConfigFileError(path, fmt.Sprintf("Unsupported config file extension %q", filepath.Ext(path)), "Use a .json, .yaml or .yml file.")
+	}
+}
+
+// validateFileConfig Check the fields a loaded config must provide
+func validateFileConfig(cfg *SDKConfig) error {
+	if strings.TrimSpace(cfg.APIKey) == "" {
+		return newConfigFieldError("api_key", "API key is required", "Set api_key, e.g. api_key: ${COMPLYANCE_API_KEY}.")
+	}
+	if cfg.Environment == "" {
+		return newConfigFieldError("environment", "Environment is required", "Set environment to one of DEV, TEST, STAGE, LOCAL, SANDBOX, SIMULATION or PRODUCTION.")
+	}
+	if !knownEnvironments[cfg.Environment] {
+		return newConfigFieldError("environment", fmt.Sprintf("Unknown environment: %s", cfg.Environment), "Set environment to one of DEV, TEST, STAGE, LOCAL, SANDBOX, SIMULATION or PRODUCTION.")
+	}
+	for i, source := range cfg.Sources {
+		if source == nil || strings.TrimSpace(source.Name) == "" {
+			return newConfigFieldError(fmt.Sprintf("sources[%d].name", i), "Source name is required", "Give every source a name and version.")
+		}
+		if strings.TrimSpace(source.Version) == "" {
+			return newConfigFieldError(fmt.Sprintf("sources[%d].version", i), "Source version is required", "Give every source a name and version.")
+		}
+	}
+	if cfg.SigningEnabled && cfg.SigningSecret == "" {
+		return newConfigFieldError("signing_secret", "Signing secret is required when signing is enabled", "Set signing_secret, e.g. signing_secret: ${COMPLYANCE_SIGNING_SECRET}.")
+	}
+	if cfg.RetryConfig.MaxAttempts < 1 {
+		return newConfigFieldError("retry_config.max_attempts", "Retry max attempts must be at least 1", "Use max_attempts: 1 to disable retries.")
+	}
+	return nil
+}
+
+// newConfigFileError Configuration error for a config file that could not be loaded
+func newConfigFileError(path, message, suggestion string) error {
+	errorDetail := NewErrorDetailWithCode(ErrorCodeConfigurationError, message).WithSuggestion(suggestion)
+	errorDetail.AddContextValue("path", path)
+	return NewSDKError(errorDetail)
+}
+
+// newConfigFieldError Missing or invalid field in a loaded config
+func newConfigFieldError(field, message, suggestion string) error {
+	errorDetail := NewErrorDetailWithCode(ErrorCodeConfigurationError, message).WithSuggestion(suggestion)
+	errorDetail.Field = &field
+	return NewSDKError(errorDetail)
+}
@@ -0,0 +1,126 @@
+package complyancesdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFromFileJSONAndYAML(t *testing.T) {
+	t.Setenv("COMPLYANCE_API_KEY", "ak_from_env")
+
+	jsonPath := writeConfigFile(t, "complyance.json", `{
+		"api_key": "${COMPLYANCE_API_KEY}",
+		"environment": "${COMPLYANCE_ENV:-sandbox}",
+		"sources": [{"name": "erp", "version": "1", "type": "FIRST_PARTY"}],
+		"retry_config": {"max_attempts": 2},
+		"auto_generate_tax_destination": false
+	}`)
+	yamlPath := writeConfigFile(t, "complyance.yaml", `
+api_key: ${COMPLYANCE_API_KEY}
+environment: ${COMPLYANCE_ENV:-sandbox}
+sources:
+  - name: erp
+    version: "1"
+    type: FIRST_PARTY
+retry_config:
+  max_attempts: 2
+auto_generate_tax_destination: false
+`)
+
+	for _, path := range []string{jsonPath, yamlPath} {
+		cfg, err := LoadConfigFromFile(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if cfg.APIKey != "ak_from_env" || cfg.Environment != EnvironmentSandbox || cfg.AutoGenerateTaxDestination {
+			t.Fatalf("%s: unexpected config %+v", path, cfg)
+		}
+		if len(cfg.Sources) != 1 || cfg.Sources[0].Name != "erp" || cfg.Sources[0].GetType() != string(SourceTypeFirstParty) {
+			t.Fatalf("%s: unexpected sources %+v", path, cfg.Sources)
+		}
+		if cfg.RetryConfig.MaxAttempts != 2 || cfg.RetryConfig.BaseDelayMs != NewDefaultRetryConfig().BaseDelayMs {
+			t.Fatalf("%s: expected retry overrides on top of defaults, got %+v", path, cfg.RetryConfig)
+		}
+	}
+}
+
+func TestLoadConfigFromFileErrors(t *testing.T) {
+	cases := map[string]string{
+		"malformed.json":   `{"api_key": "key",`,
+		"malformed.yaml":   "api_key: [unterminated\n",
+		"missing-key.json": `{"environment": "SANDBOX"}`,
+		"missing-env.yaml": "api_key: key\n",
+		"bad-env.yml":      "api_key: key\nenvironment: mars\n",
+		"bad-source.json":  `{"api_key": "key", "environment": "DEV", "sources": [{"name": "erp"}]}`,
+		"unset-var.json":   `{"api_key": "${COMPLYANCE_TEST_UNSET_KEY}", "environment": "DEV"}`,
+		"config.toml":      `api_key = "key"`,
+	}
+	for name, content := range cases {
+		_, err := LoadConfigFromFile(writeConfigFile(t, name, content))
+		sdkErr, ok := err.(*SDKError)
+		if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeConfigurationError {
+			t.Fatalf("%s: expected configuration error, got %v", name, err)
+		}
+	}
+
+	if _, err := LoadConfigFromFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
+
+func TestLoadConfigFromFileEnvironmentURLs(t *testing.T) {
+	path := writeConfigFile(t, "complyance.yaml", `
+api_key: key
+environment: dev
+environment_urls:
+  dev: https://acme.complyance.example
+`)
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GetBaseURL(); got != "https://acme.complyance.example/unify" {
+		t.Fatalf("expected override from file, got %s", got)
+	}
+}
+
+func TestLoadConfigFromFileRetryPreset(t *testing.T) {
+	path := writeConfigFile(t, "complyance.yaml", `
+api_key: ak_test_key
+environment: sandbox
+retry_preset: conservative
+retry_config:
+  max_attempts: 4
+`)
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RetryConfig.MaxAttempts != 4 || cfg.RetryConfig.BaseDelayMs != 1000 || cfg.RetryConfig.BackoffMultiplier != 2.5 {
+		t.Fatalf("expected retry_config overrides on top of the conservative preset, got %+v", cfg.RetryConfig)
+	}
+
+	cfg, err = LoadConfigFromFile(writeConfigFile(t, "production.yaml", "api_key: ak_live_key\nenvironment: production\nretry_config:\n  max_attempts: 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RetryConfig.MaxAttempts != 2 || cfg.RetryConfig.BaseDelayMs != 1000 {
+		t.Fatalf("expected retry_config overrides on top of the production default, got %+v", cfg.RetryConfig)
+	}
+
+	_, err = LoadConfigFromFile(writeConfigFile(t, "unknown.yaml", "api_key: ak_test_key\nenvironment: sandbox\nretry_preset: reckless\n"))
+	sdkErr, ok := err.(*SDKError)
+	if !ok || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "retry_preset" {
+		t.Fatalf("expected an error naming retry_preset, got %v", err)
+	}
+}
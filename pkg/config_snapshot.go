@@ -0,0 +1,110 @@
+/*
+Config snapshots used by Configure to recognise a repeated configuration.
+*/
+package complyancesdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+)
+
+// sdkConfigSnapshot Copy of an SDKConfig taken when an SDK is built, so later
+// changes to the config do not make it look unchanged
+type sdkConfigSnapshot struct {
+	encoded  []byte
+	queueDir string
+	injected []interface{}
+}
+
+// newSDKConfigSnapshot Snapshot of config, nil when config is nil or cannot be encoded
+func newSDKConfigSnapshot(config *SDKConfig) *sdkConfigSnapshot {
+	if config == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return nil
+	}
+
+	// The default queue directory follows HOME, so resolve it now
+	queueDir := config.QueueBasePath
+	if config.GetQueueMode() == QueueModeFile && queueDir == "" {
+		queueDir, _ = defaultQueueBasePath()
+	}
+
+	return &sdkConfigSnapshot{
+		encoded:  encoded,
+		queueDir: queueDir,
+		injected: []interface{}{
+			config.RejectionCorrector,
+			config.BeforeSend,
+			config.Logger,
+			config.TracerProvider,
+			config.MetricsSink,
+			config.TLSConfig,
+			config.HTTPClient,
+			config.QueueEventHandler,
+			config.Clock,
+			config.QueueEncryptionKey,
+			config.DestinationGenerator,
+			config.TextNormalizer,
+			retryBackoff(config.RetryConfig),
+			retryRandomSource(config.RetryConfig),
+		},
+	}
+}
+
+// retryBackoff Custom backoff strategy of retryConfig, nil when there is none
+func retryBackoff(retryConfig *RetryConfig) BackoffStrategy {
+	if retryConfig == nil {
+		return nil
+	}
+	return retryConfig.Backoff
+}
+
+// retryRandomSource Jitter random source of retryConfig, nil when there is none
+func retryRandomSource(retryConfig *RetryConfig) rand.Source {
+	if retryConfig == nil {
+		return nil
+	}
+	return retryConfig.RandomSource
+}
+
+// equivalent Whether other has the same settings and the same injected
+// dependencies, compared by identity
+func (s *sdkConfigSnapshot) equivalent(other *sdkConfigSnapshot) bool {
+	if s == nil || other == nil {
+		return false
+	}
+	if !bytes.Equal(s.encoded, other.encoded) || s.queueDir != other.queueDir || len(s.injected) != len(other.injected) {
+		return false
+	}
+	for i := range s.injected {
+		if !sameInjectedValue(s.injected[i], other.injected[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameInjectedValue Whether a and b are the same value; functions, maps and
+// slices are compared by pointer
+func sameInjectedValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	left, right := reflect.ValueOf(a), reflect.ValueOf(b)
+	if left.Type() != right.Type() {
+		return false
+	}
+	switch left.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return left.Pointer() == right.Pointer()
+	}
+	if !left.Type().Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,279 @@
+package complyancesdk
+
+import "testing"
+
+// withoutEnvOverride hides any ENV variable or .env file from GetBaseURL for the test
+func withoutEnvOverride(t *testing.T) {
+	t.Helper()
+	loaded, value := envValueLoaded, cachedEnvValue
+	envValueLoaded, cachedEnvValue = true, ""
+	t.Cleanup(func() { envValueLoaded, cachedEnvValue = loaded, value })
+}
+
+func TestEnvironmentGetBaseURLIsDistinctPerEnvironment(t *testing.T) {
+	withoutEnvOverride(t)
+
+	expected := map[Environment]string{
+		EnvironmentDev:        "https://dev.gets.complyance.io/unify",
+		EnvironmentTest:       "https://test.gets.complyance.io/unify",
+		EnvironmentStage:      "https://stage.gets.complyance.io/unify",
+		EnvironmentSandbox:    "https://prod.gets.complyance.io/unify",
+		EnvironmentProduction: "https://prod.gets.complyance.io/unify",
+		EnvironmentLocal:      "http://127.0.0.1:4000/unify",
+	}
+	for environment, want := range expected {
+		if got := environment.GetBaseURL(); got != want {
+			t.Fatalf("%s: expected %s, got %s", environment, want, got)
+		}
+	}
+}
+
+func TestEnvironmentURLOverrideWinsOverDefault(t *testing.T) {
+	withoutEnvOverride(t)
+	t.Setenv("HOME", t.TempDir())
+
+	urls := map[Environment]string{EnvironmentDev: "https://acme.complyance.example/"}
+	cfg := NewSDKConfig("test-key", EnvironmentDev, nil, nil).WithEnvironmentURLs(urls)
+	urls[EnvironmentDev] = "https://changed.example"
+
+	if got := cfg.GetBaseURL(); got != "https://acme.complyance.example/unify" {
+		t.Fatalf("expected override URL, got %s", got)
+	}
+	if err := Configure(cfg); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	if got := globalSDK.apiClient.GetBaseURL(); got != "https://acme.complyance.example/unify" {
+		t.Fatalf("expected API client to use override, got %s", got)
+	}
+
+	cfg.SetEnvironment(EnvironmentStage)
+	if got := cfg.GetBaseURL(); got != EnvironmentStage.GetBaseURL() {
+		t.Fatalf("expected default URL for environment without override, got %s", got)
+	}
+
+	built := NewSDKConfigBuilder().
+		APIKey("test-key").
+		Environment(EnvironmentTest).
+		EnvironmentURLs(map[Environment]string{EnvironmentTest: "https://tenant.example/unify"}).
+		Build()
+	if got := built.GetBaseURL(); got != "https://tenant.example/unify" {
+		t.Fatalf("expected builder override, got %s", got)
+	}
+}
+
+func TestValidateAcceptsDefaultConfig(t *testing.T) {
+	cfg := NewSDKConfig("ak_test_123", EnvironmentSandbox, []*Source{NewSource("erp", "1.0", nil)}, nil)
+	if results := cfg.Validate(); results.Count() != 0 {
+		t.Fatalf("expected no results, got %+v", results.Results)
+	}
+
+	cfg.APIKey = "test-key"
+	results := cfg.Validate()
+	if results.HasErrors() || results.WarningCount() != 1 || results.Results[0].Field != "api_key" {
+		t.Fatalf("expected a single api_key warning, got %+v", results.Results)
+	}
+}
+
+func TestValidateReportsEachInvalidField(t *testing.T) {
+	cases := []struct {
+		field  string
+		mutate func(cfg *SDKConfig)
+	}{
+		{"api_key", func(cfg *SDKConfig) { cfg.APIKey = "" }},
+		{"api_key", func(cfg *SDKConfig) { cfg.APIKey = " ak_test_123" }},
+		{"environment", func(cfg *SDKConfig) { cfg.Environment = "" }},
+		{"environment", func(cfg *SDKConfig) { cfg.Environment = "prod" }},
+		{"retry_config", func(cfg *SDKConfig) { cfg.RetryConfig = nil }},
+		{"retry_config.max_attempts", func(cfg *SDKConfig) { cfg.RetryConfig.MaxAttempts = 0 }},
+		{"retry_config.base_delay_ms", func(cfg *SDKConfig) { cfg.RetryConfig.BaseDelayMs = -1 }},
+		{"retry_config.max_delay_ms", func(cfg *SDKConfig) { cfg.RetryConfig.MaxDelayMs = 100 }},
+		{"retry_config.backoff_multiplier", func(cfg *SDKConfig) { cfg.RetryConfig.BackoffMultiplier = 0.5 }},
+		{"retry_config.jitter_factor", func(cfg *SDKConfig) { cfg.RetryConfig.JitterFactor = 1.5 }},
+		{"retry_config.backoff_strategy", func(cfg *SDKConfig) { cfg.RetryConfig.BackoffStrategyName = "fibonacci" }},
+		{"retry_config.failure_threshold", func(cfg *SDKConfig) { cfg.RetryConfig.FailureThreshold = 0 }},
+		{"retry_config.circuit_breaker_timeout_ms", func(cfg *SDKConfig) { cfg.RetryConfig.CircuitBreakerTimeoutMs = -1 }},
+		{"rate_limit_per_second", func(cfg *SDKConfig) { cfg.SetRateLimit(-1, 1) }},
+		{"rate_limit_burst", func(cfg *SDKConfig) { cfg.SetRateLimit(5, -1) }},
+		{"rate_limit_throttle_threshold", func(cfg *SDKConfig) { cfg.SetRateLimitThrottleThreshold(-1) }},
+		{"sources[1]", func(cfg *SDKConfig) { cfg.Sources = append(cfg.Sources, nil) }},
+		{"sources[1].name", func(cfg *SDKConfig) { cfg.Sources = append(cfg.Sources, NewSource(" ", "1.0", nil)) }},
+		{"sources[1].version", func(cfg *SDKConfig) { cfg.Sources = append(cfg.Sources, NewSource("pos", "", nil)) }},
+	}
+
+	for _, tc := range cases {
+		cfg := NewSDKConfig("ak_test_123", EnvironmentSandbox, []*Source{NewSource("erp", "1.0", nil)}, nil)
+		tc.mutate(cfg)
+
+		results := cfg.Validate()
+		if results.ErrorCount() != 1 || results.Results[0].Field != tc.field {
+			t.Fatalf("%s: expected a single error, got %+v", tc.field, results.Results)
+		}
+
+		_, err := NewSDK(cfg)
+		if code, ok := GetErrorCode(err); !ok || code != ErrorCodeConfigurationError {
+			t.Fatalf("%s: expected CONFIGURATION_ERROR from NewSDK, got %v", tc.field, err)
+		}
+		if field := err.(*SDKError).ErrorDetail.Context["field"]; field != tc.field {
+			t.Fatalf("%s: expected the error to name the field, got %v", tc.field, field)
+		}
+	}
+}
+
+func TestValidateAllowsCustomEnvironmentWithURL(t *testing.T) {
+	cfg := NewSDKConfig("ak_test_123", Environment("ACME"), nil, nil).
+		WithEnvironmentURLs(map[Environment]string{"ACME": "https://acme.example"})
+	if results := cfg.Validate(); results.HasErrors() {
+		t.Fatalf("expected a custom environment with a URL to be valid, got %+v", results.Results)
+	}
+}
+
+func TestValidateWarnsOnDuplicateSources(t *testing.T) {
+	cfg := NewSDKConfig("ak_test_123", EnvironmentSandbox, []*Source{NewSource("erp", "1.0", nil), NewSource("erp", "1.0", nil)}, nil)
+	results := cfg.Validate()
+	if results.HasErrors() || results.WarningCount() != 1 {
+		t.Fatalf("expected a duplicate source warning, got %+v", results.Results)
+	}
+}
+
+func TestAPIKeyModeMustMatchEnvironment(t *testing.T) {
+	cases := []struct {
+		apiKey      string
+		environment Environment
+		mismatch    bool
+	}{
+		{"ak_test_123", EnvironmentSandbox, false},
+		{"ak_test_123", EnvironmentDev, false},
+		{"ak_test_123", EnvironmentSimulation, false},
+		{"ak_test_123", EnvironmentProduction, true},
+		{"ak_live_123", EnvironmentProduction, false},
+		{"ak_live_123", EnvironmentSandbox, true},
+		{"ak_live_123", EnvironmentLocal, true},
+		{"ak_other_123", EnvironmentProduction, false},
+		{"test-key", EnvironmentProduction, false},
+	}
+
+	for _, tc := range cases {
+		cfg := NewSDKConfig(tc.apiKey, tc.environment, nil, nil)
+		cfg.SetQueueMode(QueueModeMemory)
+		err := cfg.CheckAPIKeyEnvironment()
+		_, newErr := NewSDK(cfg)
+		if !tc.mismatch {
+			if err != nil || newErr != nil {
+				t.Fatalf("%s in %s: expected no error, got %v / %v", tc.apiKey, tc.environment, err, newErr)
+			}
+			continue
+		}
+		if code, ok := GetErrorCode(err); !ok || code != ErrorCodeConfigurationError {
+			t.Fatalf("%s in %s: expected CONFIGURATION_ERROR, got %v", tc.apiKey, tc.environment, err)
+		}
+		if code, ok := GetErrorCode(newErr); !ok || code != ErrorCodeConfigurationError {
+			t.Fatalf("%s in %s: expected NewSDK to fail, got %v", tc.apiKey, tc.environment, newErr)
+		}
+
+		cfg.SetAllowAPIKeyEnvironmentMismatch(true)
+		if err := cfg.CheckAPIKeyEnvironment(); err != nil {
+			t.Fatalf("%s in %s: expected the override to allow the key, got %v", tc.apiKey, tc.environment, err)
+		}
+		results := cfg.Validate()
+		if results.HasErrors() || results.WarningCount() != 1 {
+			t.Fatalf("%s in %s: expected the mismatch as a warning, got %+v", tc.apiKey, tc.environment, results.Results)
+		}
+		if _, err := NewSDK(cfg); err != nil {
+			t.Fatalf("%s in %s: expected NewSDK to accept the override, got %v", tc.apiKey, tc.environment, err)
+		}
+	}
+}
+
+func TestAPIKeyModeIsNotCheckedForCustomEnvironments(t *testing.T) {
+	cfg := NewSDKConfig("ak_live_123", Environment("ACME"), nil, nil).
+		WithEnvironmentURLs(map[Environment]string{"ACME": "https://acme.example"})
+	if cfg.GetAPIKeyMode() != APIKeyModeLive {
+		t.Fatalf("expected a live key, got %q", cfg.GetAPIKeyMode())
+	}
+	if err := cfg.CheckAPIKeyEnvironment(); err != nil {
+		t.Fatalf("expected custom environments to be skipped, got %v", err)
+	}
+}
+
+func TestWithRetryPresetSelectsPresetByName(t *testing.T) {
+	cases := []struct {
+		name        string
+		maxAttempts int
+		baseDelayMs int
+		maxDelayMs  int
+		multiplier  float64
+	}{
+		{"default", 5, 500, 30000, 2.0},
+		{"aggressive", 7, 200, 60000, 1.5},
+		{"Conservative", 3, 1000, 10000, 2.5},
+		{" none ", 1, 500, 30000, 2.0},
+	}
+	for _, tc := range cases {
+		cfg := NewSDKConfig("ak_test_key", EnvironmentSandbox, nil, nil)
+		if err := cfg.WithRetryPreset(tc.name); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		retry := cfg.GetRetryConfig()
+		if retry.MaxAttempts != tc.maxAttempts || retry.BaseDelayMs != tc.baseDelayMs || retry.MaxDelayMs != tc.maxDelayMs || retry.BackoffMultiplier != tc.multiplier {
+			t.Fatalf("%s: unexpected retry config %+v", tc.name, retry)
+		}
+	}
+
+	cfg := NewSDKConfig("ak_test_key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	err := cfg.WithRetryPreset("reckless")
+	sdkErr, ok := err.(*SDKError)
+	if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeConfigurationError || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "retry_preset" {
+		t.Fatalf("expected CONFIGURATION_ERROR for an unknown preset, got %v", err)
+	}
+	if cfg.GetRetryConfig().MaxAttempts != 1 || cfg.GetRetryPreset() != "" {
+		t.Fatalf("expected an unknown preset to leave the config unchanged")
+	}
+}
+
+func TestDefaultRetryConfigFollowsEnvironment(t *testing.T) {
+	cases := []struct {
+		environment Environment
+		apiKey      string
+		maxAttempts int
+		baseDelayMs int
+	}{
+		{EnvironmentProduction, "ak_live_key", 3, 1000},
+		{EnvironmentSimulation, "ak_test_key", 3, 1000},
+		{EnvironmentSandbox, "ak_test_key", 5, 500},
+		{EnvironmentDev, "ak_test_key", 5, 500},
+		{EnvironmentTest, "ak_test_key", 5, 500},
+	}
+	t.Cleanup(func() { _ = Close() })
+	for _, tc := range cases {
+		cfg := NewSDKConfigBuilder().APIKey(tc.apiKey).Environment(tc.environment).QueueMode(QueueModeMemory).Build()
+		if retry := cfg.GetRetryConfig(); retry.MaxAttempts != tc.maxAttempts || retry.BaseDelayMs != tc.baseDelayMs {
+			t.Fatalf("%s: unexpected default retry config %+v", tc.environment, retry)
+		}
+		if err := Configure(cfg); err != nil {
+			t.Fatalf("%s: configure failed: %v", tc.environment, err)
+		}
+		if retry := currentSDK().queueManager.GetRetryConfig(); retry.MaxAttempts != tc.maxAttempts {
+			t.Fatalf("%s: expected Configure to use the default retry config, got %+v", tc.environment, retry)
+		}
+	}
+
+	cfg := NewSDKConfig("ak_live_key", EnvironmentSandbox, nil, nil)
+	cfg.SetEnvironment(EnvironmentProduction)
+	if cfg.GetRetryConfig().MaxAttempts != 3 {
+		t.Fatalf("expected the default to follow the environment, got %+v", cfg.GetRetryConfig())
+	}
+
+	supplied := NewSDKConfig("ak_live_key", EnvironmentProduction, nil, NewAggressiveRetryConfig())
+	supplied.SetEnvironment(EnvironmentSimulation)
+	if supplied.GetRetryConfig().MaxAttempts != 7 {
+		t.Fatalf("expected a supplied retry config to be kept, got %+v", supplied.GetRetryConfig())
+	}
+	preset := NewSDKConfig("ak_live_key", EnvironmentProduction, nil, nil)
+	if err := preset.WithRetryPreset(RetryPresetAggressive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preset.SetEnvironment(EnvironmentProduction)
+	if preset.GetRetryConfig().MaxAttempts != 7 {
+		t.Fatalf("expected a chosen preset to override the environment default, got %+v", preset.GetRetryConfig())
+	}
+}
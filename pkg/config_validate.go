@@ -0,0 +1,240 @@
+/*
+Field-level validation of SDKConfig.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/models"
+)
+
+// apiKeyPrefix Prefix shared by every Complyance API key
+const apiKeyPrefix = "ak_"
+
+// APIKeyMode Kind of API key, read from its prefix
+type APIKeyMode string
+
+const (
+	// APIKeyModeTest Keys starting with "ak_test_", for every environment but PRODUCTION
+	APIKeyModeTest APIKeyMode = "test"
+	// APIKeyModeLive Keys starting with "ak_live_", for PRODUCTION only
+	APIKeyModeLive APIKeyMode = "live"
+)
+
+// GetAPIKeyMode Mode of the API key from its prefix, empty when the key has
+// neither the "ak_test_" nor the "ak_live_" prefix
+func (s *SDKConfig) GetAPIKeyMode() APIKeyMode {
+	for _, mode := range []APIKeyMode{APIKeyModeTest, APIKeyModeLive} {
+		if strings.HasPrefix(s.APIKey, apiKeyPrefix+string(mode)+"_") {
+			return mode
+		}
+	}
+	return ""
+}
+
+// CheckAPIKeyEnvironment CONFIGURATION_ERROR when the API key mode does not
+// match Environment: a live key outside PRODUCTION or a test key in PRODUCTION.
+// Keys without a mode prefix and custom environments are not checked, and
+// AllowAPIKeyEnvironmentMismatch turns the error off. Configure runs this check
+// as part of Validate.
+func (s *SDKConfig) CheckAPIKeyEnvironment() error {
+	message := s.apiKeyEnvironmentMismatch()
+	if message == "" || s.AllowAPIKeyEnvironmentMismatch {
+		return nil
+	}
+	detail := NewErrorDetailWithCode(ErrorCodeConfigurationError, message).
+		WithSuggestion("Use the API key issued for this environment, or SetAllowAPIKeyEnvironmentMismatch(true) if the pairing is intended.")
+	detail.AddContextValue("apiKeyMode", string(s.GetAPIKeyMode()))
+	detail.AddContextValue("environment", string(s.Environment))
+	return NewSDKError(detail)
+}
+
+// apiKeyEnvironmentMismatch Description of the mismatch, empty when the key
+// mode matches the environment or cannot be checked
+func (s *SDKConfig) apiKeyEnvironmentMismatch() string {
+	mode := s.GetAPIKeyMode()
+	if mode == "" || !knownEnvironments[s.Environment] {
+		return ""
+	}
+	if (mode == APIKeyModeLive) == (s.Environment == EnvironmentProduction) {
+		return ""
+	}
+	return fmt.Sprintf("API key is a %s key but the environment is %s", mode, s.Environment)
+}
+
+// Validate Check the API key, environment, retry configuration, rate limit,
+// sources and monetary fields.
+// Problems that stop the SDK from working are errors, anything merely
+// unexpected (such as an API key without the "ak_" prefix) is a warning. An
+// API key mode that does not match the environment is an error, or a warning
+// with AllowAPIKeyEnvironmentMismatch. Configure fails with the first error and
+// logs the warnings.
+func (s *SDKConfig) Validate() *models.ValidationResults {
+	results := models.NewValidationResults()
+	s.validateAPIKey(results)
+	s.validateEnvironment(results)
+	s.validateRetryConfig(results)
+	s.validateRateLimit(results)
+	s.validateSources(results)
+	s.validateMonetaryFields(results)
+	return results
+}
+
+func (s *SDKConfig) validateAPIKey(results *models.ValidationResults) {
+	apiKey := strings.TrimSpace(s.APIKey)
+	switch {
+	case apiKey == "":
+		results.AddResult(models.NewValidationResult("api_key", "API key is required", models.ValidationSeverityError).
+			WithExpected(apiKeyPrefix + "..."))
+	case apiKey != s.APIKey:
+		results.AddResult(models.NewValidationResult("api_key", "API key has leading or trailing whitespace", models.ValidationSeverityError))
+	case !strings.HasPrefix(apiKey, apiKeyPrefix):
+		results.AddResult(models.NewValidationResult("api_key", fmt.Sprintf("API key does not start with %q", apiKeyPrefix), models.ValidationSeverityWarning).
+			WithExpected(apiKeyPrefix + "..."))
+	}
+
+	if mismatch := s.apiKeyEnvironmentMismatch(); mismatch != "" {
+		severity := models.ValidationSeverityError
+		if s.AllowAPIKeyEnvironmentMismatch {
+			severity = models.ValidationSeverityWarning
+		}
+		results.AddResult(models.NewValidationResult("api_key", mismatch, severity).WithValue(string(s.GetAPIKeyMode())))
+	}
+}
+
+func (s *SDKConfig) validateEnvironment(results *models.ValidationResults) {
+	if knownEnvironments[s.Environment] || strings.TrimSpace(s.EnvironmentURLs[s.Environment]) != "" {
+		return
+	}
+	message := fmt.Sprintf("Unknown environment %q", s.Environment)
+	if s.Environment == "" {
+		message = "Environment is required"
+	}
+	results.AddResult(models.NewValidationResult("environment", message, models.ValidationSeverityError).
+		WithValue(string(s.Environment)))
+}
+
+func (s *SDKConfig) validateRetryConfig(results *models.ValidationResults) {
+	if s.RetryPreset != "" && RetryConfigByName(s.RetryPreset) == nil {
+		results.AddResult(models.NewValidationResult("retry_preset", fmt.Sprintf("Unknown retry preset %q", s.RetryPreset), models.ValidationSeverityError).
+			WithValue(s.RetryPreset).WithExpected(strings.Join(RetryPresetNames(), ", ")))
+	}
+	retry := s.RetryConfig
+	if retry == nil {
+		results.AddError("retry_config", "Retry configuration is required")
+		return
+	}
+	invalid := func(field, message string, value interface{}) {
+		results.AddResult(models.NewValidationResult("retry_config."+field, message, models.ValidationSeverityError).WithValue(value))
+	}
+	if retry.MaxAttempts < 1 {
+		invalid("max_attempts", "Max attempts must be at least 1", retry.MaxAttempts)
+	}
+	if retry.BaseDelayMs < 0 {
+		invalid("base_delay_ms", "Base delay must not be negative", retry.BaseDelayMs)
+	}
+	if retry.MaxDelayMs < retry.BaseDelayMs {
+		invalid("max_delay_ms", "Max delay must not be less than the base delay", retry.MaxDelayMs)
+	}
+	if retry.BackoffMultiplier < 1 {
+		invalid("backoff_multiplier", "Backoff multiplier must be at least 1", retry.BackoffMultiplier)
+	}
+	if retry.JitterFactor < 0 || retry.JitterFactor > 1 {
+		invalid("jitter_factor", "Jitter factor must be between 0 and 1", retry.JitterFactor)
+	}
+	if retry.Backoff == nil && retry.BackoffStrategyName != "" && BackoffStrategyByName(retry.BackoffStrategyName) == nil {
+		invalid("backoff_strategy", fmt.Sprintf("Unknown backoff strategy %q", retry.BackoffStrategyName), retry.BackoffStrategyName)
+	}
+	if retry.CircuitBreakerEnabled {
+		if retry.FailureThreshold < 1 {
+			invalid("failure_threshold", "Failure threshold must be at least 1 when the circuit breaker is enabled", retry.FailureThreshold)
+		}
+		if retry.CircuitBreakerTimeoutMs < 0 {
+			invalid("circuit_breaker_timeout_ms", "Circuit breaker timeout must not be negative", retry.CircuitBreakerTimeoutMs)
+		}
+		if retry.HalfOpenMaxProbes < 0 {
+			invalid("half_open_max_probes", "Half-open probe limit must not be negative", retry.HalfOpenMaxProbes)
+		}
+		if retry.FailureWindowMs < 0 {
+			invalid("failure_window_ms", "Failure window must not be negative", retry.FailureWindowMs)
+		}
+	}
+}
+
+func (s *SDKConfig) validateRateLimit(results *models.ValidationResults) {
+	if s.RateLimitPerSecond < 0 {
+		results.AddResult(models.NewValidationResult("rate_limit_per_second", "Rate limit must not be negative", models.ValidationSeverityError).WithValue(s.RateLimitPerSecond))
+	}
+	if s.RateLimitBurst < 0 {
+		results.AddResult(models.NewValidationResult("rate_limit_burst", "Rate limit burst must not be negative", models.ValidationSeverityError).WithValue(s.RateLimitBurst))
+	}
+	if s.RateLimitThrottleThreshold < 0 {
+		results.AddResult(models.NewValidationResult("rate_limit_throttle_threshold", "Rate limit throttle threshold must not be negative", models.ValidationSeverityError).WithValue(s.RateLimitThrottleThreshold))
+	}
+}
+
+func (s *SDKConfig) validateSources(results *models.ValidationResults) {
+	seen := map[string]bool{}
+	for i, source := range s.Sources {
+		field := fmt.Sprintf("sources[%d]", i)
+		if source == nil {
+			results.AddError(field, "Source must not be nil")
+			continue
+		}
+		if strings.TrimSpace(source.Name) == "" {
+			results.AddError(field+".name", "Source name is required")
+		}
+		if strings.TrimSpace(source.Version) == "" {
+			results.AddError(field+".version", "Source version is required")
+		}
+		key := source.GetIdentity()
+		if seen[key] {
+			results.AddWarning(field, fmt.Sprintf("Source %s is listed more than once", key))
+		}
+		seen[key] = true
+	}
+	if source := s.DefaultSource; source != nil {
+		if strings.TrimSpace(source.Name) == "" {
+			results.AddError("default_source.name", "Source name is required")
+		}
+		if strings.TrimSpace(source.Version) == "" {
+			results.AddError("default_source.version", "Source version is required")
+		}
+	}
+}
+
+func (s *SDKConfig) validateMonetaryFields(results *models.ValidationResults) {
+	if s.MonetaryPrecision < 0 {
+		results.AddResult(models.NewValidationResult("monetary_precision", "Monetary precision must not be negative", models.ValidationSeverityError).WithValue(s.MonetaryPrecision))
+	}
+	for i, path := range s.MonetaryFieldPaths {
+		if strings.TrimSpace(path) == "" || strings.Contains(path, "..") || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") {
+			results.AddResult(models.NewValidationResult(fmt.Sprintf("monetary_field_paths[%d]", i), fmt.Sprintf("Invalid monetary field path %q", path), models.ValidationSeverityError).WithValue(path))
+		}
+	}
+}
+
+// firstValidationError First error in results, nil when there is none
+func firstValidationError(results *models.ValidationResults) *models.ValidationResult {
+	for _, result := range results.Results {
+		if result.IsError() {
+			return result
+		}
+	}
+	return nil
+}
+
+// newConfigValidationError CONFIGURATION_ERROR for an invalid SDKConfig field
+func newConfigValidationError(result *models.ValidationResult) *SDKError {
+	detail := NewErrorDetailWithCode(
+		ErrorCodeConfigurationError,
+		fmt.Sprintf("Invalid SDK configuration: %s: %s", result.Field, result.Message),
+	).WithSuggestion("Call SDKConfig.Validate() to list every configuration problem.")
+	detail.AddContextValue("field", result.Field)
+	if result.Value != nil {
+		detail.AddContextValue("value", result.Value)
+	}
+	return NewSDKError(detail)
+}
@@ -0,0 +1,31 @@
+/*
+Context keys read by the SDK.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"strings"
+)
+
+// contextKey Type of the context keys defined by the SDK, so they cannot
+// collide with keys set by other packages
+type contextKey string
+
+// ContextKeyTenantID Context key for the tenant a request is made for. A
+// non-empty string stored under it is sent as the X-Tenant-ID header by the
+// calls that take a context, e.g.
+// context.WithValue(ctx, ContextKeyTenantID, "acme").
+const ContextKeyTenantID contextKey = "complyance.tenantId"
+
+// tenantIDHeader Header the tenant ID from the context is sent in
+const tenantIDHeader = "X-Tenant-ID"
+
+// tenantIDFromContext Tenant ID stored in ctx, empty when there is none
+func tenantIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	tenantID, _ := ctx.Value(ContextKeyTenantID).(string)
+	return strings.TrimSpace(tenantID)
+}
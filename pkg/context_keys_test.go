@@ -0,0 +1,44 @@
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// submitWithContext Submit a payload with ctx and return the request headers the server saw
+func submitWithContext(t *testing.T, ctx context.Context) http.Header {
+	t.Helper()
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+	var seen http.Header
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, sources, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"SUB-1","status":"ACCEPTED"}}}`))
+	})
+
+	if _, err := SubmitPayloadContext(ctx, `{"invoice_data":{"invoice_number":"INV-1"}}`, "src:1", CountrySA, DocumentTypeTaxInvoice); err != nil {
+		t.Fatalf("SubmitPayloadContext failed: %v", err)
+	}
+	if seen == nil {
+		t.Fatalf("expected the request to reach the server")
+	}
+	return seen
+}
+
+func TestTenantIDFromContextIsSentAsHeader(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ContextKeyTenantID, "acme")
+	if got := submitWithContext(t, ctx).Get("X-Tenant-ID"); got != "acme" {
+		t.Fatalf("expected X-Tenant-ID acme, got %q", got)
+	}
+}
+
+func TestTenantIDHeaderIsOmittedWithoutTenant(t *testing.T) {
+	if _, ok := submitWithContext(t, context.Background())["X-Tenant-Id"]; ok {
+		t.Fatalf("expected no X-Tenant-ID header")
+	}
+	ctx := context.WithValue(context.Background(), contextKey("tenantId"), "acme")
+	if _, ok := submitWithContext(t, ctx)["X-Tenant-Id"]; ok {
+		t.Fatalf("expected other context keys to be ignored")
+	}
+}
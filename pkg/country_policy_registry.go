@@ -5,13 +5,82 @@ package complyancesdk
 
 import (
 	"strings"
+	"sync"
 )
 
-// CountryPolicyRegistry Country policy registry matching Python SDK
-type CountryPolicyRegistry struct{}
+// countryPolicyKey Key for a custom policy registration
+type countryPolicyKey struct {
+	country     Country
+	logicalType LogicalDocType
+}
+
+// CountryPolicyRegistry Country policy registry matching Python SDK. Custom
+// policies registered for a country and logical type take precedence over the
+// built-in mappings.
+type CountryPolicyRegistry struct {
+	mu     sync.RWMutex
+	custom map[countryPolicyKey]*PolicyResult
+}
+
+// Register a custom policy for a country and logical document type, replacing
+// any previous registration
+func (c *CountryPolicyRegistry) Register(country Country, logicalType LogicalDocType, policy *PolicyResult) error {
+	if policy == nil {
+		return NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			"Country policy is required",
+		).WithSuggestion("Pass a PolicyResult built with NewPolicyResult, or call Unregister to remove a policy."))
+	}
+	if strings.TrimSpace(string(policy.GetBaseType())) == "" {
+		return NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			"Country policy base type is required",
+		).WithSuggestion("Set the base DocumentType the logical type maps to, e.g. DocumentTypeTaxInvoice."))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.custom == nil {
+		c.custom = make(map[countryPolicyKey]*PolicyResult)
+	}
+	c.custom[countryPolicyKey{country: country, logicalType: logicalType}] = policy.copy()
+	return nil
+}
+
+// Unregister removes a custom policy so the built-in mapping applies again
+func (c *CountryPolicyRegistry) Unregister(country Country, logicalType LogicalDocType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.custom, countryPolicyKey{country: country, logicalType: logicalType})
+}
+
+// IsCustom reports whether a custom policy is registered for the country and logical type
+func (c *CountryPolicyRegistry) IsCustom(country Country, logicalType LogicalDocType) bool {
+	_, ok := c.customPolicy(country, logicalType)
+	return ok
+}
+
+// customPolicy Copy of the registered custom policy, if any
+func (c *CountryPolicyRegistry) customPolicy(country Country, logicalType LogicalDocType) (*PolicyResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	policy, ok := c.custom[countryPolicyKey{country: country, logicalType: logicalType}]
+	if !ok {
+		return nil, false
+	}
+	return policy.copy(), true
+}
 
 // Evaluate country policy and return policy result
 func (c *CountryPolicyRegistry) Evaluate(country Country, logicalType LogicalDocType) *PolicyResult {
+	if policy, ok := c.customPolicy(country, logicalType); ok {
+		return policy
+	}
+	return c.evaluateBuiltIn(country, logicalType)
+}
+
+// evaluateBuiltIn Policy derived from the built-in country mappings
+func (c *CountryPolicyRegistry) evaluateBuiltIn(country Country, logicalType LogicalDocType) *PolicyResult {
 	// Default base type mapping
 	baseType := DocumentTypeTaxInvoice
 	documentType := string(logicalType)
@@ -153,3 +222,21 @@ func (c *CountryPolicyRegistry) getSingaporeDocumentType(logicalType LogicalDocT
 
 // Global registry instance
 var CountryPolicyRegistryInstance = &CountryPolicyRegistry{}
+
+// RegisterCountryPolicy Register a custom policy on the global registry for a
+// country and logical document type. PushToUnify then uses its base type, document
+// type and meta.config flags instead of the built-in mapping.
+func RegisterCountryPolicy(country Country, logicalType LogicalDocType, policy *PolicyResult) error {
+	return CountryPolicyRegistryInstance.Register(country, logicalType, policy)
+}
+
+// UnregisterCountryPolicy Remove a custom policy from the global registry
+func UnregisterCountryPolicy(country Country, logicalType LogicalDocType) {
+	CountryPolicyRegistryInstance.Unregister(country, logicalType)
+}
+
+// GetPolicy Effective policy for a country and logical document type: the custom
+// registration if there is one, otherwise the built-in mapping
+func GetPolicy(country Country, logicalType LogicalDocType) *PolicyResult {
+	return CountryPolicyRegistryInstance.Evaluate(country, logicalType)
+}
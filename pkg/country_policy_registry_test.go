@@ -0,0 +1,65 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestRegisterCountryPolicyOverridesPushToUnify(t *testing.T) {
+	egypt := Country("EG")
+	policy := NewPolicyResult(DocumentTypeSimplifiedInvoice, "eg_invoice", map[string]interface{}{
+		"isExport":   true,
+		"customFlag": "eta",
+	})
+	if err := RegisterCountryPolicy(egypt, LogicalDocTypeTaxInvoice, policy); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	t.Cleanup(func() { UnregisterCountryPolicy(egypt, LogicalDocTypeTaxInvoice) })
+
+	var body map[string]interface{}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentDev, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, egypt, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-EG-1"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	documentType, _ := body["documentType"].(map[string]interface{})
+	if documentType["base"] != "simplified_invoice" {
+		t.Fatalf("expected overridden base type, got %v", body["documentType"])
+	}
+	payload, _ := body["payload"].(map[string]interface{})
+	meta, _ := payload["meta"].(map[string]interface{})
+	config, _ := meta["config"].(map[string]interface{})
+	if config["isExport"] != true || config["customFlag"] != "eta" {
+		t.Fatalf("expected custom meta.config flags, got %v", config)
+	}
+}
+
+func TestGetPolicyFallsBackToBuiltIn(t *testing.T) {
+	builtIn := GetPolicy(CountrySA, LogicalDocTypeSimplifiedTaxInvoice)
+	if builtIn.GetBaseType() != DocumentTypeSimplifiedInvoice {
+		t.Fatalf("expected built-in simplified invoice, got %s", builtIn.GetBaseType())
+	}
+
+	custom := NewPolicyResult(DocumentTypeTaxInvoice, "custom", nil)
+	if err := RegisterCountryPolicy(CountrySA, LogicalDocTypeSimplifiedTaxInvoice, custom); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	custom.BaseType = DocumentTypeCreditNote
+	if got := GetPolicy(CountrySA, LogicalDocTypeSimplifiedTaxInvoice).GetBaseType(); got != DocumentTypeTaxInvoice {
+		t.Fatalf("expected registered copy to be unaffected by later changes, got %s", got)
+	}
+
+	UnregisterCountryPolicy(CountrySA, LogicalDocTypeSimplifiedTaxInvoice)
+	if got := GetPolicy(CountrySA, LogicalDocTypeSimplifiedTaxInvoice).GetBaseType(); got != DocumentTypeSimplifiedInvoice {
+		t.Fatalf("expected built-in policy after unregister, got %s", got)
+	}
+
+	if err := RegisterCountryPolicy(CountrySA, LogicalDocTypeTaxInvoice, nil); err == nil {
+		t.Fatalf("expected error for nil policy")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Configurable rules for the destinations generated when a request has none, and
+merging of generated destinations with explicit ones.
+*/
+package complyancesdk
+
+import (
+	"sort"
+	"strings"
+)
+
+// DestinationGenerator Generate the destinations of a request sent without
+// any. documentType is the GETS base document type, e.g. "tax_invoice".
+type DestinationGenerator func(country Country, documentType string, purpose Purpose) []*Destination
+
+// DestinationRule Destinations to generate for requests matching Country,
+// DocumentType and Purpose. Empty match fields match any request.
+type DestinationRule struct {
+	Country Country
+	// DocumentType is the GETS base document type, e.g. "tax_invoice"
+	DocumentType string
+	Purpose      Purpose
+
+	// SkipTaxAuthority drops the default tax authority destination
+	SkipTaxAuthority bool
+	// Archive adds an ARCHIVE destination
+	Archive bool
+	// EmailRecipients adds an EMAIL destination when not empty
+	EmailRecipients []string
+	EmailSubject    string
+	EmailBody       string
+}
+
+// matches Whether the rule applies to a request
+func (r DestinationRule) matches(country Country, documentType string, purpose Purpose) bool {
+	if r.Country != "" && !strings.EqualFold(string(r.Country), string(country)) {
+		return false
+	}
+	if r.DocumentType != "" && !strings.EqualFold(r.DocumentType, documentType) {
+		return false
+	}
+	return r.Purpose == "" || r.Purpose == purpose
+}
+
+// NewDestinationRules Generator that starts from the default tax authority
+// destination and applies every matching rule: the tax authority is dropped
+// if any matching rule skips it, and one ARCHIVE destination and one EMAIL
+// destination per matching rule with recipients are added.
+func NewDestinationRules(rules ...DestinationRule) DestinationGenerator {
+	rules = append([]DestinationRule(nil), rules...)
+	return func(country Country, documentType string, purpose Purpose) []*Destination {
+		skipTaxAuthority, archive := false, false
+		var emails []*Destination
+		for _, rule := range rules {
+			if !rule.matches(country, documentType, purpose) {
+				continue
+			}
+			skipTaxAuthority = skipTaxAuthority || rule.SkipTaxAuthority
+			archive = archive || rule.Archive
+			if len(rule.EmailRecipients) > 0 {
+				recipients := append([]string(nil), rule.EmailRecipients...)
+				emails = append(emails, NewEmailDestination(recipients, rule.EmailSubject, rule.EmailBody))
+			}
+		}
+
+		destinations := []*Destination{}
+		if !skipTaxAuthority {
+			destinations = append(destinations, generateDefaultDestinations(string(country), documentType)...)
+		}
+		if archive {
+			destinations = append(destinations, NewArchiveDestination())
+		}
+		return append(destinations, emails...)
+	}
+}
+
+// generateDestinations Destinations for a request sent without any: none when
+// auto-generation is off, otherwise from the configured DestinationGenerator
+// or the default tax authority destination
+func (s *GETSUnifySDK) generateDestinations(country Country, documentType string, purpose Purpose) []*Destination {
+	if !s.config.AutoGenerateTaxDestination {
+		return []*Destination{}
+	}
+	if s.config.DestinationGenerator != nil {
+		if destinations := s.config.DestinationGenerator(country, documentType, purpose); destinations != nil {
+			return destinations
+		}
+		return []*Destination{}
+	}
+	return generateDefaultDestinations(string(country), documentType)
+}
+
+// generatedDestinationsMarker Placeholder added by WithGeneratedDestinations,
+// recognized by identity and never sent
+var generatedDestinationsMarker = &Destination{}
+
+// WithGeneratedDestinations Destinations for a single PushToUnify call that are
+// merged with the generated ones instead of replacing them, e.g. an ARCHIVE
+// destination on top of the tax authority. SDKConfig.SetMergeDestinations
+// does the same for every call.
+func WithGeneratedDestinations(destinations ...*Destination) []*Destination {
+	return append([]*Destination{generatedDestinationsMarker}, destinations...)
+}
+
+// splitGeneratedDestinationsMarker Destinations without the marker of
+// WithGeneratedDestinations, and whether it was present
+func splitGeneratedDestinationsMarker(destinations []*Destination) ([]*Destination, bool) {
+	for i, destination := range destinations {
+		if destination == generatedDestinationsMarker {
+			explicit := make([]*Destination, 0, len(destinations)-1)
+			explicit = append(explicit, destinations[:i]...)
+			explicit, _ = splitGeneratedDestinationsMarker(append(explicit, destinations[i+1:]...))
+			return explicit, true
+		}
+	}
+	return destinations, false
+}
+
+// mergeDestinations Generated destinations followed by the explicit ones,
+// dropping generated destinations with the same type and key as an explicit
+// one and repeated explicit destinations
+func mergeDestinations(generated, explicit []*Destination) []*Destination {
+	seen := make(map[string]bool, len(explicit))
+	for _, destination := range explicit {
+		if destination != nil {
+			seen[destinationKey(destination)] = true
+		}
+	}
+	merged := make([]*Destination, 0, len(generated)+len(explicit))
+	for _, destination := range generated {
+		if destination != nil && !seen[destinationKey(destination)] {
+			merged = append(merged, destination)
+		}
+	}
+	added := make(map[string]bool, len(explicit))
+	for _, destination := range explicit {
+		if destination != nil {
+			key := destinationKey(destination)
+			if added[key] {
+				continue
+			}
+			added[key] = true
+		}
+		merged = append(merged, destination)
+	}
+	return merged
+}
+
+// destinationKey Type and identifying details of a destination: country and
+// authority for TAX_AUTHORITY, recipients for EMAIL, participant for PEPPOL and
+// URL for WEBHOOK. There is one ARCHIVE destination per request.
+func destinationKey(destination *Destination) string {
+	details := destination.Details
+	if details == nil {
+		details = &DestinationDetails{}
+	}
+	value := func(field *string) string {
+		if field == nil {
+			return ""
+		}
+		return strings.ToUpper(strings.TrimSpace(*field))
+	}
+
+	key := string(destination.Type) + "|"
+	switch destination.Type {
+	case DestinationTypeTaxAuthority:
+		return key + value(details.Country) + "|" + value(details.Authority)
+	case DestinationTypeEmail:
+		var recipients []string
+		if details.Recipients != nil {
+			for _, recipient := range *details.Recipients {
+				recipients = append(recipients, strings.ToLower(strings.TrimSpace(recipient)))
+			}
+		}
+		sort.Strings(recipients)
+		return key + strings.Join(recipients, ",")
+	case DestinationTypePeppol:
+		return key + value(details.ParticipantID)
+	case DestinationTypeWebhook:
+		url := ""
+		if details.URL != nil {
+			url = strings.TrimSpace(*details.URL)
+		}
+		return key + value(details.Method) + " " + url
+	}
+	return key
+}
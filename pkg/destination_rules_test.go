@@ -0,0 +1,168 @@
+package complyancesdk
+
+import (
+	"reflect"
+	"testing"
+)
+
+// destinationTypes Types of destinations, in order
+func destinationTypes(destinations []*Destination) []DestinationType {
+	types := []DestinationType{}
+	for _, destination := range destinations {
+		types = append(types, destination.Type)
+	}
+	return types
+}
+
+func TestDestinationRulesGenerateMatchingDestinations(t *testing.T) {
+	cases := []struct {
+		name         string
+		rules        []DestinationRule
+		country      Country
+		documentType string
+		purpose      Purpose
+		want         []DestinationType
+	}{
+		{
+			name:         "no rules keeps the tax authority",
+			country:      CountrySA,
+			documentType: "tax_invoice",
+			purpose:      PurposeInvoicing,
+			want:         []DestinationType{DestinationTypeTaxAuthority},
+		},
+		{
+			name:         "always archive",
+			rules:        []DestinationRule{{Archive: true}},
+			country:      CountryMY,
+			documentType: "credit_note",
+			purpose:      PurposeInvoicing,
+			want:         []DestinationType{DestinationTypeTaxAuthority, DestinationTypeArchive},
+		},
+		{
+			name: "email for a country's tax invoices only",
+			rules: []DestinationRule{
+				{Country: CountryAE, DocumentType: "TAX_INVOICE", EmailRecipients: []string{"ap@example.com"}},
+			},
+			country:      CountryAE,
+			documentType: "tax_invoice",
+			purpose:      PurposeInvoicing,
+			want:         []DestinationType{DestinationTypeTaxAuthority, DestinationTypeEmail},
+		},
+		{
+			name: "email rule for another country does not apply",
+			rules: []DestinationRule{
+				{Country: CountryAE, EmailRecipients: []string{"ap@example.com"}},
+			},
+			country:      CountrySA,
+			documentType: "tax_invoice",
+			purpose:      PurposeInvoicing,
+			want:         []DestinationType{DestinationTypeTaxAuthority},
+		},
+		{
+			name: "mapping skips the tax authority",
+			rules: []DestinationRule{
+				{Archive: true},
+				{Purpose: PurposeMapping, SkipTaxAuthority: true},
+			},
+			country:      CountrySA,
+			documentType: "tax_invoice",
+			purpose:      PurposeMapping,
+			want:         []DestinationType{DestinationTypeArchive},
+		},
+	}
+
+	for _, tc := range cases {
+		generate := NewDestinationRules(tc.rules...)
+		got := destinationTypes(generate(tc.country, tc.documentType, tc.purpose))
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDestinationGeneratorAppliesToRequestsWithoutDestinations(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	cfg.SetDestinationGenerator(NewDestinationRules(
+		DestinationRule{Archive: true, EmailRecipients: []string{"finance@example.com"}},
+		DestinationRule{Purpose: PurposeMapping, SkipTaxAuthority: true},
+	))
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+
+	build := func(purpose Purpose, destinations []*Destination) []DestinationType {
+		request, err := sdk.buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+			CountrySA, OperationSingle, ModeDocuments, purpose, testInvoicePayload("INV-1"), destinations)
+		if err != nil {
+			t.Fatalf("build failed: %v", err)
+		}
+		return destinationTypes(request.GetDestinations())
+	}
+
+	want := []DestinationType{DestinationTypeTaxAuthority, DestinationTypeArchive, DestinationTypeEmail}
+	if got := build(PurposeInvoicing, nil); !reflect.DeepEqual(got, want) {
+		t.Fatalf("invoicing: got %v, want %v", got, want)
+	}
+	want = []DestinationType{DestinationTypeArchive, DestinationTypeEmail}
+	if got := build(PurposeMapping, nil); !reflect.DeepEqual(got, want) {
+		t.Fatalf("mapping: got %v, want %v", got, want)
+	}
+	if got := build(PurposeValidation, nil); len(got) != 0 {
+		t.Fatalf("expected validation requests to get no destinations, got %v", got)
+	}
+	if got := build(PurposeInvoicing, []*Destination{NewArchiveDestination()}); !reflect.DeepEqual(got, []DestinationType{DestinationTypeArchive}) {
+		t.Fatalf("expected explicit destinations to be kept, got %v", got)
+	}
+
+	cfg.SetAutoGenerateTaxDestination(false)
+	if got := build(PurposeInvoicing, nil); len(got) != 0 {
+		t.Fatalf("expected no destinations with auto-generation off, got %v", got)
+	}
+}
+
+func TestExplicitDestinationsMergeWithGeneratedOnes(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+	build := func(destinations []*Destination) []*Destination {
+		request, err := sdk.buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+			CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), destinations)
+		if err != nil {
+			t.Fatalf("build failed: %v", err)
+		}
+		return request.GetDestinations()
+	}
+	email := func() *Destination {
+		return NewEmailDestination([]string{"ap@example.com"}, "Invoice", "")
+	}
+
+	merged := build(WithGeneratedDestinations(email()))
+	if got, want := destinationTypes(merged), []DestinationType{DestinationTypeTaxAuthority, DestinationTypeEmail}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the email next to the generated tax authority, got %v", got)
+	}
+	if authority := merged[0].GetDetails().Authority; authority == nil || *authority != "ZATCA" {
+		t.Fatalf("expected the generated ZATCA destination, got %+v", merged[0].GetDetails())
+	}
+
+	explicitAuthority := NewTaxAuthorityDestination("sa", "zatca", "tax_invoice")
+	merged = build(WithGeneratedDestinations(email(), explicitAuthority, NewEmailDestination([]string{"AP@example.com "}, "Copy", "")))
+	if got, want := destinationTypes(merged), []DestinationType{DestinationTypeEmail, DestinationTypeTaxAuthority}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected duplicates by type and key to be dropped, got %v", got)
+	}
+	if merged[1] != explicitAuthority {
+		t.Fatalf("expected the explicit tax authority to replace the generated one")
+	}
+
+	if got := destinationTypes(build([]*Destination{email()})); !reflect.DeepEqual(got, []DestinationType{DestinationTypeEmail}) {
+		t.Fatalf("expected explicit destinations to replace generated ones without merging, got %v", got)
+	}
+	cfg.SetMergeDestinations(true)
+	if got, want := destinationTypes(build([]*Destination{email()})), []DestinationType{DestinationTypeTaxAuthority, DestinationTypeEmail}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the config to merge every call, got %v", got)
+	}
+}
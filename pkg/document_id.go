@@ -0,0 +1,165 @@
+/*
+Document ID extraction for queued submissions.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDocumentIDPaths Paths tried, in order, for document types without
+// configured paths. Paths are dot separated and relative to the serialized
+// UnifyRequest; a leading "$." is accepted and numeric segments index arrays.
+var DefaultDocumentIDPaths = []string{
+	"payload.invoice_data.invoice_number",
+	"payload.invoice_data.document_number",
+	"payload.invoice_data.id",
+}
+
+// unsafeDocumentIDChars Characters replaced so a document ID is safe in a file name
+var unsafeDocumentIDChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// GetDocumentIDPaths getter for the per-document-type document ID paths
+func (p *PersistentQueueManager) GetDocumentIDPaths() map[DocumentType][]string {
+	return p.documentIDPaths
+}
+
+// SetDocumentIDPaths setter for the paths tried, in order, to find a queued
+// submission's document ID per document type. Types without an entry use
+// DefaultDocumentIDPaths.
+func (p *PersistentQueueManager) SetDocumentIDPaths(paths map[DocumentType][]string) {
+	p.documentIDPaths = copyDocumentIDPaths(paths)
+}
+
+// documentIDPathsFor Paths to try for a document type
+func (p *PersistentQueueManager) documentIDPathsFor(documentType DocumentType) []string {
+	if paths, ok := p.documentIDPaths[documentType]; ok && len(paths) > 0 {
+		return paths
+	}
+	return DefaultDocumentIDPaths
+}
+
+// extractDocumentID Document ID from the first configured path that holds a
+// string or number, falling back to a timestamp
+func (p *PersistentQueueManager) extractDocumentID(payload string, documentType DocumentType) string {
+	if documentID, ok := p.findDocumentID(payload, documentType); ok {
+		return documentID
+	}
+	return fmt.Sprintf("doc_%d", p.clock.Now().UnixNano()/int64(time.Millisecond))
+}
+
+// findDocumentID Document ID from the first configured path that holds a
+// string or number, made safe for file names; false when there is none
+func (p *PersistentQueueManager) findDocumentID(payload string, documentType DocumentType) (string, bool) {
+	// Parse the complete UnifyRequest JSON
+	var requestMap map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&requestMap); err != nil {
+		p.logger.Warn("Failed to extract document ID from UnifyRequest payload", map[string]interface{}{"error": err.Error()})
+		return "", false
+	}
+
+	for _, path := range p.documentIDPathsFor(documentType) {
+		if documentID, ok := lookupDocumentID(requestMap, path); ok {
+			return unsafeDocumentIDChars.ReplaceAllString(documentID, "_"), true
+		}
+	}
+
+	p.logger.Debug("No document ID found at configured paths", map[string]interface{}{"documentType": string(documentType)})
+	return "", false
+}
+
+// submissionQueueItemID Queue item ID of a payload submission, derived from its
+// source, document ID, country and document type so the same document is only
+// queued once at a time. Once it succeeded or was dead-lettered, a corrected
+// submission of it is queued again. Submissions without a document ID use a
+// hash of the payload.
+func (p *PersistentQueueManager) submissionQueueItemID(submission *PayloadSubmission) string {
+	documentID, ok := p.findDocumentID(submission.GetPayload(), submission.GetDocumentType())
+	if !ok {
+		return p.buildQueueItemID(
+			nil,
+			string(submission.GetCountry()),
+			string(submission.GetDocumentType()),
+			submission.GetPayload(),
+		)
+	}
+	sourceID := fmt.Sprintf("%s:%s", submission.GetSource().GetName(), submission.GetSource().GetVersion())
+	return unsafeDocumentIDChars.ReplaceAllString(
+		fmt.Sprintf("%s_%s_%s_%s", sourceID, documentID, submission.GetCountry(), submission.GetDocumentType()),
+		"_",
+	)
+}
+
+// lookupDocumentID Non-empty string or number at a dot-separated path
+func lookupDocumentID(document map[string]interface{}, path string) (string, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$.")
+	if path == "" {
+		return "", false
+	}
+
+	var current interface{} = document
+	for _, segment := range strings.Split(path, ".") {
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			current = typed[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(typed) {
+				return "", false
+			}
+			current = typed[index]
+		default:
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		value = strings.TrimSpace(value)
+		return value, value != ""
+	case json.Number:
+		return value.String(), true
+	case int, int32, int64, uint, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", value), true
+	default:
+		return "", false
+	}
+}
+
+// documentIDPathsFor Paths of a document type's document number in a
+// serialized request: the configured DocumentIDPaths, or DefaultDocumentIDPaths
+// below the configured invoice data path
+func (s *SDKConfig) documentIDPathsFor(documentType DocumentType) []string {
+	if paths := s.DocumentIDPaths[documentType]; len(paths) > 0 {
+		return paths
+	}
+	invoiceDataPath := strings.Trim(s.GetInvoiceDataPath(), ".")
+	if invoiceDataPath == DefaultInvoiceDataPath {
+		return DefaultDocumentIDPaths
+	}
+	defaultPrefix := "payload." + DefaultInvoiceDataPath + "."
+	paths := make([]string, 0, len(DefaultDocumentIDPaths))
+	for _, path := range DefaultDocumentIDPaths {
+		paths = append(paths, "payload."+invoiceDataPath+"."+strings.TrimPrefix(path, defaultPrefix))
+	}
+	return paths
+}
+
+// copyDocumentIDPaths Copy of the paths so later changes by the caller have no effect
+func copyDocumentIDPaths(paths map[DocumentType][]string) map[DocumentType][]string {
+	if paths == nil {
+		return nil
+	}
+	copied := make(map[DocumentType][]string, len(paths))
+	for documentType, candidates := range paths {
+		copied[documentType] = append([]string(nil), candidates...)
+	}
+	return copied
+}
@@ -0,0 +1,118 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func mustRequestJSON(t *testing.T, payload map[string]interface{}) string {
+	t.Helper()
+	raw, err := json.Marshal(map[string]interface{}{"payload": payload})
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	return string(raw)
+}
+
+func TestExtractDocumentIDUsesPathsForCreditNotes(t *testing.T) {
+	manager := newTestQueueManager(t, NewMemoryQueueStore())
+	manager.SetDocumentIDPaths(map[DocumentType][]string{
+		DocumentTypeCreditNote: {"$.payload.credit_note.number", "payload.invoice_data.credit_note_number"},
+	})
+
+	creditNote := mustRequestJSON(t, map[string]interface{}{
+		"invoice_data": map[string]interface{}{"credit_note_number": "CN/2024/17"},
+	})
+	if got := manager.extractDocumentID(creditNote, DocumentTypeCreditNote); got != "CN_2024_17" {
+		t.Fatalf("expected credit note number from the second path, got %q", got)
+	}
+	nested := mustRequestJSON(t, map[string]interface{}{
+		"credit_note": map[string]interface{}{"number": 9001},
+	})
+	if got := manager.extractDocumentID(nested, DocumentTypeCreditNote); got != "9001" {
+		t.Fatalf("expected numeric credit note number, got %q", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		raw, _ := json.Marshal(map[string]interface{}{
+			"requestId": fmt.Sprintf("req-%d", i),
+			"payload": map[string]interface{}{
+				"invoice_data": map[string]interface{}{"credit_note_number": "CN/2024/17"},
+			},
+		})
+		submission := NewPayloadSubmission(string(raw), NewSource("erp", "1", nil), CountrySA, DocumentTypeCreditNote)
+		if err := manager.Enqueue(submission); err != nil {
+			t.Fatalf("enqueue %d failed: %v", i, err)
+		}
+	}
+	pending, _ := manager.GetStore().List(QueueStatePending)
+	if len(pending) != 1 || !strings.Contains(pending[0], "CN_2024_17") {
+		t.Fatalf("expected one pending item keyed by the credit note number, got %v", pending)
+	}
+}
+
+func TestExtractDocumentIDDefaultsAndFallback(t *testing.T) {
+	manager := newTestQueueManager(t, NewMemoryQueueStore())
+
+	invoice := mustRequestJSON(t, testInvoicePayload("INV-42"))
+	if got := manager.extractDocumentID(invoice, DocumentTypeTaxInvoice); got != "INV-42" {
+		t.Fatalf("expected invoice number, got %q", got)
+	}
+	lines := mustRequestJSON(t, map[string]interface{}{
+		"lines": []interface{}{map[string]interface{}{"ref": "LINE-1"}},
+	})
+	manager.SetDocumentIDPaths(map[DocumentType][]string{DocumentTypeDebitNote: {"payload.lines.0.ref"}})
+	if got := manager.extractDocumentID(lines, DocumentTypeDebitNote); got != "LINE-1" {
+		t.Fatalf("expected array index path to resolve, got %q", got)
+	}
+	if got := manager.extractDocumentID(lines, DocumentTypeTaxInvoice); !strings.HasPrefix(got, "doc_") {
+		t.Fatalf("expected timestamp fallback, got %q", got)
+	}
+}
+
+func TestFinishedDocumentIsQueuedAgainWhenCorrected(t *testing.T) {
+	fileStore, err := NewFileQueueStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("file store: %v", err)
+	}
+	for name, store := range map[string]QueueStore{"file": fileStore, "memory": NewMemoryQueueStore()} {
+		manager := newTestQueueManager(t, store)
+		enqueue := func(total float64) string {
+			payload := testInvoicePayload("INV-1")
+			payload["invoice_data"].(map[string]interface{})["total"] = total
+			submission := NewPayloadSubmission(mustRequestJSON(t, payload), NewSource("erp", "1", nil), CountrySA, DocumentTypeTaxInvoice)
+			if err := manager.Enqueue(submission); err != nil {
+				t.Fatalf("%s: enqueue failed: %v", name, err)
+			}
+			pending, _ := store.List(QueueStatePending)
+			if len(pending) != 1 {
+				t.Fatalf("%s: expected one pending item, got %v", name, pending)
+			}
+			return pending[0]
+		}
+
+		id := enqueue(100)
+		enqueue(100)
+		if _, err := store.Claim(id); err != nil {
+			t.Fatalf("%s: claim failed: %v", name, err)
+		}
+		if err := store.MarkSuccess(id); err != nil {
+			t.Fatalf("%s: mark success failed: %v", name, err)
+		}
+
+		id = enqueue(110)
+		if _, err := store.Claim(id); err != nil {
+			t.Fatalf("%s: claim failed: %v", name, err)
+		}
+		if err := store.MarkFailed(id, []byte(`{}`)); err != nil {
+			t.Fatalf("%s: mark failed failed: %v", name, err)
+		}
+		if err := store.DeadLetter(id); err != nil {
+			t.Fatalf("%s: dead-letter failed: %v", name, err)
+		}
+
+		enqueue(120)
+	}
+}
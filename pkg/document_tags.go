@@ -0,0 +1,165 @@
+/*
+Submission of typed document structs that declare their logical type and country in a struct tag.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DocumentTagName Name of the struct tag read by SubmitDocument
+const DocumentTagName = "complyance"
+
+// documentTagExample Example tag shown in error suggestions
+const documentTagExample = "_ struct{} `complyance:\"doc_type=TAX_INVOICE,country=SA\"`"
+
+// DocumentTag Submission settings declared by a document struct
+type DocumentTag struct {
+	LogicalType LogicalDocType
+	Country     Country
+	Operation   Operation
+	Mode        Mode
+	Purpose     Purpose
+}
+
+// ParseDocumentTag Read the complyance tag of the struct type of doc, which may
+// be a struct or a pointer to one. The tag is a comma separated list of
+// key=value pairs: doc_type and country are required, operation, mode and
+// purpose default to single, documents and invoicing. It is usually put on a
+// blank field:
+//
+//	type SalesInvoice struct {
+//		_             struct{} `complyance:"doc_type=TAX_INVOICE,country=SA"`
+//		InvoiceNumber string   `json:"invoice_number"`
+//	}
+func ParseDocumentTag(doc interface{}) (*DocumentTag, error) {
+	docType := reflect.TypeOf(doc)
+	for docType != nil && docType.Kind() == reflect.Ptr {
+		docType = docType.Elem()
+	}
+	if docType == nil || docType.Kind() != reflect.Struct {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			fmt.Sprintf("Document must be a struct or a pointer to a struct, got %v", reflect.TypeOf(doc)),
+		).WithSuggestion("Declare a struct type for the document with a complyance tag: " + documentTagExample))
+	}
+
+	tagValue, found := "", false
+	for i := 0; i < docType.NumField(); i++ {
+		if tagValue, found = docType.Field(i).Tag.Lookup(DocumentTagName); found {
+			break
+		}
+	}
+	if !found {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			fmt.Sprintf("Document type %s has no %s tag", docType, DocumentTagName),
+		).WithSuggestion("Add a field with the tag to the struct: " + documentTagExample)
+		detail.AddContextValue("type", docType.String())
+		return nil, NewSDKError(detail)
+	}
+
+	tag := &DocumentTag{
+		Operation: OperationSingle,
+		Mode:      ModeDocuments,
+		Purpose:   PurposeInvoicing,
+	}
+	for _, pair := range strings.Split(tagValue, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, err := splitDocumentTagPair(docType, pair)
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "doc_type":
+			tag.LogicalType, err = ParseLogicalDocType(value)
+		case "country":
+			tag.Country = Country(strings.ToUpper(value))
+		case "operation":
+			tag.Operation, err = ParseOperation(value)
+		case "mode":
+			tag.Mode, err = ParseMode(value)
+		case "purpose":
+			tag.Purpose, err = ParsePurpose(value)
+		default:
+			return nil, invalidDocumentTagError(docType, tagValue, fmt.Sprintf("unknown key %q", key))
+		}
+		if err != nil {
+			if sdkErr, ok := err.(*SDKError); ok {
+				sdkErr.ErrorDetail.AddContextValue("type", docType.String())
+			}
+			return nil, err
+		}
+	}
+
+	if tag.LogicalType == "" {
+		return nil, invalidDocumentTagError(docType, tagValue, "doc_type is required")
+	}
+	if tag.Country == "" {
+		return nil, invalidDocumentTagError(docType, tagValue, "country is required")
+	}
+	return tag, nil
+}
+
+// splitDocumentTagPair Key and value of one key=value pair of a tag
+func splitDocumentTagPair(docType reflect.Type, pair string) (string, string, error) {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+		return "", "", invalidDocumentTagError(docType, pair, "expected key=value")
+	}
+	return strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), nil
+}
+
+// invalidDocumentTagError INVALID_ARGUMENT error for a malformed complyance tag
+func invalidDocumentTagError(docType reflect.Type, tagValue, reason string) error {
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		fmt.Sprintf("Invalid %s tag on %s: %s", DocumentTagName, docType, reason),
+	).WithSuggestion("Use a tag such as " + documentTagExample)
+	detail.AddContextValue("type", docType.String())
+	detail.AddContextValue("tag", tagValue)
+	return NewSDKError(detail)
+}
+
+// SubmitDocument Submit doc with the logical type, country, operation, mode
+// and purpose declared by its complyance tag (see ParseDocumentTag). The
+// document is serialized as for PushToUnifyFromStruct. Uses the SDK set up by
+// Configure.
+func SubmitDocument[T any](ctx context.Context, source *Source, doc T) (*UnifyResponse, error) {
+	sdk, release := acquireSDK()
+	defer release()
+	return SubmitDocumentWith(ctx, sdk, source, doc)
+}
+
+// SubmitDocumentWith SubmitDocument using sdk. A nil source uses the
+// configured SDKConfig.DefaultSource.
+func SubmitDocumentWith[T any](ctx context.Context, sdk *GETSUnifySDK, source *Source, doc T) (*UnifyResponse, error) {
+	if source == nil && (sdk == nil || sdk.config == nil || sdk.config.DefaultSource == nil) {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Source is required",
+		).WithSuggestion("Pass the source the document comes from, e.g. NewSource(name, version, nil), or set SDKConfig.DefaultSource."))
+	}
+	if source == nil {
+		source = &Source{}
+	}
+	tag, err := ParseDocumentTag(doc)
+	if err != nil {
+		return nil, err
+	}
+	if sdk == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		))
+	}
+	return sdk.pushToUnifyFromStructContext(
+		ctx, source.Name, source.Version, tag.LogicalType, tag.Country,
+		tag.Operation, tag.Mode, tag.Purpose, doc, nil,
+	)
+}
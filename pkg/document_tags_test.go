@@ -0,0 +1,135 @@
+package complyancesdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type taggedInvoiceData struct {
+	InvoiceNumber         string `json:"invoice_number"`
+	Currency              string `json:"currency"`
+	OriginalInvoiceNumber string `json:"original_invoice_number,omitempty"`
+}
+
+type saudiTaxInvoice struct {
+	_           struct{}          `complyance:"doc_type=TAX_INVOICE,country=SA"`
+	InvoiceData taggedInvoiceData `json:"invoice_data"`
+}
+
+type malaysianCreditNote struct {
+	_           struct{}          `complyance:"doc_type=credit-note, country=my, purpose=validation"`
+	InvoiceData taggedInvoiceData `json:"invoice_data"`
+}
+
+// submitTaggedDocument Submit doc and return the request body the server saw
+func submitTaggedDocument[T any](t *testing.T, doc T) map[string]interface{} {
+	t.Helper()
+	var seen map[string]interface{}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &seen); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"SUB-1","status":"ACCEPTED"}}}`))
+	})
+
+	if _, err := SubmitDocument(context.Background(), NewSource("erp", "1", nil), doc); err != nil {
+		t.Fatalf("SubmitDocument failed: %v", err)
+	}
+	if seen == nil {
+		t.Fatalf("expected the request to reach the server")
+	}
+	return seen
+}
+
+func TestSubmitDocumentUsesTypeAndCountryFromTag(t *testing.T) {
+	cases := []struct {
+		name     string
+		submit   func(t *testing.T) map[string]interface{}
+		country  string
+		base     string
+		purpose  string
+		document string
+	}{
+		{
+			name: "saudi tax invoice",
+			submit: func(t *testing.T) map[string]interface{} {
+				return submitTaggedDocument(t, saudiTaxInvoice{InvoiceData: taggedInvoiceData{InvoiceNumber: "INV-1", Currency: "SAR"}})
+			},
+			country:  "SA",
+			base:     string(GetsDocumentBaseTaxInvoice),
+			purpose:  string(PurposeInvoicing),
+			document: "INV-1",
+		},
+		{
+			name: "malaysian credit note by pointer",
+			submit: func(t *testing.T) map[string]interface{} {
+				return submitTaggedDocument(t, &malaysianCreditNote{InvoiceData: taggedInvoiceData{InvoiceNumber: "CN-1", Currency: "MYR", OriginalInvoiceNumber: "INV-1"}})
+			},
+			country:  "MY",
+			base:     string(GetsDocumentBaseCreditNote),
+			purpose:  string(PurposeValidation),
+			document: "CN-1",
+		},
+	}
+
+	for _, tc := range cases {
+		body := tc.submit(t)
+		if body["country"] != tc.country {
+			t.Fatalf("%s: expected country %s, got %v", tc.name, tc.country, body["country"])
+		}
+		documentType, _ := body["documentType"].(map[string]interface{})
+		if documentType["base"] != tc.base {
+			t.Fatalf("%s: expected document type base %s, got %v", tc.name, tc.base, body["documentType"])
+		}
+		if body["purpose"] != tc.purpose {
+			t.Fatalf("%s: expected purpose %s, got %v", tc.name, tc.purpose, body["purpose"])
+		}
+		payload, _ := body["payload"].(map[string]interface{})
+		invoiceData, _ := payload["invoice_data"].(map[string]interface{})
+		if invoiceData["invoice_number"] != tc.document {
+			t.Fatalf("%s: expected the struct to be serialized, got payload %v", tc.name, body["payload"])
+		}
+	}
+}
+
+func TestParseDocumentTagRejectsMissingOrInvalidTags(t *testing.T) {
+	type untagged struct {
+		InvoiceNumber string `json:"invoice_number"`
+	}
+	type unknownType struct {
+		_ struct{} `complyance:"doc_type=PURCHASE_ORDER,country=SA"`
+	}
+	type noCountry struct {
+		_ struct{} `complyance:"doc_type=TAX_INVOICE"`
+	}
+	type unknownKey struct {
+		_ struct{} `complyance:"doc_type=TAX_INVOICE,country=SA,currency=SAR"`
+	}
+
+	cases := []struct {
+		name string
+		doc  interface{}
+		code ErrorCode
+	}{
+		{"untagged", untagged{}, ErrorCodeMissingField},
+		{"not a struct", map[string]interface{}{}, ErrorCodeInvalidArgument},
+		{"unknown doc_type", unknownType{}, ErrorCodeInvalidArgument},
+		{"missing country", noCountry{}, ErrorCodeInvalidArgument},
+		{"unknown key", unknownKey{}, ErrorCodeInvalidArgument},
+	}
+	for _, tc := range cases {
+		_, err := ParseDocumentTag(tc.doc)
+		sdkErr, ok := err.(*SDKError)
+		if !ok || sdkErr.ErrorDetail.Code == nil || *sdkErr.ErrorDetail.Code != tc.code {
+			t.Fatalf("%s: expected %s, got %v", tc.name, tc.code, err)
+		}
+	}
+
+	if _, err := SubmitDocument(context.Background(), nil, saudiTaxInvoice{}); err == nil {
+		t.Fatalf("expected a nil source to be rejected")
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Parsers for the request enums that report unknown values as errors.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// documentTypes lists every DocumentType in declaration order
+var documentTypes = []DocumentType{
+	DocumentTypeTaxInvoice,
+	DocumentTypeSimplifiedInvoice,
+	DocumentTypeCreditNote,
+	DocumentTypeSimplifiedCreditNote,
+	DocumentTypeDebitNote,
+	DocumentTypeSimplifiedDebitNote,
+	DocumentTypePrepaymentInvoice,
+	DocumentTypeSimplifiedPrepaymentInvoice,
+	DocumentTypePrepaymentAdjustedInvoice,
+	DocumentTypeSimplifiedPrepaymentAdjustedInvoice,
+}
+
+// operations lists every Operation
+var operations = []Operation{OperationSingle, OperationBulk}
+
+// modes lists every Mode
+var modes = []Mode{ModeDocuments, ModeOnboarding}
+
+// purposes lists every Purpose
+var purposes = []Purpose{PurposeMapping, PurposeInvoicing, PurposeValidation, PurposeConversion}
+
+// logicalDocTypes lists every LogicalDocType in declaration order
+var logicalDocTypes = []LogicalDocType{
+	LogicalDocTypeInvoice,
+	LogicalDocTypeCreditNote,
+	LogicalDocTypeDebitNote,
+	LogicalDocTypeReceipt,
+	LogicalDocTypeTaxInvoice,
+	LogicalDocTypeTaxInvoiceCreditNote,
+	LogicalDocTypeTaxInvoiceDebitNote,
+	LogicalDocTypeTaxInvoicePrepayment,
+	LogicalDocTypeTaxInvoicePrepaymentAdjusted,
+	LogicalDocTypeTaxInvoiceExportInvoice,
+	LogicalDocTypeTaxInvoiceExportCreditNote,
+	LogicalDocTypeTaxInvoiceExportDebitNote,
+	LogicalDocTypeTaxInvoiceThirdPartyInvoice,
+	LogicalDocTypeTaxInvoiceSelfBilledInvoice,
+	LogicalDocTypeTaxInvoiceNominalSupplyInvoice,
+	LogicalDocTypeTaxInvoiceSummaryInvoice,
+	LogicalDocTypeSimplifiedTaxInvoice,
+	LogicalDocTypeSimplifiedTaxInvoiceCreditNote,
+	LogicalDocTypeSimplifiedTaxInvoiceDebitNote,
+	LogicalDocTypeSimplifiedTaxInvoicePrepayment,
+	LogicalDocTypeSimplifiedTaxInvoicePrepaymentAdjusted,
+	LogicalDocTypeSimplifiedTaxInvoiceExportInvoice,
+	LogicalDocTypeSimplifiedTaxInvoiceExportCreditNote,
+	LogicalDocTypeSimplifiedTaxInvoiceExportDebitNote,
+	LogicalDocTypeSimplifiedTaxInvoiceThirdPartyInvoice,
+	LogicalDocTypeSimplifiedTaxInvoiceSelfBilledInvoice,
+	LogicalDocTypeSimplifiedTaxInvoiceNominalSupplyInvoice,
+	LogicalDocTypeSimplifiedTaxInvoiceSummaryInvoice,
+	LogicalDocTypeExportInvoice,
+	LogicalDocTypeExportCreditNote,
+	LogicalDocTypeExportThirdPartyInvoice,
+	LogicalDocTypeThirdPartyInvoice,
+	LogicalDocTypeSelfBilledInvoice,
+	LogicalDocTypeNominalSupplyInvoice,
+	LogicalDocTypeSummaryInvoice,
+}
+
+// ParseDocumentType Parse value as a DocumentType, ignoring case and surrounding
+// spaces. Unlike DocumentType.FromString an unknown value is an error.
+func ParseDocumentType(value string) (DocumentType, error) {
+	if documentType := DocumentType("").FromString(normalizeEnumValue(value)); documentType != "" {
+		return documentType, nil
+	}
+	allowed := make([]string, len(documentTypes))
+	for i, documentType := range documentTypes {
+		allowed[i] = string(documentType)
+	}
+	return "", invalidEnumValueError("document type", value, allowed)
+}
+
+// ParseOperation Parse value as an Operation, ignoring case and surrounding
+// spaces. Unlike Operation.FromString an unknown value is an error.
+func ParseOperation(value string) (Operation, error) {
+	if operation := Operation("").FromString(normalizeEnumValue(value)); operation != "" {
+		return operation, nil
+	}
+	allowed := make([]string, len(operations))
+	for i, operation := range operations {
+		allowed[i] = string(operation)
+	}
+	return "", invalidEnumValueError("operation", value, allowed)
+}
+
+// ParseMode Parse value as a Mode, ignoring case and surrounding spaces. Unlike
+// Mode.FromString an unknown value is an error.
+func ParseMode(value string) (Mode, error) {
+	if mode := Mode("").FromString(normalizeEnumValue(value)); mode != "" {
+		return mode, nil
+	}
+	allowed := make([]string, len(modes))
+	for i, mode := range modes {
+		allowed[i] = string(mode)
+	}
+	return "", invalidEnumValueError("mode", value, allowed)
+}
+
+// ParsePurpose Parse value as a Purpose, ignoring case and surrounding spaces.
+// Unlike Purpose.FromString an unknown value is an error.
+func ParsePurpose(value string) (Purpose, error) {
+	if purpose := Purpose("").FromString(normalizeEnumValue(value)); purpose != "" {
+		return purpose, nil
+	}
+	allowed := make([]string, len(purposes))
+	for i, purpose := range purposes {
+		allowed[i] = string(purpose)
+	}
+	return "", invalidEnumValueError("purpose", value, allowed)
+}
+
+// normalizeEnumValue Lower-cased value without surrounding spaces
+func normalizeEnumValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// invalidEnumValueError INVALID_ARGUMENT error naming the rejected value and the allowed ones
+func invalidEnumValueError(kind, value string, allowed []string) error {
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		fmt.Sprintf("Invalid %s %q", kind, value),
+	).WithSuggestion(fmt.Sprintf("Use one of: %s.", strings.Join(allowed, ", ")))
+	detail.AddContextValue("value", value)
+	detail.AddContextValue("allowed", allowed)
+	return NewSDKError(detail)
+}
+
+// IsValid Whether the logical type is one of the defined LogicalDocType constants
+func (l LogicalDocType) IsValid() bool {
+	for _, logicalType := range logicalDocTypes {
+		if l == logicalType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLogicalDocType Parse value as a LogicalDocType, ignoring case and
+// surrounding spaces and treating "-" and " " as "_". An unknown value is an
+// error that suggests the closest defined types.
+func ParseLogicalDocType(value string) (LogicalDocType, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+	if logicalType := LogicalDocType(normalized); logicalType.IsValid() {
+		return logicalType, nil
+	}
+	return "", invalidLogicalDocTypeError(value)
+}
+
+// invalidLogicalDocTypeError INVALID_ARGUMENT error for an unknown logical type,
+// suggesting the defined types closest to it
+func invalidLogicalDocTypeError(value string) error {
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		fmt.Sprintf("Invalid logical document type %q", value),
+	)
+	matches := closeLogicalDocTypes(value)
+	if len(matches) > 0 {
+		detail = detail.WithSuggestion(fmt.Sprintf("Did you mean %s?", strings.Join(matches, " or ")))
+		detail.AddContextValue("closeMatches", matches)
+	} else {
+		detail = detail.WithSuggestion("Use one of the LogicalDocType constants, e.g. LogicalDocTypeTaxInvoice.")
+	}
+	detail.AddContextValue("value", value)
+	return NewSDKError(detail)
+}
+
+// maxCloseLogicalDocTypes caps how many suggestions an invalid logical type lists
+const maxCloseLogicalDocTypes = 3
+
+// closeLogicalDocTypes Defined logical types within a few edits of value,
+// closest first
+func closeLogicalDocTypes(value string) []string {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	if normalized == "" {
+		return nil
+	}
+	maxDistance := len(normalized) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for _, logicalType := range logicalDocTypes {
+		if distance := editDistance(normalized, string(logicalType)); distance <= maxDistance {
+			candidates = append(candidates, candidate{string(logicalType), distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var matches []string
+	for _, match := range candidates {
+		if len(matches) == maxCloseLogicalDocTypes {
+			break
+		}
+		matches = append(matches, match.name)
+	}
+	return matches
+}
+
+// editDistance Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
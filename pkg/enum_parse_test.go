@@ -0,0 +1,106 @@
+package complyancesdk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseEnumsAcceptKnownValues(t *testing.T) {
+	for _, documentType := range documentTypes {
+		parsed, err := ParseDocumentType(" " + strings.ToUpper(string(documentType)) + " ")
+		if err != nil || parsed != documentType {
+			t.Fatalf("ParseDocumentType(%q) = %q, %v", documentType, parsed, err)
+		}
+	}
+	for _, operation := range operations {
+		if parsed, err := ParseOperation(string(operation)); err != nil || parsed != operation {
+			t.Fatalf("ParseOperation(%q) = %q, %v", operation, parsed, err)
+		}
+	}
+	for _, mode := range modes {
+		if parsed, err := ParseMode(strings.ToUpper(string(mode))); err != nil || parsed != mode {
+			t.Fatalf("ParseMode(%q) = %q, %v", mode, parsed, err)
+		}
+	}
+	for _, purpose := range purposes {
+		if parsed, err := ParsePurpose(string(purpose)); err != nil || parsed != purpose {
+			t.Fatalf("ParsePurpose(%q) = %q, %v", purpose, parsed, err)
+		}
+	}
+}
+
+func TestParseEnumsRejectUnknownValues(t *testing.T) {
+	parsers := map[string]func(string) error{
+		"document type": func(value string) error { _, err := ParseDocumentType(value); return err },
+		"operation":     func(value string) error { _, err := ParseOperation(value); return err },
+		"mode":          func(value string) error { _, err := ParseMode(value); return err },
+		"purpose":       func(value string) error { _, err := ParsePurpose(value); return err },
+	}
+	for kind, parse := range parsers {
+		for _, value := range []string{"", "tax-invoice", "nonsense"} {
+			err := parse(value)
+			if code, ok := GetErrorCode(err); !ok || code != ErrorCodeInvalidArgument {
+				t.Fatalf("%s %q: expected INVALID_ARGUMENT, got %v", kind, value, err)
+			}
+			if !strings.Contains(err.Error(), kind) || !strings.Contains(err.Error(), `"`+value+`"`) {
+				t.Fatalf("%s %q: expected the error to name the input, got %v", kind, value, err)
+			}
+		}
+	}
+
+	// The lenient methods keep returning an empty value
+	if DocumentType("").FromString("nonsense") != "" || Purpose("").FromString("Invoicing") != "" {
+		t.Fatalf("expected FromString to stay unchanged")
+	}
+}
+
+func TestParseLogicalDocTypeAcceptsEveryDefinedType(t *testing.T) {
+	for _, logicalType := range logicalDocTypes {
+		if !logicalType.IsValid() {
+			t.Fatalf("expected %s to be valid", logicalType)
+		}
+		input := strings.ToLower(strings.Replace(string(logicalType), "_", "-", 1))
+		if parsed, err := ParseLogicalDocType(input); err != nil || parsed != logicalType {
+			t.Fatalf("ParseLogicalDocType(%q) = %q, %v", input, parsed, err)
+		}
+	}
+	if LogicalDocType("tax_invoice").IsValid() {
+		t.Fatalf("expected IsValid to require the exact constant")
+	}
+}
+
+func TestParseLogicalDocTypeSuggestsCloseMatches(t *testing.T) {
+	_, err := ParseLogicalDocType("TAX_INVIOCE")
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeInvalidArgument {
+		t.Fatalf("expected INVALID_ARGUMENT, got %v", err)
+	}
+	matches := closeLogicalDocTypes("TAX_INVIOCE")
+	if len(matches) == 0 || matches[0] != string(LogicalDocTypeTaxInvoice) {
+		t.Fatalf("expected TAX_INVOICE as the closest match, got %v", matches)
+	}
+	if !strings.Contains(err.Error(), "TAX_INVIOCE") {
+		t.Fatalf("expected the error to name the input, got %v", err)
+	}
+	if matches := closeLogicalDocTypes("PURCHASE_ORDER"); len(matches) != 0 {
+		t.Fatalf("expected no suggestions for an unrelated value, got %v", matches)
+	}
+}
+
+func TestPushToUnifyRejectsUnknownLogicalType(t *testing.T) {
+	calls := 0
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	_, err := PushToUnify("src", "1", LogicalDocType("TAX_INVIOCE"), CountrySA, OperationSingle, ModeDocuments,
+		PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{})
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.ErrorDetail.Suggestion == nil || !strings.Contains(*sdkErr.ErrorDetail.Suggestion, "TAX_INVOICE") {
+		t.Fatalf("expected an error suggesting TAX_INVOICE, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected the request to be rejected before sending, got %d calls", calls)
+	}
+}
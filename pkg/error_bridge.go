@@ -0,0 +1,159 @@
+/*
+Conversion between complyancesdk.SDKError and the pkg/errors SDKError.
+*/
+package complyancesdk
+
+import (
+	sdkerrors "github.com/complyance-io/complyance-go-sdk/v3/pkg/errors"
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/models"
+)
+
+// pkgErrorCodeContextKey carries the detailed ErrorCode through a pkg/errors
+// SDKError so that converting back restores the original code.
+const pkgErrorCodeContextKey = "errorCode"
+
+// errorCodeCategories maps every detailed ErrorCode onto the coarse
+// classification used by pkg/errors and pkg/models.
+var errorCodeCategories = map[ErrorCode]models.ErrorCode{
+	ErrorCodeMissingField:                models.ErrorCodeValidationError,
+	ErrorCodeInvalidSource:               models.ErrorCodeValidationError,
+	ErrorCodeInvalidArgument:             models.ErrorCodeValidationError,
+	ErrorCodeValidationFailed:            models.ErrorCodeValidationError,
+	ErrorCodeEmptyPayload:                models.ErrorCodeValidationError,
+	ErrorCodeMalformedJSON:               models.ErrorCodeValidationError,
+	ErrorCodeInvalidPayloadFormat:        models.ErrorCodeValidationError,
+	ErrorCodeSubmissionAborted:           models.ErrorCodeValidationError,
+	ErrorCodeAuthenticationFailed:        models.ErrorCodeAuthenticationError,
+	ErrorCodeAuthorizationDenied:         models.ErrorCodeAuthenticationError,
+	ErrorCodeTemplateNotFound:            models.ErrorCodeAPIError,
+	ErrorCodeConversionError:             models.ErrorCodeAPIError,
+	ErrorCodeDocumentError:               models.ErrorCodeAPIError,
+	ErrorCodeSubmissionError:             models.ErrorCodeAPIError,
+	ErrorCodeProcessingError:             models.ErrorCodeAPIError,
+	ErrorCodeAPIError:                    models.ErrorCodeAPIError,
+	ErrorCodeNetworkError:                models.ErrorCodeNetworkError,
+	ErrorCodeTimeoutError:                models.ErrorCodeNetworkError,
+	ErrorCodeSubmissionTimeout:           models.ErrorCodeNetworkError,
+	ErrorCodeRateLimitExceeded:           models.ErrorCodeRateLimitError,
+	ErrorCodeInternalServerError:         models.ErrorCodeServerError,
+	ErrorCodeServiceUnavailable:          models.ErrorCodeServerError,
+	ErrorCodeDatabaseError:               models.ErrorCodeServerError,
+	ErrorCodeQueueError:                  models.ErrorCodeServerError,
+	ErrorCodeGovernmentSystemUnavailable: models.ErrorCodeServerError,
+	ErrorCodeCircuitBreakerOpen:          models.ErrorCodeServerError,
+	ErrorCodeMaxRetriesExceeded:          models.ErrorCodeServerError,
+	ErrorCodeConfigurationError:          models.ErrorCodeConfigurationError,
+	ErrorCodeUnknownError:                models.ErrorCodeUnknownError,
+}
+
+// categoryErrorCodes maps each pkg/models classification onto the detailed
+// ErrorCode used when no more specific code is known.
+var categoryErrorCodes = map[models.ErrorCode]ErrorCode{
+	models.ErrorCodeConfigurationError:  ErrorCodeConfigurationError,
+	models.ErrorCodeValidationError:     ErrorCodeValidationFailed,
+	models.ErrorCodeNetworkError:        ErrorCodeNetworkError,
+	models.ErrorCodeAPIError:            ErrorCodeAPIError,
+	models.ErrorCodeAuthenticationError: ErrorCodeAuthenticationFailed,
+	models.ErrorCodeRateLimitError:      ErrorCodeRateLimitExceeded,
+	models.ErrorCodeServerError:         ErrorCodeInternalServerError,
+	models.ErrorCodeUnknownError:        ErrorCodeUnknownError,
+}
+
+// Category Get the pkg/models classification for this error code
+func (e ErrorCode) Category() models.ErrorCode {
+	if category, ok := errorCodeCategories[e]; ok {
+		return category
+	}
+	return models.ErrorCodeUnknownError
+}
+
+// ErrorCodeFromCategory Get the detailed error code used for a pkg/models classification
+func ErrorCodeFromCategory(category models.ErrorCode) ErrorCode {
+	if code, ok := categoryErrorCodes[category]; ok {
+		return code
+	}
+	return ErrorCodeUnknownError
+}
+
+// ToPkgError Convert an SDKError into the pkg/errors representation. The
+// detailed error code is kept in the context so FromPkgError can restore it.
+func ToPkgError(err *SDKError) *sdkerrors.SDKError {
+	if err == nil {
+		return nil
+	}
+
+	pkgErr := &sdkerrors.SDKError{
+		Code:    models.ErrorCodeUnknownError,
+		Message: err.Error(),
+		Context: make(map[string]interface{}),
+	}
+
+	detail := err.GetErrorDetail()
+	if detail == nil {
+		return pkgErr
+	}
+
+	for key, value := range detail.Context {
+		pkgErr.Context[key] = value
+	}
+	if detail.Code != nil {
+		pkgErr.Code = detail.Code.Category()
+		pkgErr.Context[pkgErrorCodeContextKey] = string(*detail.Code)
+	}
+	if detail.Message != nil {
+		pkgErr.Message = *detail.Message
+	}
+	if detail.Suggestion != nil {
+		pkgErr.Suggestion = *detail.Suggestion
+	}
+	pkgErr.Retryable = detail.Retryable
+	return pkgErr
+}
+
+// FromPkgError Convert a pkg/errors SDKError into an SDKError
+func FromPkgError(err *sdkerrors.SDKError) *SDKError {
+	if err == nil {
+		return nil
+	}
+
+	// Errors that originated here carry their exact code and retryability;
+	// native pkg/errors values are classified from their category.
+	code := ErrorCodeFromCategory(err.Code)
+	retryable := sdkerrors.IsRetryableError(err)
+	if raw, ok := err.Context[pkgErrorCodeContextKey].(string); ok && raw != "" {
+		code = ErrorCode(raw)
+		retryable = err.Retryable
+	}
+
+	detail := NewErrorDetailWithCode(code, err.Message)
+	if err.Suggestion != "" {
+		detail.WithSuggestion(err.Suggestion)
+	}
+	for key, value := range err.Context {
+		if key == pkgErrorCodeContextKey {
+			continue
+		}
+		detail.AddContextValue(key, value)
+	}
+	if err.Err != nil {
+		detail.AddContextValue("cause", err.Err.Error())
+	}
+	detail.Retryable = retryable
+	return NewSDKError(detail)
+}
+
+// As lets errors.As match an SDKError against *pkg/errors.SDKError, so the
+// pkg/errors predicates (IsValidationError, IsRetryableError, ...) work on it.
+// It also lets the typed wrappers (ValidationError, NetworkError, ...) match
+// *SDKError, since they promote this method from the embedded SDKError.
+func (s *SDKError) As(target interface{}) bool {
+	switch t := target.(type) {
+	case **sdkerrors.SDKError:
+		*t = ToPkgError(s)
+		return true
+	case **SDKError:
+		*t = s
+		return true
+	}
+	return false
+}
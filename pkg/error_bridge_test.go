@@ -0,0 +1,88 @@
+package complyancesdk
+
+import (
+	"errors"
+	"testing"
+
+	sdkerrors "github.com/complyance-io/complyance-go-sdk/v3/pkg/errors"
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/models"
+)
+
+func TestPkgErrorRoundTripPreservesCodeMessageAndRetryability(t *testing.T) {
+	cases := []struct {
+		code      ErrorCode
+		retryable bool
+	}{
+		{ErrorCodeValidationFailed, false},
+		{ErrorCodeServiceUnavailable, true},
+		{ErrorCodeRateLimitExceeded, true},
+		{ErrorCodeAuthenticationFailed, false},
+		{ErrorCodeNetworkError, false},
+	}
+
+	for _, tc := range cases {
+		detail := NewErrorDetailWithCode(tc.code, "something went wrong").WithSuggestion("try again")
+		detail.Retryable = tc.retryable
+		detail.AddContextValue("httpStatus", 503)
+		original := NewSDKError(detail)
+
+		pkgErr := ToPkgError(original)
+		if pkgErr.Code != tc.code.Category() {
+			t.Fatalf("%s: expected category %s, got %s", tc.code, tc.code.Category(), pkgErr.Code)
+		}
+		if pkgErr.Message != "something went wrong" || pkgErr.Suggestion != "try again" {
+			t.Fatalf("%s: message or suggestion lost: %+v", tc.code, pkgErr)
+		}
+
+		restored := FromPkgError(pkgErr)
+		restoredDetail := restored.GetErrorDetail()
+		if restoredDetail.Code == nil || *restoredDetail.Code != tc.code {
+			t.Fatalf("%s: code not preserved, got %v", tc.code, restoredDetail.Code)
+		}
+		if *restoredDetail.Message != "something went wrong" {
+			t.Fatalf("%s: message not preserved, got %q", tc.code, *restoredDetail.Message)
+		}
+		if restoredDetail.Retryable != tc.retryable {
+			t.Fatalf("%s: retryable not preserved, got %t", tc.code, restoredDetail.Retryable)
+		}
+		if restoredDetail.GetContextValue("httpStatus") != 503 {
+			t.Fatalf("%s: context not preserved", tc.code)
+		}
+		if _, leaked := restoredDetail.Context[pkgErrorCodeContextKey]; leaked {
+			t.Fatalf("%s: internal code key leaked into context", tc.code)
+		}
+	}
+}
+
+func TestFromPkgErrorMapsNativeCategories(t *testing.T) {
+	restored := FromPkgError(sdkerrors.NewRateLimitError("slow down", nil))
+	if *restored.GetErrorDetail().Code != ErrorCodeRateLimitExceeded {
+		t.Fatalf("expected RATE_LIMIT_EXCEEDED, got %s", *restored.GetErrorDetail().Code)
+	}
+	if !restored.GetErrorDetail().Retryable {
+		t.Fatalf("expected rate limit errors to be retryable")
+	}
+
+	for category := range categoryErrorCodes {
+		if ErrorCodeFromCategory(category).Category() != category {
+			t.Fatalf("category %s does not round-trip through ErrorCodeFromCategory", category)
+		}
+	}
+}
+
+func TestPkgErrorPredicatesMatchSDKError(t *testing.T) {
+	var err error = NewSDKError(NewErrorDetailWithCode(ErrorCodeValidationFailed, "bad input"))
+	if !sdkerrors.IsValidationError(err) {
+		t.Fatalf("expected pkg/errors predicate to match a validation SDKError")
+	}
+
+	var pkgErr *sdkerrors.SDKError
+	if !errors.As(err, &pkgErr) || pkgErr.Code != models.ErrorCodeValidationError {
+		t.Fatalf("expected errors.As to expose the pkg/errors view, got %+v", pkgErr)
+	}
+
+	serverErr := NewSDKError(NewErrorDetailWithCode(ErrorCodeServiceUnavailable, "down"))
+	if !sdkerrors.IsServerError(serverErr) || !sdkerrors.IsRetryableError(serverErr) {
+		t.Fatalf("expected service unavailable to be a retryable server error")
+	}
+}
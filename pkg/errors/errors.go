@@ -35,6 +35,9 @@ type SDKError struct {
 	// Context contains additional error-specific information
 	Context map[string]interface{}
 
+	// Retryable marks errors that may succeed on retry regardless of Code
+	Retryable bool
+
 	// Err is the underlying error
 	Err error
 }
@@ -237,6 +240,9 @@ func IsRetryableError(err error) bool {
 
 	var sdkErr *SDKError
 	if errors.As(err, &sdkErr) {
+		if sdkErr.Retryable {
+			return true
+		}
 		switch sdkErr.Code {
 		case models.ErrorCodeNetworkError, 
 			 models.ErrorCodeServerError, 
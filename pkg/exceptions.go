@@ -3,9 +3,17 @@ Exceptions for the Complyance SDK matching Python SDK exactly.
 */
 package complyancesdk
 
+import (
+	"context"
+	"errors"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/models"
+)
+
 // SDKError Main SDK error matching Python SDK
 type SDKError struct {
 	ErrorDetail *ErrorDetail
+	cause       error
 }
 
 // NewSDKError creates a new SDK error
@@ -28,6 +36,37 @@ func (s *SDKError) Error() string {
 	return "Unknown SDK error"
 }
 
+// Unwrap returns the underlying cause, if any
+func (s *SDKError) Unwrap() error {
+	return s.cause
+}
+
+// ValidationErrors Typed validation errors reported by the server, from this
+// error or the error it wraps
+func (s *SDKError) ValidationErrors() []*ValidationErrorModel {
+	if s.ErrorDetail != nil {
+		if validationErrors := s.ErrorDetail.GetValidationErrors(); len(validationErrors) > 0 {
+			return validationErrors
+		}
+	}
+	var inner *SDKError
+	if s.cause != nil && errors.As(s.cause, &inner) {
+		return inner.ValidationErrors()
+	}
+	return nil
+}
+
+// newContextSDKError Wrap a context cancellation or deadline error
+func newContextSDKError(err error) *SDKError {
+	code := ErrorCodeNetworkError
+	if err == context.DeadlineExceeded {
+		code = ErrorCodeTimeoutError
+	}
+	errorDetail := NewErrorDetailWithCode(code, "Operation cancelled: "+err.Error())
+	errorDetail.Retryable = false
+	return &SDKError{ErrorDetail: errorDetail, cause: err}
+}
+
 // String string representation
 func (s *SDKError) String() string {
 	if s.ErrorDetail != nil {
@@ -36,6 +75,59 @@ func (s *SDKError) String() string {
 	return "Unknown SDK error"
 }
 
+// GetErrorCode Get the error code of the first SDKError in err's chain
+func GetErrorCode(err error) (ErrorCode, bool) {
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.ErrorDetail == nil || sdkErr.ErrorDetail.Code == nil {
+		return "", false
+	}
+	return *sdkErr.ErrorDetail.Code, true
+}
+
+// IsRetryable Check whether the failure behind err can be retried. When the
+// retry strategy gave up, this reports on the last attempt's error rather
+// than the MAX_RETRIES_EXCEEDED wrapper.
+func IsRetryable(err error) bool {
+	sdkErr := rootSDKError(err)
+	return sdkErr != nil && sdkErr.ErrorDetail != nil && sdkErr.ErrorDetail.Retryable
+}
+
+// IsAuthError Check whether err is an authentication or authorization failure
+func IsAuthError(err error) bool {
+	return rootErrorCategory(err) == models.ErrorCodeAuthenticationError
+}
+
+// IsValidationError Check whether err is a request or payload validation failure
+func IsValidationError(err error) bool {
+	return rootErrorCategory(err) == models.ErrorCodeValidationError
+}
+
+// rootSDKError Innermost SDKError in err's chain, i.e. the error the server or
+// transport actually reported
+func rootSDKError(err error) *SDKError {
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) {
+		return nil
+	}
+	for sdkErr.cause != nil {
+		var inner *SDKError
+		if !errors.As(sdkErr.cause, &inner) {
+			break
+		}
+		sdkErr = inner
+	}
+	return sdkErr
+}
+
+// rootErrorCategory Classification of the innermost SDKError's code
+func rootErrorCategory(err error) models.ErrorCode {
+	sdkErr := rootSDKError(err)
+	if sdkErr == nil || sdkErr.ErrorDetail == nil || sdkErr.ErrorDetail.Code == nil {
+		return ""
+	}
+	return sdkErr.ErrorDetail.Code.Category()
+}
+
 // ValidationError Validation error exception
 type ValidationError struct {
 	*SDKError
@@ -0,0 +1,69 @@
+package complyancesdk
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorPredicatesOnUnwrappedErrors(t *testing.T) {
+	validation := NewSDKError(NewErrorDetailWithCode(ErrorCodeValidationFailed, "bad payload"))
+	if !IsValidationError(validation) || IsAuthError(validation) || IsRetryable(validation) {
+		t.Fatalf("unexpected classification for validation error")
+	}
+	if code, ok := GetErrorCode(validation); !ok || code != ErrorCodeValidationFailed {
+		t.Fatalf("unexpected code: %v %v", code, ok)
+	}
+
+	auth := NewSDKError(NewErrorDetailWithCode(ErrorCodeAuthorizationDenied, "forbidden"))
+	if !IsAuthError(auth) || IsValidationError(auth) {
+		t.Fatalf("unexpected classification for auth error")
+	}
+
+	unavailable := NewSDKError(NewErrorDetailWithCode(ErrorCodeServiceUnavailable, "down"))
+	if !IsRetryable(unavailable) {
+		t.Fatalf("expected service unavailable to be retryable")
+	}
+
+	// The typed wrappers match *SDKError too
+	if !IsValidationError(NewValidationError("missing field", nil)) {
+		t.Fatalf("expected ValidationError to be classified as validation")
+	}
+	if !IsRetryable(NewNetworkError("connection reset", nil)) {
+		t.Fatalf("expected NetworkError to be retryable")
+	}
+}
+
+func TestErrorPredicatesOnWrappedErrors(t *testing.T) {
+	inner := NewSDKError(NewErrorDetailWithCode(ErrorCodeAuthenticationFailed, "bad key"))
+	wrapped := fmt.Errorf("submitting invoice: %w", inner)
+	if !IsAuthError(wrapped) {
+		t.Fatalf("expected wrapped auth error to be detected")
+	}
+	if code, ok := GetErrorCode(wrapped); !ok || code != ErrorCodeAuthenticationFailed {
+		t.Fatalf("unexpected code: %v %v", code, ok)
+	}
+
+	// A retry wrapper reports its own code but classifies by the last attempt
+	retried := &SDKError{
+		ErrorDetail: NewErrorDetailWithCode(ErrorCodeMaxRetriesExceeded, "gave up"),
+		cause:       NewSDKError(NewErrorDetailWithCode(ErrorCodeServiceUnavailable, "down")),
+	}
+	if code, _ := GetErrorCode(retried); code != ErrorCodeMaxRetriesExceeded {
+		t.Fatalf("expected the wrapper's code, got %v", code)
+	}
+	if !IsRetryable(fmt.Errorf("batch: %w", retried)) {
+		t.Fatalf("expected the underlying 503 to be retryable")
+	}
+}
+
+func TestErrorPredicatesOnNonSDKErrors(t *testing.T) {
+	for _, err := range []error{nil, errors.New("plain")} {
+		if IsRetryable(err) || IsAuthError(err) || IsValidationError(err) {
+			t.Fatalf("expected no classification for %v", err)
+		}
+		if code, ok := GetErrorCode(err); ok || code != "" {
+			t.Fatalf("expected no code for %v, got %v", err, code)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Machine-readable snapshot of SDK health for /healthz handlers.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// HealthStatus Overall state reported by HealthSnapshot
+type HealthStatus string
+
+const (
+	// HealthStatusOK The circuit breaker is closed and nothing is waiting for a retry
+	HealthStatusOK HealthStatus = "ok"
+	// HealthStatusDegraded Submissions are queued or the circuit breaker is not closed
+	HealthStatusDegraded HealthStatus = "degraded"
+	// HealthStatusUnavailable The circuit breaker is open, so sends are being held back
+	HealthStatusUnavailable HealthStatus = "unavailable"
+	// HealthStatusNotConfigured The SDK has not been configured
+	HealthStatusNotConfigured HealthStatus = "not_configured"
+)
+
+// CircuitBreakerHealth State of the circuit breaker in a Health snapshot
+type CircuitBreakerHealth struct {
+	State        CircuitState `json:"state"`
+	FailureCount int          `json:"failure_count"`
+	// LastFailureAt is nil when the breaker has not recorded a failure
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// Health Snapshot of queue, circuit breaker and retry state
+type Health struct {
+	Status         HealthStatus          `json:"status"`
+	Environment    Environment           `json:"environment,omitempty"`
+	Queue          *QueueStatusDetailed  `json:"queue,omitempty"`
+	CircuitBreaker *CircuitBreakerHealth `json:"circuit_breaker,omitempty"`
+	// LastRetryAt is nil when no request has been retried
+	LastRetryAt *time.Time `json:"last_retry_at,omitempty"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// Healthy Check if submissions can currently be sent
+func (h *Health) Healthy() bool {
+	return h != nil && (h.Status == HealthStatusOK || h.Status == HealthStatusDegraded)
+}
+
+// HealthSnapshot Queue counts, circuit breaker state, last retry time and
+// environment in one value that encodes to JSON.
+// Uses the SDK set up by Configure.
+func HealthSnapshot() *Health {
+	return currentSDK().HealthSnapshot()
+}
+
+// HealthSnapshot Queue counts, circuit breaker state, last retry time and
+// environment in one value that encodes to JSON. An SDK that is not
+// configured reports HealthStatusNotConfigured.
+func (s *GETSUnifySDK) HealthSnapshot() *Health {
+	if s == nil || s.config == nil || s.apiClient == nil {
+		return &Health{Status: HealthStatusNotConfigured, GeneratedAt: time.Now().UTC()}
+	}
+
+	breaker := s.apiClient.GetCircuitBreaker()
+	health := &Health{
+		Status:      HealthStatusOK,
+		Environment: s.config.Environment,
+		CircuitBreaker: &CircuitBreakerHealth{
+			State:        breaker.GetState(),
+			FailureCount: breaker.GetFailureCount(),
+		},
+		GeneratedAt: s.apiClient.clock.Now().UTC(),
+	}
+	if lastFailure := breaker.GetLastFailureTime(); lastFailure > 0 {
+		at := time.Unix(0, lastFailure*int64(time.Millisecond)).UTC()
+		health.CircuitBreaker.LastFailureAt = &at
+	}
+	if lastRetry := s.apiClient.retryStrategy.LastRetryTime(); !lastRetry.IsZero() {
+		at := lastRetry.UTC()
+		health.LastRetryAt = &at
+	}
+	if s.queueManager != nil {
+		health.Queue = s.queueManager.GetQueueStatusDetailed()
+	}
+
+	switch {
+	case breaker.IsOpen():
+		health.Status = HealthStatusUnavailable
+	case !breaker.IsClosed():
+		health.Status = HealthStatusDegraded
+	case health.Queue != nil && health.Queue.PendingCount+health.Queue.FailedCount > 0:
+		health.Status = HealthStatusDegraded
+	}
+	return health
+}
+
+// HealthHandler HTTP handler writing HealthSnapshot as JSON, with status 503
+// when the SDK is unavailable or not configured. Uses the SDK set up by
+// Configure at the time of each request.
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, HealthSnapshot())
+	})
+}
+
+// HealthHandler HTTP handler writing the SDK's HealthSnapshot as JSON, with
+// status 503 when the SDK is unavailable
+func (s *GETSUnifySDK) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, s.HealthSnapshot())
+	})
+}
+
+// writeHealth Write health as a JSON response
+func writeHealth(w http.ResponseWriter, health *Health) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !health.Healthy() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(health)
+}
@@ -0,0 +1,71 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthSnapshotReflectsOpenBreakerAndPendingQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	retryConfig := NewDefaultRetryConfig()
+	retryConfig.MaxAttempts = 2
+	retryConfig.BaseDelayMs = 1
+	retryConfig.MaxDelayMs = 1
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, retryConfig)
+	cfg.SetQueueMode(QueueModeMemory)
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+	sdk.apiClient.baseURL = server.URL
+
+	if health := sdk.HealthSnapshot(); health.Status != HealthStatusOK || health.LastRetryAt != nil {
+		t.Fatalf("expected a fresh SDK to be ok without retries, got %+v", health)
+	}
+
+	// Open the breaker first so the queue holds the failed push back
+	for i := 0; i < retryConfig.FailureThreshold; i++ {
+		_, _ = sdk.apiClient.GetCircuitBreaker().Execute(func() (interface{}, error) {
+			return nil, fmt.Errorf("outage")
+		})
+	}
+	// The failed push is retried once and then queued
+	_, _ = sdk.PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+		PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{})
+
+	health := sdk.HealthSnapshot()
+	if health.Status != HealthStatusUnavailable || health.Healthy() {
+		t.Fatalf("expected an unavailable SDK, got %s", health.Status)
+	}
+	if health.CircuitBreaker.State != CircuitStateOpen || health.CircuitBreaker.FailureCount != retryConfig.FailureThreshold || health.CircuitBreaker.LastFailureAt == nil {
+		t.Fatalf("expected an open breaker with %d failures, got %+v", retryConfig.FailureThreshold, health.CircuitBreaker)
+	}
+	if health.Queue == nil || health.Queue.PendingCount == 0 {
+		t.Fatalf("expected the failed push to be pending, got %+v", health.Queue)
+	}
+	if health.LastRetryAt == nil || health.Environment != EnvironmentSandbox {
+		t.Fatalf("expected the retry time and environment, got %+v", health)
+	}
+
+	recorder := httptest.NewRecorder()
+	sdk.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 from the handler, got %d", recorder.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON body: %v", err)
+	}
+	breaker, _ := body["circuit_breaker"].(map[string]interface{})
+	queue, _ := body["queue"].(map[string]interface{})
+	if body["status"] != "unavailable" || breaker["state"] != "OPEN" || queue["pending_count"] == float64(0) {
+		t.Fatalf("unexpected health JSON %s", recorder.Body.String())
+	}
+}
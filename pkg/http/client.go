@@ -151,10 +151,18 @@ func (c *DefaultClient) doRequest(ctx context.Context, req *Request) (*Response,
 
 	// Read response body
 	defer httpResp.Body.Close()
-	body, err := io.ReadAll(httpResp.Body)
+	maxBytes := c.config.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultMaxResponseBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxBytes+1))
 	if err != nil {
 		return nil, errors.NewNetworkError("failed to read response body", err)
 	}
+	if int64(len(body)) > maxBytes {
+		return nil, errors.NewAPIError(fmt.Sprintf("response body exceeds %d bytes", maxBytes), nil).
+			WithSuggestion("Raise MaxResponseBytes if responses this large are expected")
+	}
 	resp.Body = body
 
 	// Handle error responses
@@ -0,0 +1,255 @@
+/*
+Invoice and line item helpers that compute totals and VAT for payloads.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// LineItem Invoice line with the amounts needed to compute its totals and VAT
+type LineItem struct {
+	Description string
+	Quantity    float64
+	UnitPrice   float64
+	// DiscountAmount is subtracted from quantity times unit price
+	DiscountAmount float64
+	// VATRate is the VAT percentage, e.g. 15; 0 for zero-rated and exempt lines
+	VATRate float64
+	// VATCategory is the VAT category code such as "S", "Z" or "E", left out
+	// of the payload when empty
+	VATCategory string
+}
+
+// Invoice Invoice whose totals and VAT breakdown are computed from its lines.
+// Amounts are rounded to Precision decimal places, halves away from zero:
+// each line's net amount is rounded first and VAT is then computed per rate
+// and category on the sum of the rounded net amounts, as tax authorities
+// expect. Line VAT amounts are rounded on their own and are informational.
+type Invoice struct {
+	InvoiceNumber string
+	IssueDate     string
+	Currency      string
+	// Precision is the number of decimal places of amounts,
+	// DefaultMonetaryPrecision when 0
+	Precision int
+	Lines     []LineItem
+}
+
+// VATSubtotal Taxable amount and VAT of the lines sharing a rate and category
+type VATSubtotal struct {
+	Rate          float64
+	Category      string
+	TaxableAmount string
+	VATAmount     string
+}
+
+// NetAmount Quantity times unit price less the discount, rounded to precision
+func (l LineItem) NetAmount(precision int) string {
+	return l.netAmount(precision).FloatString(precision)
+}
+
+// VATAmount VAT on the line's rounded net amount, rounded to precision
+func (l LineItem) VATAmount(precision int) string {
+	return l.vatAmount(precision).FloatString(precision)
+}
+
+// TotalAmount Rounded net amount plus rounded VAT
+func (l LineItem) TotalAmount(precision int) string {
+	total := new(big.Rat).Add(l.netAmount(precision), l.vatAmount(precision))
+	return total.FloatString(precision)
+}
+
+// netAmount Rounded net amount of the line
+func (l LineItem) netAmount(precision int) *big.Rat {
+	net := new(big.Rat).Mul(floatRat(l.Quantity), floatRat(l.UnitPrice))
+	net.Sub(net, floatRat(l.DiscountAmount))
+	return roundRat(net, precision)
+}
+
+// vatAmount Rounded VAT on the line's rounded net amount
+func (l LineItem) vatAmount(precision int) *big.Rat {
+	return vatOn(l.netAmount(precision), l.VATRate, precision)
+}
+
+// GetPrecision Decimal places of amounts, DefaultMonetaryPrecision unless set
+func (i *Invoice) GetPrecision() int {
+	if i.Precision <= 0 {
+		return DefaultMonetaryPrecision
+	}
+	return i.Precision
+}
+
+// AddLine Append line to the invoice
+func (i *Invoice) AddLine(line LineItem) *Invoice {
+	i.Lines = append(i.Lines, line)
+	return i
+}
+
+// VATBreakdown Taxable amount and VAT per rate and category, in the order the
+// rates first appear in the lines
+func (i *Invoice) VATBreakdown() []VATSubtotal {
+	precision := i.GetPrecision()
+	type group struct {
+		rate     float64
+		category string
+		taxable  *big.Rat
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, line := range i.Lines {
+		key := strconv.FormatFloat(line.VATRate, 'f', -1, 64) + "|" + line.VATCategory
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{rate: line.VATRate, category: line.VATCategory, taxable: new(big.Rat)}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.taxable.Add(g.taxable, line.netAmount(precision))
+	}
+
+	breakdown := make([]VATSubtotal, 0, len(groups))
+	for _, g := range groups {
+		breakdown = append(breakdown, VATSubtotal{
+			Rate:          g.rate,
+			Category:      g.category,
+			TaxableAmount: g.taxable.FloatString(precision),
+			VATAmount:     vatOn(g.taxable, g.rate, precision).FloatString(precision),
+		})
+	}
+	return breakdown
+}
+
+// NetTotal Sum of the lines' rounded net amounts
+func (i *Invoice) NetTotal() string {
+	return i.netTotal().FloatString(i.GetPrecision())
+}
+
+// VATTotal Sum of the VAT of each rate in VATBreakdown
+func (i *Invoice) VATTotal() string {
+	return i.vatTotal().FloatString(i.GetPrecision())
+}
+
+// Total Net total plus VAT total
+func (i *Invoice) Total() string {
+	total := new(big.Rat).Add(i.netTotal(), i.vatTotal())
+	return total.FloatString(i.GetPrecision())
+}
+
+// netTotal Sum of the lines' rounded net amounts
+func (i *Invoice) netTotal() *big.Rat {
+	total := new(big.Rat)
+	for _, line := range i.Lines {
+		total.Add(total, line.netAmount(i.GetPrecision()))
+	}
+	return total
+}
+
+// vatTotal Sum of the rounded VAT per rate
+func (i *Invoice) vatTotal() *big.Rat {
+	total := new(big.Rat)
+	for _, subtotal := range i.VATBreakdown() {
+		vat, _ := new(big.Rat).SetString(subtotal.VATAmount)
+		total.Add(total, vat)
+	}
+	return total
+}
+
+// Payload Invoice payload with invoice_data, line_items and the computed
+// amounts as exact decimal numbers. A line amount that is NaN or infinite is
+// an INVALID_ARGUMENT error.
+func (i *Invoice) Payload() (map[string]interface{}, error) {
+	precision := i.GetPrecision()
+	lines := make([]interface{}, 0, len(i.Lines))
+	for index, line := range i.Lines {
+		for name, value := range map[string]float64{
+			"quantity":        line.Quantity,
+			"unit_price":      line.UnitPrice,
+			"discount_amount": line.DiscountAmount,
+			"tax_rate":        line.VATRate,
+		} {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				field := fmt.Sprintf("line_items[%d].%s", index, name)
+				detail := NewErrorDetailWithCode(
+					ErrorCodeInvalidArgument,
+					fmt.Sprintf("%s is %v, not a number", field, value),
+				).WithSuggestion("Set every line amount to a finite number.")
+				detail.Field = &field
+				return nil, NewSDKError(detail)
+			}
+		}
+
+		item := map[string]interface{}{
+			"description":  line.Description,
+			"quantity":     line.Quantity,
+			"unit_price":   line.UnitPrice,
+			"tax_rate":     line.VATRate,
+			"net_amount":   json.Number(line.NetAmount(precision)),
+			"tax_amount":   json.Number(line.VATAmount(precision)),
+			"total_amount": json.Number(line.TotalAmount(precision)),
+		}
+		if line.DiscountAmount != 0 {
+			item["discount_amount"] = json.Number(roundRat(floatRat(line.DiscountAmount), precision).FloatString(precision))
+		}
+		if line.VATCategory != "" {
+			item["tax_category"] = line.VATCategory
+		}
+		lines = append(lines, item)
+	}
+
+	breakdown := make([]interface{}, 0, len(i.Lines))
+	for _, subtotal := range i.VATBreakdown() {
+		entry := map[string]interface{}{
+			"tax_rate":       subtotal.Rate,
+			"taxable_amount": json.Number(subtotal.TaxableAmount),
+			"tax_amount":     json.Number(subtotal.VATAmount),
+		}
+		if subtotal.Category != "" {
+			entry["tax_category"] = subtotal.Category
+		}
+		breakdown = append(breakdown, entry)
+	}
+
+	invoiceData := map[string]interface{}{
+		"invoice_number":      i.InvoiceNumber,
+		"currency":            i.Currency,
+		"total_excluding_tax": json.Number(i.NetTotal()),
+		"tax_amount":          json.Number(i.VATTotal()),
+		"total_amount":        json.Number(i.Total()),
+		"tax_breakdown":       breakdown,
+	}
+	if i.IssueDate != "" {
+		invoiceData["issue_date"] = i.IssueDate
+	}
+	return map[string]interface{}{
+		DefaultInvoiceDataPath: invoiceData,
+		"line_items":           lines,
+	}, nil
+}
+
+// floatRat Exact decimal value of f from its shortest representation, zero
+// when f is NaN or infinite
+func floatRat(f float64) *big.Rat {
+	rat, ok := decimalRat(f)
+	if !ok {
+		return new(big.Rat)
+	}
+	return rat
+}
+
+// roundRat value rounded to precision decimal places, halves away from zero
+func roundRat(value *big.Rat, precision int) *big.Rat {
+	rounded, _ := new(big.Rat).SetString(value.FloatString(precision))
+	return rounded
+}
+
+// vatOn VAT at rate percent on amount, rounded to precision
+func vatOn(amount *big.Rat, rate float64, precision int) *big.Rat {
+	vat := new(big.Rat).Mul(amount, floatRat(rate))
+	vat.Quo(vat, big.NewRat(100, 1))
+	return roundRat(vat, precision)
+}
@@ -0,0 +1,95 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestInvoiceTotalsWithMixedVATRates(t *testing.T) {
+	invoice := &Invoice{InvoiceNumber: "INV-1", Currency: "SAR"}
+	invoice.
+		AddLine(LineItem{Description: "Standard", Quantity: 3, UnitPrice: 19.99, VATRate: 15, VATCategory: "S"}).
+		AddLine(LineItem{Description: "Discounted", Quantity: 2, UnitPrice: 50, DiscountAmount: 10, VATRate: 5, VATCategory: "S"}).
+		AddLine(LineItem{Description: "Export", Quantity: 1, UnitPrice: 200, VATCategory: "Z"})
+
+	if got := invoice.Lines[0].NetAmount(2); got != "59.97" {
+		t.Fatalf("expected line net 59.97, got %s", got)
+	}
+	if got := invoice.Lines[1].TotalAmount(2); got != "94.50" {
+		t.Fatalf("expected discounted line total 94.50, got %s", got)
+	}
+	if got := invoice.Lines[2].VATAmount(2); got != "0.00" {
+		t.Fatalf("expected no VAT on a zero-rated line, got %s", got)
+	}
+
+	breakdown := invoice.VATBreakdown()
+	if len(breakdown) != 3 {
+		t.Fatalf("expected one subtotal per rate, got %+v", breakdown)
+	}
+	want := []VATSubtotal{
+		{Rate: 15, Category: "S", TaxableAmount: "59.97", VATAmount: "9.00"},
+		{Rate: 5, Category: "S", TaxableAmount: "90.00", VATAmount: "4.50"},
+		{Rate: 0, Category: "Z", TaxableAmount: "200.00", VATAmount: "0.00"},
+	}
+	for i := range want {
+		if breakdown[i] != want[i] {
+			t.Fatalf("subtotal %d: expected %+v, got %+v", i, want[i], breakdown[i])
+		}
+	}
+	if invoice.NetTotal() != "349.97" || invoice.VATTotal() != "13.50" || invoice.Total() != "363.47" {
+		t.Fatalf("unexpected totals %s + %s = %s", invoice.NetTotal(), invoice.VATTotal(), invoice.Total())
+	}
+}
+
+func TestInvoiceRoundsVATPerRate(t *testing.T) {
+	invoice := &Invoice{InvoiceNumber: "INV-2", Currency: "SAR"}
+	for i := 0; i < 3; i++ {
+		invoice.AddLine(LineItem{Quantity: 1, UnitPrice: 0.1, VATRate: 15})
+	}
+	if got := invoice.Lines[0].VATAmount(2); got != "0.02" {
+		t.Fatalf("expected line VAT 0.015 to round half away from zero, got %s", got)
+	}
+	if got := invoice.VATTotal(); got != "0.05" {
+		t.Fatalf("expected VAT of 0.045 on the summed net amount to round to 0.05, got %s", got)
+	}
+
+	invoice.Precision = 3
+	if got := invoice.VATTotal(); got != "0.045" {
+		t.Fatalf("expected 3 decimal places, got %s", got)
+	}
+	negative := LineItem{Quantity: -1, UnitPrice: 2.675, VATRate: 15}
+	if got := negative.NetAmount(2); got != "-2.68" {
+		t.Fatalf("expected negative halves to round away from zero, got %s", got)
+	}
+}
+
+func TestInvoicePayload(t *testing.T) {
+	invoice := &Invoice{InvoiceNumber: "INV-3", IssueDate: "2024-01-15", Currency: "SAR"}
+	invoice.AddLine(LineItem{Description: "Item", Quantity: 1, UnitPrice: 100, VATRate: 15, VATCategory: "S"})
+	payload, err := invoice.Payload()
+	if err != nil {
+		t.Fatalf("Payload failed: %v", err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	for _, want := range []string{
+		`"invoice_number":"INV-3"`, `"issue_date":"2024-01-15"`, `"total_excluding_tax":100.00`,
+		`"tax_amount":15.00`, `"total_amount":115.00`, `"tax_breakdown":[{"tax_amount":15.00,"tax_category":"S","tax_rate":15,"taxable_amount":100.00}]`,
+		`"line_items":[{"description":"Item","net_amount":100.00,"quantity":1,"tax_amount":15.00,"tax_category":"S","tax_rate":15,"total_amount":115.00,"unit_price":100}]`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected %s in %s", want, body)
+		}
+	}
+
+	invoice.AddLine(LineItem{Quantity: math.NaN(), UnitPrice: 1})
+	_, err = invoice.Payload()
+	sdkErr, ok := err.(*SDKError)
+	if !ok || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "line_items[1].quantity" {
+		t.Fatalf("expected an error naming the NaN quantity, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Logging for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Logger receives the SDK's diagnostic output as structured fields. Its method
+// set is a superset of pkg/http.Logger, so one implementation serves both clients.
+type Logger interface {
+	Debug(msg string, fields map[string]interface{})
+	Info(msg string, fields map[string]interface{})
+	Warn(msg string, fields map[string]interface{})
+	Error(msg string, fields map[string]interface{})
+}
+
+// noopLogger Logger used when none is configured
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, map[string]interface{}) {}
+func (noopLogger) Info(string, map[string]interface{})  {}
+func (noopLogger) Warn(string, map[string]interface{})  {}
+func (noopLogger) Error(string, map[string]interface{}) {}
+
+// NewNoopLogger creates a logger that discards everything
+func NewNoopLogger() Logger {
+	return noopLogger{}
+}
+
+// loggerOrNoop Fall back to the no-op logger when none is provided
+func loggerOrNoop(logger Logger) Logger {
+	if logger == nil {
+		return noopLogger{}
+	}
+	return logger
+}
+
+// StdLogger Logger writing through a standard library *log.Logger
+type StdLogger struct {
+	logger *log.Logger
+	debug  bool
+}
+
+// NewStdLogger creates a Logger on top of the given *log.Logger, or the
+// standard logger when nil. Debug output is only written when debug is true.
+func NewStdLogger(logger *log.Logger, debug bool) *StdLogger {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &StdLogger{logger: logger, debug: debug}
+}
+
+// Debug logs at debug level
+func (l *StdLogger) Debug(msg string, fields map[string]interface{}) {
+	if l.debug {
+		l.write("DEBUG", msg, fields)
+	}
+}
+
+// Info logs at info level
+func (l *StdLogger) Info(msg string, fields map[string]interface{}) {
+	l.write("INFO", msg, fields)
+}
+
+// Warn logs at warn level
+func (l *StdLogger) Warn(msg string, fields map[string]interface{}) {
+	l.write("WARN", msg, fields)
+}
+
+// Error logs at error level
+func (l *StdLogger) Error(msg string, fields map[string]interface{}) {
+	l.write("ERROR", msg, fields)
+}
+
+func (l *StdLogger) write(level, msg string, fields map[string]interface{}) {
+	var builder strings.Builder
+	builder.WriteString(level)
+	builder.WriteString(" ")
+	builder.WriteString(msg)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&builder, " %s=%v", key, fields[key])
+	}
+	l.logger.Println(builder.String())
+}
+
+// sensitiveLogKeys Keys whose values are masked before they reach a Logger
+var sensitiveLogKeys = map[string]bool{
+	"authorization": true,
+	"apikey":        true,
+	"api_key":       true,
+	"x-api-key":     true,
+}
+
+// isSensitiveLogKey Check whether a field or header name carries a secret
+func isSensitiveLogKey(key string) bool {
+	return sensitiveLogKeys[strings.ToLower(key)]
+}
+
+// RedactSecrets returns a copy of data with Authorization and API key values
+// replaced by RedactedValue at any depth. The input is left untouched.
+func RedactSecrets(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if isSensitiveLogKey(key) {
+			redacted[key] = RedactedValue
+			continue
+		}
+		redacted[key] = redactSecretsValue(value)
+	}
+	return redacted
+}
+
+func redactSecretsValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return RedactSecrets(typed)
+	case []interface{}:
+		items := make([]interface{}, len(typed))
+		for i, item := range typed {
+			items[i] = redactSecretsValue(item)
+		}
+		return items
+	case map[string]string:
+		return RedactHeaders(typed)
+	default:
+		return value
+	}
+}
+
+// RedactHeaders returns a copy of headers with Authorization and API key values masked
+func RedactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if isSensitiveLogKey(key) {
+			redacted[key] = RedactedValue
+		} else {
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,126 @@
+package complyancesdk
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *recordingLogger) Debug(msg string, fields map[string]interface{}) {
+	l.record("DEBUG", msg, fields)
+}
+func (l *recordingLogger) Info(msg string, fields map[string]interface{}) {
+	l.record("INFO", msg, fields)
+}
+func (l *recordingLogger) Warn(msg string, fields map[string]interface{}) {
+	l.record("WARN", msg, fields)
+}
+func (l *recordingLogger) Error(msg string, fields map[string]interface{}) {
+	l.record("ERROR", msg, fields)
+}
+
+func (l *recordingLogger) text() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var builder strings.Builder
+	for _, entry := range l.entries {
+		fmt.Fprintf(&builder, "%s %s %v\n", entry.level, entry.msg, entry.fields)
+	}
+	return builder.String()
+}
+
+func TestConfiguredLoggerNeverReceivesSecrets(t *testing.T) {
+	const secret = "sk-live-very-secret"
+	logger := &recordingLogger{}
+	cfg := NewSDKConfigBuilder().
+		APIKey(secret).
+		Environment(EnvironmentSandbox).
+		RetryConfig(NewNoRetryConfig()).
+		Logger(logger).
+		Build()
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+secret {
+			t.Fatalf("expected the real token on the wire")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-LOG"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := logger.text()
+	if output == "" {
+		t.Fatalf("expected the configured logger to receive entries")
+	}
+	if strings.Contains(output, secret) {
+		t.Fatalf("secret leaked into logs:\n%s", output)
+	}
+	if !strings.Contains(output, RedactedValue) {
+		t.Fatalf("expected redacted headers in debug output:\n%s", output)
+	}
+}
+
+func TestAPIClientDefaultsToNoopLogger(t *testing.T) {
+	client := NewAPIClient("key", EnvironmentSandbox, NewDefaultRetryConfig())
+	if _, ok := client.GetLogger().(noopLogger); !ok {
+		t.Fatalf("expected no-op logger by default, got %T", client.GetLogger())
+	}
+	client.SetLogger(nil)
+	if _, ok := client.GetLogger().(noopLogger); !ok {
+		t.Fatalf("expected nil logger to fall back to no-op, got %T", client.GetLogger())
+	}
+}
+
+func TestRedactSecretsMasksNestedKeysWithoutMutatingInput(t *testing.T) {
+	data := map[string]interface{}{
+		"apiKey": "secret",
+		"source": map[string]interface{}{"name": "erp", "api_key": "nested"},
+		"items":  []interface{}{map[string]interface{}{"Authorization": "Bearer x"}},
+		"headers": map[string]string{
+			"X-API-Key":    "secret",
+			"Content-Type": "application/json",
+		},
+	}
+
+	redacted := RedactSecrets(data)
+
+	if redacted["apiKey"] != RedactedValue {
+		t.Fatalf("apiKey not redacted: %v", redacted["apiKey"])
+	}
+	source := redacted["source"].(map[string]interface{})
+	if source["api_key"] != RedactedValue || source["name"] != "erp" {
+		t.Fatalf("unexpected nested redaction: %v", source)
+	}
+	item := redacted["items"].([]interface{})[0].(map[string]interface{})
+	if item["Authorization"] != RedactedValue {
+		t.Fatalf("authorization in list not redacted: %v", item)
+	}
+	headers := redacted["headers"].(map[string]string)
+	if headers["X-API-Key"] != RedactedValue || headers["Content-Type"] != "application/json" {
+		t.Fatalf("unexpected header redaction: %v", headers)
+	}
+	if data["apiKey"] != "secret" || data["source"].(map[string]interface{})["api_key"] != "nested" {
+		t.Fatalf("input was mutated: %v", data)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Metrics for the Complyance SDK.
+*/
+package complyancesdk
+
+import "github.com/complyance-io/complyance-go-sdk/v3/pkg/retry"
+
+// MetricsSink receives retry, circuit breaker and queue metrics. It is the same
+// interface as retry.MetricsSink; metric and label names are defined there.
+type MetricsSink = retry.MetricsSink
+
+// metricsSinkOrNoop Fall back to a no-op sink when none is provided
+func metricsSinkOrNoop(sink MetricsSink) MetricsSink {
+	return retry.SinkOrNoop(sink)
+}
+
+// circuitStateMetricValue Gauge value for a circuit state (0 closed, 1 open, 2 half-open)
+func circuitStateMetricValue(state CircuitState) float64 {
+	switch state {
+	case CircuitStateOpen:
+		return float64(retry.CircuitOpen)
+	case CircuitStateHalfOpen:
+		return float64(retry.CircuitHalfOpen)
+	default:
+		return float64(retry.CircuitClosed)
+	}
+}
+
+// circuitStateMetricLabel Label value for a circuit state, matching pkg/retry
+func circuitStateMetricLabel(state CircuitState) string {
+	switch state {
+	case CircuitStateOpen:
+		return retry.CircuitOpen.String()
+	case CircuitStateHalfOpen:
+		return retry.CircuitHalfOpen.String()
+	default:
+		return retry.CircuitClosed.String()
+	}
+}
+
+// errorCodeMetricLabel Error code used to label failure metrics
+func errorCodeMetricLabel(err error) string {
+	if sdkErr, ok := err.(*SDKError); ok && sdkErr.ErrorDetail != nil && sdkErr.ErrorDetail.Code != nil {
+		return string(*sdkErr.ErrorDetail.Code)
+	}
+	return "unknown"
+}
@@ -0,0 +1,117 @@
+package complyancesdk
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/retry"
+)
+
+type metricEvent struct {
+	kind   string
+	name   string
+	value  float64
+	labels map[string]string
+}
+
+type fakeMetricsSink struct {
+	mu     sync.Mutex
+	events []metricEvent
+}
+
+func (s *fakeMetricsSink) add(event metricEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *fakeMetricsSink) IncrCounter(name string, labels map[string]string) {
+	s.add(metricEvent{kind: "counter", name: name, value: 1, labels: labels})
+}
+
+func (s *fakeMetricsSink) RecordDuration(name string, duration time.Duration, labels map[string]string) {
+	s.add(metricEvent{kind: "duration", name: name, value: float64(duration), labels: labels})
+}
+
+func (s *fakeMetricsSink) Gauge(name string, value float64, labels map[string]string) {
+	s.add(metricEvent{kind: "gauge", name: name, value: value, labels: labels})
+}
+
+func (s *fakeMetricsSink) find(name string) []metricEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matched []metricEvent
+	for _, event := range s.events {
+		if event.name == name {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+func TestMetricsSinkReceivesRetryMetrics(t *testing.T) {
+	sink := &fakeMetricsSink{}
+	attempts := 0
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewDefaultRetryConfig())
+	cfg.RetryConfig.BaseDelayMs = 1
+	cfg.SetMetricsSink(sink)
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-METRICS"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := sink.find(retry.MetricRetryAttempts); len(got) != 3 || got[0].labels[retry.LabelOperation] == "" {
+		t.Fatalf("expected 3 labelled attempts, got %+v", got)
+	}
+	failures := sink.find(retry.MetricRetryFailures)
+	if len(failures) != 2 || failures[0].labels[retry.LabelErrorCode] == "" {
+		t.Fatalf("expected 2 labelled failures, got %+v", failures)
+	}
+	if got := sink.find(retry.MetricRetrySuccesses); len(got) != 1 {
+		t.Fatalf("expected 1 success, got %+v", got)
+	}
+	durations := sink.find(retry.MetricRetryDuration)
+	if len(durations) != 1 || durations[0].kind != "duration" || durations[0].labels[retry.LabelOutcome] != "success" {
+		t.Fatalf("expected one successful duration, got %+v", durations)
+	}
+
+	GetQueueStatus()
+	states := make(map[string]bool)
+	for _, gauge := range sink.find(retry.MetricQueueDepth) {
+		states[gauge.labels[retry.LabelState]] = true
+	}
+	for _, state := range []string{PendingDir, ProcessingDir, FailedDir, SuccessDir} {
+		if !states[state] {
+			t.Fatalf("missing queue depth gauge for %s: %v", state, states)
+		}
+	}
+}
+
+func TestCircuitBreakerReportsStateTransitions(t *testing.T) {
+	sink := &fakeMetricsSink{}
+	breaker := NewCircuitBreaker(NewCircuitBreakerConfig(1, 60000))
+	breaker.SetMetricsSink(sink)
+
+	_, _ = breaker.Execute(func() (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	transitions := sink.find(retry.MetricCircuitTransitions)
+	if len(transitions) != 1 || transitions[0].labels[retry.LabelFrom] != "closed" || transitions[0].labels[retry.LabelTo] != "open" {
+		t.Fatalf("expected closed->open transition, got %+v", transitions)
+	}
+	if gauges := sink.find(retry.MetricCircuitState); len(gauges) != 1 || gauges[0].value != float64(retry.CircuitOpen) {
+		t.Fatalf("expected open state gauge, got %+v", gauges)
+	}
+}
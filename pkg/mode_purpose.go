@@ -0,0 +1,51 @@
+/*
+Compatibility of request modes and purposes.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// modePurposes Purposes allowed with each Mode. Documents are mapped,
+// submitted, validated or converted; onboarding requests only map and
+// validate the participant data. Modes missing here are not checked.
+var modePurposes = map[Mode][]Purpose{
+	ModeDocuments:  {PurposeMapping, PurposeInvoicing, PurposeValidation, PurposeConversion},
+	ModeOnboarding: {PurposeMapping, PurposeValidation},
+}
+
+// PurposesForMode Purposes allowed with mode, nil when mode has no restrictions
+func PurposesForMode(mode Mode) []Purpose {
+	allowed, ok := modePurposes[mode]
+	if !ok {
+		return nil
+	}
+	return append([]Purpose(nil), allowed...)
+}
+
+// ValidateModePurpose INVALID_ARGUMENT error when purpose cannot be used with
+// mode, suggesting the purposes that can
+func ValidateModePurpose(mode Mode, purpose Purpose) error {
+	allowed, ok := modePurposes[mode]
+	if !ok {
+		return nil
+	}
+	names := make([]string, len(allowed))
+	for i, candidate := range allowed {
+		if candidate == purpose {
+			return nil
+		}
+		names[i] = string(candidate)
+	}
+
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		fmt.Sprintf("Purpose %q cannot be used with mode %q", purpose, mode),
+	).WithSuggestion(fmt.Sprintf("With mode %q use one of the purposes: %s.", mode, strings.Join(names, ", ")))
+	detail.AddContextValue("mode", string(mode))
+	detail.AddContextValue("purpose", string(purpose))
+	detail.AddContextValue("allowed", names)
+	return NewSDKError(detail)
+}
@@ -0,0 +1,75 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPushToUnifyRejectsInvalidModePurposeCombinations(t *testing.T) {
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+	var calls int32
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, sources, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"SUB-1","status":"ACCEPTED"}}}`))
+	})
+
+	invalid := []struct {
+		mode    Mode
+		purpose Purpose
+	}{
+		{ModeOnboarding, PurposeInvoicing},
+		{ModeOnboarding, PurposeConversion},
+	}
+	for _, tc := range invalid {
+		_, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, tc.mode, tc.purpose, testInvoicePayload("INV-1"), nil)
+		sdkErr, ok := err.(*SDKError)
+		if !ok || sdkErr.ErrorDetail.Code == nil || *sdkErr.ErrorDetail.Code != ErrorCodeInvalidArgument {
+			t.Fatalf("%s/%s: expected INVALID_ARGUMENT, got %v", tc.mode, tc.purpose, err)
+		}
+		if sdkErr.ErrorDetail.Context["mode"] != string(tc.mode) || sdkErr.ErrorDetail.Context["purpose"] != string(tc.purpose) {
+			t.Fatalf("%s/%s: expected the combination in the error context, got %v", tc.mode, tc.purpose, sdkErr.ErrorDetail.Context)
+		}
+		if sdkErr.ErrorDetail.Suggestion == nil || *sdkErr.ErrorDetail.Suggestion == "" {
+			t.Fatalf("%s/%s: expected a suggestion listing the valid purposes", tc.mode, tc.purpose)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Fatalf("expected invalid combinations to be rejected before sending, got %d requests", got)
+	}
+}
+
+func TestValidModePurposeCombinationsBuildRequests(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+
+	checked := 0
+	for _, mode := range modes {
+		allowed := PurposesForMode(mode)
+		for _, purpose := range purposes {
+			valid := false
+			for _, candidate := range allowed {
+				valid = valid || candidate == purpose
+			}
+			if err := ValidateModePurpose(mode, purpose); (err == nil) != valid {
+				t.Fatalf("%s/%s: expected valid=%v, got %v", mode, purpose, valid, err)
+			}
+			if !valid {
+				continue
+			}
+			if _, err := sdk.buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+				CountrySA, OperationSingle, mode, purpose, testInvoicePayload("INV-1"), nil); err != nil {
+				t.Fatalf("%s/%s: expected the request to build, got %v", mode, purpose, err)
+			}
+			checked++
+		}
+	}
+	if checked != 6 {
+		t.Fatalf("expected 6 valid combinations, got %d", checked)
+	}
+}
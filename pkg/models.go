@@ -7,6 +7,9 @@ package complyancesdk
 
 import (
 	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 )
@@ -93,19 +96,33 @@ func readEnvFromFile(filePath string) string {
 	return ""
 }
 
+// environmentSubdomains Default gets.complyance.io subdomain for each environment.
+// SANDBOX, SIMULATION and PRODUCTION share the production host and are told
+// apart by the env field of the request.
+var environmentSubdomains = map[Environment]string{
+	EnvironmentDev:        "dev",
+	EnvironmentTest:       "test",
+	EnvironmentStage:      "stage",
+	EnvironmentSandbox:    "prod",
+	EnvironmentSimulation: "prod",
+	EnvironmentProduction: "prod",
+}
+
 // GetBaseURL Get the base URL for this environment (matching Java SDK)
-// URLs are dynamically constructed based on the ENV environment variable.
-// If ENV is set to "dev", "test", or "stage", that subdomain is used.
-// If not set, defaults to "prod" (production).
-// LOCAL environment always uses localhost.
+// DEV, TEST and STAGE use their own subdomain and the remaining environments
+// use "prod". If the ENV environment variable is set, its value is used as the
+// subdomain instead. LOCAL environment always uses localhost.
+// SDKConfig.EnvironmentURLs overrides all of these.
 func (e Environment) GetBaseURL() string {
 	if e == EnvironmentLocal {
 		return "http://127.0.0.1:4000/unify"
 	}
 
-	envValue := getEnvValue()
-	subdomain := "prod"
-	if envValue != "" {
+	subdomain, ok := environmentSubdomains[e]
+	if !ok {
+		subdomain = "prod"
+	}
+	if envValue := getEnvValue(); envValue != "" {
 		subdomain = strings.ToLower(strings.TrimSpace(envValue))
 	}
 
@@ -120,6 +137,8 @@ const (
 	CountryMY Country = "MY" // Malaysia
 	CountryAE Country = "AE" // UAE
 	CountrySG Country = "SG" // Singapore
+	CountryEG Country = "EG" // Egypt
+	CountryIN Country = "IN" // India
 )
 
 // DocumentType enumeration matching Python SDK
@@ -273,6 +292,10 @@ type Purpose string
 const (
 	PurposeMapping   Purpose = "mapping"
 	PurposeInvoicing Purpose = "invoicing"
+	// PurposeValidation runs mapping and validation only; nothing is submitted
+	PurposeValidation Purpose = "validation"
+	// PurposeConversion converts the payload to a GETS document only; nothing is submitted
+	PurposeConversion Purpose = "conversion"
 )
 
 // FromString Convert string to Purpose enum
@@ -282,6 +305,10 @@ func (p Purpose) FromString(value string) Purpose {
 		return PurposeMapping
 	case "invoicing":
 		return PurposeInvoicing
+	case "validation":
+		return PurposeValidation
+	case "conversion":
+		return PurposeConversion
 	default:
 		return ""
 	}
@@ -308,6 +335,7 @@ const (
 	DestinationTypeEmail        DestinationType = "EMAIL"
 	DestinationTypeArchive      DestinationType = "ARCHIVE"
 	DestinationTypePeppol       DestinationType = "PEPPOL"
+	DestinationTypeWebhook      DestinationType = "WEBHOOK"
 )
 
 // ErrorCode enumeration matching Python SDK
@@ -340,6 +368,9 @@ const (
 	ErrorCodeEmptyPayload                  ErrorCode = "EMPTY_PAYLOAD"
 	ErrorCodeMalformedJSON                 ErrorCode = "MALFORMED_JSON"
 	ErrorCodeInvalidPayloadFormat          ErrorCode = "INVALID_PAYLOAD_FORMAT"
+	ErrorCodeConfigurationError            ErrorCode = "CONFIGURATION_ERROR"
+	ErrorCodeSubmissionAborted             ErrorCode = "SUBMISSION_ABORTED"
+	ErrorCodeUnknownError                  ErrorCode = "UNKNOWN_ERROR"
 )
 
 // SubmissionStatus enumeration matching Python SDK
@@ -355,6 +386,16 @@ const (
 	SubmissionStatusQueued     SubmissionStatus = "QUEUED"
 )
 
+// IsTerminal Check if the status is final (ACCEPTED, REJECTED or FAILED)
+func (s SubmissionStatus) IsTerminal() bool {
+	switch SubmissionStatus(strings.ToUpper(string(s))) {
+	case SubmissionStatusAccepted, SubmissionStatusRejected, SubmissionStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
 // Source model matching Python SDK
 type Source struct {
 	Name    string      `json:"name"`
@@ -415,15 +456,37 @@ func (s *Source) GetVersion() string {
 
 // DestinationDetails model matching Python SDK
 type DestinationDetails struct {
-	Country       *string   `json:"country,omitempty"`
-	Authority     *string   `json:"authority,omitempty"`
-	DocumentType  *string   `json:"document_type,omitempty"`
-	Recipients    *[]string `json:"recipients,omitempty"`
-	Subject       *string   `json:"subject,omitempty"`
-	Body          *string   `json:"body,omitempty"`
-	ParticipantID *string   `json:"participant_id,omitempty"`
-	ProcessID     *string   `json:"process_id,omitempty"`
-}
+	Country       *string           `json:"country,omitempty"`
+	Authority     *string           `json:"authority,omitempty"`
+	DocumentType  *string           `json:"document_type,omitempty"`
+	Recipients    *[]string         `json:"recipients,omitempty"`
+	Subject       *string           `json:"subject,omitempty"`
+	Body          *string           `json:"body,omitempty"`
+	ParticipantID *string           `json:"participant_id,omitempty"`
+	ProcessID     *string           `json:"process_id,omitempty"`
+	URL           *string           `json:"url,omitempty"`
+	Method        *string           `json:"method,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	// RetentionYears, StorageClass, Bucket and Prefix apply to ARCHIVE
+	// destinations; the server defaults are used for those left nil
+	RetentionYears *int    `json:"retention_years,omitempty"`
+	StorageClass   *string `json:"storage_class,omitempty"`
+	Bucket         *string `json:"bucket,omitempty"`
+	Prefix         *string `json:"prefix,omitempty"`
+}
+
+// ArchiveStorageClass Storage tier of archived documents
+type ArchiveStorageClass string
+
+const (
+	ArchiveStorageStandard         ArchiveStorageClass = "STANDARD"
+	ArchiveStorageInfrequentAccess ArchiveStorageClass = "INFREQUENT_ACCESS"
+	ArchiveStorageCold             ArchiveStorageClass = "COLD"
+)
+
+// MinArchiveRetentionYears Shortest retention accepted for archived tax
+// documents, the six years ZATCA requires
+const MinArchiveRetentionYears = 6
 
 // SetCountry setter for country
 func (d *DestinationDetails) SetCountry(country string) {
@@ -465,6 +528,42 @@ func (d *DestinationDetails) SetProcessID(processID string) {
 	d.ProcessID = &processID
 }
 
+// SetURL setter for webhook URL
+func (d *DestinationDetails) SetURL(url string) {
+	d.URL = &url
+}
+
+// SetMethod setter for webhook HTTP method
+func (d *DestinationDetails) SetMethod(method string) {
+	d.Method = &method
+}
+
+// SetHeaders setter for webhook headers
+func (d *DestinationDetails) SetHeaders(headers map[string]string) {
+	d.Headers = headers
+}
+
+// SetRetentionYears setter for how many years archived documents are kept
+func (d *DestinationDetails) SetRetentionYears(retentionYears int) {
+	d.RetentionYears = &retentionYears
+}
+
+// SetStorageClass setter for the storage tier of archived documents
+func (d *DestinationDetails) SetStorageClass(storageClass ArchiveStorageClass) {
+	value := string(storageClass)
+	d.StorageClass = &value
+}
+
+// SetBucket setter for the bucket archived documents are stored in
+func (d *DestinationDetails) SetBucket(bucket string) {
+	d.Bucket = &bucket
+}
+
+// SetPrefix setter for the key prefix of archived documents
+func (d *DestinationDetails) SetPrefix(prefix string) {
+	d.Prefix = &prefix
+}
+
 // Destination model matching Python SDK
 type Destination struct {
 	Type    DestinationType     `json:"type"`
@@ -503,6 +602,16 @@ func NewArchiveDestination() *Destination {
 	}
 }
 
+// NewArchiveDestinationWithRetention Create archive destination keeping
+// documents for retentionYears in storageClass. Set a bucket and prefix on the
+// details with SetBucket and SetPrefix.
+func NewArchiveDestinationWithRetention(retentionYears int, storageClass ArchiveStorageClass) *Destination {
+	destination := NewArchiveDestination()
+	destination.Details.SetRetentionYears(retentionYears)
+	destination.Details.SetStorageClass(storageClass)
+	return destination
+}
+
 // NewPeppolDestination Create PEPPOL destination
 func NewPeppolDestination(participantID, processID, documentType string) *Destination {
 	details := &DestinationDetails{}
@@ -515,6 +624,29 @@ func NewPeppolDestination(participantID, processID, documentType string) *Destin
 	}
 }
 
+// NewWebhookDestination Create webhook destination. Processed documents are sent
+// to url with method (POST when empty) and the given headers.
+func NewWebhookDestination(url, method string, headers map[string]string) *Destination {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = http.MethodPost
+	}
+	details := &DestinationDetails{}
+	details.SetURL(url)
+	details.SetMethod(method)
+	if len(headers) > 0 {
+		copied := make(map[string]string, len(headers))
+		for key, value := range headers {
+			copied[key] = value
+		}
+		details.SetHeaders(copied)
+	}
+	return &Destination{
+		Type:    DestinationTypeWebhook,
+		Details: details,
+	}
+}
+
 // GetType getter for type
 func (d *Destination) GetType() DestinationType {
 	return d.Type
@@ -525,12 +657,139 @@ func (d *Destination) GetDetails() *DestinationDetails {
 	return d.Details
 }
 
+// Validate Check that the details required by the destination type are present
+func (d *Destination) Validate() error {
+	details := d.Details
+	if details == nil {
+		details = &DestinationDetails{}
+	}
+
+	switch d.Type {
+	case DestinationTypeTaxAuthority:
+		if isBlank(details.Country) {
+			return newDestinationValidationError(d.Type, "country", "Set the ISO country code with SetCountry, e.g. \"SA\".")
+		}
+		if isBlank(details.Authority) {
+			return newDestinationValidationError(d.Type, "authority", "Set the tax authority with SetAuthority, e.g. \"ZATCA\".")
+		}
+		if isBlank(details.DocumentType) {
+			return newDestinationValidationError(d.Type, "document_type", "Set the document type with SetDocumentType, e.g. \"tax_invoice\".")
+		}
+	case DestinationTypeEmail:
+		if details.Recipients == nil || len(*details.Recipients) == 0 {
+			return newDestinationValidationError(d.Type, "recipients", "Provide at least one recipient email address.")
+		}
+		for _, recipient := range *details.Recipients {
+			if strings.TrimSpace(recipient) == "" {
+				return newDestinationValidationError(d.Type, "recipients", "Remove empty entries from the recipient list.")
+			}
+		}
+	case DestinationTypePeppol:
+		if isBlank(details.ParticipantID) {
+			return newDestinationValidationError(d.Type, "participant_id", "Set the receiver's PEPPOL participant ID, e.g. \"0088:1234567890128\".")
+		}
+		if isBlank(details.ProcessID) {
+			return newDestinationValidationError(d.Type, "process_id", "Set the PEPPOL process ID for the business process.")
+		}
+	case DestinationTypeWebhook:
+		if isBlank(details.URL) {
+			return newDestinationValidationError(d.Type, "url", "Set the HTTP(S) endpoint that should receive processed documents.")
+		}
+		if parsed, err := url.Parse(strings.TrimSpace(*details.URL)); err != nil ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			detail := NewErrorDetailWithCode(
+				ErrorCodeValidationFailed,
+				fmt.Sprintf("WEBHOOK destination has an invalid url: %s", *details.URL),
+			).WithSuggestion("Use an absolute http:// or https:// URL, e.g. \"https://example.com/hooks/complyance\".")
+			field := "details.url"
+			detail.Field = &field
+			detail.FieldValue = *details.URL
+			return NewSDKError(detail)
+		}
+		if isBlank(details.Method) {
+			return newDestinationValidationError(d.Type, "method", "Set the HTTP method to POST or PUT.")
+		}
+		if method := strings.ToUpper(strings.TrimSpace(*details.Method)); method != http.MethodPost && method != http.MethodPut {
+			detail := NewErrorDetailWithCode(
+				ErrorCodeValidationFailed,
+				fmt.Sprintf("WEBHOOK destination method must be POST or PUT, got %s", *details.Method),
+			).WithSuggestion("Use POST or PUT for webhook destinations.")
+			field := "details.method"
+			detail.Field = &field
+			detail.FieldValue = *details.Method
+			return NewSDKError(detail)
+		}
+	case DestinationTypeArchive:
+		// Archive destinations have no required details, but the options given must be valid
+		if details.RetentionYears != nil && *details.RetentionYears < MinArchiveRetentionYears {
+			detail := NewErrorDetailWithCode(
+				ErrorCodeValidationFailed,
+				fmt.Sprintf("ARCHIVE destination retention of %d years is below the %d year minimum for tax documents", *details.RetentionYears, MinArchiveRetentionYears),
+			).WithSuggestion(fmt.Sprintf("Keep tax documents for at least %d years, or leave retention_years unset for the server default.", MinArchiveRetentionYears))
+			field := "details.retention_years"
+			detail.Field = &field
+			detail.FieldValue = *details.RetentionYears
+			return NewSDKError(detail)
+		}
+		if details.StorageClass != nil {
+			switch ArchiveStorageClass(strings.ToUpper(strings.TrimSpace(*details.StorageClass))) {
+			case ArchiveStorageStandard, ArchiveStorageInfrequentAccess, ArchiveStorageCold:
+			default:
+				detail := NewErrorDetailWithCode(
+					ErrorCodeValidationFailed,
+					fmt.Sprintf("ARCHIVE destination has an unknown storage class: %s", *details.StorageClass),
+				).WithSuggestion("Use STANDARD, INFREQUENT_ACCESS or COLD.")
+				field := "details.storage_class"
+				detail.Field = &field
+				detail.FieldValue = *details.StorageClass
+				return NewSDKError(detail)
+			}
+		}
+	default:
+		detail := NewErrorDetailWithCode(
+			ErrorCodeValidationFailed,
+			fmt.Sprintf("Unsupported destination type: %s", d.Type),
+		).WithSuggestion("Use one of TAX_AUTHORITY, EMAIL, ARCHIVE, PEPPOL or WEBHOOK.")
+		field := "type"
+		detail.Field = &field
+		detail.FieldValue = string(d.Type)
+		return NewSDKError(detail)
+	}
+	return nil
+}
+
+// newDestinationValidationError ValidationFailed error for a missing destination detail
+func newDestinationValidationError(destinationType DestinationType, field, suggestion string) error {
+	fieldPath := "details." + field
+	detail := NewErrorDetailWithCode(
+		ErrorCodeValidationFailed,
+		fmt.Sprintf("%s destination is missing required field %s", destinationType, fieldPath),
+	).WithSuggestion(suggestion)
+	detail.Field = &fieldPath
+	detail.AddValidationError(fieldPath, "Field is required", string(ErrorCodeMissingField))
+	return NewSDKError(detail)
+}
+
+// isBlank Check if an optional string is nil or whitespace
+func isBlank(value *string) bool {
+	return value == nil || strings.TrimSpace(*value) == ""
+}
+
 // CircuitBreakerConfig model matching Python SDK
 type CircuitBreakerConfig struct {
 	FailureThreshold   int `json:"failure_threshold"`
 	TimeoutDurationMs int `json:"timeout_duration_ms"`
+	// HalfOpenMaxProbes is how many calls a half-open breaker lets through,
+	// DefaultHalfOpenMaxProbes when 0
+	HalfOpenMaxProbes int `json:"half_open_max_probes,omitempty"`
+	// FailureWindowMs only counts failures from the last FailureWindowMs
+	// milliseconds towards FailureThreshold; every failure counts when 0
+	FailureWindowMs int `json:"failure_window_ms,omitempty"`
 }
 
+// DefaultHalfOpenMaxProbes Calls a half-open circuit breaker lets through by default
+const DefaultHalfOpenMaxProbes = 1
+
 // NewCircuitBreakerConfig creates a new circuit breaker config
 func NewCircuitBreakerConfig(failureThreshold, timeoutDurationMs int) *CircuitBreakerConfig {
 	return &CircuitBreakerConfig{
@@ -549,6 +808,24 @@ func (c *CircuitBreakerConfig) GetTimeout() int {
 	return c.TimeoutDurationMs
 }
 
+// GetHalfOpenMaxProbes getter for the calls a half-open breaker lets through,
+// all of which must succeed before it closes
+func (c *CircuitBreakerConfig) GetHalfOpenMaxProbes() int {
+	if c.HalfOpenMaxProbes <= 0 {
+		return DefaultHalfOpenMaxProbes
+	}
+	return c.HalfOpenMaxProbes
+}
+
+// GetFailureWindowMs getter for the rolling failure window in milliseconds,
+// 0 when every failure counts
+func (c *CircuitBreakerConfig) GetFailureWindowMs() int {
+	if c.FailureWindowMs < 0 {
+		return 0
+	}
+	return c.FailureWindowMs
+}
+
 // RetryConfig model matching Python SDK
 type RetryConfig struct {
 	MaxAttempts              int         `json:"max_attempts"`
@@ -561,6 +838,23 @@ type RetryConfig struct {
 	CircuitBreakerEnabled    bool        `json:"circuit_breaker_enabled"`
 	FailureThreshold         int         `json:"failure_threshold"`
 	CircuitBreakerTimeoutMs int         `json:"circuit_breaker_timeout_ms"`
+	// HalfOpenMaxProbes is how many calls the circuit breaker lets through once
+	// its timeout has passed, DefaultHalfOpenMaxProbes when 0
+	HalfOpenMaxProbes int `json:"half_open_max_probes,omitempty"`
+	// FailureWindowMs makes the circuit breaker open on FailureThreshold
+	// failures within this many milliseconds instead of in total
+	FailureWindowMs int `json:"failure_window_ms,omitempty"`
+	// BackoffStrategyName selects a built-in backoff strategy, exponential when empty
+	BackoffStrategyName string `json:"backoff_strategy,omitempty"`
+	// Backoff is a custom backoff strategy used instead of BackoffStrategyName
+	Backoff BackoffStrategy `json:"-"`
+	// JitterSeed seeds the random numbers used for jitter so delay sequences
+	// are reproducible; a time-seeded source is used when nil
+	JitterSeed *int64 `json:"jitter_seed,omitempty"`
+	// RandomSource is used for jitter instead of JitterSeed when set
+	RandomSource rand.Source `json:"-"`
+
+	jitter *jitterRandom
 }
 
 // NewDefaultRetryConfig Create default retry configuration
@@ -614,7 +908,10 @@ func NewNoRetryConfig() *RetryConfig {
 
 // GetCircuitBreakerConfig Get circuit breaker configuration
 func (r *RetryConfig) GetCircuitBreakerConfig() *CircuitBreakerConfig {
-	return NewCircuitBreakerConfig(r.FailureThreshold, r.CircuitBreakerTimeoutMs)
+	config := NewCircuitBreakerConfig(r.FailureThreshold, r.CircuitBreakerTimeoutMs)
+	config.HalfOpenMaxProbes = r.HalfOpenMaxProbes
+	config.FailureWindowMs = r.FailureWindowMs
+	return config
 }
 
 // ShouldRetry Check if error should be retried
@@ -88,6 +88,12 @@ func (fm *FieldMapping) Validate() error {
 		return errors.New("target path is required")
 	}
 
+	if strings.TrimSpace(fm.Transformation) != "" {
+		if _, _, err := lookupTransformation(fm.Transformation); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -233,4 +239,50 @@ func (fms *FieldMappingSet) GetMappingBySourcePath(sourcePath string) *FieldMapp
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// ApplyMappings builds a new document from source by reading each mapping's
+// SourcePath, applying its Transformation and writing the result to TargetPath.
+// A missing source value falls back to DefaultValue, which is written as is;
+// a missing required value without a default is an error. source is not modified.
+func (fms *FieldMappingSet) ApplyMappings(source map[string]interface{}) (map[string]interface{}, error) {
+	target := make(map[string]interface{})
+	for i, mapping := range fms.Mappings {
+		if mapping == nil {
+			return nil, fmt.Errorf("invalid mapping at index %d: mapping is nil", i)
+		}
+		if err := mapping.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid mapping at index %d: %w", i, err)
+		}
+
+		sourcePath, err := parseJSONPath(mapping.SourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mapping at index %d: %w", i, err)
+		}
+		targetPath, err := parseJSONPath(mapping.TargetPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mapping at index %d: %w", i, err)
+		}
+
+		value, found := getJSONPath(source, sourcePath)
+		if !found || value == nil {
+			if mapping.DefaultValue != nil {
+				value = mapping.DefaultValue
+			} else if mapping.Required {
+				return nil, fmt.Errorf("required field %s is missing", mapping.SourcePath)
+			} else {
+				continue
+			}
+		} else {
+			value, err = ApplyTransformation(mapping.Transformation, value)
+			if err != nil {
+				return nil, fmt.Errorf("transformation %s failed for %s: %w", mapping.Transformation, mapping.SourcePath, err)
+			}
+		}
+
+		if err := setJSONPath(target, targetPath, value); err != nil {
+			return nil, fmt.Errorf("cannot write %s: %w", mapping.TargetPath, err)
+		}
+	}
+	return target, nil
+}
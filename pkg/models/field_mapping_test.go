@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApplyMappingsTransformsValues(t *testing.T) {
+	source := map[string]interface{}{
+		"invoice": map[string]interface{}{
+			"currency": "sar",
+			"status":   "DRAFT",
+			"number":   "  INV-1  ",
+			"issued":   "2024-03-05T10:15:00Z",
+			"lines": []interface{}{
+				map[string]interface{}{"due": "2024-04-01"},
+			},
+		},
+	}
+
+	set := NewFieldMappingSet("erp", "SA", DocumentTypeTaxInvoice).
+		AddMapping(NewFieldMapping("$.invoice.currency", "invoice_data.currency").WithTransformation(TransformationToUpperCase)).
+		AddMapping(NewFieldMapping("$.invoice.status", "invoice_data.status").WithTransformation(TransformationToLowerCase)).
+		AddMapping(NewFieldMapping("$.invoice.number", "invoice_data.invoice_number").WithTransformation(TransformationTrim)).
+		AddMapping(NewFieldMapping("$.invoice.issued", "invoice_data.issue_date").WithTransformation(TransformationDateFormat)).
+		AddMapping(NewFieldMapping("$.invoice.lines[0].due", "invoice_data.due_date").WithTransformation("dateFormat:02/01/2006"))
+
+	result, err := set.ApplyMappings(source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invoiceData, _ := result["invoice_data"].(map[string]interface{})
+	expected := map[string]interface{}{
+		"currency":       "SAR",
+		"status":         "draft",
+		"invoice_number": "INV-1",
+		"issue_date":     "2024-03-05",
+		"due_date":       "01/04/2024",
+	}
+	for key, want := range expected {
+		if invoiceData[key] != want {
+			t.Fatalf("expected %s=%v, got %v", key, want, invoiceData[key])
+		}
+	}
+	if source["invoice"].(map[string]interface{})["currency"] != "sar" {
+		t.Fatalf("source document was modified")
+	}
+}
+
+func TestApplyMappingsDefaultsAndRequired(t *testing.T) {
+	set := NewFieldMappingSet("erp", "SA", DocumentTypeTaxInvoice).
+		AddMapping(NewFieldMapping("$.currency", "invoice_data.currency").WithDefaultValue("SAR").WithRequired(true)).
+		AddMapping(NewFieldMapping("$.notes", "invoice_data.notes"))
+
+	result, err := set.ApplyMappings(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	invoiceData, _ := result["invoice_data"].(map[string]interface{})
+	if invoiceData["currency"] != "SAR" {
+		t.Fatalf("expected default currency, got %v", invoiceData["currency"])
+	}
+	if _, ok := invoiceData["notes"]; ok {
+		t.Fatalf("expected optional missing field to be skipped")
+	}
+
+	set.AddSimpleMapping("$.invoice_number", "invoice_data.invoice_number", true)
+	if _, err := set.ApplyMappings(map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "$.invoice_number") {
+		t.Fatalf("expected required field error, got %v", err)
+	}
+}
+
+func TestApplyMappingsTransformationErrors(t *testing.T) {
+	cases := []struct {
+		transformation string
+		value          interface{}
+	}{
+		{TransformationToUpperCase, 42},
+		{TransformationDateFormat, "not a date"},
+		{"reverse", "abc"},
+	}
+	for _, tc := range cases {
+		set := NewFieldMappingSet("erp", "SA", DocumentTypeTaxInvoice).
+			AddMapping(NewFieldMapping("value", "out").WithTransformation(tc.transformation))
+		if _, err := set.ApplyMappings(map[string]interface{}{"value": tc.value}); err == nil {
+			t.Fatalf("expected %s to fail for %v", tc.transformation, tc.value)
+		}
+	}
+}
+
+func TestRegisterTransformationUsedByMappingSet(t *testing.T) {
+	RegisterTransformation("sarToHalalas", func(value interface{}) (interface{}, error) {
+		amount, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", value)
+		}
+		return int64(amount * 100), nil
+	})
+	t.Cleanup(func() { UnregisterTransformation("sarToHalalas") })
+
+	set := NewFieldMappingSet("erp", "SA", DocumentTypeTaxInvoice).
+		AddMapping(NewFieldMapping("$.total", "invoice_data.total_halalas").WithTransformation("sarToHalalas"))
+	if err := set.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	result, err := set.ApplyMappings(map[string]interface{}{"total": 12.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result["invoice_data"].(map[string]interface{})["total_halalas"]; got != int64(1250) {
+		t.Fatalf("expected 1250, got %v", got)
+	}
+}
+
+func TestValidateRejectsUnknownTransformation(t *testing.T) {
+	mapping := NewFieldMapping("$.total", "invoice_data.total").WithTransformation("doesNotExist")
+	if err := mapping.Validate(); err == nil || !strings.Contains(err.Error(), "doesNotExist") {
+		t.Fatalf("expected unknown transformation error, got %v", err)
+	}
+
+	set := NewFieldMappingSet("erp", "SA", DocumentTypeTaxInvoice).AddMapping(mapping)
+	if err := set.Validate(); err == nil {
+		t.Fatalf("expected mapping set validation to fail")
+	}
+	if err := NewFieldMapping("$.date", "date").WithTransformation("dateFormat:02/01/2006").Validate(); err != nil {
+		t.Fatalf("expected parameterized built-in to validate, got %v", err)
+	}
+}
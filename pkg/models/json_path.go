@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a parsed JSON path: a map key or an array index
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath parses a simple JSONPath such as "$.invoice.lines[0].amount".
+// The leading "$" is optional and only dot-separated keys and numeric array
+// indexes are supported.
+func parseJSONPath(path string) ([]pathSegment, error) {
+	trimmed := strings.TrimSpace(path)
+	trimmed = strings.TrimPrefix(trimmed, "$")
+	trimmed = strings.TrimPrefix(trimmed, ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("invalid JSON path %q: no fields", path)
+	}
+
+	segments := make([]pathSegment, 0)
+	for _, part := range strings.Split(trimmed, ".") {
+		key := part
+		var indexes []int
+		if open := strings.Index(part, "["); open >= 0 {
+			key = part[:open]
+			rest := part[open:]
+			for rest != "" {
+				closing := strings.Index(rest, "]")
+				if !strings.HasPrefix(rest, "[") || closing < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: malformed index in %q", path, part)
+				}
+				index, err := strconv.Atoi(rest[1:closing])
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: index must be a non-negative integer in %q", path, part)
+				}
+				indexes = append(indexes, index)
+				rest = rest[closing+1:]
+			}
+		}
+		if key == "" && len(indexes) == 0 {
+			return nil, fmt.Errorf("invalid JSON path %q: empty field name", path)
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+		for _, index := range indexes {
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+		}
+	}
+	return segments, nil
+}
+
+// getJSONPath returns the value at path and whether it was found
+func getJSONPath(document map[string]interface{}, segments []pathSegment) (interface{}, bool) {
+	var current interface{} = document
+	for _, segment := range segments {
+		if segment.isIndex {
+			items, ok := current.([]interface{})
+			if !ok || segment.index >= len(items) {
+				return nil, false
+			}
+			current = items[segment.index]
+			continue
+		}
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = fields[segment.key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setJSONPath writes value at path, creating intermediate objects as needed.
+// Array indexes must refer to existing elements.
+func setJSONPath(document map[string]interface{}, segments []pathSegment, value interface{}) error {
+	var current interface{} = document
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment.isIndex {
+			items, ok := current.([]interface{})
+			if !ok || segment.index >= len(items) {
+				return fmt.Errorf("array index %d does not exist", segment.index)
+			}
+			if last {
+				items[segment.index] = value
+				return nil
+			}
+			if items[segment.index] == nil && !segments[i+1].isIndex {
+				items[segment.index] = make(map[string]interface{})
+			}
+			current = items[segment.index]
+			continue
+		}
+
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field %q is not an object", segment.key)
+		}
+		if last {
+			fields[segment.key] = value
+			return nil
+		}
+		next, exists := fields[segment.key]
+		if !exists || next == nil {
+			if segments[i+1].isIndex {
+				return fmt.Errorf("array %q does not exist", segment.key)
+			}
+			next = make(map[string]interface{})
+			fields[segment.key] = next
+		}
+		current = next
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in field mapping transformations
+const (
+	// TransformationToUpperCase upper-cases a string value
+	TransformationToUpperCase = "toUpperCase"
+
+	// TransformationToLowerCase lower-cases a string value
+	TransformationToLowerCase = "toLowerCase"
+
+	// TransformationTrim removes leading and trailing whitespace from a string value
+	TransformationTrim = "trim"
+
+	// TransformationDateFormat reformats a date. The output layout follows a
+	// colon using Go reference time, e.g. "dateFormat:02/01/2006", and defaults
+	// to DefaultDateFormatLayout.
+	TransformationDateFormat = "dateFormat"
+)
+
+// DefaultDateFormatLayout is the output layout used by dateFormat when none is given
+const DefaultDateFormatLayout = "2006-01-02"
+
+// dateInputLayouts are the layouts dateFormat accepts for string input
+var dateInputLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// TransformFunc transforms a mapped value. arg is the text after the colon in
+// the transformation name, or empty when there is none.
+type TransformFunc func(value interface{}, arg string) (interface{}, error)
+
+// transformationRegistry holds the transformations FieldMapping resolves by name
+var transformationRegistry = struct {
+	sync.RWMutex
+	transformations map[string]TransformFunc
+}{transformations: make(map[string]TransformFunc)}
+
+func init() {
+	RegisterTransformation(TransformationToUpperCase, stringTransform(strings.ToUpper))
+	RegisterTransformation(TransformationToLowerCase, stringTransform(strings.ToLower))
+	RegisterTransformation(TransformationTrim, stringTransform(strings.TrimSpace))
+	RegisterParameterizedTransformation(TransformationDateFormat, formatDate)
+}
+
+// RegisterTransformation makes fn available to field mappings under name,
+// replacing any transformation already registered with that name. It panics
+// if name is empty or contains a colon, or if fn is nil.
+func RegisterTransformation(name string, fn func(interface{}) (interface{}, error)) {
+	if fn == nil {
+		panic("models: RegisterTransformation called with nil function for " + name)
+	}
+	RegisterParameterizedTransformation(name, func(value interface{}, _ string) (interface{}, error) {
+		return fn(value)
+	})
+}
+
+// RegisterParameterizedTransformation registers a transformation that also
+// receives the argument following the colon in "name:arg"
+func RegisterParameterizedTransformation(name string, fn TransformFunc) {
+	if strings.TrimSpace(name) == "" || strings.Contains(name, ":") {
+		panic(fmt.Sprintf("models: invalid transformation name %q", name))
+	}
+	if fn == nil {
+		panic("models: RegisterParameterizedTransformation called with nil function for " + name)
+	}
+	transformationRegistry.Lock()
+	defer transformationRegistry.Unlock()
+	transformationRegistry.transformations[strings.TrimSpace(name)] = fn
+}
+
+// UnregisterTransformation removes a registered transformation
+func UnregisterTransformation(name string) {
+	transformationRegistry.Lock()
+	defer transformationRegistry.Unlock()
+	delete(transformationRegistry.transformations, strings.TrimSpace(name))
+}
+
+// lookupTransformation returns the transformation registered for the name part
+// of transformation and its argument
+func lookupTransformation(transformation string) (TransformFunc, string, error) {
+	name, arg := splitTransformation(transformation)
+	transformationRegistry.RLock()
+	defer transformationRegistry.RUnlock()
+	transform, ok := transformationRegistry.transformations[name]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown transformation: %s", name)
+	}
+	return transform, arg, nil
+}
+
+// ApplyTransformation applies the named transformation, e.g. "trim" or
+// "dateFormat:2006-01-02", to value. An empty name returns value unchanged.
+func ApplyTransformation(transformation string, value interface{}) (interface{}, error) {
+	if strings.TrimSpace(transformation) == "" {
+		return value, nil
+	}
+	transform, arg, err := lookupTransformation(transformation)
+	if err != nil {
+		return nil, err
+	}
+	return transform(value, arg)
+}
+
+// splitTransformation splits "name:arg" into its name and argument
+func splitTransformation(transformation string) (string, string) {
+	name := strings.TrimSpace(transformation)
+	arg := ""
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		arg = strings.TrimSpace(name[colon+1:])
+		name = strings.TrimSpace(name[:colon])
+	}
+	return name, arg
+}
+
+// stringTransform adapts a string function into a transformation
+func stringTransform(fn func(string) string) func(interface{}) (interface{}, error) {
+	return func(value interface{}) (interface{}, error) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", value)
+		}
+		return fn(s), nil
+	}
+}
+
+// formatDate parses a date string or time.Time and formats it with the layout in arg
+func formatDate(value interface{}, arg string) (interface{}, error) {
+	layout := arg
+	if layout == "" {
+		layout = DefaultDateFormatLayout
+	}
+
+	switch typed := value.(type) {
+	case time.Time:
+		return typed.Format(layout), nil
+	case string:
+		input := strings.TrimSpace(typed)
+		for _, inputLayout := range dateInputLayouts {
+			if parsed, err := time.Parse(inputLayout, input); err == nil {
+				return parsed.Format(layout), nil
+			}
+		}
+		return nil, fmt.Errorf("cannot parse %q as a date", typed)
+	default:
+		return nil, fmt.Errorf("expected a date string, got %T", value)
+	}
+}
@@ -0,0 +1,153 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDestinationValidateRequiredFields(t *testing.T) {
+	cases := []struct {
+		name        string
+		destination *Destination
+		field       string
+	}{
+		{"tax authority valid", NewTaxAuthorityDestination("SA", "ZATCA", "tax_invoice"), ""},
+		{"tax authority country", NewTaxAuthorityDestination("", "ZATCA", "tax_invoice"), "details.country"},
+		{"tax authority authority", NewTaxAuthorityDestination("SA", " ", "tax_invoice"), "details.authority"},
+		{"tax authority document type", NewTaxAuthorityDestination("SA", "ZATCA", ""), "details.document_type"},
+		{"email valid", NewEmailDestination([]string{"ap@example.com"}, "Invoice", ""), ""},
+		{"email no recipients", NewEmailDestination(nil, "Invoice", ""), "details.recipients"},
+		{"email blank recipient", NewEmailDestination([]string{"ap@example.com", ""}, "Invoice", ""), "details.recipients"},
+		{"peppol valid", NewPeppolDestination("0088:1234567890128", "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0", "invoice"), ""},
+		{"peppol participant", NewPeppolDestination("", "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0", "invoice"), "details.participant_id"},
+		{"peppol process", NewPeppolDestination("0088:1234567890128", "", "invoice"), "details.process_id"},
+		{"webhook valid", NewWebhookDestination("https://example.com/hooks", "put", nil), ""},
+		{"webhook url", NewWebhookDestination("", "POST", nil), "details.url"},
+		{"webhook relative url", NewWebhookDestination("/hooks", "POST", nil), "details.url"},
+		{"webhook scheme", NewWebhookDestination("ftp://example.com/hooks", "POST", nil), "details.url"},
+		{"webhook method", NewWebhookDestination("https://example.com/hooks", "GET", nil), "details.method"},
+		{"archive valid", NewArchiveDestination(), ""},
+		{"archive without details", &Destination{Type: DestinationTypeArchive}, ""},
+		{"unknown type", &Destination{Type: DestinationType("FAX")}, "type"},
+	}
+
+	for _, tc := range cases {
+		err := tc.destination.Validate()
+		if tc.field == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		sdkErr, ok := err.(*SDKError)
+		if !ok {
+			t.Fatalf("%s: expected SDKError, got %v", tc.name, err)
+		}
+		detail := sdkErr.ErrorDetail
+		if *detail.Code != ErrorCodeValidationFailed || detail.Field == nil || *detail.Field != tc.field {
+			t.Fatalf("%s: expected ValidationFailed for %s, got %s", tc.name, tc.field, detail)
+		}
+	}
+}
+
+func TestPushToUnifyRejectsInvalidDestinationBeforeSending(t *testing.T) {
+	requests := 0
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	destinations := []*Destination{NewArchiveDestination(), NewPeppolDestination("", "process", "invoice")}
+	_, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-DEST"), destinations)
+	sdkErr, ok := err.(*SDKError)
+	if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeValidationFailed {
+		t.Fatalf("expected ValidationFailed error, got %v", err)
+	}
+	if sdkErr.ErrorDetail.GetContextValue("destinationIndex") != 1 {
+		t.Fatalf("expected destination index in context, got %v", sdkErr.ErrorDetail.Context)
+	}
+	if requests != 0 {
+		t.Fatalf("expected no request to be sent, got %d", requests)
+	}
+}
+
+func TestWebhookDestinationSerializationRoundTrip(t *testing.T) {
+	headers := map[string]string{"X-Signature": "abc"}
+	destination := NewWebhookDestination("https://example.com/hooks", "", headers)
+	headers["X-Signature"] = "changed"
+
+	encoded, err := json.Marshal(NewAPIClient("key", EnvironmentSandbox, NewNoRetryConfig()).serializeDestination(destination))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var wire map[string]interface{}
+	if err := json.Unmarshal(encoded, &wire); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	details, _ := wire["details"].(map[string]interface{})
+	wireHeaders, _ := details["headers"].(map[string]interface{})
+	if wire["type"] != "WEBHOOK" || details["url"] != "https://example.com/hooks" || details["method"] != "POST" || wireHeaders["X-Signature"] != "abc" {
+		t.Fatalf("unexpected serialized webhook destination: %s", encoded)
+	}
+
+	persisted, err := json.Marshal(destination)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var restored Destination
+	if err := json.Unmarshal(persisted, &restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(&restored, destination) {
+		t.Fatalf("round trip mismatch: %s", persisted)
+	}
+}
+
+func TestArchiveDestinationSerializesRetentionOptions(t *testing.T) {
+	destination := NewArchiveDestinationWithRetention(10, ArchiveStorageCold)
+	destination.Details.SetBucket("tax-archive")
+	destination.Details.SetPrefix("sa/2024/")
+	if err := destination.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	encoded, err := json.Marshal(NewAPIClient("key", EnvironmentSandbox, NewNoRetryConfig()).serializeDestination(destination))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	want := `{"details":{"bucket":"tax-archive","prefix":"sa/2024/","retentionYears":10,"storageClass":"COLD"},"type":"ARCHIVE"}`
+	if string(encoded) != want {
+		t.Fatalf("unexpected serialized archive destination:\n got %s\nwant %s", encoded, want)
+	}
+
+	persisted, err := json.Marshal(destination)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var restored Destination
+	if err := json.Unmarshal(persisted, &restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(&restored, destination) {
+		t.Fatalf("round trip mismatch: %s", persisted)
+	}
+	if encoded, _ := json.Marshal(NewArchiveDestination()); string(encoded) != `{"type":"ARCHIVE","details":{}}` {
+		t.Fatalf("expected a plain archive destination to stay empty, got %s", encoded)
+	}
+}
+
+func TestArchiveDestinationRejectsShortRetention(t *testing.T) {
+	err := NewArchiveDestinationWithRetention(MinArchiveRetentionYears-1, ArchiveStorageStandard).Validate()
+	sdkErr, ok := err.(*SDKError)
+	if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeValidationFailed || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "details.retention_years" {
+		t.Fatalf("expected a retention_years validation error, got %v", err)
+	}
+	if err := NewArchiveDestinationWithRetention(MinArchiveRetentionYears, ArchiveStorageStandard).Validate(); err != nil {
+		t.Fatalf("expected the minimum retention to be accepted, got %v", err)
+	}
+	if err := NewArchiveDestinationWithRetention(7, "GLACIER").Validate(); err == nil {
+		t.Fatalf("expected an unknown storage class to be rejected")
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Fixed-precision formatting of monetary amounts in payloads.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultMonetaryPrecision Decimal places of monetary amounts when SDKConfig.MonetaryPrecision is 0
+const DefaultMonetaryPrecision = 2
+
+// FormatDecimal value as a decimal string with precision decimal places,
+// rounding halves away from zero. Floats are read from their shortest
+// representation, so 1000.1 becomes "1000.10" rather than the binary
+// approximation 1000.0999.... value may be any Go integer or float,
+// json.Number, numeric string, *big.Rat or *big.Float.
+func FormatDecimal(value interface{}, precision int) (string, error) {
+	amount, ok := decimalRat(value)
+	if !ok {
+		return "", fmt.Errorf("%v (%T) is not a number", value, value)
+	}
+	return amount.FloatString(precision), nil
+}
+
+// decimalRat Exact value of a number, false when value is not one
+func decimalRat(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch typed := value.(type) {
+	case float64:
+		text = strconv.FormatFloat(typed, 'f', -1, 64)
+	case float32:
+		text = strconv.FormatFloat(float64(typed), 'f', -1, 32)
+	case int:
+		text = strconv.Itoa(typed)
+	case int32:
+		text = strconv.FormatInt(int64(typed), 10)
+	case int64:
+		text = strconv.FormatInt(typed, 10)
+	case uint:
+		text = strconv.FormatUint(uint64(typed), 10)
+	case uint32:
+		text = strconv.FormatUint(uint64(typed), 10)
+	case uint64:
+		text = strconv.FormatUint(typed, 10)
+	case json.Number:
+		text = typed.String()
+	case string:
+		text = strings.TrimSpace(typed)
+	case *big.Rat:
+		if typed == nil {
+			return nil, false
+		}
+		return new(big.Rat).Set(typed), true
+	case *big.Float:
+		if typed == nil || typed.IsInf() {
+			return nil, false
+		}
+		rat, _ := typed.Rat(nil)
+		return rat, true
+	default:
+		return nil, false
+	}
+	rat, ok := new(big.Rat).SetString(text)
+	return rat, ok
+}
+
+// formatMonetaryFields Replace the amounts at paths in payload, a request copy,
+// with decimal strings of precision places. Paths are dot separated and
+// relative to the payload, "*" matches any key and arrays are matched element
+// by element, as in RedactionConfig. Missing and null amounts are skipped; any
+// other value that is not a number is an INVALID_ARGUMENT error.
+func formatMonetaryFields(payload map[string]interface{}, paths []string, precision int) error {
+	for _, path := range paths {
+		if err := formatMonetaryPath(payload, strings.Split(path, "."), "payload", precision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatMonetaryPath Format every value matching the path segments in place
+func formatMonetaryPath(value interface{}, segments []string, location string, precision int) error {
+	if len(segments) == 0 {
+		return nil
+	}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if segments[0] != "*" && segments[0] != key {
+				continue
+			}
+			childLocation := location + "." + key
+			if len(segments) > 1 {
+				if err := formatMonetaryPath(child, segments[1:], childLocation, precision); err != nil {
+					return err
+				}
+				continue
+			}
+			if child == nil {
+				continue
+			}
+			formatted, err := FormatDecimal(child, precision)
+			if err != nil {
+				detail := NewErrorDetailWithCode(
+					ErrorCodeInvalidArgument,
+					fmt.Sprintf("Monetary field %s is not a number: %v", childLocation, err),
+				).WithSuggestion("Pass amounts as numbers or numeric strings, or remove the path from SDKConfig.MonetaryFieldPaths.")
+				detail.Field = &childLocation
+				return NewSDKError(detail)
+			}
+			typed[key] = formatted
+		}
+	case []interface{}:
+		for i, item := range typed {
+			if err := formatMonetaryPath(item, segments, fmt.Sprintf("%s[%d]", location, i), precision); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for i, item := range typed {
+			if err := formatMonetaryPath(item, segments, fmt.Sprintf("%s[%d]", location, i), precision); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormatDecimalAvoidsFloatArtifacts(t *testing.T) {
+	cases := []struct {
+		value     interface{}
+		precision int
+		want      string
+	}{
+		{1000.1, 2, "1000.10"},
+		{0.1 + 0.2, 2, "0.30"},
+		{2.675, 2, "2.68"},
+		{1.005, 2, "1.01"},
+		{-2.675, 2, "-2.68"},
+		{float32(19.99), 2, "19.99"},
+		{1500, 3, "1500.000"},
+		{json.Number("115.005"), 2, "115.01"},
+		{" 42.5 ", 2, "42.50"},
+		{1000.1, 0, "1000"},
+	}
+	for _, tc := range cases {
+		got, err := FormatDecimal(tc.value, tc.precision)
+		if err != nil || got != tc.want {
+			t.Fatalf("FormatDecimal(%v, %d): got %q, %v, want %q", tc.value, tc.precision, got, err, tc.want)
+		}
+	}
+	if _, err := FormatDecimal(true, 2); err == nil {
+		t.Fatalf("expected an error for a non-numeric value")
+	}
+}
+
+func TestMonetaryFieldsAreSerializedExactly(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	cfg.SetMonetaryFields(0, "invoice_data.total_amount", "invoice_data.vat_amount", "line_items.unit_price")
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+
+	payload := testInvoicePayload("INV-1")
+	payload["invoice_data"] = map[string]interface{}{
+		"invoice_number": "INV-1",
+		"total_amount":   1000.1,
+		"vat_amount":     0.1 + 0.2,
+	}
+	payload["line_items"] = []interface{}{
+		map[string]interface{}{"unit_price": 2.675, "quantity": 3},
+		map[string]interface{}{"unit_price": nil},
+	}
+	request, err := sdk.buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+		CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil)
+	if err != nil {
+		t.Fatalf("buildUnifyRequestV2 failed: %v", err)
+	}
+	body, err := json.Marshal(request.GetPayload())
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	for _, want := range []string{`"total_amount":"1000.10"`, `"vat_amount":"0.30"`, `"unit_price":"2.68"`, `"quantity":3`, `"unit_price":null`} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected %s in %s", want, body)
+		}
+	}
+	if payload["invoice_data"].(map[string]interface{})["total_amount"] != 1000.1 {
+		t.Fatalf("expected the caller's payload to be left as is")
+	}
+
+	payload["invoice_data"].(map[string]interface{})["total_amount"] = "n/a"
+	_, err = sdk.buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+		CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil)
+	sdkErr, ok := err.(*SDKError)
+	if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeInvalidArgument || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "payload.invoice_data.total_amount" {
+		t.Fatalf("expected INVALID_ARGUMENT naming the amount, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Checks that credit and debit notes reference the original invoice.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultOriginalReferenceFields Invoice data fields checked for the original
+// invoice of a credit or debit note when SDKConfig.OriginalReferenceFields is empty
+var DefaultOriginalReferenceFields = []string{
+	"original_invoice_number",
+	"billing_reference",
+}
+
+// IsAdjustmentNote Whether logicalType is a credit or debit note
+func IsAdjustmentNote(logicalType LogicalDocType) bool {
+	name := strings.ToUpper(string(logicalType))
+	return strings.Contains(name, "CREDIT_NOTE") || strings.Contains(name, "DEBIT_NOTE")
+}
+
+// validateOriginalReference Require one of the configured reference fields in
+// the invoice data of a credit or debit note. Other logical types are not
+// checked.
+func (s *SDKConfig) validateOriginalReference(logicalType LogicalDocType, payload map[string]interface{}) error {
+	if !IsAdjustmentNote(logicalType) {
+		return nil
+	}
+	fields := make([]string, 0, len(s.GetOriginalReferenceFields()))
+	for _, field := range s.GetOriginalReferenceFields() {
+		path := s.GetInvoiceDataPath() + "." + field
+		if hasPayloadValue(payload, path) {
+			return nil
+		}
+		fields = append(fields, path)
+	}
+	field := fields[0]
+	detail := NewErrorDetailWithCode(
+		ErrorCodeMissingField,
+		fmt.Sprintf("%s must reference the original invoice in %s", logicalType, strings.Join(fields, " or ")),
+	).WithSuggestion(fmt.Sprintf("Set payload.%s to the number of the invoice being adjusted, or configure the field with SDKConfig.SetOriginalReferenceFields.", field))
+	detail.Field = &field
+	detail.AddContextValue("logicalType", string(logicalType))
+	detail.AddContextValue("fields", fields)
+	return NewSDKError(detail)
+}
+
+// hasPayloadValue Whether the dot separated path resolves to a value other
+// than nil or a blank string
+func hasPayloadValue(payload map[string]interface{}, path string) bool {
+	var value interface{} = payload
+	for _, segment := range strings.Split(path, ".") {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = current[segment]; !ok {
+			return false
+		}
+	}
+	switch typed := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(typed) != ""
+	}
+	return true
+}
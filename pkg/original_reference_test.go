@@ -0,0 +1,59 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreditNoteRequiresOriginalReference(t *testing.T) {
+	requests := 0
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	push := func(logicalType LogicalDocType, payload map[string]interface{}) error {
+		_, err := PushToUnify("src", "1", logicalType, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil)
+		return err
+	}
+
+	for _, logicalType := range []LogicalDocType{LogicalDocTypeCreditNote, LogicalDocTypeSimplifiedTaxInvoiceDebitNote} {
+		err := push(logicalType, testInvoicePayload("CN-1"))
+		sdkErr, ok := err.(*SDKError)
+		if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeMissingField || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "invoice_data.original_invoice_number" {
+			t.Fatalf("%s: expected MISSING_FIELD naming the reference, got %v", logicalType, err)
+		}
+		if sdkErr.ErrorDetail.Suggestion == nil {
+			t.Fatalf("%s: expected a suggestion", logicalType)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("expected nothing to be sent without a reference, got %d requests", requests)
+	}
+
+	payload := testInvoicePayload("CN-1")
+	payload["invoice_data"].(map[string]interface{})["billing_reference"] = "INV-1"
+	if err := push(LogicalDocTypeCreditNote, payload); err != nil {
+		t.Fatalf("expected a credit note with a reference to be sent, got %v", err)
+	}
+	if err := push(LogicalDocTypeTaxInvoice, testInvoicePayload("INV-2")); err != nil {
+		t.Fatalf("expected invoices not to need a reference, got %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestOriginalReferenceFieldsCanBeConfigured(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetOriginalReferenceFields("references.invoice_id")
+
+	payload := testInvoicePayload("CN-1")
+	payload["invoice_data"].(map[string]interface{})["original_invoice_number"] = "INV-1"
+	if err := cfg.validateOriginalReference(LogicalDocTypeCreditNote, payload); err == nil {
+		t.Fatalf("expected the default field to be ignored once others are configured")
+	}
+	payload["invoice_data"].(map[string]interface{})["references"] = map[string]interface{}{"invoice_id": "INV-1"}
+	if err := cfg.validateOriginalReference(LogicalDocTypeCreditNote, payload); err != nil {
+		t.Fatalf("expected the configured field to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Helpers for the payload structure analysis returned with each stored payload.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxMappedPayloadDepth Deepest key nesting the mapping is expected to handle;
+// AnalysisResponse.Warnings flags payloads nested deeper than this
+const MaxMappedPayloadDepth = 5
+
+// IsFlat Check if the payload has no nested objects or arrays
+func (a *AnalysisResponse) IsFlat() bool {
+	return a == nil || !a.HasNested
+}
+
+// TopLevelKeys Distinct top-level keys of the payload, in the order reported.
+// Dotted keys such as "invoice_data.seller.name" count as "invoice_data".
+func (a *AnalysisResponse) TopLevelKeys() []string {
+	if a == nil {
+		return nil
+	}
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, key := range a.Keys {
+		topLevel := strings.SplitN(key, ".", 2)[0]
+		if topLevel == "" || seen[topLevel] {
+			continue
+		}
+		seen[topLevel] = true
+		keys = append(keys, topLevel)
+	}
+	return keys
+}
+
+// Depth Deepest nesting among the reported keys, counting dotted segments: 1
+// for a flat payload, 0 when no keys were reported
+func (a *AnalysisResponse) Depth() int {
+	if a == nil {
+		return 0
+	}
+	depth := 0
+	for _, key := range a.Keys {
+		if segments := len(strings.Split(key, ".")); segments > depth {
+			depth = segments
+		}
+	}
+	if depth == 1 && a.HasNested {
+		// Nested without dotted keys: only the top level was reported
+		depth = 2
+	}
+	return depth
+}
+
+// Warnings Structural problems that commonly make mapping fail: an empty
+// payload, a payload without keys, or nesting deeper than
+// MaxMappedPayloadDepth. Empty when nothing looks wrong.
+func (a *AnalysisResponse) Warnings() []string {
+	if a == nil {
+		return nil
+	}
+	warnings := []string{}
+	if a.Size != nil && *a.Size == 0 {
+		warnings = append(warnings, "Payload is empty (size 0); check that the document was serialized before sending")
+	} else if len(a.Keys) == 0 {
+		warnings = append(warnings, "Payload has no keys; the mapping has no fields to read")
+	}
+	if depth := a.Depth(); depth > MaxMappedPayloadDepth {
+		warnings = append(warnings, fmt.Sprintf("Payload is nested %d levels deep; the mapping may not handle structures deeper than %d levels", depth, MaxMappedPayloadDepth))
+	}
+	return warnings
+}
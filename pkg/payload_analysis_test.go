@@ -0,0 +1,71 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeAnalysis(t *testing.T, body string) *AnalysisResponse {
+	t.Helper()
+	analysis := &AnalysisResponse{}
+	if err := json.Unmarshal([]byte(body), analysis); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return analysis
+}
+
+func TestAnalysisOfFlatPayload(t *testing.T) {
+	analysis := decodeAnalysis(t, `{"has_nested": false, "keys": ["invoice_number", "currency", "total"], "size": 3}`)
+
+	if !analysis.IsFlat() || analysis.Depth() != 1 {
+		t.Fatalf("expected a flat payload, got depth %d", analysis.Depth())
+	}
+	if got := analysis.TopLevelKeys(); !reflect.DeepEqual(got, []string{"invoice_number", "currency", "total"}) {
+		t.Fatalf("unexpected top-level keys: %v", got)
+	}
+	if warnings := analysis.Warnings(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+}
+
+func TestAnalysisOfNestedPayload(t *testing.T) {
+	analysis := decodeAnalysis(t, `{
+		"has_nested": true,
+		"keys": ["invoice_data.invoice_number", "invoice_data.seller.name", "invoice_data.seller.address.city", "meta_config"],
+		"size": 4
+	}`)
+
+	if analysis.IsFlat() || analysis.Depth() != 4 {
+		t.Fatalf("expected a payload nested 4 levels, got depth %d", analysis.Depth())
+	}
+	if got := analysis.TopLevelKeys(); !reflect.DeepEqual(got, []string{"invoice_data", "meta_config"}) {
+		t.Fatalf("unexpected top-level keys: %v", got)
+	}
+	if warnings := analysis.Warnings(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+
+	// Nested without dotted keys still counts as more than one level
+	if depth := decodeAnalysis(t, `{"has_nested": true, "keys": ["invoice_data"], "size": 1}`).Depth(); depth != 2 {
+		t.Fatalf("expected depth 2, got %d", depth)
+	}
+}
+
+func TestAnalysisWarnings(t *testing.T) {
+	empty := decodeAnalysis(t, `{"has_nested": false, "keys": [], "size": 0}`)
+	if warnings := empty.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "empty") {
+		t.Fatalf("expected an empty payload warning, got %v", warnings)
+	}
+
+	deep := decodeAnalysis(t, `{"has_nested": true, "keys": ["a.b.c.d.e.f", "a.b"], "size": 2}`)
+	if warnings := deep.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "6 levels") {
+		t.Fatalf("expected a deep nesting warning, got %v", warnings)
+	}
+
+	var missing *AnalysisResponse
+	if !missing.IsFlat() || missing.TopLevelKeys() != nil || missing.Warnings() != nil {
+		t.Fatalf("expected a nil analysis to be safe to use")
+	}
+}
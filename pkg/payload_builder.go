@@ -0,0 +1,174 @@
+/*
+Builder for nested payload maps addressed by JSONPath-style paths.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PayloadBuilder Builds a payload map from values set at paths such as
+// "invoice_data.line_items[0].unit_price", creating the nested objects and
+// arrays on the way. A leading "$" is allowed, and keys containing dots can be
+// quoted in brackets, e.g. "meta['x.y']". Setting a path again replaces the
+// value there.
+type PayloadBuilder struct {
+	payload map[string]interface{}
+	err     error
+}
+
+// payloadPathSegment Object key or array index in a payload path
+type payloadPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// NewPayloadBuilder Create an empty PayloadBuilder
+func NewPayloadBuilder() *PayloadBuilder {
+	return &PayloadBuilder{payload: make(map[string]interface{})}
+}
+
+// Set Set value at path. Maps and slices are copied, so later changes by the
+// caller have no effect. An invalid path, or one that runs through a value
+// that is not an object or array, makes Build fail; the first such error is
+// kept and later calls are ignored.
+func (b *PayloadBuilder) Set(path string, value interface{}) *PayloadBuilder {
+	if b.err != nil {
+		return b
+	}
+	segments, err := parsePayloadPath(path)
+	if err == nil {
+		var root interface{}
+		root, err = setPayloadPathValue(b.payload, segments, deepCopyValue(value))
+		if err == nil {
+			b.payload = root.(map[string]interface{})
+		}
+	}
+	if err != nil {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			fmt.Sprintf("Cannot set payload path %q: %s", path, err.Error()),
+		).WithSuggestion("Use dot-separated keys with [n] for array indexes, e.g. invoice_data.line_items[0].unit_price.")
+		detail.Field = &path
+		b.err = NewSDKError(detail)
+	}
+	return b
+}
+
+// SetInvoiceData Set field of the invoice data object at DefaultInvoiceDataPath
+func (b *PayloadBuilder) SetInvoiceData(field string, value interface{}) *PayloadBuilder {
+	return b.Set(DefaultInvoiceDataPath+"."+field, value)
+}
+
+// SetMeta Set field of the meta object, e.g. "config.validation_only"
+func (b *PayloadBuilder) SetMeta(field string, value interface{}) *PayloadBuilder {
+	return b.Set("meta."+field, value)
+}
+
+// Build Copy of the payload built so far, or the first error from Set
+func (b *PayloadBuilder) Build() (map[string]interface{}, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return deepCopyPayload(b.payload), nil
+}
+
+// parsePayloadPath Split path into object keys and array indexes
+func parsePayloadPath(path string) ([]payloadPathSegment, error) {
+	rest := strings.TrimSpace(path)
+	if strings.HasPrefix(rest, "$") {
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "$"), ".")
+	}
+	if rest == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	var segments []payloadPathSegment
+	expectKey := true
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket")
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, payloadPathSegment{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("array index %q is not a non-negative number", inner)
+				}
+				segments = append(segments, payloadPathSegment{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+			expectKey = false
+		case rest[0] == '.':
+			if expectKey {
+				return nil, fmt.Errorf("empty key")
+			}
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("path ends with a dot")
+			}
+			expectKey = true
+		default:
+			if !expectKey {
+				return nil, fmt.Errorf("missing dot before %q", rest)
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, payloadPathSegment{key: rest[:end]})
+			rest = rest[end:]
+			expectKey = false
+		}
+	}
+	if segments[0].isIndex {
+		return nil, fmt.Errorf("the payload is an object, not an array")
+	}
+	return segments, nil
+}
+
+// setPayloadPathValue Set value below current at segments, creating objects
+// and arrays that are missing, and return the updated current value
+func setPayloadPathValue(current interface{}, segments []payloadPathSegment, value interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+	segment := segments[0]
+	if segment.isIndex {
+		items, ok := current.([]interface{})
+		if !ok && current != nil {
+			return nil, fmt.Errorf("value before [%d] is not an array", segment.index)
+		}
+		for len(items) <= segment.index {
+			items = append(items, nil)
+		}
+		child, err := setPayloadPathValue(items[segment.index], segments[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		items[segment.index] = child
+		return items, nil
+	}
+
+	object, ok := current.(map[string]interface{})
+	if !ok {
+		if current != nil {
+			return nil, fmt.Errorf("value before %q is not an object", segment.key)
+		}
+		object = make(map[string]interface{})
+	}
+	child, err := setPayloadPathValue(object[segment.key], segments[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	object[segment.key] = child
+	return object, nil
+}
@@ -0,0 +1,58 @@
+package complyancesdk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPayloadBuilderBuildsNestedPayload(t *testing.T) {
+	lineItem := map[string]interface{}{"description": "Widget"}
+	payload, err := NewPayloadBuilder().
+		SetInvoiceData("invoice_number", "INV-1").
+		Set("invoice_data.seller.address.city", "Riyadh").
+		Set("$.invoice_data.line_items[1]", lineItem).
+		Set("invoice_data.line_items[1].unit_price", 10.5).
+		Set("invoice_data.line_items[0].quantity", 2).
+		Set("invoice_data['tax.category']", "S").
+		SetMeta("config.validation_only", true).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"invoice_data": map[string]interface{}{
+			"invoice_number": "INV-1",
+			"seller": map[string]interface{}{
+				"address": map[string]interface{}{"city": "Riyadh"},
+			},
+			"line_items": []interface{}{
+				map[string]interface{}{"quantity": 2},
+				map[string]interface{}{"description": "Widget", "unit_price": 10.5},
+			},
+			"tax.category": "S",
+		},
+		"meta": map[string]interface{}{
+			"config": map[string]interface{}{"validation_only": true},
+		},
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Fatalf("unexpected payload:\n got %#v\nwant %#v", payload, want)
+	}
+	if _, changed := lineItem["unit_price"]; changed {
+		t.Fatalf("expected the caller's map to be copied, not modified")
+	}
+}
+
+func TestPayloadBuilderRejectsInvalidPaths(t *testing.T) {
+	for _, path := range []string{"", "invoice_data..total", "items[x]", "items[0", "[0].a", "invoice_number.total"} {
+		_, err := NewPayloadBuilder().
+			Set("invoice_number", "INV-1").
+			Set(path, 1).
+			Build()
+		sdkErr, ok := err.(*SDKError)
+		if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeInvalidArgument || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != path {
+			t.Fatalf("%q: expected INVALID_ARGUMENT naming the path, got %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Request payload size limits for the Complyance SDK.
+*/
+package complyancesdk
+
+import "fmt"
+
+// DefaultMaxPayloadBytes is the largest serialized request the client sends by default
+const DefaultMaxPayloadBytes int64 = 10 << 20
+
+// DefaultMaxBulkPayloadBytes is the largest serialized OperationBulk request the
+// client sends by default
+const DefaultMaxBulkPayloadBytes int64 = 50 << 20
+
+// SetMaxPayloadBytes Limit the size of serialized requests, with bulkMaxBytes
+// applying to OperationBulk requests. Larger requests fail before they are
+// sent instead of being rejected by the gateway. A limit of 0 or less restores
+// DefaultMaxPayloadBytes or DefaultMaxBulkPayloadBytes.
+func (a *APIClient) SetMaxPayloadBytes(maxBytes, bulkMaxBytes int64) {
+	a.maxPayloadBytes = maxBytes
+	a.maxBulkPayloadBytes = bulkMaxBytes
+}
+
+// GetMaxPayloadBytes Effective request size limit for operation
+func (a *APIClient) GetMaxPayloadBytes(operation Operation) int64 {
+	if operation == OperationBulk {
+		if a.maxBulkPayloadBytes <= 0 {
+			return DefaultMaxBulkPayloadBytes
+		}
+		return a.maxBulkPayloadBytes
+	}
+	if a.maxPayloadBytes <= 0 {
+		return DefaultMaxPayloadBytes
+	}
+	return a.maxPayloadBytes
+}
+
+// checkPayloadSize Reject a serialized request larger than the limit for its
+// operation, measured before compression
+func (a *APIClient) checkPayloadSize(request *UnifyRequest, jsonPayload []byte) error {
+	operation := OperationSingle
+	if request.GetOperation() != nil {
+		operation = *request.GetOperation()
+	}
+	maxBytes := a.GetMaxPayloadBytes(operation)
+	size := int64(len(jsonPayload))
+	if size <= maxBytes {
+		return nil
+	}
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidPayloadFormat,
+		fmt.Sprintf("Request payload is %d bytes, over the %d byte limit", size, maxBytes),
+	).WithSuggestion("Split the payload into smaller documents, or raise MaxPayloadBytes in SDKConfig if the API accepts requests this large")
+	detail.AddContextValue("payloadBytes", size)
+	detail.AddContextValue("maxPayloadBytes", maxBytes)
+	detail.AddContextValue("operation", string(operation))
+	detail.Retryable = false
+	return NewSDKError(detail)
+}
@@ -0,0 +1,59 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPayloadLargerThanLimitIsNotSent(t *testing.T) {
+	requests := 0
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	client.SetMaxPayloadBytes(1024, 0)
+
+	request := newTestUnifyRequest("INV-HUGE")
+	request.GetPayload()["notes"] = strings.Repeat("x", 2048)
+	_, err := client.SendUnifyRequest(request)
+	root := rootSDKError(err)
+	if root == nil || *root.ErrorDetail.Code != ErrorCodeInvalidPayloadFormat || !strings.Contains(*root.ErrorDetail.GetMessage(), "over the 1024 byte limit") {
+		t.Fatalf("expected INVALID_PAYLOAD_FORMAT naming the limit, got %v", err)
+	}
+	if size, ok := root.ErrorDetail.Context["payloadBytes"].(int64); !ok || size <= 1024 {
+		t.Fatalf("expected the payload size in the error context, got %v", root.ErrorDetail.Context["payloadBytes"])
+	}
+	if IsRetryable(err) {
+		t.Fatalf("expected the size limit error not to be retryable")
+	}
+	if requests != 0 {
+		t.Fatalf("expected nothing to be sent, got %d requests", requests)
+	}
+}
+
+func TestPayloadWithinLimitIsSent(t *testing.T) {
+	requests := 0
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	client.SetMaxPayloadBytes(1024, 8192)
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-SMALL")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bulk := newTestUnifyRequest("INV-BULK")
+	bulk.Operation = &[]Operation{OperationBulk}[0]
+	bulk.GetPayload()["notes"] = strings.Repeat("x", 2048)
+	if _, err := client.SendUnifyRequest(bulk); err != nil {
+		t.Fatalf("expected bulk requests to use the bulk limit, got %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+	if got := (&APIClient{}).GetMaxPayloadBytes(OperationBulk); got != DefaultMaxBulkPayloadBytes {
+		t.Fatalf("expected default bulk limit %d, got %d", DefaultMaxBulkPayloadBytes, got)
+	}
+}
@@ -4,17 +4,22 @@ Persistent Queue Manager implementation matching Python SDK exactly.
 package complyancesdk
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/retry"
 )
 
 // QueueStatus model matching Python SDK
@@ -23,6 +28,7 @@ type QueueStatus struct {
 	ProcessingCount int  `json:"processing_count"`
 	FailedCount     int  `json:"failed_count"`
 	SuccessCount    int  `json:"success_count"`
+	DeadLetterCount int  `json:"dead_letter_count"`
 	IsRunning       bool `json:"is_running"`
 }
 
@@ -31,6 +37,7 @@ type QueueStatusDetailed struct {
 	ProcessingCount int    `json:"processing_count"`
 	FailedCount     int    `json:"failed_count"`
 	SuccessCount    int    `json:"success_count"`
+	DeadLetterCount int    `json:"dead_letter_count"`
 	TotalCount      int    `json:"total_count"`
 	IsRunning       bool   `json:"is_running"`
 	IsPaused        bool   `json:"is_paused"`
@@ -57,6 +64,11 @@ func (q *QueueStatus) GetSuccessCount() int {
 	return q.SuccessCount
 }
 
+// GetDeadLetterCount getter for dead-letter count
+func (q *QueueStatus) GetDeadLetterCount() int {
+	return q.DeadLetterCount
+}
+
 // IsQueueRunning getter for is running
 func (q *QueueStatus) IsQueueRunning() bool {
 	return q.IsRunning
@@ -64,13 +76,18 @@ func (q *QueueStatus) IsQueueRunning() bool {
 
 // String string representation
 func (q *QueueStatus) String() string {
-	return fmt.Sprintf("QueueStatus{pending=%d, processing=%d, failed=%d, success=%d, running=%t}",
-		q.PendingCount, q.ProcessingCount, q.FailedCount, q.SuccessCount, q.IsRunning)
+	return fmt.Sprintf("QueueStatus{pending=%d, processing=%d, failed=%d, success=%d, deadLetter=%d, running=%t}",
+		q.PendingCount, q.ProcessingCount, q.FailedCount, q.SuccessCount, q.DeadLetterCount, q.IsRunning)
 }
 
 // PersistentSubmissionRecord model matching Python SDK
 type PersistentSubmissionRecord struct {
+	QueueItemID  string                 `json:"queueItemId"`
+	RequestID    string                 `json:"requestId"`
 	Payload      map[string]interface{} `json:"payload"`
+	Attempts     int                    `json:"attemptCount"`
+	LastError    string                 `json:"lastErrorMessage,omitempty"`
+	NextRetryAt  string                 `json:"nextRetryAt,omitempty"`
 	SourceID     string                 `json:"source_id"`
 	Country      string                 `json:"country"`
 	DocumentType string                 `json:"document_type"`
@@ -78,6 +95,31 @@ type PersistentSubmissionRecord struct {
 	Timestamp    int64                  `json:"timestamp"`
 }
 
+// GetQueueItemID getter for queue item ID
+func (p *PersistentSubmissionRecord) GetQueueItemID() string {
+	return p.QueueItemID
+}
+
+// GetRequestID getter for request ID
+func (p *PersistentSubmissionRecord) GetRequestID() string {
+	return p.RequestID
+}
+
+// GetAttempts getter for the number of failed send attempts
+func (p *PersistentSubmissionRecord) GetAttempts() int {
+	return p.Attempts
+}
+
+// GetLastError getter for the error from the last failed attempt
+func (p *PersistentSubmissionRecord) GetLastError() string {
+	return p.LastError
+}
+
+// GetNextRetryAt getter for the earliest time the record is moved back to pending
+func (p *PersistentSubmissionRecord) GetNextRetryAt() string {
+	return p.NextRetryAt
+}
+
 // GetPayload getter for payload
 func (p *PersistentSubmissionRecord) GetPayload() map[string]interface{} {
 	return p.Payload
@@ -110,13 +152,25 @@ func (p *PersistentSubmissionRecord) GetTimestamp() int64 {
 
 // PersistentQueueManager Persistent queue manager matching Python SDK
 type PersistentQueueManager struct {
-	apiKey         string
-	local          bool
-	queueBasePath  string
-	isRunning      bool
-	isPaused       bool
-	processingLock bool
+	apiKey        string
+	local         bool
+	queueBasePath string
+	// isRunning and isPaused are 0 or 1 and accessed atomically, since
+	// processing is started, paused and checked from different goroutines
+	isRunning      int32
+	isPaused       int32
+	processingLock sync.Mutex
 	circuitBreaker *CircuitBreaker
+	store          QueueStore
+	logger         Logger
+	metrics        MetricsSink
+	events         QueueEventHandler
+	maxAttempts    int
+	retryConfig    *RetryConfig
+	apiClient      *APIClient
+	clock          Clock
+
+	documentIDPaths map[DocumentType][]string
 }
 
 const (
@@ -125,18 +179,32 @@ const (
 	ProcessingDir = "processing"
 	FailedDir     = "failed"
 	SuccessDir    = "success"
-)
+	DeadLetterDir = "dead-letter"
+	// InstancesDir holds the default queues of SDKs created with NewSDK
+	InstancesDir = "instances"
 
-// NewPersistentQueueManager creates a new persistent queue manager
-func NewPersistentQueueManager(apiKey string, local bool, circuitBreaker *CircuitBreaker) *PersistentQueueManager {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		log.Printf("Failed to get user home directory: %v", err)
-		homeDir = "."
-	}
+	// DefaultQueueMaxAttempts is how many times a queued submission is sent
+	// before it is moved to dead-letter
+	DefaultQueueMaxAttempts = 5
+
+	// drainPollInterval is how long DrainQueue waits between passes over the queue
+	drainPollInterval = 250 * time.Millisecond
+)
 
-	queueBasePath := filepath.Join(homeDir, QueueDir)
+var (
+	// errQueueItemDeadLettered is returned when a failed item was dead-lettered instead of requeued
+	errQueueItemDeadLettered = errors.New("queue item exceeded max attempts and was dead-lettered")
+	// errQueueItemNotDue is returned when a failed item's nextRetryAt has not passed yet
+	errQueueItemNotDue = errors.New("queue item is not due for retry yet")
+	// errQueueCircuitOpen is returned when the circuit breaker held a queue item
+	// back, e.g. while its half-open probes are in flight; the item stays pending
+	errQueueCircuitOpen = errors.New("circuit breaker held the queue item back")
+)
 
+// NewPersistentQueueManager creates a new persistent queue manager. An optional
+// QueueStore may be supplied; by default items are stored under ~/complyance-queue,
+// and an error is returned if that directory cannot be created.
+func NewPersistentQueueManager(apiKey string, local bool, circuitBreaker *CircuitBreaker, store ...QueueStore) (*PersistentQueueManager, error) {
 	// Use shared circuit breaker or create default
 	if circuitBreaker == nil {
 		circuitBreaker = NewCircuitBreaker(NewCircuitBreakerConfig(3, 60000)) // 3 failures, 1 minute timeout
@@ -145,64 +213,238 @@ func NewPersistentQueueManager(apiKey string, local bool, circuitBreaker *Circui
 	manager := &PersistentQueueManager{
 		apiKey:         apiKey,
 		local:          local,
-		queueBasePath:  queueBasePath,
-		isRunning:      false,
-		isPaused:       false,
-		processingLock: false,
 		circuitBreaker: circuitBreaker,
+		logger:         noopLogger{},
+		metrics:        metricsSinkOrNoop(nil),
+		maxAttempts:    DefaultQueueMaxAttempts,
+		retryConfig:    NewDefaultRetryConfig(),
+		clock:          SystemClock,
 	}
 
-	manager.initializeQueueDirectories()
-	log.Printf("PersistentQueueManager initialized with queue directory: %s", manager.queueBasePath)
+	if len(store) > 0 && store[0] != nil {
+		manager.store = store[0]
+		if fileStore, ok := store[0].(*FileQueueStore); ok {
+			manager.queueBasePath = fileStore.BasePath()
+		}
+		manager.logger.Info("PersistentQueueManager initialized with custom queue store", nil)
+	} else {
+		if err := manager.initializeQueueDirectories(); err != nil {
+			return nil, err
+		}
+		manager.logger.Info("PersistentQueueManager initialized", map[string]interface{}{"queueDirectory": manager.queueBasePath})
+	}
 
 	// Automatically start processing and retry any existing failed submissions
 	manager.StartProcessing()
 	manager.RetryFailedSubmissions()
 
-	return manager
+	return manager, nil
 }
 
-// initializeQueueDirectories Initialize queue directories
-func (p *PersistentQueueManager) initializeQueueDirectories() {
-	dirs := []string{PendingDir, ProcessingDir, FailedDir, SuccessDir}
-	for _, dir := range dirs {
-		dirPath := filepath.Join(p.queueBasePath, dir)
-		if err := os.MkdirAll(dirPath, 0755); err != nil {
-			log.Printf("Failed to create queue directory %s: %v", dirPath, err)
-			panic(fmt.Sprintf("Failed to initialize persistent queue: %v", err))
-		}
+// initializeQueueDirectories Initialize the default file store under the user's home directory
+func (p *PersistentQueueManager) initializeQueueDirectories() error {
+	basePath, err := defaultQueueBasePath()
+	if err != nil {
+		p.logger.Warn("Failed to get user home directory", map[string]interface{}{"error": err.Error()})
+	}
+
+	p.queueBasePath = basePath
+	fileStore, err := openFileQueueStore(p.queueBasePath)
+	if err != nil {
+		p.logger.Error("Failed to initialize persistent queue", map[string]interface{}{"error": err.Error()})
+		return err
 	}
-	log.Println("Queue directories initialized")
+	p.store = fileStore
+	p.logger.Debug("Queue directories initialized", nil)
+	return nil
+}
+
+// defaultQueueBasePath Queue directory under the user's home directory, or under
+// the working directory when the home directory cannot be determined
+func defaultQueueBasePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, QueueDir), err
+}
+
+// instanceQueueName Directory of the default queue of an SDK created with
+// NewSDK: the sanitized InstanceName, or a hash of the API key and environment
+// so the key itself never appears on disk
+func (s *SDKConfig) instanceQueueName() string {
+	if name := unsafeDocumentIDChars.ReplaceAllString(strings.TrimSpace(s.InstanceName), "_"); strings.Trim(name, "._") != "" {
+		return name
+	}
+	sum := sha256.Sum256([]byte(s.APIKey + "|" + string(s.Environment)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// openFileQueueStore File queue store rooted at basePath, with creation failures
+// reported as a non-retryable QUEUE_ERROR
+func openFileQueueStore(basePath string) (*FileQueueStore, error) {
+	fileStore, err := NewFileQueueStore(basePath)
+	if err != nil {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeQueueError,
+			fmt.Sprintf("Failed to initialize persistent queue: %v", err),
+		).WithSuggestion("Point QueueBasePath at a writable directory, or set QueueMode to QueueModeMemory on read-only or ephemeral filesystems such as AWS Lambda.")
+		detail.AddContextValue("queueDirectory", basePath)
+		detail.Retryable = false
+		return nil, NewSDKError(detail)
+	}
+	return fileStore, nil
+}
+
+// GetLogger getter for the logger
+func (p *PersistentQueueManager) GetLogger() Logger {
+	return p.logger
+}
+
+// SetLogger setter for the logger; nil silences the queue
+func (p *PersistentQueueManager) SetLogger(logger Logger) {
+	p.logger = loggerOrNoop(logger)
+}
+
+// SetMetricsSink setter for the sink that receives queue depth gauges
+func (p *PersistentQueueManager) SetMetricsSink(sink MetricsSink) {
+	p.metrics = metricsSinkOrNoop(sink)
+}
+
+// GetEventHandler getter for the queue event handler
+func (p *PersistentQueueManager) GetEventHandler() QueueEventHandler {
+	return p.events
+}
+
+// SetEventHandler setter for the handler notified as submissions move through
+// the queue; nil disables notifications
+func (p *PersistentQueueManager) SetEventHandler(handler QueueEventHandler) {
+	p.events = handler
+}
+
+// GetMaxAttempts getter for the number of attempts before a submission is dead-lettered
+func (p *PersistentQueueManager) GetMaxAttempts() int {
+	return p.maxAttempts
+}
+
+// SetMaxAttempts setter for the number of attempts before a submission is
+// dead-lettered; values below 1 restore DefaultQueueMaxAttempts
+func (p *PersistentQueueManager) SetMaxAttempts(maxAttempts int) {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultQueueMaxAttempts
+	}
+	p.maxAttempts = maxAttempts
+}
+
+// GetRetryConfig getter for the backoff used to schedule failed submissions
+func (p *PersistentQueueManager) GetRetryConfig() *RetryConfig {
+	return p.retryConfig
+}
+
+// SetRetryConfig setter for the backoff used to schedule failed submissions;
+// nil restores the default retry config
+func (p *PersistentQueueManager) SetRetryConfig(config *RetryConfig) {
+	if config == nil {
+		config = NewDefaultRetryConfig()
+	}
+	p.retryConfig = config
+}
+
+// GetAPIClient getter for the client queued submissions are resent with
+func (p *PersistentQueueManager) GetAPIClient() *APIClient {
+	return p.apiClient
+}
+
+// SetAPIClient setter for the client queued submissions are resent with; when
+// nil the client of the SDK set up by Configure is used
+func (p *PersistentQueueManager) SetAPIClient(client *APIClient) {
+	p.apiClient = client
+}
+
+// GetClock getter for the clock used to timestamp and schedule submissions
+func (p *PersistentQueueManager) GetClock() Clock {
+	return p.clock
+}
+
+// SetClock Set the clock used to timestamp and schedule submissions; nil restores SystemClock
+func (p *PersistentQueueManager) SetClock(clock Clock) {
+	p.clock = clockOrSystem(clock)
+}
+
+// sendClient Client to resend queued submissions with, nil when none is available
+func (p *PersistentQueueManager) sendClient() *APIClient {
+	if p.apiClient != nil {
+		return p.apiClient
+	}
+	if sdk := currentSDK(); sdk != nil {
+		return sdk.apiClient
+	}
+	return nil
+}
+
+// reportQueueDepth Publish the current queue depth; skipped when no sink is configured
+func (p *PersistentQueueManager) reportQueueDepth() {
+	if _, ok := p.metrics.(retry.NoopMetricsSink); ok {
+		return
+	}
+	p.GetQueueStatus()
+}
+
+// GetStore getter for the queue storage backend
+func (p *PersistentQueueManager) GetStore() QueueStore {
+	return p.store
 }
 
 // Enqueue a payload submission
 func (p *PersistentQueueManager) Enqueue(submission *PayloadSubmission) error {
-	queueItemID := p.buildQueueItemID(
-		nil,
-		string(submission.GetCountry()),
-		string(submission.GetDocumentType()),
-		submission.GetPayload(),
-	)
-	fileName := queueItemID + ".json"
-	filePath := filepath.Join(p.queueBasePath, PendingDir, fileName)
+	queueItemID, recordJSON, err := p.buildSubmissionRecord(submission)
+	if err != nil {
+		return err
+	}
 
-	if p.existsAcrossQueues(fileName) {
-		return nil // Skip duplicate submission
+	if err := p.store.Enqueue(queueItemID, recordJSON); err != nil {
+		if errors.Is(err, ErrQueueItemExists) {
+			return nil // Skip duplicate submission
+		}
+		return fmt.Errorf("failed to write submission to queue: %v", err)
 	}
 
+	p.notifyEnqueue(recordJSON, queueItemID)
+
+	p.logger.Info("Enqueued submission to persistent storage", map[string]interface{}{
+		"file":    queueItemID + ".json",
+		"source":  submission.GetSource().GetName() + ":" + submission.GetSource().GetVersion(),
+		"country": string(submission.GetCountry()),
+	})
+
+	p.reportQueueDepth()
+
+	// Start processing if not already running
+	p.StartProcessing()
+
+	return nil
+}
+
+// buildSubmissionRecord Queue item ID and stored record of a payload submission
+func (p *PersistentQueueManager) buildSubmissionRecord(submission *PayloadSubmission) (string, []byte, error) {
+	queueItemID := p.submissionQueueItemID(submission)
+
 	// Parse the UnifyRequest JSON string to proper JSON object
 	jsonPayload := submission.GetPayload()
 	if strings.TrimSpace(jsonPayload) == "" || jsonPayload == "{}" {
-		return fmt.Errorf("cannot enqueue empty payload")
+		return "", nil, fmt.Errorf("cannot enqueue empty payload")
 	}
 
 	// Parse the UnifyRequest JSON string to a proper JSON object
 	var unifyRequestMap map[string]interface{}
 	if err := json.Unmarshal([]byte(jsonPayload), &unifyRequestMap); err != nil {
-		return fmt.Errorf("failed to parse UnifyRequest JSON: %v", err)
+		return "", nil, fmt.Errorf("failed to parse UnifyRequest JSON: %v", err)
 	}
+	// Never persist the API key; it is added back from the sending client
+	delete(unifyRequestMap, "apiKey")
+	delete(unifyRequestMap, "api_key")
 
-	now := time.Now().UTC().Format(time.RFC3339)
+	now := p.clock.Now().UTC().Format(time.RFC3339)
 	record := map[string]interface{}{
 		"queueItemId":     queueItemID,
 		"requestId":       unifyRequestMap["requestId"],
@@ -218,32 +460,46 @@ func (p *PersistentQueueManager) Enqueue(submission *PayloadSubmission) error {
 		"country":         string(submission.GetCountry()),
 		"document_type":   string(submission.GetDocumentType()),
 		"enqueued_at":     now,
-		"timestamp":       time.Now().UnixNano() / int64(time.Millisecond),
+		"timestamp":       p.clock.Now().UnixNano() / int64(time.Millisecond),
 	}
 
-	// Write to file
 	recordJSON, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal submission record: %v", err)
-	}
-
-	if err := os.WriteFile(filePath, recordJSON, 0644); err != nil {
-		return fmt.Errorf("failed to write submission to file: %v", err)
+		return "", nil, fmt.Errorf("failed to marshal submission record: %v", err)
 	}
-
-	log.Printf("Enqueued submission to persistent storage: %s for source: %s:%s, country: %s",
-		fileName, submission.GetSource().GetName(), submission.GetSource().GetVersion(), submission.GetCountry())
-
-	// Start processing if not already running
-	p.StartProcessing()
-
-	return nil
+	return queueItemID, recordJSON, nil
 }
 
 func (p *PersistentQueueManager) EnqueueForRetry(request *UnifyRequest, operationName string, errorCode *string, httpStatus *int) error {
 	if request == nil {
 		return nil
 	}
+	queueItemID, recordJSON, err := p.buildRetryRecord(request, operationName, errorCode, httpStatus)
+	if err != nil {
+		return err
+	}
+	if err := p.store.Enqueue(queueItemID, recordJSON); err != nil {
+		if errors.Is(err, ErrQueueItemExists) {
+			return nil
+		}
+		return err
+	}
+	p.notifyEnqueue(recordJSON, queueItemID)
+	p.reportQueueDepth()
+	return nil
+}
+
+// queuedRetry A request that failed with a retryable error, with what
+// EnqueueForRetry records about the failure
+type queuedRetry struct {
+	request       *UnifyRequest
+	operationName string
+	errorCode     *string
+	httpStatus    *int
+}
+
+// buildRetryRecord Queue item ID and stored record of a request to retry
+func (p *PersistentQueueManager) buildRetryRecord(request *UnifyRequest, operationName string, errorCode *string, httpStatus *int) (string, []byte, error) {
 	requestPayload := p.serializeUnifyRequestForQueue(request)
 	requestJSON, _ := json.Marshal(requestPayload)
 	queueItemID := p.buildQueueItemID(
@@ -252,12 +508,7 @@ func (p *PersistentQueueManager) EnqueueForRetry(request *UnifyRequest, operatio
 		p.documentTypeToken(request),
 		string(requestJSON),
 	)
-	fileName := queueItemID + ".json"
-	if p.existsAcrossQueues(fileName) {
-		return nil
-	}
-
-	now := time.Now().UTC().Format(time.RFC3339)
+	now := p.clock.Now().UTC().Format(time.RFC3339)
 	record := map[string]interface{}{
 		"queueItemId":     queueItemID,
 		"requestId":       request.GetRequestID(),
@@ -269,78 +520,43 @@ func (p *PersistentQueueManager) EnqueueForRetry(request *UnifyRequest, operatio
 		"nextRetryAt":     now,
 		"operationName":   operationName,
 		"payload":         requestPayload,
-		"timestamp":       time.Now().UnixNano() / int64(time.Millisecond),
+		"timestamp":       p.clock.Now().UnixNano() / int64(time.Millisecond),
 	}
 	recordJSON, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	return os.WriteFile(filepath.Join(p.queueBasePath, PendingDir, fileName), recordJSON, 0644)
-}
-
-// generateFileName Generate filename for submission
-func (p *PersistentQueueManager) generateFileName(submission *PayloadSubmission) string {
-	// Extract document ID from payload to create unique reference
-	documentID := p.extractDocumentID(submission.GetPayload())
-
-	// Generate filename using source and document ID for unique reference
-	sourceID := fmt.Sprintf("%s:%s", submission.GetSource().GetName(), submission.GetSource().GetVersion())
-	// Replace special characters with underscores
-	re := regexp.MustCompile(`[^a-zA-Z0-9]`)
-	sourceIDClean := re.ReplaceAllString(sourceID, "_")
-	country := string(submission.GetCountry())
-	return fmt.Sprintf("%s_%s_%s_%s.json", sourceIDClean, documentID, country, string(submission.GetDocumentType()))
-}
-
-// extractDocumentID Extract document ID from payload
-func (p *PersistentQueueManager) extractDocumentID(payload string) string {
-	// Parse the complete UnifyRequest JSON
-	var requestMap map[string]interface{}
-	if err := json.Unmarshal([]byte(payload), &requestMap); err != nil {
-		log.Printf("Failed to extract document ID from UnifyRequest payload, using timestamp: %v", err)
-		return fmt.Sprintf("doc_%d", time.Now().UnixNano()/int64(time.Millisecond))
-	}
-
-	// Extract from payload.invoice_data.invoice_number
-	if payloadMap, ok := requestMap["payload"].(map[string]interface{}); ok {
-		if invoiceData, ok := payloadMap["invoice_data"].(map[string]interface{}); ok {
-			if invoiceNumber, ok := invoiceData["invoice_number"].(string); ok {
-				return invoiceNumber
-			}
-		}
-	}
-
-	// Fallback to timestamp if no invoice number found
-	return fmt.Sprintf("doc_%d", time.Now().UnixNano()/int64(time.Millisecond))
+	return queueItemID, recordJSON, nil
 }
 
 // StartProcessing Start processing queue
 func (p *PersistentQueueManager) StartProcessing() {
-	if !p.isRunning {
-		p.isRunning = true
+	if atomic.CompareAndSwapInt32(&p.isRunning, 0, 1) {
 		// Note: In a real implementation, this would start a background goroutine
 		// For now, we'll process on-demand
-		log.Println("Started persistent queue processing")
+		p.logger.Debug("Started persistent queue processing", nil)
 	}
 }
 
 // ProcessPendingSubmissionsNow Manually trigger processing of pending submissions
 func (p *PersistentQueueManager) ProcessPendingSubmissionsNow() {
-	if p.isPaused {
+	if p.paused() {
 		return
 	}
 	// Check circuit breaker state before manual processing
 	if p.circuitBreaker.IsOpen() {
-		currentTime := time.Now().UnixNano() / int64(time.Millisecond)
+		currentTime := p.clock.Now().UnixNano() / int64(time.Millisecond)
 		timeSinceLastFailure := currentTime - p.circuitBreaker.GetLastFailureTime()
 
-		if timeSinceLastFailure < 60000 { // 1 minute = 60000ms
-			remainingTime := 60000 - timeSinceLastFailure
-			log.Printf("🚫 Circuit breaker is OPEN - remaining time: %dms (%d seconds). Manual processing skipped.",
-				remainingTime, remainingTime/1000)
+		timeout := int64(p.circuitBreaker.GetTimeout())
+		if timeSinceLastFailure < timeout {
+			remainingTime := timeout - timeSinceLastFailure
+			p.logger.Warn("Circuit breaker is open, manual processing skipped", map[string]interface{}{
+				"remainingMs": remainingTime,
+			})
 			return
 		} else {
-			log.Printf("✅ Circuit breaker timeout expired (%dms) - proceeding with manual processing", timeSinceLastFailure)
+			p.logger.Info("Circuit breaker timeout expired, proceeding with manual processing", map[string]interface{}{"timeSinceLastFailureMs": timeSinceLastFailure})
 		}
 	}
 
@@ -349,33 +565,37 @@ func (p *PersistentQueueManager) ProcessPendingSubmissionsNow() {
 
 // StopProcessing Stop processing queue
 func (p *PersistentQueueManager) StopProcessing() {
-	p.isRunning = false
-	log.Println("Stopped persistent queue processing")
+	atomic.StoreInt32(&p.isRunning, 0)
+	p.logger.Debug("Stopped persistent queue processing", nil)
+}
+
+// shutdown Stop processing and wait for a pass already over the queue to finish
+func (p *PersistentQueueManager) shutdown() {
+	p.StopProcessing()
+	p.processingLock.Lock()
+	defer p.processingLock.Unlock()
 }
 
 // processPendingSubmissions Process pending submissions
 func (p *PersistentQueueManager) processPendingSubmissions() {
-	if !p.isRunning {
+	if !p.running() {
 		return
 	}
-	if p.isPaused {
+	if p.paused() {
 		return
 	}
 
-	if p.processingLock {
+	// Only one pass over the queue at a time within this process
+	if !p.processingLock.TryLock() {
 		return
 	}
+	defer p.processingLock.Unlock()
+	defer p.reportQueueDepth()
 
-	p.processingLock = true
-	defer func() {
-		p.processingLock = false
-	}()
-
-	// First check if there are any pending files
-	pendingDir := filepath.Join(p.queueBasePath, PendingDir)
-	files, err := filepath.Glob(filepath.Join(pendingDir, "*.json"))
+	// First check if there are any pending items
+	files, err := p.store.List(QueueStatePending)
 	if err != nil {
-		log.Printf("Error reading pending directory: %v", err)
+		p.logger.Error("Error reading pending queue", map[string]interface{}{"error": err.Error()})
 		return
 	}
 
@@ -383,74 +603,124 @@ func (p *PersistentQueueManager) processPendingSubmissions() {
 		return
 	}
 
-	log.Printf("🔄 Found %d pending submissions in queue", len(files))
+	p.logger.Info("Found pending submissions in queue", map[string]interface{}{"count": len(files)})
 
 	// Check circuit breaker state before attempting to process
 	if p.circuitBreaker.IsOpen() {
-		currentTime := time.Now().UnixNano() / int64(time.Millisecond)
+		currentTime := p.clock.Now().UnixNano() / int64(time.Millisecond)
 		timeSinceLastFailure := currentTime - p.circuitBreaker.GetLastFailureTime()
 
-		// Wait for full 1 minute timeout before attempting to process
-		if timeSinceLastFailure < 60000 { // 1 minute = 60000ms
-			remainingTime := 60000 - timeSinceLastFailure
-			log.Printf("🚫 Circuit breaker is OPEN - %d seconds remaining. Queue has %d items waiting.",
-				remainingTime/1000, len(files))
+		// Wait for the breaker's full timeout before attempting to process
+		timeout := int64(p.circuitBreaker.GetTimeout())
+		if timeSinceLastFailure < timeout {
+			remainingTime := timeout - timeSinceLastFailure
+			p.logger.Warn("Circuit breaker is open, queued items waiting", map[string]interface{}{
+				"remainingMs": remainingTime,
+				"waiting":     len(files),
+			})
 			return
 		} else {
-			log.Printf("✅ Circuit breaker timeout expired - attempting to process %d queued items", len(files))
+			p.logger.Info("Circuit breaker timeout expired, processing queued items", map[string]interface{}{"count": len(files)})
 		}
 	}
 
-	// Process each file in the queue
-	for _, filePath := range files {
-		// Check if file still exists before processing
-		if _, err := os.Stat(filePath); err == nil {
-			if err := p.processSubmissionFile(filePath); err != nil {
-				log.Printf("Failed to process queued submission %s: %v", filepath.Base(filePath), err)
-				// Continue processing other files even if one fails
+	// Process each item in the queue
+	for _, queueItemID := range files {
+		if err := p.processQueueItem(queueItemID); err != nil {
+			if errors.Is(err, ErrQueueItemClaimed) {
+				continue
 			}
+			if errors.Is(err, errQueueCircuitOpen) {
+				p.logger.Info("Circuit breaker is holding queued items back", map[string]interface{}{"queueItemId": queueItemID})
+				break
+			}
+			p.logger.Error("Failed to process queued submission", map[string]interface{}{"queueItemId": queueItemID, "error": err.Error()})
+			// Continue processing other items even if one fails
 		}
 	}
 }
 
-// processSubmissionFile Process a single submission file
-func (p *PersistentQueueManager) processSubmissionFile(filePath string) error {
-	fileName := filepath.Base(filePath)
-	processingPath := filepath.Join(p.queueBasePath, ProcessingDir, fileName)
-	if err := os.Rename(filePath, processingPath); err != nil {
-		return err
+// processQueueItem Claim and send a single queued submission
+func (p *PersistentQueueManager) processQueueItem(queueItemID string) error {
+	raw, err := p.store.Claim(queueItemID)
+	if errors.Is(err, ErrQueueItemUnreadable) {
+		p.logger.Warn("Dead-lettered unreadable queued submission", map[string]interface{}{"queueItemId": queueItemID, "error": err.Error()})
+		if p.events != nil {
+			p.events.OnPermanentFailure(decodeSubmissionRecord(nil, queueItemID), err)
+		}
+		return nil
 	}
-
-	raw, err := os.ReadFile(processingPath)
 	if err != nil {
 		return err
 	}
+
 	record := map[string]interface{}{}
 	if err := json.Unmarshal(raw, &record); err != nil {
-		return err
+		return p.failPermanently(queueItemID, raw, record, fmt.Errorf("invalid queued record: %v", err))
 	}
 
 	payloadMap, _ := record["payload"].(map[string]interface{})
 	request := p.mapToUnifyRequest(payloadMap)
 	if request == nil {
-		return p.moveProcessingToFailed(processingPath, record, "invalid queued payload")
+		return p.failPermanently(queueItemID, raw, record, errors.New("invalid queued payload"))
 	}
 
-	if globalSDK == nil || globalSDK.apiClient == nil {
-		return p.moveProcessingToFailed(processingPath, record, "sdk not configured")
+	client := p.sendClient()
+	if client == nil {
+		return p.moveProcessingToFailed(queueItemID, record, "sdk not configured")
+	}
+	if request.GetAPIKey() == nil || *request.GetAPIKey() == "" {
+		request.SetAPIKey(client.apiKey)
 	}
 
-	response, sendErr := globalSDK.apiClient.SendUnifyRequest(request)
-	if sendErr == nil && response != nil && response.GetStatus() == "success" {
-		successPath := filepath.Join(p.queueBasePath, SuccessDir, fileName)
-		return os.Rename(processingPath, successPath)
+	// Send through the circuit breaker so a half-open breaker only lets its
+	// probes through instead of the whole queue
+	sent := false
+	_, sendErr := p.circuitBreaker.Execute(func() (interface{}, error) {
+		sent = true
+		response, err := client.SendUnifyRequest(request)
+		if err == nil && (response == nil || response.GetStatus() != "success") {
+			err = errors.New("non-success response")
+		}
+		return response, err
+	})
+	if !sent {
+		// Put the item back untouched so the attempt is not counted
+		if err := p.store.MarkFailed(queueItemID, raw); err != nil {
+			return err
+		}
+		if err := p.store.Requeue(queueItemID); err != nil {
+			return err
+		}
+		return errQueueCircuitOpen
+	}
+	if sendErr == nil {
+		if err := p.store.MarkSuccess(queueItemID); err != nil {
+			return err
+		}
+		if p.events != nil {
+			p.events.OnSuccess(decodeSubmissionRecord(raw, queueItemID), nil)
+		}
+		return nil
 	}
 
-	errMessage := "non-success response"
-	if sendErr != nil {
-		errMessage = sendErr.Error()
+	return p.moveProcessingToFailed(queueItemID, record, sendErr.Error())
+}
+
+// failPermanently Move a record that can never be sent straight to dead-letter
+// and report it as a permanent failure
+func (p *PersistentQueueManager) failPermanently(queueItemID string, raw []byte, record map[string]interface{}, cause error) error {
+	if err := p.moveProcessingToFailed(queueItemID, record, cause.Error()); err != nil {
+		return err
 	}
-	return p.moveProcessingToFailed(processingPath, record, errMessage)
+	if err := p.store.DeadLetter(queueItemID); err != nil {
+		return err
+	}
+	p.logger.Warn("Dead-lettered unreadable queued submission", map[string]interface{}{"queueItemId": queueItemID, "error": cause.Error()})
+	if p.events != nil {
+		p.events.OnPermanentFailure(decodeSubmissionRecord(raw, queueItemID), cause)
+	}
+	return nil
 }
 
 // GetQueueStatus Get queue status
@@ -459,157 +729,232 @@ func (p *PersistentQueueManager) GetQueueStatus() *QueueStatus {
 	processingCount := p.countFilesInDir(ProcessingDir)
 	failedCount := p.countFilesInDir(FailedDir)
 	successCount := p.countFilesInDir(SuccessDir)
+	deadLetterCount := p.countFilesInDir(DeadLetterDir)
+
+	p.metrics.Gauge(retry.MetricQueueDepth, float64(pendingCount), map[string]string{retry.LabelState: PendingDir})
+	p.metrics.Gauge(retry.MetricQueueDepth, float64(processingCount), map[string]string{retry.LabelState: ProcessingDir})
+	p.metrics.Gauge(retry.MetricQueueDepth, float64(failedCount), map[string]string{retry.LabelState: FailedDir})
+	p.metrics.Gauge(retry.MetricQueueDepth, float64(successCount), map[string]string{retry.LabelState: SuccessDir})
+	p.metrics.Gauge(retry.MetricQueueDepth, float64(deadLetterCount), map[string]string{retry.LabelState: DeadLetterDir})
 
 	return &QueueStatus{
 		PendingCount:    pendingCount,
 		ProcessingCount: processingCount,
 		FailedCount:     failedCount,
 		SuccessCount:    successCount,
-		IsRunning:       p.isRunning,
+		DeadLetterCount: deadLetterCount,
+		IsRunning:       p.running(),
 	}
 }
 
 func (p *PersistentQueueManager) GetQueueStatusDetailed() *QueueStatusDetailed {
 	status := p.GetQueueStatus()
-	total := status.PendingCount + status.ProcessingCount + status.FailedCount + status.SuccessCount
+	total := status.PendingCount + status.ProcessingCount + status.FailedCount + status.SuccessCount + status.DeadLetterCount
 	return &QueueStatusDetailed{
 		PendingCount:    status.PendingCount,
 		ProcessingCount: status.ProcessingCount,
 		FailedCount:     status.FailedCount,
 		SuccessCount:    status.SuccessCount,
+		DeadLetterCount: status.DeadLetterCount,
 		TotalCount:      total,
-		IsRunning:       p.isRunning,
-		IsPaused:        p.isPaused,
+		IsRunning:       p.running(),
+		IsPaused:        p.paused(),
 		QueueDir:        p.queueBasePath,
 	}
 }
 
-// countFilesInDir Count files in a directory
+// GetDeadLetterCount Number of submissions moved to dead-letter
+func (p *PersistentQueueManager) GetDeadLetterCount() int {
+	return p.countFilesInDir(DeadLetterDir)
+}
+
+// ListDeadLetters Records moved to dead-letter, for manual inspection
+func (p *PersistentQueueManager) ListDeadLetters() ([]*PersistentSubmissionRecord, error) {
+	ids, err := p.store.List(QueueStateDeadLetter)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*PersistentSubmissionRecord, 0, len(ids))
+	for _, queueItemID := range ids {
+		raw, err := p.store.Get(QueueStateDeadLetter, queueItemID)
+		if err != nil {
+			continue
+		}
+		records = append(records, decodeSubmissionRecord(raw, queueItemID))
+	}
+	return records, nil
+}
+
+// countFilesInDir Count items in a queue state
 func (p *PersistentQueueManager) countFilesInDir(dirName string) int {
-	dirPath := filepath.Join(p.queueBasePath, dirName)
-	files, err := filepath.Glob(filepath.Join(dirPath, "*.json"))
+	ids, err := p.store.List(QueueState(dirName))
 	if err != nil {
-		log.Printf("Failed to count files in %s: %v", dirName, err)
+		p.logger.Error("Failed to count queue items", map[string]interface{}{"state": dirName, "error": err.Error()})
 		return 0
 	}
-	return len(files)
+	return len(ids)
 }
 
 // RetryFailedSubmissions Retry failed submissions
 func (p *PersistentQueueManager) RetryFailedSubmissions() {
-	failedDir := filepath.Join(p.queueBasePath, FailedDir)
-	pendingDir := filepath.Join(p.queueBasePath, PendingDir)
-
-	files, err := filepath.Glob(filepath.Join(failedDir, "*.json"))
+	files, err := p.store.List(QueueStateFailed)
 	if err != nil {
-		log.Printf("Error reading failed directory: %v", err)
+		p.logger.Error("Error reading failed queue", map[string]interface{}{"error": err.Error()})
 		return
 	}
 
 	if len(files) == 0 {
-		log.Println("No failed submissions to retry")
+		p.logger.Debug("No failed submissions to retry", nil)
 		return
 	}
 
-	log.Printf("Retrying %d failed submissions", len(files))
+	p.logger.Info("Retrying failed submissions", map[string]interface{}{"count": len(files)})
 
-	for _, filePath := range files {
-		fileName := filepath.Base(filePath)
-		pendingPath := filepath.Join(pendingDir, fileName)
+	for _, queueItemID := range files {
+		if err := p.requeueFailed(queueItemID, false); err != nil {
+			if errors.Is(err, errQueueItemNotDue) {
+				continue
+			}
+			if !errors.Is(err, ErrQueueItemExists) && !errors.Is(err, errQueueItemDeadLettered) {
+				p.logger.Warn("Failed to move failed submission back to pending", map[string]interface{}{"queueItemId": queueItemID, "error": err.Error()})
+			}
+		} else {
+			p.logger.Debug("Moved failed submission back to pending", map[string]interface{}{"queueItemId": queueItemID})
+		}
+	}
+}
 
-		if p.existsAcrossQueues(fileName, FailedDir) {
-			_ = os.Remove(filePath)
-			continue
+// requeueFailed Move a failed item back to pending and report the retry. Items
+// that have used up their attempts are moved to dead-letter instead, and unless
+// force is set items whose nextRetryAt is still in the future are left alone.
+func (p *PersistentQueueManager) requeueFailed(queueItemID string, force bool) error {
+	raw, _ := p.store.Get(QueueStateFailed, queueItemID)
+	stored := map[string]interface{}{}
+	_ = json.Unmarshal(raw, &stored)
+	if attempts := readAttemptCount(stored); attempts >= p.maxAttempts {
+		if err := p.store.DeadLetter(queueItemID); err != nil {
+			return err
+		}
+		p.logger.Warn("Queued submission exceeded max attempts, moved to dead-letter", map[string]interface{}{
+			"queueItemId": queueItemID,
+			"attempts":    attempts,
+		})
+		if p.events != nil {
+			p.events.OnPermanentFailure(decodeSubmissionRecord(raw, queueItemID), lastSubmissionError(raw))
 		}
+		return errQueueItemDeadLettered
+	}
 
-		if err := os.Rename(filePath, pendingPath); err != nil {
-			log.Printf("Failed to move failed submission back to pending: %v", err)
-		} else {
-			log.Printf("Moved failed submission back to pending: %s", fileName)
+	if !force {
+		if nextRetryAt, ok := stored["nextRetryAt"].(string); ok {
+			if due, err := time.Parse(time.RFC3339Nano, nextRetryAt); err == nil && p.clock.Now().Before(due) {
+				return errQueueItemNotDue
+			}
 		}
 	}
+
+	if err := p.store.Requeue(queueItemID); err != nil {
+		return err
+	}
+	if p.events != nil {
+		record := decodeSubmissionRecord(raw, queueItemID)
+		p.events.OnRetry(record, lastSubmissionError(raw))
+	}
+	return nil
 }
 
 func (p *PersistentQueueManager) RetryFailed(queueItemID string) bool {
 	if strings.TrimSpace(queueItemID) == "" {
 		return false
 	}
-	fileName := p.findFailedFilenameByQueueItemID(queueItemID)
-	if fileName == "" {
+	storedID := p.findFailedQueueItemID(queueItemID)
+	if storedID == "" {
 		return false
 	}
-	failedPath := filepath.Join(p.queueBasePath, FailedDir, fileName)
-	pendingPath := filepath.Join(p.queueBasePath, PendingDir, fileName)
-	if _, err := os.Stat(failedPath); err != nil {
-		return false
-	}
-	if p.existsAcrossQueues(fileName, FailedDir) {
-		_ = os.Remove(failedPath)
-		return false
-	}
-	return os.Rename(failedPath, pendingPath) == nil
+	return p.requeueFailed(storedID, true) == nil
 }
 
 func (p *PersistentQueueManager) PauseProcessing() {
-	p.isPaused = true
+	atomic.StoreInt32(&p.isPaused, 1)
 }
 
 func (p *PersistentQueueManager) ResumeProcessing() {
-	p.isPaused = false
+	atomic.StoreInt32(&p.isPaused, 0)
 	p.StartProcessing()
 }
 
-func (p *PersistentQueueManager) DrainQueue(timeout time.Duration) bool {
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		status := p.GetQueueStatus()
-		if status.PendingCount == 0 && status.ProcessingCount == 0 {
-			return true
-		}
-		time.Sleep(250 * time.Millisecond)
-	}
-	status := p.GetQueueStatus()
-	return status.PendingCount == 0 && status.ProcessingCount == 0
+// running Whether processing was started and not stopped since
+func (p *PersistentQueueManager) running() bool {
+	return atomic.LoadInt32(&p.isRunning) == 1
 }
 
-// CleanupOldSuccessFiles Clean up old success files
-func (p *PersistentQueueManager) CleanupOldSuccessFiles(daysToKeep int) {
-	successDir := filepath.Join(p.queueBasePath, SuccessDir)
-	cutoffTime := time.Now().AddDate(0, 0, -daysToKeep)
+// paused Whether processing is paused
+func (p *PersistentQueueManager) paused() bool {
+	return atomic.LoadInt32(&p.isPaused) == 1
+}
 
-	files, err := filepath.Glob(filepath.Join(successDir, "*.json"))
-	if err != nil {
-		log.Printf("Error reading success directory: %v", err)
-		return
-	}
+// DrainQueue Process pending and failed submissions until none are left or ctx
+// is done, returning how many are still waiting. Failed submissions are retried
+// on their backoff schedule and an open circuit breaker is waited out, so ctx
+// bounds how long shutdown can take. When ctx ends first the error wraps
+// ctx.Err(). A paused or stopped queue is not processed; the number of
+// submissions waiting is returned straight away. Submissions a crashed process
+// left in processing are moved back to pending on each pass.
+func (p *PersistentQueueManager) DrainQueue(ctx context.Context) (int, error) {
+	for {
+		if p.paused() || !p.running() {
+			return p.remainingCount(), nil
+		}
+		p.recoverAbandonedClaims()
+		p.RetryFailedSubmissions()
+		p.processPendingSubmissions()
 
-	var oldFiles []string
-	for _, filePath := range files {
-		fileInfo, err := os.Stat(filePath)
-		if err != nil {
-			continue
+		remaining := p.remainingCount()
+		if remaining == 0 {
+			return 0, nil
 		}
-		if fileInfo.ModTime().Before(cutoffTime) {
-			oldFiles = append(oldFiles, filePath)
+
+		select {
+		case <-ctx.Done():
+			sdkErr := newContextSDKError(ctx.Err())
+			sdkErr.ErrorDetail.AddContextValue("remaining", remaining)
+			return p.remainingCount(), sdkErr
+		case <-p.clock.After(drainPollInterval):
 		}
 	}
+}
 
-	for _, filePath := range oldFiles {
-		if err := os.Remove(filePath); err != nil {
-			log.Printf("Failed to remove old success file %s: %v", filepath.Base(filePath), err)
-		} else {
-			log.Printf("Cleaned up old success file: %s", filepath.Base(filePath))
-		}
+// recoverAbandonedClaims Move submissions a crashed process left in processing
+// back to pending; only the file store can tell them apart from live claims
+func (p *PersistentQueueManager) recoverAbandonedClaims() {
+	fileStore, ok := p.store.(*FileQueueStore)
+	if !ok {
+		return
+	}
+	if recovered := fileStore.recoverAbandonedClaims(); recovered > 0 {
+		p.logger.Info("Recovered abandoned submissions from processing", map[string]interface{}{"count": recovered})
 	}
+}
+
+// remainingCount Number of submissions still to be sent: pending, processing and failed
+func (p *PersistentQueueManager) remainingCount() int {
+	status := p.GetQueueStatus()
+	return status.PendingCount + status.ProcessingCount + status.FailedCount
+}
 
-	if len(oldFiles) > 0 {
-		log.Printf("Cleaned up %d old success files", len(oldFiles))
+// CleanupOldSuccessFiles Clean up old success files
+func (p *PersistentQueueManager) CleanupOldSuccessFiles(daysToKeep int) {
+	fileStore, ok := p.store.(*FileQueueStore)
+	if !ok {
+		p.logger.Warn("Success file cleanup is only supported by the file queue store", nil)
+		return
 	}
+	fileStore.cleanupOldSuccessFiles(p.clock.Now().AddDate(0, 0, -daysToKeep), p.logger)
 }
 
 // ClearAllQueues Clear all files from the queue (emergency cleanup)
 func (p *PersistentQueueManager) ClearAllQueues() {
-	log.Println("Clearing all queue directories...")
+	p.logger.Info("Clearing all queue directories", nil)
 
 	// Clear pending
 	p.clearDirectory(PendingDir)
@@ -623,106 +968,43 @@ func (p *PersistentQueueManager) ClearAllQueues() {
 	// Clear success
 	p.clearDirectory(SuccessDir)
 
-	log.Println("All queue directories cleared successfully")
+	// Clear dead-letter
+	p.clearDirectory(DeadLetterDir)
+
+	p.logger.Info("All queue directories cleared successfully", nil)
 }
 
-// clearDirectory Clear a specific directory
+// clearDirectory Clear a specific queue state
 func (p *PersistentQueueManager) clearDirectory(dirName string) {
-	dirPath := filepath.Join(p.queueBasePath, dirName)
-	files, err := filepath.Glob(filepath.Join(dirPath, "*.json"))
+	state := QueueState(dirName)
+	ids, err := p.store.List(state)
 	if err != nil {
-		log.Printf("Error reading directory %s: %v", dirName, err)
+		p.logger.Error("Error reading queue directory", map[string]interface{}{"state": dirName, "error": err.Error()})
 		return
 	}
 
-	for _, filePath := range files {
-		if err := os.Remove(filePath); err != nil {
-			log.Printf("Failed to delete file %s: %v", filepath.Base(filePath), err)
+	for _, queueItemID := range ids {
+		if err := p.store.Remove(state, queueItemID); err != nil {
+			p.logger.Warn("Failed to delete queue item", map[string]interface{}{"queueItemId": queueItemID, "error": err.Error()})
 		} else {
-			log.Printf("Deleted file: %s", filepath.Base(filePath))
+			p.logger.Debug("Deleted queue item", map[string]interface{}{"queueItemId": queueItemID})
 		}
 	}
 
-	log.Printf("Cleared %d files from %s", len(files), dirName)
+	p.logger.Info("Cleared queue directory", map[string]interface{}{"state": dirName, "count": len(ids)})
 }
 
 // CleanupDuplicateFiles Clean up duplicate files across queue directories
 func (p *PersistentQueueManager) CleanupDuplicateFiles() {
-	log.Println("Cleaning up duplicate files across queue directories...")
-
-	// Get all files from all directories
-	fileMap := make(map[string]string)
-	queueItemMap := make(map[string]string)
-
-	dirs := []string{PendingDir, ProcessingDir, FailedDir, SuccessDir}
-	for _, dirName := range dirs {
-		dirPath := filepath.Join(p.queueBasePath, dirName)
-		files, err := filepath.Glob(filepath.Join(dirPath, "*.json"))
-		if err != nil {
-			log.Printf("Error reading directory %s: %v", dirName, err)
-			continue
-		}
-
-		for _, filePath := range files {
-			fileName := filepath.Base(filePath)
-			queueItemID := p.readQueueItemIDFromFile(filePath, fileName)
-			dedupeKey := queueItemID
-			if strings.TrimSpace(dedupeKey) == "" {
-				dedupeKey = strings.TrimSuffix(fileName, ".json")
-			}
-			existingFile, exists := queueItemMap[dedupeKey]
-			if !exists {
-				existingFile, exists = fileMap[fileName]
-			}
-
-			if exists {
-				// File exists in multiple directories, keep the one with latest modification time
-				existingInfo, err1 := os.Stat(existingFile)
-				currentInfo, err2 := os.Stat(filePath)
-
-				if err1 != nil || err2 != nil {
-					log.Printf("Could not compare modification times for duplicate file: %s", fileName)
-					// Keep the existing file, delete current
-					os.Remove(filePath)
-					continue
-				}
-
-				if currentInfo.ModTime().After(existingInfo.ModTime()) {
-					// Delete the older file
-					os.Remove(existingFile)
-					queueItemMap[dedupeKey] = filePath
-					fileMap[fileName] = filePath
-					log.Printf("Removed duplicate file (older): %s", existingFile)
-				} else {
-					// Delete the current file
-					os.Remove(filePath)
-					log.Printf("Removed duplicate file (older): %s", filePath)
-				}
-			} else {
-				queueItemMap[dedupeKey] = filePath
-				fileMap[fileName] = filePath
-			}
-		}
+	fileStore, ok := p.store.(*FileQueueStore)
+	if !ok {
+		p.logger.Warn("Duplicate cleanup is only supported by the file queue store", nil)
+		return
 	}
 
-	log.Println("Duplicate file cleanup completed")
-}
-
-func (p *PersistentQueueManager) existsAcrossQueues(fileName string, excludeDir ...string) bool {
-	excluded := ""
-	if len(excludeDir) > 0 {
-		excluded = excludeDir[0]
-	}
-	dirs := []string{PendingDir, ProcessingDir, FailedDir, SuccessDir}
-	for _, dirName := range dirs {
-		if excluded != "" && dirName == excluded {
-			continue
-		}
-		if _, err := os.Stat(filepath.Join(p.queueBasePath, dirName, fileName)); err == nil {
-			return true
-		}
-	}
-	return false
+	p.logger.Info("Cleaning up duplicate files across queue directories", nil)
+	fileStore.cleanupDuplicateFiles(p.logger)
+	p.logger.Info("Duplicate file cleanup completed", nil)
 }
 
 func (p *PersistentQueueManager) buildQueueItemID(requestID *string, country string, documentType string, payload string) string {
@@ -768,9 +1050,6 @@ func (p *PersistentQueueManager) serializeUnifyRequestForQueue(request *UnifyReq
 	if request.GetPurpose() != nil {
 		requestData["purpose"] = string(*request.GetPurpose())
 	}
-	if request.GetAPIKey() != nil {
-		requestData["apiKey"] = *request.GetAPIKey()
-	}
 	if request.GetRequestID() != nil {
 		requestData["requestId"] = *request.GetRequestID()
 	}
@@ -783,6 +1062,9 @@ func (p *PersistentQueueManager) serializeUnifyRequestForQueue(request *UnifyReq
 	if request.GetCorrelationID() != nil {
 		requestData["correlationId"] = *request.GetCorrelationID()
 	}
+	if request.GetIdempotencyKey() != nil {
+		requestData["idempotencyKey"] = *request.GetIdempotencyKey()
+	}
 	if request.GetDocumentTypeV2() == nil || len(request.GetDocumentTypeV2()) == 0 {
 		requestData["documentType"] = strings.ToUpper(string(request.GetDocumentType()))
 	}
@@ -814,6 +1096,7 @@ func (p *PersistentQueueManager) mapToUnifyRequest(payload map[string]interface{
 	timestamp, _ := payload["timestamp"].(string)
 	env, _ := payload["env"].(string)
 	correlationID, _ := payload["correlationId"].(string)
+	idempotencyKey, _ := payload["idempotencyKey"].(string)
 
 	builder := NewUnifyRequestBuilder().
 		Source(source).
@@ -831,6 +1114,9 @@ func (p *PersistentQueueManager) mapToUnifyRequest(payload map[string]interface{
 	if strings.TrimSpace(correlationID) != "" {
 		builder.CorrelationID(correlationID)
 	}
+	if strings.TrimSpace(idempotencyKey) != "" {
+		builder.IdempotencyKey(idempotencyKey)
+	}
 
 	if documentTypeObj, ok := payload["documentType"].(map[string]interface{}); ok {
 		builder.DocumentTypeV2(documentTypeObj)
@@ -844,82 +1130,84 @@ func (p *PersistentQueueManager) mapToUnifyRequest(payload map[string]interface{
 	return builder.Build()
 }
 
-func (p *PersistentQueueManager) moveProcessingToFailed(processingPath string, record map[string]interface{}, reason string) error {
-	fileName := filepath.Base(processingPath)
-	failedPath := filepath.Join(p.queueBasePath, FailedDir, fileName)
-
-	attempts := 1
-	if val, ok := record["attemptCount"]; ok {
-		switch n := val.(type) {
-		case float64:
-			attempts = int(n) + 1
-		case int:
-			attempts = n + 1
-		case string:
-			if parsed, err := strconv.Atoi(n); err == nil {
-				attempts = parsed + 1
-			}
-		}
-	}
+// nextRetryAt Earliest time a submission that has failed attempts times is retried,
+// using the retry config's backoff strategy
+func (p *PersistentQueueManager) nextRetryAt(attempts int) time.Time {
+	delay := time.Duration(backoffDelayMs(p.retryConfig, attempts, 0) * float64(time.Millisecond))
+	return p.clock.Now().Add(delay).UTC()
+}
+
+func (p *PersistentQueueManager) moveProcessingToFailed(queueItemID string, record map[string]interface{}, reason string) error {
+	attempts := readAttemptCount(record) + 1
 
 	record["attemptCount"] = attempts
-	record["lastAttemptAt"] = time.Now().UTC().Format(time.RFC3339)
+	record["lastAttemptAt"] = p.clock.Now().UTC().Format(time.RFC3339)
 	record["lastErrorMessage"] = reason
-	record["nextRetryAt"] = time.Now().Add(time.Duration(min(64, 1<<(attempts-1))) * time.Second).UTC().Format(time.RFC3339)
+	record["nextRetryAt"] = p.nextRetryAt(attempts).Format(time.RFC3339Nano)
 
 	encoded, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(failedPath, encoded, 0644); err != nil {
-		return err
-	}
-	_ = os.Remove(processingPath)
-	return nil
+	return p.store.MarkFailed(queueItemID, encoded)
 }
 
-func (p *PersistentQueueManager) findFailedFilenameByQueueItemID(queueItemID string) string {
+func (p *PersistentQueueManager) findFailedQueueItemID(queueItemID string) string {
 	normalizedID := strings.TrimSuffix(strings.TrimSpace(queueItemID), ".json")
 	if normalizedID == "" {
 		return ""
 	}
 
-	failedDir := filepath.Join(p.queueBasePath, FailedDir)
-	exactName := normalizedID + ".json"
-	if _, err := os.Stat(filepath.Join(failedDir, exactName)); err == nil {
-		return exactName
-	}
-
-	files, err := filepath.Glob(filepath.Join(failedDir, "*.json"))
+	ids, err := p.store.List(QueueStateFailed)
 	if err != nil {
 		return ""
 	}
 
-	for _, filePath := range files {
-		fileName := filepath.Base(filePath)
-		fileStem := strings.TrimSuffix(fileName, ".json")
-		if fileStem == normalizedID || strings.HasPrefix(fileStem, normalizedID) {
-			return fileName
+	for _, storedID := range ids {
+		if storedID == normalizedID {
+			return storedID
+		}
+	}
+
+	for _, storedID := range ids {
+		if strings.HasPrefix(storedID, normalizedID) {
+			return storedID
 		}
 
-		fileQueueID := p.readQueueItemIDFromFile(filePath, fileName)
+		raw, err := p.store.Get(QueueStateFailed, storedID)
+		if err != nil {
+			continue
+		}
+		fileQueueID := readQueueItemID(raw, storedID)
 		if fileQueueID == normalizedID || fileQueueID == strings.TrimSpace(queueItemID) {
-			return fileName
+			return storedID
 		}
 	}
 
 	return ""
 }
 
-func (p *PersistentQueueManager) readQueueItemIDFromFile(filePath string, fallbackFileName string) string {
-	raw, err := os.ReadFile(filePath)
-	if err != nil {
-		return strings.TrimSuffix(fallbackFileName, ".json")
+// readAttemptCount Read the attemptCount from a stored record, which may have been
+// written as a number or a string
+func readAttemptCount(record map[string]interface{}) int {
+	switch n := record["attemptCount"].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case string:
+		if parsed, err := strconv.Atoi(n); err == nil {
+			return parsed
+		}
 	}
+	return 0
+}
 
+// readQueueItemID Read the queueItemId from a stored record, falling back to the storage ID
+func readQueueItemID(raw []byte, fallbackID string) string {
 	var payload map[string]interface{}
 	if err := json.Unmarshal(raw, &payload); err != nil {
-		return strings.TrimSuffix(fallbackFileName, ".json")
+		return fallbackID
 	}
 
 	if value, ok := payload["queueItemId"]; ok && value != nil {
@@ -929,5 +1217,5 @@ func (p *PersistentQueueManager) readQueueItemIDFromFile(filePath string, fallba
 		}
 	}
 
-	return strings.TrimSuffix(fallbackFileName, ".json")
+	return fallbackID
 }
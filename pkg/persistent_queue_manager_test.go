@@ -0,0 +1,754 @@
+package complyancesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newQueueTestServer(t *testing.T, handler http.HandlerFunc) *APIClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewAPIClient("test-key", EnvironmentSandbox, NewNoRetryConfig())
+	client.baseURL = server.URL
+	return client
+}
+
+// newTestQueueManager creates a queue manager, failing the test if it cannot be initialized
+func newTestQueueManager(t *testing.T, store ...QueueStore) *PersistentQueueManager {
+	t.Helper()
+	manager, err := NewPersistentQueueManager("test-key", false, nil, store...)
+	if err != nil {
+		t.Fatalf("queue manager init failed: %v", err)
+	}
+	return manager
+}
+
+// useFakeQueueClock lets tests move a queue manager's clock past retry schedules
+func useFakeQueueClock(manager *PersistentQueueManager) *FakeClock {
+	clock := NewFakeClock(time.Now())
+	manager.SetClock(clock)
+	return clock
+}
+
+func writePendingRecord(t *testing.T, basePath string, requestID string) {
+	t.Helper()
+	record := map[string]interface{}{
+		"queueItemId":  requestID,
+		"requestId":    requestID,
+		"attemptCount": 0,
+		"payload": map[string]interface{}{
+			"country":      "SA",
+			"documentType": "TAX_INVOICE",
+			"requestId":    requestID,
+			"source":       map[string]interface{}{"name": "src", "version": "1"},
+			"payload":      map[string]interface{}{"invoice_data": map[string]interface{}{"invoice_number": requestID}},
+		},
+	}
+	raw, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("marshal record: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(basePath, PendingDir, requestID+".json"), raw, 0644); err != nil {
+		t.Fatalf("write record: %v", err)
+	}
+}
+
+func TestConcurrentQueueManagersProcessEachFileOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		seen[fmt.Sprintf("%v", body["requestId"])]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","message":"ok"}`))
+	})
+
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: client}
+	t.Cleanup(func() { globalSDK = previous })
+
+	first := newTestQueueManager(t)
+	second := newTestQueueManager(t)
+	if first.queueBasePath != second.queueBasePath {
+		t.Fatalf("expected managers to share a queue directory")
+	}
+
+	const total = 25
+	for i := 0; i < total; i++ {
+		writePendingRecord(t, first.queueBasePath, fmt.Sprintf("req-%03d", i))
+	}
+
+	var wg sync.WaitGroup
+	for _, manager := range []*PersistentQueueManager{first, second} {
+		wg.Add(1)
+		go func(m *PersistentQueueManager) {
+			defer wg.Done()
+			m.ProcessPendingSubmissionsNow()
+		}(manager)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != total {
+		t.Fatalf("expected %d distinct submissions, got %d", total, len(seen))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Fatalf("submission %s processed %d times", id, count)
+		}
+	}
+
+	status := first.GetQueueStatus()
+	if status.SuccessCount != total || status.PendingCount != 0 || status.ProcessingCount != 0 {
+		t.Fatalf("unexpected queue status: %s", status.String())
+	}
+}
+
+func TestAbandonedClaimsAreRecoveredButLiveClaimsAreKept(t *testing.T) {
+	if !fileLockingSupported {
+		t.Skip("claims cannot be told apart without advisory locks")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	var mu sync.Mutex
+	var sent []string
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		sent = append(sent, fmt.Sprintf("%v", body["requestId"]))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","message":"ok"}`))
+	})
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: client}
+	t.Cleanup(func() { globalSDK = previous })
+
+	manager := newTestQueueManager(t)
+	for _, id := range []string{"req-crashed", "req-live"} {
+		writePendingRecord(t, manager.queueBasePath, id)
+	}
+	live, err := NewFileQueueStore(manager.queueBasePath)
+	if err != nil {
+		t.Fatalf("file store: %v", err)
+	}
+	crashed, err := NewFileQueueStore(manager.queueBasePath)
+	if err != nil {
+		t.Fatalf("file store: %v", err)
+	}
+	if _, err := live.Claim("req-live"); err != nil {
+		t.Fatalf("live claim failed: %v", err)
+	}
+	if _, err := crashed.Claim("req-crashed"); err != nil {
+		t.Fatalf("crashed claim failed: %v", err)
+	}
+	// A claimer that dies loses its lock and leaves the item in processing
+	crashed.release("req-crashed")
+
+	restarted, err := NewFileQueueStore(manager.queueBasePath)
+	if err != nil {
+		t.Fatalf("restarted store: %v", err)
+	}
+	if pending, _ := restarted.List(QueueStatePending); len(pending) != 1 || pending[0] != "req-crashed" {
+		t.Fatalf("expected the abandoned claim back in pending on start, got %v", pending)
+	}
+	if processing, _ := restarted.List(QueueStateProcessing); len(processing) != 1 || processing[0] != "req-live" {
+		t.Fatalf("expected the live claim to stay in processing, got %v", processing)
+	}
+
+	// Abandon it again while the manager is running; drain picks it up
+	if _, err := crashed.Claim("req-crashed"); err != nil {
+		t.Fatalf("crashed claim failed: %v", err)
+	}
+	crashed.release("req-crashed")
+	if err := live.MarkSuccess("req-live"); err != nil {
+		t.Fatalf("live claimer should still finish its item: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if remaining, err := manager.DrainQueue(ctx); err != nil || remaining != 0 {
+		t.Fatalf("expected drain to send the abandoned claim, got %d, %v", remaining, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 || sent[0] != "req-crashed" {
+		t.Fatalf("expected only the abandoned claim to be sent, got %v", sent)
+	}
+}
+
+func TestConcurrentEnqueueOfSameItemStoresItOnce(t *testing.T) {
+	basePath := t.TempDir()
+	var stores []*FileQueueStore
+	for i := 0; i < 2; i++ {
+		store, err := NewFileQueueStore(basePath)
+		if err != nil {
+			t.Fatalf("file store: %v", err)
+		}
+		stores = append(stores, store)
+	}
+
+	const writers = 64
+	results := make(chan error, writers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results <- stores[i%len(stores)].Enqueue("req-1", []byte(fmt.Sprintf(`{"writer":%d}`, i)))
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(results)
+
+	stored := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			stored++
+		case !errors.Is(err, ErrQueueItemExists):
+			t.Fatalf("unexpected enqueue error: %v", err)
+		}
+	}
+	if stored != 1 {
+		t.Fatalf("expected exactly one writer to store the item, got %d", stored)
+	}
+	entries, _ := os.ReadDir(filepath.Join(basePath, string(QueueStatePending)))
+	if len(entries) != 1 {
+		t.Fatalf("expected only the stored item in pending, got %d entries", len(entries))
+	}
+}
+
+func TestQueueManagerUsesInjectedStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	failFirst := true
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if failFirst {
+			failFirst = false
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","error":{"code":"VALIDATION_FAILED","message":"bad"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: client}
+	t.Cleanup(func() { globalSDK = previous })
+
+	store := NewMemoryQueueStore()
+	manager := newTestQueueManager(t, store)
+	if manager.GetStore() != store {
+		t.Fatalf("expected injected store to be used")
+	}
+
+	request := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload("INV-9")).
+		RequestID("req-memory").
+		Build()
+	if err := manager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	if err := manager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("duplicate enqueue should be ignored, got %v", err)
+	}
+	if status := manager.GetQueueStatus(); status.PendingCount != 1 {
+		t.Fatalf("expected 1 pending item, got %s", status.String())
+	}
+
+	manager.ProcessPendingSubmissionsNow()
+	if status := manager.GetQueueStatus(); status.FailedCount != 1 {
+		t.Fatalf("expected failed item after rejected send, got %s", status.String())
+	}
+	raw, err := store.Get(QueueStateFailed, "req-memory")
+	if err != nil {
+		t.Fatalf("failed item not readable: %v", err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(raw, &record); err != nil || record["attemptCount"] != float64(1) {
+		t.Fatalf("expected attemptCount 1 in failed record, got %v (%v)", record["attemptCount"], err)
+	}
+
+	if !manager.RetryFailed("req-memory") {
+		t.Fatalf("expected RetryFailed to requeue the item")
+	}
+	manager.ProcessPendingSubmissionsNow()
+	if status := manager.GetQueueStatus(); status.SuccessCount != 1 || status.PendingCount != 0 {
+		t.Fatalf("expected item to succeed on retry, got %s", status.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(home, QueueDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected no queue directory to be created when a store is injected")
+	}
+}
+
+func TestQueueDeadLettersRecordsAfterMaxAttempts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sends := 0
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sends++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: client}
+	t.Cleanup(func() { globalSDK = previous })
+
+	manager := newTestQueueManager(t)
+	manager.SetMaxAttempts(2)
+	clock := useFakeQueueClock(manager)
+	writePendingRecord(t, manager.queueBasePath, "req-dead")
+
+	// Two failed attempts use up the budget
+	for i := 0; i < 2; i++ {
+		manager.ProcessPendingSubmissionsNow()
+		clock.Advance(time.Minute)
+		manager.RetryFailedSubmissions()
+	}
+
+	status := manager.GetQueueStatus()
+	if status.DeadLetterCount != 1 || status.PendingCount != 0 || status.FailedCount != 0 {
+		t.Fatalf("expected record in dead-letter, got %s", status.String())
+	}
+	if _, err := os.Stat(filepath.Join(manager.queueBasePath, DeadLetterDir, "req-dead.json")); err != nil {
+		t.Fatalf("expected dead-letter file: %v", err)
+	}
+	if manager.GetDeadLetterCount() != 1 {
+		t.Fatalf("expected dead-letter count 1, got %d", manager.GetDeadLetterCount())
+	}
+
+	records, err := manager.ListDeadLetters()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one dead-lettered record, got %v (%v)", records, err)
+	}
+	if records[0].GetQueueItemID() != "req-dead" || records[0].GetAttempts() != 2 || records[0].GetLastError() == "" {
+		t.Fatalf("unexpected dead-lettered record: %+v", records[0])
+	}
+
+	// Dead-lettered records are never retried again
+	manager.RetryFailedSubmissions()
+	manager.ProcessPendingSubmissionsNow()
+	if manager.RetryFailed("req-dead") {
+		t.Fatalf("dead-lettered record should not be retryable")
+	}
+	if sends != 2 {
+		t.Fatalf("expected 2 sends, got %d", sends)
+	}
+}
+
+func TestQueueWaitsForNextRetryAtBeforeRequeueing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: client}
+	t.Cleanup(func() { globalSDK = previous })
+
+	manager := newTestQueueManager(t)
+	retryConfig := NewDefaultRetryConfig()
+	retryConfig.BaseDelayMs = 10000
+	retryConfig.MaxDelayMs = 60000
+	retryConfig.JitterFactor = 0.1
+	manager.SetRetryConfig(retryConfig)
+	clock := useFakeQueueClock(manager)
+	writePendingRecord(t, manager.queueBasePath, "req-backoff")
+
+	manager.ProcessPendingSubmissionsNow()
+	raw, err := manager.GetStore().Get(QueueStateFailed, "req-backoff")
+	if err != nil {
+		t.Fatalf("expected failed record: %v", err)
+	}
+	record := decodeSubmissionRecord(raw, "req-backoff")
+	scheduled, err := time.Parse(time.RFC3339Nano, record.GetNextRetryAt())
+	if err != nil {
+		t.Fatalf("invalid nextRetryAt %q: %v", record.GetNextRetryAt(), err)
+	}
+	if delay := scheduled.Sub(clock.now); delay < 9*time.Second || delay > 11*time.Second {
+		t.Fatalf("expected ~10s backoff with jitter after the first attempt, got %s", delay)
+	}
+
+	// Not due yet: the record stays in failed
+	clock.Advance(8 * time.Second)
+	manager.RetryFailedSubmissions()
+	if status := manager.GetQueueStatus(); status.FailedCount != 1 || status.PendingCount != 0 {
+		t.Fatalf("record retried before its scheduled time: %s", status.String())
+	}
+
+	// An explicit retry ignores the schedule
+	if !manager.RetryFailed("req-backoff") {
+		t.Fatalf("expected manual retry to requeue the record")
+	}
+	manager.ProcessPendingSubmissionsNow()
+
+	// The second failure backs off further
+	raw, _ = manager.GetStore().Get(QueueStateFailed, "req-backoff")
+	record = decodeSubmissionRecord(raw, "req-backoff")
+	scheduled, _ = time.Parse(time.RFC3339Nano, record.GetNextRetryAt())
+	if delay := scheduled.Sub(clock.now); delay < 18*time.Second || delay > 22*time.Second {
+		t.Fatalf("expected ~20s backoff after the second attempt, got %s", delay)
+	}
+	clock.Advance(25 * time.Second)
+	manager.RetryFailedSubmissions()
+	if status := manager.GetQueueStatus(); status.PendingCount != 1 || status.FailedCount != 0 {
+		t.Fatalf("expected record requeued once due: %s", status.String())
+	}
+}
+
+// unwritableHome points HOME at a regular file so the default file queue cannot be created
+func unwritableHome(t *testing.T) string {
+	t.Helper()
+	home := filepath.Join(t.TempDir(), "home")
+	if err := os.WriteFile(home, []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("write home file: %v", err)
+	}
+	t.Setenv("HOME", home)
+	return home
+}
+
+func TestMemoryQueueModeProcessesWithoutDisk(t *testing.T) {
+	unwritableHome(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	if err := Configure(cfg); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	globalSDK.apiClient.baseURL = server.URL
+
+	manager := globalSDK.queueManager
+	if _, ok := manager.GetStore().(*MemoryQueueStore); !ok || manager.queueBasePath != "" {
+		t.Fatalf("expected an in-memory store, got %T at %q", manager.GetStore(), manager.queueBasePath)
+	}
+
+	request := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload("INV-LAMBDA")).
+		RequestID("req-lambda").
+		Build()
+	if err := manager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	manager.ProcessPendingSubmissionsNow()
+	if status := manager.GetQueueStatus(); status.SuccessCount != 1 || status.PendingCount != 0 {
+		t.Fatalf("expected the item to be processed, got %s", status.String())
+	}
+}
+
+func TestQueueInitFailureIsReturnedInsteadOfPanicking(t *testing.T) {
+	unwritableHome(t)
+
+	if manager, err := NewPersistentQueueManager("test-key", false, nil); err == nil || manager != nil {
+		t.Fatalf("expected NewPersistentQueueManager to fail, got %v", err)
+	}
+
+	previous := globalSDK
+	t.Cleanup(func() { globalSDK = previous })
+	err := Configure(NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()))
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeQueueError {
+		t.Fatalf("expected a queue error from Configure, got %v", err)
+	}
+	if globalSDK != nil {
+		t.Fatalf("expected a failed Configure to leave the SDK unconfigured")
+	}
+}
+
+func TestQueueBasePathIsolatesQueueDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	basePath := filepath.Join(t.TempDir(), "service-a")
+
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueBasePath(basePath)
+	if err := Configure(cfg); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	request := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload("INV-ISOLATED")).
+		RequestID("req-isolated").
+		Build()
+	if err := globalSDK.queueManager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	pending, err := filepath.Glob(filepath.Join(basePath, PendingDir, "*.json"))
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected one pending file under %s, got %v (%v)", basePath, pending, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, QueueDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing under the home directory, got %v", err)
+	}
+	if dir := globalSDK.queueManager.GetQueueStatusDetailed().QueueDir; dir != basePath {
+		t.Fatalf("expected queue status to report %s, got %s", basePath, dir)
+	}
+}
+
+func TestQueueWaitsForConfiguredCircuitBreakerTimeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var mu sync.Mutex
+	sent := 0
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	clock := NewFakeClock(time.Now())
+	breaker := NewCircuitBreaker(NewCircuitBreakerConfig(1, 5000))
+	breaker.SetClock(clock)
+	manager, err := NewPersistentQueueManager("test-key", false, breaker)
+	if err != nil {
+		t.Fatalf("queue manager init failed: %v", err)
+	}
+	manager.SetClock(clock)
+	manager.SetAPIClient(client)
+
+	_, _ = breaker.Execute(func() (interface{}, error) { return nil, fmt.Errorf("boom") })
+	if !breaker.IsOpen() {
+		t.Fatalf("expected the breaker to be open")
+	}
+	writePendingRecord(t, manager.queueBasePath, "req-breaker")
+
+	sentCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent
+	}
+
+	clock.Advance(4999 * time.Millisecond)
+	manager.ProcessPendingSubmissionsNow()
+	if sentCount() != 0 {
+		t.Fatalf("expected the queue to wait while the breaker is open")
+	}
+
+	clock.Advance(time.Millisecond)
+	manager.ProcessPendingSubmissionsNow()
+	if sentCount() != 1 {
+		t.Fatalf("expected the queue to resume at the 5s breaker timeout, sent %d", sentCount())
+	}
+	if status := manager.GetQueueStatus(); status.SuccessCount != 1 || status.PendingCount != 0 {
+		t.Fatalf("unexpected queue status: %s", status.String())
+	}
+}
+
+func TestDrainQueueSendsEverySubmission(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var mu sync.Mutex
+	calls := 0
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","error":{"code":"VALIDATION_FAILED","message":"bad"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	manager := newTestQueueManager(t)
+	manager.SetAPIClient(client)
+	retryConfig := NewDefaultRetryConfig()
+	retryConfig.BaseDelayMs = 1
+	retryConfig.MaxDelayMs = 1
+	manager.SetRetryConfig(retryConfig)
+
+	for i := 0; i < 4; i++ {
+		writePendingRecord(t, manager.queueBasePath, fmt.Sprintf("req-drain-%d", i))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	remaining, err := manager.DrainQueue(ctx)
+	if err != nil || remaining != 0 {
+		t.Fatalf("expected the queue to drain, got %d remaining, %v", remaining, err)
+	}
+	if status := manager.GetQueueStatus(); status.PendingCount != 0 || status.FailedCount != 0 || status.SuccessCount != 4 {
+		t.Fatalf("unexpected queue status: %s", status.String())
+	}
+}
+
+func TestDrainQueueStopsWhenContextExpires(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","error":{"code":"VALIDATION_FAILED","message":"bad"}}`))
+	})
+	manager := newTestQueueManager(t)
+	manager.SetAPIClient(client)
+	writePendingRecord(t, manager.queueBasePath, "req-stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	remaining, err := manager.DrainQueue(ctx)
+	if remaining != 1 {
+		t.Fatalf("expected 1 submission left, got %d", remaining)
+	}
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeTimeoutError {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestDrainQueueReturnsAtOnceWhenPausedOrStopped(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	manager := newTestQueueManager(t)
+	writePendingRecord(t, manager.queueBasePath, "req-held")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for name, hold := range map[string]func(){"paused": manager.PauseProcessing, "stopped": manager.StopProcessing} {
+		manager.ResumeProcessing()
+		hold()
+		begin := time.Now()
+		remaining, err := manager.DrainQueue(ctx)
+		if err != nil || remaining != 1 {
+			t.Fatalf("%s: expected 1 submission left and no error, got %d, %v", name, remaining, err)
+		}
+		if elapsed := time.Since(begin); elapsed > time.Second {
+			t.Fatalf("%s: expected drain to return at once, took %s", name, elapsed)
+		}
+	}
+}
+
+func TestDrainQueueDeadLettersUndecryptableRecords(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	basePath := t.TempDir()
+	writer, _ := NewFileQueueStore(basePath)
+	if err := writer.SetEncryptionKey(testQueueEncryptionKey); err != nil {
+		t.Fatalf("set key failed: %v", err)
+	}
+	if err := writer.Enqueue("req-other-key", []byte(`{"queueItemId":"req-other-key"}`)); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	sealed, _ := os.ReadFile(writer.itemPath(QueueStatePending, "req-other-key"))
+
+	store, _ := NewFileQueueStore(basePath)
+	if err := store.SetEncryptionKey(bytes.Repeat([]byte{0x24}, 32)); err != nil {
+		t.Fatalf("set key failed: %v", err)
+	}
+	manager := newTestQueueManager(t, store)
+	manager.SetAPIClient(client)
+	events := &recordingQueueEventHandler{}
+	manager.SetEventHandler(events)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if remaining, err := manager.DrainQueue(ctx); err != nil || remaining != 0 {
+		t.Fatalf("expected the unreadable record not to hold the drain up, got %d, %v", remaining, err)
+	}
+	if ids, _ := store.List(QueueStateDeadLetter); len(ids) != 1 || ids[0] != "req-other-key" {
+		t.Fatalf("expected the unreadable record in dead-letter, got %v", ids)
+	}
+	if kept, _ := os.ReadFile(store.itemPath(QueueStateDeadLetter, "req-other-key")); !bytes.Equal(kept, sealed) {
+		t.Fatalf("expected the dead-lettered record to be kept as stored")
+	}
+	if kinds := events.kinds(); len(kinds) != 1 || kinds[0] != "permanent_failure" || events.events[0].record.QueueItemID != "req-other-key" {
+		t.Fatalf("expected one permanent failure event, got %v", kinds)
+	}
+}
+
+func TestQueuedFilesNeverContainAPIKey(t *testing.T) {
+	const apiKey = "ak_test_secret_0123456789"
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+
+	var mu sync.Mutex
+	var authorizations []string
+	configureTestSDK(t, NewSDKConfig(apiKey, EnvironmentSandbox, sources, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		first := len(authorizations) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+		PurposeInvoicing, testInvoicePayload("INV-KEY"), []*Destination{}); err != nil {
+		t.Fatalf("expected the failure to be queued, got %v", err)
+	}
+
+	request := NewUnifyRequest()
+	request.SetAPIKey(apiKey)
+	encoded, _ := json.Marshal(request)
+	if err := globalSDK.queueManager.Enqueue(NewPayloadSubmission(string(encoded), sources[0], CountrySA, DocumentTypeTaxInvoice)); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	queueDir := globalSDK.queueManager.queueBasePath
+	files := 0
+	err := filepath.Walk(queueDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files++
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(raw), apiKey) {
+			t.Fatalf("queued file %s contains the API key", path)
+		}
+		return nil
+	})
+	if err != nil || files != 2 {
+		t.Fatalf("expected 2 queued files, got %d, %v", files, err)
+	}
+
+	globalSDK.queueManager.ProcessPendingSubmissionsNow()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(authorizations) < 2 || authorizations[1] != "Bearer "+apiKey {
+		t.Fatalf("expected the resent submission to carry the client's API key, got %v", authorizations)
+	}
+}
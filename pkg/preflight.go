@@ -0,0 +1,128 @@
+/*
+Connectivity and credential check that submits nothing.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PreflightPath Endpoint called by Preflight, relative to the environment base URL
+const PreflightPath = "/api/v3/health"
+
+// Response headers Preflight reads the server version and environment from
+// when the body does not carry them
+const (
+	serverVersionHeader     = "X-API-Version"
+	serverEnvironmentHeader = "X-Environment"
+)
+
+// PreflightResult Outcome of Preflight
+type PreflightResult struct {
+	// Reachable is true when the endpoint answered with any HTTP status
+	Reachable bool `json:"reachable"`
+	// Authenticated is true when the endpoint accepted the API key
+	Authenticated bool `json:"authenticated"`
+	StatusCode    int  `json:"status_code,omitempty"`
+	// Environment is the environment reported by the server, empty when it reports none
+	Environment           string        `json:"environment,omitempty"`
+	ConfiguredEnvironment Environment   `json:"configured_environment"`
+	ServerVersion         string        `json:"server_version,omitempty"`
+	Latency               time.Duration `json:"latency"`
+	// Message describes why the check failed, empty when it passed
+	Message string `json:"message,omitempty"`
+}
+
+// OK Check if the endpoint was reachable and accepted the API key
+func (p *PreflightResult) OK() bool {
+	return p != nil && p.Reachable && p.Authenticated
+}
+
+// Preflight Check that the API is reachable and accepts the API key, without
+// submitting a document. Uses the SDK set up by Configure.
+func Preflight(ctx context.Context) (*PreflightResult, error) {
+	return currentSDK().Preflight(ctx)
+}
+
+// Preflight Check that the API is reachable and accepts the API key, without
+// submitting a document. An unreachable endpoint or a rejected key is reported
+// in the result; the error is only set when the SDK is not configured or ctx
+// is done.
+func (s *GETSUnifySDK) Preflight(ctx context.Context) (*PreflightResult, error) {
+	if s == nil || s.apiClient == nil || s.config == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+	result, err := s.apiClient.Preflight(ctx)
+	if result != nil {
+		result.ConfiguredEnvironment = s.config.Environment
+	}
+	return result, err
+}
+
+// Preflight Call PreflightPath once, bypassing the retry strategy and circuit breaker
+func (a *APIClient) Preflight(ctx context.Context) (*PreflightResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", a.serviceURL(PreflightPath), nil)
+	if err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeNetworkError,
+			fmt.Sprintf("Failed to create HTTP request: %v", err),
+		))
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	req.Header.Set("X-API-Key", a.apiKey)
+
+	result := &PreflightResult{}
+	started := a.clock.Now()
+	resp, err := a.doRequest(req)
+	result.Latency = a.clock.Now().Sub(started)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, newContextSDKError(ctx.Err())
+		}
+		result.Message = fmt.Sprintf("Network error: %v", err)
+		return result, nil
+	}
+	defer resp.Body.Close()
+
+	result.Reachable = true
+	result.StatusCode = resp.StatusCode
+	result.ServerVersion = resp.Header.Get(serverVersionHeader)
+	result.Environment = resp.Header.Get(serverEnvironmentHeader)
+
+	body, _ := a.readResponseBody(resp.Body)
+	var parsed map[string]interface{}
+	if json.Unmarshal(body, &parsed) == nil {
+		section := parsed
+		if data, ok := parsed["data"].(map[string]interface{}); ok {
+			section = data
+		}
+		if version := bulkString(section, "version", "serverVersion", "server_version"); version != "" {
+			result.ServerVersion = version
+		}
+		if environment := bulkString(section, "environment", "env"); environment != "" {
+			result.Environment = environment
+		}
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		result.Authenticated = true
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Message = fmt.Sprintf("API key was rejected with status %d", resp.StatusCode)
+	default:
+		result.Message = fmt.Sprintf("Preflight request failed with status %d", resp.StatusCode)
+		if text := strings.TrimSpace(string(body)); text != "" && parsed == nil {
+			result.Message += ": " + text
+		}
+	}
+	return result, nil
+}
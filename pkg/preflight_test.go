@@ -0,0 +1,64 @@
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestPreflightReportsHealthyEndpoint(t *testing.T) {
+	var path, apiKey string
+	configureTestSDK(t, NewSDKConfig("ak_test_key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey = r.URL.Path, r.Header.Get("X-API-Key")
+		w.Header().Set("X-API-Version", "3.4.1")
+		_, _ = w.Write([]byte(`{"status":"ok","data":{"environment":"sandbox"}}`))
+	})
+
+	result, err := Preflight(context.Background())
+	if err != nil {
+		t.Fatalf("Preflight failed: %v", err)
+	}
+	if path != PreflightPath || apiKey != "ak_test_key" {
+		t.Fatalf("expected an authenticated call to %s, got %s with key %q", PreflightPath, path, apiKey)
+	}
+	if !result.OK() || !result.Reachable || !result.Authenticated || result.StatusCode != http.StatusOK {
+		t.Fatalf("expected a passing preflight, got %+v", result)
+	}
+	if result.Environment != "sandbox" || result.ConfiguredEnvironment != EnvironmentSandbox {
+		t.Fatalf("expected sandbox environment, got %q (configured %q)", result.Environment, result.ConfiguredEnvironment)
+	}
+	if result.ServerVersion != "3.4.1" || result.Message != "" {
+		t.Fatalf("expected server version 3.4.1 and no message, got %+v", result)
+	}
+}
+
+func TestPreflightReportsRejectedAPIKey(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("ak_test_revoked", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"status":"error","error":{"code":"AUTHENTICATION_FAILED","message":"Invalid API key"}}`))
+	})
+
+	result, err := Preflight(context.Background())
+	if err != nil {
+		t.Fatalf("expected a rejected key to be reported in the result, got %v", err)
+	}
+	if result.OK() || !result.Reachable || result.Authenticated || result.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a reachable endpoint rejecting the key, got %+v", result)
+	}
+	if result.Message == "" {
+		t.Fatalf("expected a message explaining the failure")
+	}
+}
+
+func TestPreflightReportsUnreachableEndpoint(t *testing.T) {
+	server := configureTestSDK(t, NewSDKConfig("ak_test_key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {})
+	server.Close()
+
+	result, err := Preflight(context.Background())
+	if err != nil {
+		t.Fatalf("expected an unreachable endpoint to be reported in the result, got %v", err)
+	}
+	if result.Reachable || result.Authenticated || result.Message == "" {
+		t.Fatalf("expected an unreachable result with a message, got %+v", result)
+	}
+}
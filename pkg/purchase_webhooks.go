@@ -7,15 +7,20 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 )
 
 // ListPurchaseInvoices fetches purchase invoices from the documents API.
+// Uses the SDK set up by Configure.
 func ListPurchaseInvoices(filters map[string]string) (map[string]interface{}, error) {
-	if globalSDK == nil || globalSDK.config == nil {
+	return currentSDK().ListPurchaseInvoices(filters)
+}
+
+// ListPurchaseInvoices fetches purchase invoices from the documents API.
+func (s *GETSUnifySDK) ListPurchaseInvoices(filters map[string]string) (map[string]interface{}, error) {
+	if s == nil || s.config == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDK not configured",
@@ -30,11 +35,17 @@ func ListPurchaseInvoices(filters map[string]string) (map[string]interface{}, er
 		}
 	}
 
-	return getJSON(fmt.Sprintf("/documents?%s", query.Encode()))
+	return s.getJSON(fmt.Sprintf("/documents?%s", query.Encode()))
 }
 
 // GetPurchaseInvoice fetches a single purchase invoice from the documents API.
+// Uses the SDK set up by Configure.
 func GetPurchaseInvoice(id string) (map[string]interface{}, error) {
+	return currentSDK().GetPurchaseInvoice(id)
+}
+
+// GetPurchaseInvoice fetches a single purchase invoice from the documents API.
+func (s *GETSUnifySDK) GetPurchaseInvoice(id string) (map[string]interface{}, error) {
 	if strings.TrimSpace(id) == "" {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
@@ -42,7 +53,7 @@ func GetPurchaseInvoice(id string) (map[string]interface{}, error) {
 		))
 	}
 
-	return getJSON(fmt.Sprintf("/documents/%s?type=purchases", url.PathEscape(id)))
+	return s.getJSON(fmt.Sprintf("/documents/%s?type=purchases", url.PathEscape(id)))
 }
 
 // VerifyWebhookSignature verifies an inbound webhook signature using constant-time comparison.
@@ -87,15 +98,15 @@ func VerifyWebhookSignature(payload string, signature string, secret string, alg
 	return true, nil
 }
 
-func getJSON(path string) (map[string]interface{}, error) {
-	if globalSDK == nil || globalSDK.config == nil {
+func (s *GETSUnifySDK) getJSON(path string) (map[string]interface{}, error) {
+	if s == nil || s.config == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDK not configured",
 		))
 	}
 
-	request, err := http.NewRequest("GET", resolveServiceURL(path), nil)
+	request, err := http.NewRequest("GET", s.resolveServiceURL(path), nil)
 	if err != nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
@@ -104,10 +115,10 @@ func getJSON(path string) (map[string]interface{}, error) {
 	}
 
 	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", globalSDK.config.APIKey))
-	request.Header.Set("X-API-Key", globalSDK.config.APIKey)
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.APIKey))
+	request.Header.Set("X-API-Key", s.config.APIKey)
 
-	response, err := globalSDK.apiClient.httpClient.Do(request)
+	response, err := s.apiClient.doRequest(request)
 	if err != nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeNetworkError,
@@ -116,12 +127,9 @@ func getJSON(path string) (map[string]interface{}, error) {
 	}
 	defer response.Body.Close()
 
-	responseBody, err := io.ReadAll(response.Body)
+	responseBody, err := s.apiClient.readResponseBody(response.Body)
 	if err != nil {
-		return nil, NewSDKError(NewErrorDetailWithCode(
-			ErrorCodeAPIError,
-			fmt.Sprintf("Failed to read purchase invoice response: %v", err),
-		))
+		return nil, err
 	}
 
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
@@ -146,8 +154,8 @@ func getJSON(path string) (map[string]interface{}, error) {
 	return parsed, nil
 }
 
-func resolveServiceURL(path string) string {
-	baseURL := globalSDK.config.Environment.GetBaseURL()
+func (s *GETSUnifySDK) resolveServiceURL(path string) string {
+	baseURL := s.config.GetBaseURL()
 	normalizedBase := strings.TrimSuffix(baseURL, "/unify")
 	if strings.HasPrefix(path, "/") {
 		return normalizedBase + path
@@ -0,0 +1,241 @@
+/*
+Atomic enqueueing of several submissions at once.
+*/
+package complyancesdk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// queueBatchDirPrefix Prefix of the staging directories of FileQueueStore.EnqueueBatch
+	queueBatchDirPrefix = ".batch-"
+	// queueBatchLockFile File in a staging directory locked while its batch is written
+	queueBatchLockFile = ".lock"
+	// staleQueueBatchAge How long a staging directory must be untouched before
+	// NewFileQueueStore considers it left behind by a crash
+	staleQueueBatchAge = time.Hour
+)
+
+// writeQueueBatchFile Writes the staged records of FileQueueStore.EnqueueBatch;
+// a variable so tests can make a write fail part way through a batch
+var writeQueueBatchFile = os.WriteFile
+
+// BatchQueueStore QueueStore that can store several new pending items at once.
+// PersistentQueueManager uses it for EnqueueBatch when the store implements
+// it; other stores get the items one by one, with the written ones removed
+// again on failure.
+type BatchQueueStore interface {
+	QueueStore
+	// EnqueueBatch stores new pending items, skipping IDs already pending,
+	// processing or failed, and returns the IDs it stored. On error the items
+	// already stored are removed again, except any a worker claimed meanwhile.
+	EnqueueBatch(ids []string, records [][]byte) ([]string, error)
+}
+
+// EnqueueBatch writes every record to a staging directory next to the state
+// directories and links them into pending only once all writes succeeded, so
+// a failed write stores nothing. A link never replaces an item another writer
+// put in pending meanwhile; that item is kept and left out of the returned
+// IDs. If a link fails otherwise, the items already linked are removed again;
+// the links are made one by one, so a worker may claim one of them before
+// that, and it is then sent rather than removed. The staging directory is locked
+// while the batch is written, and staging directories left behind by a crash
+// are removed by NewFileQueueStore.
+func (s *FileQueueStore) EnqueueBatch(ids []string, records [][]byte) ([]string, error) {
+	if len(ids) != len(records) {
+		return nil, fmt.Errorf("got %d queue item IDs for %d records", len(ids), len(records))
+	}
+	stagingDir, err := os.MkdirTemp(s.basePath, queueBatchDirPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+	lockHandle, err := os.Create(filepath.Join(stagingDir, queueBatchLockFile))
+	if err != nil {
+		return nil, err
+	}
+	defer lockHandle.Close()
+	if err := tryLockFile(lockHandle); err != nil {
+		return nil, err
+	}
+	defer unlockFile(lockHandle)
+
+	var staged []string
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] || s.exists(id) {
+			continue
+		}
+		seen[id] = true
+		sealed, err := s.sealRecord(id, records[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQueueBatchFile(filepath.Join(stagingDir, id+".json"), sealed, 0644); err != nil {
+			return nil, err
+		}
+		staged = append(staged, id)
+	}
+
+	var moved []string
+	for _, id := range staged {
+		if err := os.Link(filepath.Join(stagingDir, id+".json"), s.itemPath(QueueStatePending, id)); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			for _, movedID := range moved {
+				os.Remove(s.itemPath(QueueStatePending, movedID))
+			}
+			return nil, err
+		}
+		moved = append(moved, id)
+	}
+	return moved, nil
+}
+
+// removeStaleBatches Remove staging directories of batches interrupted before
+// their records were moved into pending. A directory is only removed once it
+// has been untouched for staleQueueBatchAge and its lock is not held, so
+// batches another process is writing into a shared queue directory are kept.
+func (s *FileQueueStore) removeStaleBatches() {
+	dirs, _ := filepath.Glob(filepath.Join(s.basePath, queueBatchDirPrefix+"*"))
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || time.Since(info.ModTime()) < staleQueueBatchAge {
+			continue
+		}
+		if lockHandle, err := os.Open(filepath.Join(dir, queueBatchLockFile)); err == nil {
+			locked := tryLockFile(lockHandle) != nil
+			if !locked {
+				_ = unlockFile(lockHandle)
+			}
+			lockHandle.Close()
+			if locked {
+				continue
+			}
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// EnqueueBatch stores new pending items under a single lock
+func (m *MemoryQueueStore) EnqueueBatch(ids []string, records [][]byte) ([]string, error) {
+	if len(ids) != len(records) {
+		return nil, fmt.Errorf("got %d queue item IDs for %d records", len(ids), len(records))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var stored []string
+	for i, id := range ids {
+		if m.active(id) {
+			continue
+		}
+		m.items[QueueStatePending][id] = append([]byte(nil), records[i]...)
+		stored = append(stored, id)
+	}
+	return stored, nil
+}
+
+// EnqueueBatch Enqueue several payload submissions at once. With the file
+// store a failed write stores none of them; see BatchQueueStore for what is
+// left behind by other failures. Submissions with the same queue item ID as an
+// earlier one in the batch or an item already in the queue are skipped, as
+// with Enqueue.
+func (p *PersistentQueueManager) EnqueueBatch(submissions []*PayloadSubmission) error {
+	ids := make([]string, 0, len(submissions))
+	records := make([][]byte, 0, len(submissions))
+	for i, submission := range submissions {
+		if submission == nil {
+			return fmt.Errorf("submission %d is nil", i)
+		}
+		queueItemID, recordJSON, err := p.buildSubmissionRecord(submission)
+		if err != nil {
+			return fmt.Errorf("submission %d: %v", i, err)
+		}
+		ids = append(ids, queueItemID)
+		records = append(records, recordJSON)
+	}
+	return p.enqueueRecordBatch(ids, records)
+}
+
+// enqueueRetryBatch Enqueue several requests that failed with a retryable
+// error at once, as EnqueueBatch does for payload submissions
+func (p *PersistentQueueManager) enqueueRetryBatch(retries []*queuedRetry) error {
+	ids := make([]string, 0, len(retries))
+	records := make([][]byte, 0, len(retries))
+	for _, retry := range retries {
+		queueItemID, recordJSON, err := p.buildRetryRecord(retry.request, retry.operationName, retry.errorCode, retry.httpStatus)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, queueItemID)
+		records = append(records, recordJSON)
+	}
+	return p.enqueueRecordBatch(ids, records)
+}
+
+// enqueueRecordBatch Store built records as one batch, skipping repeated queue
+// item IDs, and announce the ones stored
+func (p *PersistentQueueManager) enqueueRecordBatch(ids []string, records [][]byte) error {
+	uniqueIDs := make([]string, 0, len(ids))
+	uniqueRecords := make([][]byte, 0, len(records))
+	recordByID := make(map[string][]byte, len(ids))
+	for i, queueItemID := range ids {
+		if _, duplicate := recordByID[queueItemID]; duplicate {
+			continue
+		}
+		recordByID[queueItemID] = records[i]
+		uniqueIDs = append(uniqueIDs, queueItemID)
+		uniqueRecords = append(uniqueRecords, records[i])
+	}
+	if len(uniqueIDs) == 0 {
+		return nil
+	}
+
+	stored, err := p.enqueueBatchInStore(uniqueIDs, uniqueRecords)
+	if err != nil {
+		return fmt.Errorf("failed to write submissions to queue: %v", err)
+	}
+	for _, queueItemID := range stored {
+		p.notifyEnqueue(recordByID[queueItemID], queueItemID)
+	}
+
+	p.logger.Info("Enqueued submission batch to persistent storage", map[string]interface{}{
+		"count":      len(stored),
+		"duplicates": len(ids) - len(stored),
+	})
+
+	p.reportQueueDepth()
+
+	// Start processing if not already running
+	p.StartProcessing()
+
+	return nil
+}
+
+// enqueueBatchInStore Store the items with the store's EnqueueBatch, or one by
+// one when it has none, removing the written items again on failure
+func (p *PersistentQueueManager) enqueueBatchInStore(ids []string, records [][]byte) ([]string, error) {
+	if batchStore, ok := p.store.(BatchQueueStore); ok {
+		return batchStore.EnqueueBatch(ids, records)
+	}
+	var stored []string
+	for i, id := range ids {
+		if err := p.store.Enqueue(id, records[i]); err != nil {
+			if errors.Is(err, ErrQueueItemExists) {
+				continue
+			}
+			for _, written := range stored {
+				_ = p.store.Remove(QueueStatePending, written)
+			}
+			return nil, err
+		}
+		stored = append(stored, id)
+	}
+	return stored, nil
+}
@@ -0,0 +1,162 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testBatchSubmissions(t *testing.T, count int) []*PayloadSubmission {
+	t.Helper()
+	submissions := make([]*PayloadSubmission, 0, count)
+	for i := 0; i < count; i++ {
+		request, err := json.Marshal(map[string]interface{}{
+			"requestId": fmt.Sprintf("req-batch-%d", i),
+			"payload":   testInvoicePayload(fmt.Sprintf("INV-%d", i)),
+		})
+		if err != nil {
+			t.Fatalf("marshal request: %v", err)
+		}
+		submissions = append(submissions, NewPayloadSubmission(string(request), NewSource("erp", "1", nil), CountrySA, DocumentTypeTaxInvoice))
+	}
+	return submissions
+}
+
+func replaceQueueBatchWriteFile(t *testing.T, writeFile func(name string, data []byte, perm os.FileMode) error) {
+	t.Helper()
+	writeQueueBatchFile = writeFile
+	t.Cleanup(func() { writeQueueBatchFile = os.WriteFile })
+}
+
+func TestEnqueueBatchStoresEverySubmissionOnce(t *testing.T) {
+	store, err := NewFileQueueStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("store init failed: %v", err)
+	}
+	manager := newTestQueueManager(t, store)
+
+	submissions := testBatchSubmissions(t, 100)
+	submissions = append(submissions, submissions[0], submissions[99])
+	if err := manager.EnqueueBatch(submissions); err != nil {
+		t.Fatalf("EnqueueBatch failed: %v", err)
+	}
+	if pending, _ := store.List(QueueStatePending); len(pending) != 100 {
+		t.Fatalf("expected 100 pending items, got %d", len(pending))
+	}
+
+	if err := manager.EnqueueBatch(testBatchSubmissions(t, 101)); err != nil {
+		t.Fatalf("EnqueueBatch with queued items failed: %v", err)
+	}
+	if pending, _ := store.List(QueueStatePending); len(pending) != 101 {
+		t.Fatalf("expected only the new item to be added, got %d pending", len(pending))
+	}
+}
+
+func TestEnqueueBatchStoresNothingWhenAWriteFails(t *testing.T) {
+	basePath := t.TempDir()
+	store, err := NewFileQueueStore(basePath)
+	if err != nil {
+		t.Fatalf("store init failed: %v", err)
+	}
+	writes := 0
+	diskFull := errors.New("no space left on device")
+	replaceQueueBatchWriteFile(t, func(name string, data []byte, perm os.FileMode) error {
+		writes++
+		if writes == 50 {
+			return diskFull
+		}
+		return os.WriteFile(name, data, perm)
+	})
+	manager := newTestQueueManager(t, store)
+
+	if err := manager.EnqueueBatch(testBatchSubmissions(t, 100)); err == nil {
+		t.Fatalf("expected the failed write to fail the batch")
+	}
+	if pending, _ := store.List(QueueStatePending); len(pending) != 0 {
+		t.Fatalf("expected no pending items after a failed batch, got %d", len(pending))
+	}
+	if staging, _ := filepath.Glob(filepath.Join(basePath, queueBatchDirPrefix+"*")); len(staging) != 0 {
+		t.Fatalf("expected the staging directory to be removed, got %v", staging)
+	}
+
+	writeQueueBatchFile = os.WriteFile
+	if err := manager.EnqueueBatch(testBatchSubmissions(t, 100)); err != nil {
+		t.Fatalf("retrying the batch failed: %v", err)
+	}
+	if pending, _ := store.List(QueueStatePending); len(pending) != 100 {
+		t.Fatalf("expected 100 pending items after the retry, got %d", len(pending))
+	}
+}
+
+func TestEnqueueBatchKeepsItemsEnqueuedConcurrently(t *testing.T) {
+	store, err := NewFileQueueStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("store init failed: %v", err)
+	}
+	replaceQueueBatchWriteFile(t, func(name string, data []byte, perm os.FileMode) error {
+		if filepath.Base(name) == "b.json" {
+			// Another writer enqueues "a" after the batch checked for it
+			if err := store.Enqueue("a", []byte("concurrent")); err != nil {
+				return err
+			}
+		}
+		return os.WriteFile(name, data, perm)
+	})
+
+	stored, err := store.EnqueueBatch([]string{"a", "b"}, [][]byte{[]byte("batch-a"), []byte("batch-b")})
+	if err != nil {
+		t.Fatalf("EnqueueBatch failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0] != "b" {
+		t.Fatalf("expected only b to be stored by the batch, got %v", stored)
+	}
+	if raw, _ := store.Get(QueueStatePending, "a"); string(raw) != "concurrent" {
+		t.Fatalf("expected the concurrently enqueued record to be kept, got %q", raw)
+	}
+}
+
+func TestNewFileQueueStoreRemovesOnlyAbandonedBatches(t *testing.T) {
+	basePath := t.TempDir()
+	old := time.Now().Add(-2 * staleQueueBatchAge)
+	makeBatchDir := func(name string, modTime time.Time) string {
+		dir := filepath.Join(basePath, queueBatchDirPrefix+name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, queueBatchLockFile), nil, 0644); err != nil {
+			t.Fatalf("write lock file: %v", err)
+		}
+		if err := os.Chtimes(dir, modTime, modTime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+		return dir
+	}
+	abandoned := makeBatchDir("abandoned", old)
+	recent := makeBatchDir("recent", time.Now())
+	live := makeBatchDir("live", old)
+	lockHandle, err := os.Open(filepath.Join(live, queueBatchLockFile))
+	if err != nil {
+		t.Fatalf("open lock file: %v", err)
+	}
+	defer lockHandle.Close()
+	if err := tryLockFile(lockHandle); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	defer unlockFile(lockHandle)
+
+	if _, err := NewFileQueueStore(basePath); err != nil {
+		t.Fatalf("store init failed: %v", err)
+	}
+	if _, err := os.Stat(abandoned); !os.IsNotExist(err) {
+		t.Fatalf("expected the abandoned staging directory to be removed")
+	}
+	for _, dir := range []string{recent, live} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("expected %s to be kept, got %v", filepath.Base(dir), err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package complyancesdk
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testQueueEncryptionKey is a fixed AES-256 key for the encryption tests
+var testQueueEncryptionKey = bytes.Repeat([]byte{0x42}, 32)
+
+func TestEncryptedFileQueueStoreRoundTripsRecords(t *testing.T) {
+	basePath := t.TempDir()
+	store, err := NewFileQueueStore(basePath)
+	if err != nil {
+		t.Fatalf("store init failed: %v", err)
+	}
+	if err := store.SetEncryptionKey(testQueueEncryptionKey); err != nil {
+		t.Fatalf("set key failed: %v", err)
+	}
+
+	record := []byte(`{"queueItemId":"item-1","payload":{"customer_name":"Acme Trading","total":"1150.00"}}`)
+	if err := store.Enqueue("item-1", record); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	onDisk, err := os.ReadFile(filepath.Join(basePath, PendingDir, "item-1.json"))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if !bytes.HasPrefix(onDisk, encryptedQueueRecordPrefix) || bytes.Contains(onDisk, []byte("Acme Trading")) || bytes.Contains(onDisk, []byte("queueItemId")) {
+		t.Fatalf("expected the record to be encrypted on disk, got %q", onDisk)
+	}
+
+	claimed, err := store.Claim("item-1")
+	if err != nil || !bytes.Equal(claimed, record) {
+		t.Fatalf("expected the claimed record to decrypt, got %q, %v", claimed, err)
+	}
+	updated := []byte(`{"queueItemId":"item-1","attemptCount":1}`)
+	if err := store.MarkFailed("item-1", updated); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	failed, err := store.Get(QueueStateFailed, "item-1")
+	if err != nil || !bytes.Equal(failed, updated) {
+		t.Fatalf("expected the failed record to decrypt, got %q, %v", failed, err)
+	}
+
+	// A record moved under another ID fails authentication
+	onDisk, _ = os.ReadFile(filepath.Join(basePath, FailedDir, "item-1.json"))
+	if err := os.WriteFile(filepath.Join(basePath, FailedDir, "item-2.json"), onDisk, 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := store.Get(QueueStateFailed, "item-2"); err == nil {
+		t.Fatalf("expected a record copied to another ID to be rejected")
+	}
+
+	other, _ := NewFileQueueStore(basePath)
+	if _, err := other.Get(QueueStateFailed, "item-1"); err == nil {
+		t.Fatalf("expected reading an encrypted record without the key to fail")
+	}
+	_ = other.SetEncryptionKey(bytes.Repeat([]byte{0x24}, 32))
+	if _, err := other.Get(QueueStateFailed, "item-1"); err == nil {
+		t.Fatalf("expected reading an encrypted record with the wrong key to fail")
+	}
+}
+
+func TestEncryptedFileQueueStoreReadsPlaintextRecords(t *testing.T) {
+	basePath := t.TempDir()
+	plain, _ := NewFileQueueStore(basePath)
+	record := []byte(`{"queueItemId":"legacy"}`)
+	if err := plain.Enqueue("legacy", record); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	store, _ := NewFileQueueStore(basePath)
+	if err := store.SetEncryptionKey(testQueueEncryptionKey); err != nil {
+		t.Fatalf("set key failed: %v", err)
+	}
+	if raw, err := store.Get(QueueStatePending, "legacy"); err != nil || !bytes.Equal(raw, record) {
+		t.Fatalf("expected records written before encryption to stay readable, got %q, %v", raw, err)
+	}
+
+	if err := store.SetEncryptionKey([]byte("too-short")); err == nil {
+		t.Fatalf("expected a key that is not 16, 24 or 32 bytes to be rejected")
+	} else if code, ok := GetErrorCode(err); !ok || code != ErrorCodeConfigurationError {
+		t.Fatalf("expected CONFIGURATION_ERROR, got %v", err)
+	}
+}
+
+func TestQueueEncryptionKeyEncryptsQueuedSubmissions(t *testing.T) {
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, sources, NewNoRetryConfig())
+	cfg.SetQueueEncryptionKey(testQueueEncryptionKey)
+
+	sent := 0
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		if sent == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+		PurposeInvoicing, testInvoicePayload("INV-SECRET-42"), []*Destination{}); err != nil {
+		t.Fatalf("expected the failure to be queued, got %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(globalSDK.queueManager.queueBasePath, PendingDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected one queued file, got %v", files)
+	}
+	onDisk, _ := os.ReadFile(files[0])
+	if bytes.Contains(onDisk, []byte("INV-SECRET-42")) {
+		t.Fatalf("expected the queued payload to be encrypted on disk")
+	}
+
+	globalSDK.queueManager.ProcessPendingSubmissionsNow()
+	if status := GetDetailedQueueStatus(); sent != 2 || status.SuccessCount != 1 || status.PendingCount != 0 {
+		t.Fatalf("expected the encrypted submission to be resent, got %d sends and %+v", sent, status)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Queue lifecycle notifications for the persistent queue manager.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// QueueEventHandler is notified as submissions move through the persistent
+// queue. Callbacks run synchronously on the goroutine that moved the record, so
+// they should return quickly.
+type QueueEventHandler interface {
+	// OnEnqueue is called after a submission is written to pending; err is always nil
+	OnEnqueue(record *PersistentSubmissionRecord, err error)
+
+	// OnRetry is called when a failed submission is moved back to pending; err
+	// is the error from its last attempt, if one was recorded
+	OnRetry(record *PersistentSubmissionRecord, err error)
+
+	// OnSuccess is called after a queued submission is accepted; err is always nil
+	OnSuccess(record *PersistentSubmissionRecord, err error)
+
+	// OnPermanentFailure is called when a submission is moved to dead-letter,
+	// either because it used up its attempts or because its record is corrupt
+	OnPermanentFailure(record *PersistentSubmissionRecord, err error)
+}
+
+// notifyEnqueue Report a newly enqueued record
+func (p *PersistentQueueManager) notifyEnqueue(raw []byte, queueItemID string) {
+	if p.events != nil {
+		p.events.OnEnqueue(decodeSubmissionRecord(raw, queueItemID), nil)
+	}
+}
+
+// decodeSubmissionRecord Decode a stored queue record for event handlers. Records
+// that cannot be decoded still carry their queue item ID.
+func decodeSubmissionRecord(raw []byte, queueItemID string) *PersistentSubmissionRecord {
+	record := &PersistentSubmissionRecord{}
+	_ = json.Unmarshal(raw, record)
+	if record.QueueItemID == "" {
+		record.QueueItemID = queueItemID
+	}
+	return record
+}
+
+// lastSubmissionError Error recorded by the last failed attempt of a stored record
+func lastSubmissionError(raw []byte) error {
+	var record struct {
+		LastErrorMessage string `json:"lastErrorMessage"`
+	}
+	if err := json.Unmarshal(raw, &record); err != nil || strings.TrimSpace(record.LastErrorMessage) == "" {
+		return nil
+	}
+	return errors.New(record.LastErrorMessage)
+}
@@ -0,0 +1,136 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type queueEvent struct {
+	kind   string
+	record *PersistentSubmissionRecord
+	err    error
+}
+
+type recordingQueueEventHandler struct {
+	mu     sync.Mutex
+	events []queueEvent
+}
+
+func (h *recordingQueueEventHandler) add(kind string, record *PersistentSubmissionRecord, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, queueEvent{kind: kind, record: record, err: err})
+}
+
+func (h *recordingQueueEventHandler) OnEnqueue(record *PersistentSubmissionRecord, err error) {
+	h.add("enqueue", record, err)
+}
+
+func (h *recordingQueueEventHandler) OnRetry(record *PersistentSubmissionRecord, err error) {
+	h.add("retry", record, err)
+}
+
+func (h *recordingQueueEventHandler) OnSuccess(record *PersistentSubmissionRecord, err error) {
+	h.add("success", record, err)
+}
+
+func (h *recordingQueueEventHandler) OnPermanentFailure(record *PersistentSubmissionRecord, err error) {
+	h.add("permanent_failure", record, err)
+}
+
+func (h *recordingQueueEventHandler) kinds() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	kinds := make([]string, len(h.events))
+	for i, event := range h.events {
+		kinds[i] = event.kind
+	}
+	return kinds
+}
+
+func TestQueueEventHandlerFollowsRecordThroughQueue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	failFirst := true
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if failFirst {
+			failFirst = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: client}
+	t.Cleanup(func() { globalSDK = previous })
+
+	handler := &recordingQueueEventHandler{}
+	manager := newTestQueueManager(t, NewMemoryQueueStore())
+	manager.SetEventHandler(handler)
+	clock := useFakeQueueClock(manager)
+
+	request := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload("INV-EVENTS")).
+		RequestID("req-events").
+		Build()
+	if err := manager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	// Duplicates are not reported
+	_ = manager.EnqueueForRetry(request, "push_to_unify", nil, nil)
+
+	manager.ProcessPendingSubmissionsNow()
+	clock.Advance(time.Minute)
+	manager.RetryFailedSubmissions()
+	manager.ProcessPendingSubmissionsNow()
+
+	if got := strings.Join(handler.kinds(), ","); got != "enqueue,retry,success" {
+		t.Fatalf("unexpected event sequence: %s", got)
+	}
+	for _, event := range handler.events {
+		if event.record.GetQueueItemID() != "req-events" || event.record.GetRequestID() != "req-events" {
+			t.Fatalf("%s event has wrong record: %+v", event.kind, event.record)
+		}
+	}
+	if retry := handler.events[1]; retry.err == nil || !strings.Contains(retry.err.Error(), string(ErrorCodeMaxRetriesExceeded)) {
+		t.Fatalf("expected retry event to carry the last error, got %v", retry.err)
+	}
+	if handler.events[0].err != nil || handler.events[2].err != nil {
+		t.Fatalf("expected no error on enqueue and success events")
+	}
+}
+
+func TestQueueEventHandlerReportsCorruptRecordsAsPermanentFailures(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	previous := globalSDK
+	globalSDK = &GETSUnifySDK{apiClient: newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("corrupt record should not be sent")
+	})}
+	t.Cleanup(func() { globalSDK = previous })
+
+	handler := &recordingQueueEventHandler{}
+	store := NewMemoryQueueStore()
+	manager := newTestQueueManager(t, store)
+	manager.SetEventHandler(handler)
+	if err := store.Enqueue("corrupt", []byte(`{"queueItemId":"corrupt","payload":"not an object"}`)); err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+
+	manager.ProcessPendingSubmissionsNow()
+
+	if got := strings.Join(handler.kinds(), ","); got != "permanent_failure" {
+		t.Fatalf("unexpected event sequence: %s", got)
+	}
+	if event := handler.events[0]; event.record.GetQueueItemID() != "corrupt" || event.err == nil {
+		t.Fatalf("unexpected permanent failure event: %+v", event)
+	}
+	if status := manager.GetQueueStatus(); status.DeadLetterCount != 1 || status.FailedCount != 0 {
+		t.Fatalf("expected corrupt record in dead-letter, got %s", status.String())
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package complyancesdk
+
+import "os"
+
+// fileLockingSupported Advisory locks are not available, so claims left in
+// processing by a crash cannot be told apart from live ones
+const fileLockingSupported = false
+
+// tryLockFile Advisory locking is not available on this platform; the atomic
+// rename into the processing directory is the only claim mechanism.
+func tryLockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile No-op on platforms without advisory locking
+func unlockFile(f *os.File) error {
+	return nil
+}
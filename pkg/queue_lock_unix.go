@@ -0,0 +1,22 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package complyancesdk
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileLockingSupported Advisory locks are available, so a claim held by a
+// live process can be told apart from one left behind by a crash
+const fileLockingSupported = true
+
+// tryLockFile Acquire a non-blocking exclusive advisory lock on the file
+func tryLockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// unlockFile Release the advisory lock held on the file
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,618 @@
+/*
+Queue storage backends for the persistent queue manager.
+*/
+package complyancesdk
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueueState identifies the lifecycle bucket a queued item lives in
+type QueueState string
+
+const (
+	QueueStatePending    QueueState = PendingDir
+	QueueStateProcessing QueueState = ProcessingDir
+	QueueStateFailed     QueueState = FailedDir
+	QueueStateSuccess    QueueState = SuccessDir
+	QueueStateDeadLetter QueueState = DeadLetterDir
+)
+
+// queueStates lists every state in lifecycle order
+var queueStates = []QueueState{QueueStatePending, QueueStateProcessing, QueueStateFailed, QueueStateSuccess, QueueStateDeadLetter}
+
+// activeQueueStates lists the states of items not yet finished; only these
+// block a new item with the same ID
+var activeQueueStates = []QueueState{QueueStatePending, QueueStateProcessing, QueueStateFailed}
+
+var (
+	// ErrQueueItemExists is returned when an item with the same ID is already pending, processing or failed
+	ErrQueueItemExists = errors.New("queue item already exists")
+	// ErrQueueItemNotFound is returned when an item is not present in the requested state
+	ErrQueueItemNotFound = errors.New("queue item not found")
+	// ErrQueueItemClaimed is returned by Claim when another worker (possibly in
+	// another process) has already picked up the item
+	ErrQueueItemClaimed = errors.New("queue item already claimed by another worker")
+	// ErrQueueItemUnreadable is returned by Claim when the stored record cannot
+	// be decrypted; the item is moved to dead-letter as stored, so it can still
+	// be recovered with the right key
+	ErrQueueItemUnreadable = errors.New("queue item cannot be read")
+)
+
+// QueueStore Storage backend used by PersistentQueueManager. Records are opaque
+// JSON documents keyed by queue item ID; the store only tracks which state each
+// item is in and guarantees that an item is claimed by at most one worker.
+type QueueStore interface {
+	// Enqueue stores a new pending item, returning ErrQueueItemExists if the ID
+	// is pending, processing or failed. An item with the ID in success or
+	// dead-letter does not block it, so a corrected document can be queued again.
+	Enqueue(id string, record []byte) error
+	// Claim moves a pending item to processing and returns its record
+	Claim(id string) ([]byte, error)
+	// MarkSuccess moves a claimed item to success
+	MarkSuccess(id string) error
+	// MarkFailed replaces a claimed item's record and moves it to failed
+	MarkFailed(id string, record []byte) error
+	// List returns the IDs of all items in a state
+	List(state QueueState) ([]string, error)
+	// Get returns the record of an item in a state
+	Get(state QueueState, id string) ([]byte, error)
+	// Requeue moves a failed item back to pending
+	Requeue(id string) error
+	// DeadLetter moves a failed item to dead-letter, where it is kept for inspection but never retried
+	DeadLetter(id string) error
+	// Remove deletes an item from a state
+	Remove(state QueueState, id string) error
+}
+
+// QueueMode selects the storage backend of the persistent queue
+type QueueMode string
+
+const (
+	// QueueModeFile stores queued submissions under ~/complyance-queue so they
+	// survive restarts (the default)
+	QueueModeFile QueueMode = "file"
+	// QueueModeMemory keeps queued submissions in process memory, for read-only
+	// or ephemeral filesystems such as AWS Lambda. Items are lost when the process exits.
+	QueueModeMemory QueueMode = "memory"
+)
+
+// FileQueueStore QueueStore backed by one directory per state under a base path.
+// Claims are made with an advisory lock plus an atomic rename into the
+// processing directory, so several processes can safely share the same
+// directory, and items a crashed process left in processing are moved back to
+// pending.
+type FileQueueStore struct {
+	basePath string
+	mu       sync.Mutex
+	claimed  map[string]*os.File
+	aead     cipher.AEAD
+}
+
+// NewFileQueueStore creates a file queue store rooted at basePath, creating the state directories
+func NewFileQueueStore(basePath string) (*FileQueueStore, error) {
+	for _, state := range queueStates {
+		dirPath := filepath.Join(basePath, string(state))
+		if err := os.MkdirAll(dirPath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create queue directory %s: %w", dirPath, err)
+		}
+	}
+	store := &FileQueueStore{
+		basePath: basePath,
+		claimed:  make(map[string]*os.File),
+	}
+	store.removeStaleBatches()
+	store.recoverAbandonedClaims()
+	return store, nil
+}
+
+// BasePath getter for base path
+func (s *FileQueueStore) BasePath() string {
+	return s.basePath
+}
+
+func (s *FileQueueStore) itemPath(state QueueState, id string) string {
+	return filepath.Join(s.basePath, string(state), id+".json")
+}
+
+// exists Whether the item is pending, processing or failed, ignoring excludeState
+func (s *FileQueueStore) exists(id string, excludeState ...QueueState) bool {
+	for _, state := range activeQueueStates {
+		if len(excludeState) > 0 && state == excludeState[0] {
+			continue
+		}
+		if _, err := os.Stat(s.itemPath(state, id)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Enqueue writes the record to a temporary file and links it into pending so
+// readers never observe a partially written item. The link fails rather than
+// replace an item another writer put in pending meanwhile.
+func (s *FileQueueStore) Enqueue(id string, record []byte) error {
+	if s.exists(id) {
+		return ErrQueueItemExists
+	}
+	sealed, err := s.sealRecord(id, record)
+	if err != nil {
+		return err
+	}
+	pendingPath := s.itemPath(QueueStatePending, id)
+	tmpPath, err := writeTempFile(filepath.Dir(pendingPath), sealed)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	if err := os.Link(tmpPath, pendingPath); err != nil {
+		if os.IsExist(err) {
+			return ErrQueueItemExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileQueueStore) writeAtomic(path string, data []byte) error {
+	tmpPath, err := writeTempFile(filepath.Dir(path), data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// writeTempFile Write data to a new temporary file in dir, returning its path
+func writeTempFile(dir string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
+}
+
+// Claim takes an advisory lock on the pending item and renames it into
+// processing. The lock moves with the file, so an item in processing is locked
+// for as long as its claimer is alive; it is held until MarkSuccess or
+// MarkFailed is called for the item. A record that cannot be decrypted is
+// moved to dead-letter and ErrQueueItemUnreadable returned.
+func (s *FileQueueStore) Claim(id string) ([]byte, error) {
+	pendingPath := s.itemPath(QueueStatePending, id)
+	processingPath := s.itemPath(QueueStateProcessing, id)
+
+	var lockHandle *os.File
+	if fileLockingSupported {
+		handle, err := os.Open(pendingPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrQueueItemClaimed
+			}
+			return nil, err
+		}
+		if err := tryLockFile(handle); err != nil {
+			handle.Close()
+			return nil, ErrQueueItemClaimed
+		}
+		lockHandle = handle
+	}
+	if err := os.Rename(pendingPath, processingPath); err != nil {
+		closeLockHandle(lockHandle)
+		if os.IsNotExist(err) {
+			return nil, ErrQueueItemClaimed
+		}
+		return nil, err
+	}
+	if lockHandle != nil && !sameFile(lockHandle, processingPath) {
+		// Another worker finished the locked item and it was queued again
+		// before the rename; the new copy is not covered by the lock
+		_ = os.Rename(processingPath, pendingPath)
+		closeLockHandle(lockHandle)
+		return nil, ErrQueueItemClaimed
+	}
+
+	raw, err := os.ReadFile(processingPath)
+	if err != nil {
+		// Leave the item pending rather than stranded in processing
+		_ = os.Rename(processingPath, pendingPath)
+		closeLockHandle(lockHandle)
+		return nil, err
+	}
+	raw, err = s.openRecord(id, raw)
+	if err != nil {
+		// Retrying cannot make the record readable
+		if moveErr := os.Rename(processingPath, s.itemPath(QueueStateDeadLetter, id)); moveErr != nil {
+			_ = os.Rename(processingPath, pendingPath)
+			closeLockHandle(lockHandle)
+			return nil, err
+		}
+		closeLockHandle(lockHandle)
+		return nil, fmt.Errorf("%w: %v", ErrQueueItemUnreadable, err)
+	}
+
+	s.mu.Lock()
+	s.claimed[id] = lockHandle
+	s.mu.Unlock()
+	return raw, nil
+}
+
+// sameFile Whether the open file is the one at path
+func sameFile(f *os.File, path string) bool {
+	openInfo, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	pathInfo, err := os.Stat(path)
+	return err == nil && os.SameFile(openInfo, pathInfo)
+}
+
+// closeLockHandle Release the lock on a claimed item and close it; nil is ignored
+func closeLockHandle(lockHandle *os.File) {
+	if lockHandle == nil {
+		return
+	}
+	_ = unlockFile(lockHandle)
+	_ = lockHandle.Close()
+}
+
+func (s *FileQueueStore) release(id string) {
+	s.mu.Lock()
+	lockHandle := s.claimed[id]
+	delete(s.claimed, id)
+	s.mu.Unlock()
+	closeLockHandle(lockHandle)
+}
+
+// recoverAbandonedClaims Move items a claimer left in processing when it exited
+// without finishing them back to pending, returning how many were moved. An
+// item is only moved once its lock can be taken, so items a live process is
+// sending are kept. Without advisory locking nothing is moved.
+func (s *FileQueueStore) recoverAbandonedClaims() int {
+	if !fileLockingSupported {
+		return 0
+	}
+	ids, err := s.List(QueueStateProcessing)
+	if err != nil {
+		return 0
+	}
+	recovered := 0
+	for _, id := range ids {
+		processingPath := s.itemPath(QueueStateProcessing, id)
+		lockHandle, err := os.Open(processingPath)
+		if err != nil {
+			continue
+		}
+		if err := tryLockFile(lockHandle); err != nil {
+			lockHandle.Close()
+			continue
+		}
+		if sameFile(lockHandle, processingPath) && os.Rename(processingPath, s.itemPath(QueueStatePending, id)) == nil {
+			recovered++
+		}
+		closeLockHandle(lockHandle)
+	}
+	return recovered
+}
+
+// MarkSuccess moves the claimed item into success
+func (s *FileQueueStore) MarkSuccess(id string) error {
+	defer s.release(id)
+	return os.Rename(s.itemPath(QueueStateProcessing, id), s.itemPath(QueueStateSuccess, id))
+}
+
+// MarkFailed writes the updated record into failed and drops the processing copy
+func (s *FileQueueStore) MarkFailed(id string, record []byte) error {
+	defer s.release(id)
+	sealed, err := s.sealRecord(id, record)
+	if err != nil {
+		return err
+	}
+	if err := s.writeAtomic(s.itemPath(QueueStateFailed, id), sealed); err != nil {
+		return err
+	}
+	_ = os.Remove(s.itemPath(QueueStateProcessing, id))
+	return nil
+}
+
+// List returns the IDs of all items in the state directory
+func (s *FileQueueStore) List(state QueueState) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.basePath, string(state), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(files))
+	for _, filePath := range files {
+		ids = append(ids, strings.TrimSuffix(filepath.Base(filePath), ".json"))
+	}
+	return ids, nil
+}
+
+// Get reads the record of an item in a state
+func (s *FileQueueStore) Get(state QueueState, id string) ([]byte, error) {
+	raw, err := os.ReadFile(s.itemPath(state, id))
+	if os.IsNotExist(err) {
+		return nil, ErrQueueItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.openRecord(id, raw)
+}
+
+// Requeue moves a failed item back to pending. If the same item is already
+// pending or processing the failed copy is dropped and ErrQueueItemExists returned.
+func (s *FileQueueStore) Requeue(id string) error {
+	failedPath := s.itemPath(QueueStateFailed, id)
+	if _, err := os.Stat(failedPath); err != nil {
+		return ErrQueueItemNotFound
+	}
+	if s.exists(id, QueueStateFailed) {
+		_ = os.Remove(failedPath)
+		return ErrQueueItemExists
+	}
+	return os.Rename(failedPath, s.itemPath(QueueStatePending, id))
+}
+
+// DeadLetter moves a failed item into the dead-letter directory
+func (s *FileQueueStore) DeadLetter(id string) error {
+	err := os.Rename(s.itemPath(QueueStateFailed, id), s.itemPath(QueueStateDeadLetter, id))
+	if os.IsNotExist(err) {
+		return ErrQueueItemNotFound
+	}
+	return err
+}
+
+// Remove deletes an item from a state
+func (s *FileQueueStore) Remove(state QueueState, id string) error {
+	err := os.Remove(s.itemPath(state, id))
+	if os.IsNotExist(err) {
+		return ErrQueueItemNotFound
+	}
+	return err
+}
+
+// cleanupOldSuccessFiles Remove success files last modified before cutoffTime
+func (s *FileQueueStore) cleanupOldSuccessFiles(cutoffTime time.Time, logger Logger) {
+	successDir := filepath.Join(s.basePath, SuccessDir)
+
+	files, err := filepath.Glob(filepath.Join(successDir, "*.json"))
+	if err != nil {
+		logger.Error("Error reading success directory", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	var oldFiles []string
+	for _, filePath := range files {
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		if fileInfo.ModTime().Before(cutoffTime) {
+			oldFiles = append(oldFiles, filePath)
+		}
+	}
+
+	for _, filePath := range oldFiles {
+		if err := os.Remove(filePath); err != nil {
+			logger.Warn("Failed to remove old success file", map[string]interface{}{"file": filepath.Base(filePath), "error": err.Error()})
+		} else {
+			logger.Debug("Cleaned up old success file", map[string]interface{}{"file": filepath.Base(filePath)})
+		}
+	}
+
+	if len(oldFiles) > 0 {
+		logger.Info("Cleaned up old success files", map[string]interface{}{"count": len(oldFiles)})
+	}
+}
+
+// cleanupDuplicateFiles Keep only the most recently modified copy of each queue item across directories
+func (s *FileQueueStore) cleanupDuplicateFiles(logger Logger) {
+	fileMap := make(map[string]string)
+	queueItemMap := make(map[string]string)
+
+	for _, state := range queueStates {
+		dirPath := filepath.Join(s.basePath, string(state))
+		files, err := filepath.Glob(filepath.Join(dirPath, "*.json"))
+		if err != nil {
+			logger.Error("Error reading queue directory", map[string]interface{}{"state": string(state), "error": err.Error()})
+			continue
+		}
+
+		for _, filePath := range files {
+			fileName := filepath.Base(filePath)
+			id := strings.TrimSuffix(fileName, ".json")
+			raw, _ := os.ReadFile(filePath)
+			raw, _ = s.openRecord(id, raw)
+			dedupeKey := readQueueItemID(raw, id)
+			existingFile, exists := queueItemMap[dedupeKey]
+			if !exists {
+				existingFile, exists = fileMap[fileName]
+			}
+
+			if exists {
+				// File exists in multiple directories, keep the one with latest modification time
+				existingInfo, err1 := os.Stat(existingFile)
+				currentInfo, err2 := os.Stat(filePath)
+
+				if err1 != nil || err2 != nil {
+					logger.Warn("Could not compare modification times for duplicate file", map[string]interface{}{"file": fileName})
+					// Keep the existing file, delete current
+					os.Remove(filePath)
+					continue
+				}
+
+				if currentInfo.ModTime().After(existingInfo.ModTime()) {
+					// Delete the older file
+					os.Remove(existingFile)
+					queueItemMap[dedupeKey] = filePath
+					fileMap[fileName] = filePath
+					logger.Debug("Removed older duplicate file", map[string]interface{}{"file": existingFile})
+				} else {
+					// Delete the current file
+					os.Remove(filePath)
+					logger.Debug("Removed older duplicate file", map[string]interface{}{"file": filePath})
+				}
+			} else {
+				queueItemMap[dedupeKey] = filePath
+				fileMap[fileName] = filePath
+			}
+		}
+	}
+}
+
+// MemoryQueueStore QueueStore that keeps records in process memory. It never
+// touches the filesystem, so queued items do not survive a restart.
+type MemoryQueueStore struct {
+	mu    sync.Mutex
+	items map[QueueState]map[string][]byte
+}
+
+// NewMemoryQueueStore creates an empty in-memory queue store
+func NewMemoryQueueStore() *MemoryQueueStore {
+	items := make(map[QueueState]map[string][]byte, len(queueStates))
+	for _, state := range queueStates {
+		items[state] = make(map[string][]byte)
+	}
+	return &MemoryQueueStore{items: items}
+}
+
+// active Whether the item is pending, processing or failed
+func (m *MemoryQueueStore) active(id string) bool {
+	for _, state := range activeQueueStates {
+		if _, ok := m.items[state][id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// move Move an item between states, replacing its record when one is given
+func (m *MemoryQueueStore) move(from, to QueueState, id string, record []byte) error {
+	current, ok := m.items[from][id]
+	if !ok {
+		return ErrQueueItemNotFound
+	}
+	if record == nil {
+		record = current
+	}
+	delete(m.items[from], id)
+	m.items[to][id] = append([]byte(nil), record...)
+	return nil
+}
+
+// Enqueue stores a new pending item
+func (m *MemoryQueueStore) Enqueue(id string, record []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active(id) {
+		return ErrQueueItemExists
+	}
+	m.items[QueueStatePending][id] = append([]byte(nil), record...)
+	return nil
+}
+
+// Claim moves a pending item to processing
+func (m *MemoryQueueStore) Claim(id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.items[QueueStatePending][id]
+	if !ok {
+		return nil, ErrQueueItemClaimed
+	}
+	return append([]byte(nil), record...), m.move(QueueStatePending, QueueStateProcessing, id, nil)
+}
+
+// MarkSuccess moves a claimed item to success
+func (m *MemoryQueueStore) MarkSuccess(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.move(QueueStateProcessing, QueueStateSuccess, id, nil)
+}
+
+// MarkFailed replaces a claimed item's record and moves it to failed
+func (m *MemoryQueueStore) MarkFailed(id string, record []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.move(QueueStateProcessing, QueueStateFailed, id, record)
+}
+
+// List returns the IDs of all items in a state
+func (m *MemoryQueueStore) List(state QueueState) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.items[state]))
+	for id := range m.items[state] {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Get returns the record of an item in a state
+func (m *MemoryQueueStore) Get(state QueueState, id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.items[state][id]
+	if !ok {
+		return nil, ErrQueueItemNotFound
+	}
+	return append([]byte(nil), record...), nil
+}
+
+// Requeue moves a failed item back to pending. If the same item is already
+// pending or processing the failed copy is dropped and ErrQueueItemExists returned.
+func (m *MemoryQueueStore) Requeue(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[QueueStateFailed][id]; !ok {
+		return ErrQueueItemNotFound
+	}
+	for _, state := range activeQueueStates {
+		if _, ok := m.items[state][id]; ok && state != QueueStateFailed {
+			delete(m.items[QueueStateFailed], id)
+			return ErrQueueItemExists
+		}
+	}
+	return m.move(QueueStateFailed, QueueStatePending, id, nil)
+}
+
+// DeadLetter moves a failed item to dead-letter
+func (m *MemoryQueueStore) DeadLetter(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.move(QueueStateFailed, QueueStateDeadLetter, id, nil)
+}
+
+// Remove deletes an item from a state
+func (m *MemoryQueueStore) Remove(state QueueState, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[state][id]; !ok {
+		return ErrQueueItemNotFound
+	}
+	delete(m.items[state], id)
+	return nil
+}
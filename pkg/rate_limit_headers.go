@@ -0,0 +1,143 @@
+/*
+Server-side rate limit status from X-RateLimit response headers.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limit headers returned by the Unify API
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// rateLimitResetEpochThreshold X-RateLimit-Reset values at or above this are
+// Unix timestamps; smaller values are seconds until the reset
+const rateLimitResetEpochThreshold = 1000000000
+
+// RateLimitStatus Rate limit the API reported on its latest response
+type RateLimitStatus struct {
+	// Limit is the number of requests allowed per window, 0 when not reported
+	Limit int
+	// Remaining is the number of requests left in the current window
+	Remaining int
+	// Reset is when the window resets, zero when not reported
+	Reset time.Time
+	// ObservedAt is when the response carrying these values was received
+	ObservedAt time.Time
+}
+
+// rateLimitTracker Latest server rate limit status and the throttling threshold
+type rateLimitTracker struct {
+	mu        sync.Mutex
+	status    *RateLimitStatus
+	threshold int
+}
+
+// parseRateLimitHeaders Status from the X-RateLimit headers, false when
+// X-RateLimit-Remaining is missing or invalid
+func parseRateLimitHeaders(header http.Header, now time.Time) (RateLimitStatus, bool) {
+	remaining, err := strconv.Atoi(strings.TrimSpace(header.Get(HeaderRateLimitRemaining)))
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+	status := RateLimitStatus{Remaining: remaining, ObservedAt: now}
+	if limit, err := strconv.Atoi(strings.TrimSpace(header.Get(HeaderRateLimitLimit))); err == nil {
+		status.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(strings.TrimSpace(header.Get(HeaderRateLimitReset)), 10, 64); err == nil && reset >= 0 {
+		if reset >= rateLimitResetEpochThreshold {
+			status.Reset = time.Unix(reset, 0)
+		} else {
+			status.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return status, true
+}
+
+// RateLimitStatus Rate limit reported on the latest response that carried
+// X-RateLimit headers, nil before any such response
+func (a *APIClient) RateLimitStatus() *RateLimitStatus {
+	a.serverRateLimit.mu.Lock()
+	defer a.serverRateLimit.mu.Unlock()
+	if a.serverRateLimit.status == nil {
+		return nil
+	}
+	status := *a.serverRateLimit.status
+	return &status
+}
+
+// GetRateLimitThrottleThreshold getter for the remaining count below which requests wait for the reset
+func (a *APIClient) GetRateLimitThrottleThreshold() int {
+	a.serverRateLimit.mu.Lock()
+	defer a.serverRateLimit.mu.Unlock()
+	return a.serverRateLimit.threshold
+}
+
+// SetRateLimitThrottleThreshold Delay requests until X-RateLimit-Reset while
+// the last reported X-RateLimit-Remaining is below threshold; 0 never delays
+func (a *APIClient) SetRateLimitThrottleThreshold(threshold int) {
+	a.serverRateLimit.mu.Lock()
+	defer a.serverRateLimit.mu.Unlock()
+	a.serverRateLimit.threshold = threshold
+}
+
+// recordRateLimit Remember the rate limit reported on a response
+func (a *APIClient) recordRateLimit(header http.Header) {
+	status, ok := parseRateLimitHeaders(header, a.clock.Now())
+	if !ok {
+		return
+	}
+	a.serverRateLimit.mu.Lock()
+	a.serverRateLimit.status = &status
+	a.serverRateLimit.mu.Unlock()
+}
+
+// waitForRateLimitReset Wait for the reported reset when fewer requests remain
+// than the throttle threshold. Returns ctx.Err() if ctx ends first.
+func (a *APIClient) waitForRateLimitReset(ctx context.Context) error {
+	a.serverRateLimit.mu.Lock()
+	status, threshold := a.serverRateLimit.status, a.serverRateLimit.threshold
+	a.serverRateLimit.mu.Unlock()
+	if status == nil || threshold <= 0 || status.Remaining >= threshold || status.Reset.IsZero() {
+		return nil
+	}
+	wait := status.Reset.Sub(a.clock.Now())
+	if wait <= 0 {
+		return nil
+	}
+	a.logger.Warn("API rate limit nearly exhausted, waiting for reset", map[string]interface{}{
+		"remaining": status.Remaining,
+		"waitMs":    wait.Milliseconds(),
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-a.clock.After(wait):
+		return nil
+	}
+}
+
+// GetRateLimitStatus Rate limit the API reported on its latest response, nil
+// before any response carried X-RateLimit headers
+// Uses the SDK set up by Configure.
+func GetRateLimitStatus() *RateLimitStatus {
+	return currentSDK().GetRateLimitStatus()
+}
+
+// GetRateLimitStatus Rate limit the API reported on its latest response, nil
+// before any response carried X-RateLimit headers
+func (s *GETSUnifySDK) GetRateLimitStatus() *RateLimitStatus {
+	if s == nil || s.apiClient == nil {
+		return nil
+	}
+	return s.apiClient.RateLimitStatus()
+}
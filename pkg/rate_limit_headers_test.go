@@ -0,0 +1,84 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHeadersAreParsedFromEveryResponse(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	if client.RateLimitStatus() != nil {
+		t.Fatalf("expected no status before the first response")
+	}
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := client.RateLimitStatus()
+	if status == nil || status.Limit != 100 || status.Remaining != 42 || status.Reset.Unix() != reset {
+		t.Fatalf("unexpected rate limit status: %+v", status)
+	}
+
+	// Small reset values are seconds from now
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "30")
+	parsed, ok := parseRateLimitHeaders(header, clock.Now())
+	if !ok || parsed.Remaining != 0 || !parsed.Reset.Equal(clock.Now().Add(30*time.Second)) {
+		t.Fatalf("unexpected relative reset: %+v", parsed)
+	}
+	if _, ok := parseRateLimitHeaders(http.Header{}, clock.Now()); ok {
+		t.Fatalf("expected no status without X-RateLimit-Remaining")
+	}
+}
+
+func TestLowRemainingRateLimitDelaysTheNextRequest(t *testing.T) {
+	requests := 0
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "1")
+		w.Header().Set("X-RateLimit-Reset", "10")
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	client.SetClock(clock)
+	client.SetRateLimitThrottleThreshold(5)
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.SendUnifyRequest(newTestUnifyRequest("INV-2"))
+		done <- err
+	}()
+	for clock.PendingTimers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatalf("expected the request to wait for the rate limit reset")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if requests != 1 {
+		t.Fatalf("expected the second request to be held back, got %d requests", requests)
+	}
+
+	clock.Advance(10 * time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected the second request after the reset, got %d requests", requests)
+	}
+}
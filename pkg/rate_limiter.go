@@ -0,0 +1,132 @@
+/*
+Token bucket rate limiting of API requests.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter Token bucket allowing requestsPerSecond requests on average and
+// up to burst requests at once. Safe for concurrent use.
+type RateLimiter struct {
+	mu                sync.Mutex
+	requestsPerSecond float64
+	burst             float64
+	tokens            float64
+	last              time.Time
+	clock             Clock
+}
+
+// NewRateLimiter creates a new rate limiter with a full bucket; a burst below
+// 1 is treated as 1
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		requestsPerSecond: requestsPerSecond,
+		burst:             float64(burst),
+		tokens:            float64(burst),
+		clock:             SystemClock,
+	}
+}
+
+// SetClock Set the clock used to refill and wait for tokens; nil restores SystemClock
+func (r *RateLimiter) SetClock(clock Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = clockOrSystem(clock)
+	r.last = time.Time{}
+}
+
+// GetRequestsPerSecond getter for the average rate
+func (r *RateLimiter) GetRequestsPerSecond() float64 {
+	return r.requestsPerSecond
+}
+
+// GetBurst getter for the number of requests allowed at once
+func (r *RateLimiter) GetBurst() int {
+	return int(r.burst)
+}
+
+// Wait Block until a token is available and take it. Returns ctx.Err() if
+// ctx ends first, in which case no token is taken.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait, clock := r.reserve()
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(wait):
+		}
+	}
+}
+
+// reserve Take a token if one is available, otherwise return how long until
+// the next one is
+func (r *RateLimiter) reserve() (time.Duration, Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	if !r.last.IsZero() {
+		r.tokens += now.Sub(r.last).Seconds() * r.requestsPerSecond
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+	r.last = now
+
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0, r.clock
+	}
+	wait := time.Duration((1 - r.tokens) / r.requestsPerSecond * float64(time.Second))
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	return wait, r.clock
+}
+
+// GetRateLimiter getter for the rate limiter, nil when requests are not limited
+func (a *APIClient) GetRateLimiter() *RateLimiter {
+	return a.rateLimiter
+}
+
+// SetRateLimit Limit every request, including queue retries, to
+// requestsPerSecond on average with bursts of up to burst requests. A rate of
+// 0 or less removes the limit.
+func (a *APIClient) SetRateLimit(requestsPerSecond float64, burst int) {
+	if requestsPerSecond <= 0 {
+		a.rateLimiter = nil
+		return
+	}
+	a.rateLimiter = NewRateLimiter(requestsPerSecond, burst)
+	a.rateLimiter.SetClock(a.clock)
+}
+
+// doRequest Send req once the server rate limit has reset (when throttling)
+// and the rate limiter allows it, recording the X-RateLimit headers of the
+// response. Returns the request context's error if it ends while waiting.
+func (a *APIClient) doRequest(req *http.Request) (*http.Response, error) {
+	if err := a.waitForRateLimitReset(req.Context()); err != nil {
+		return nil, err
+	}
+	if a.rateLimiter != nil {
+		if err := a.rateLimiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := a.httpClient.Do(req)
+	if err == nil {
+		a.recordRateLimit(resp.Header)
+	}
+	return resp, err
+}
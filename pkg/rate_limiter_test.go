@@ -0,0 +1,78 @@
+package complyancesdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSpreadsRequestsOverTime(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetRateLimit(20, 1)
+	var mu sync.Mutex
+	var arrivals []time.Time
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	const requests = 5
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+				PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{}); err != nil {
+				t.Errorf("PushToUnify failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(arrivals) != requests {
+		t.Fatalf("expected %d requests, got %d", requests, len(arrivals))
+	}
+	// 1 request from the burst, then one every 50ms
+	if spread := arrivals[len(arrivals)-1].Sub(arrivals[0]); spread < 180*time.Millisecond {
+		t.Fatalf("expected requests spread over at least 200ms, got %v", spread)
+	}
+}
+
+func TestRateLimiterWaitStopsWhenContextEnds(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(1, 1)
+	limiter.SetClock(clock)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("expected the burst token to be available, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- limiter.Wait(ctx) }()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected Wait to return once the context was cancelled")
+	}
+
+	// The cancelled wait took no token, so one is available after a second
+	timers := clock.PendingTimers()
+	go func() { done <- limiter.Wait(context.Background()) }()
+	for clock.PendingTimers() == timers {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("expected a token after a second, got %v", err)
+	}
+}
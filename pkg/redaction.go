@@ -0,0 +1,181 @@
+/*
+Log redaction for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DefaultRedactionFieldPaths Field paths masked when no RedactionConfig is supplied
+var DefaultRedactionFieldPaths = []string{
+	"apiKey",
+	"Authorization",
+	"payload.invoice_data.*.tax_id",
+	"payload.invoice_data.*.vat_number",
+	"payload.invoice_data.*.tax_registration_number",
+	"payload.invoice_data.*.national_id",
+}
+
+// RedactionConfig lists JSON field paths that are masked before anything reaches
+// a Logger. Paths are dot separated and relative to the logged document, e.g.
+// "payload.invoice_data.customer.tax_id". A "*" segment matches any key and
+// arrays are matched element by element. Authorization and API key values are
+// always masked, whatever the configured paths.
+type RedactionConfig struct {
+	FieldPaths []string `json:"field_paths"`
+}
+
+// NewRedactionConfig creates a redaction config masking the given paths
+func NewRedactionConfig(fieldPaths ...string) *RedactionConfig {
+	return &RedactionConfig{FieldPaths: fieldPaths}
+}
+
+// NewDefaultRedactionConfig creates a redaction config with DefaultRedactionFieldPaths
+func NewDefaultRedactionConfig() *RedactionConfig {
+	return NewRedactionConfig(DefaultRedactionFieldPaths...)
+}
+
+// GetFieldPaths getter for field paths
+func (r *RedactionConfig) GetFieldPaths() []string {
+	return r.FieldPaths
+}
+
+// AddFieldPaths Append field paths to mask
+func (r *RedactionConfig) AddFieldPaths(fieldPaths ...string) *RedactionConfig {
+	r.FieldPaths = append(r.FieldPaths, fieldPaths...)
+	return r
+}
+
+// Redact returns a masked copy of document. The input is left untouched.
+func (r *RedactionConfig) Redact(document map[string]interface{}) map[string]interface{} {
+	redacted := RedactSecrets(document)
+	if redacted == nil || r == nil {
+		return redacted
+	}
+	for _, path := range r.FieldPaths {
+		maskFieldPath(redacted, strings.Split(path, "."))
+	}
+	return redacted
+}
+
+// RedactJSON returns a masked copy of a JSON document. Bodies that cannot be
+// parsed are replaced entirely, since they cannot be inspected for secrets.
+func (r *RedactionConfig) RedactJSON(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return body
+	}
+	var document interface{}
+	if err := json.Unmarshal([]byte(trimmed), &document); err != nil {
+		return RedactedValue
+	}
+	masked, err := json.Marshal(r.redactValue(document))
+	if err != nil {
+		return RedactedValue
+	}
+	return string(masked)
+}
+
+// redactValue Mask a single logged value, parsing JSON strings as documents
+func (r *RedactionConfig) redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return r.Redact(typed)
+	case []interface{}:
+		items := make([]interface{}, len(typed))
+		for i, item := range typed {
+			items[i] = r.redactValue(item)
+		}
+		return items
+	case map[string]string:
+		return RedactHeaders(typed)
+	case string:
+		trimmed := strings.TrimSpace(typed)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			return r.RedactJSON(typed)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// redactFields Mask every value in a set of log fields
+func (r *RedactionConfig) redactFields(fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if isSensitiveLogKey(key) {
+			redacted[key] = RedactedValue
+			continue
+		}
+		redacted[key] = r.redactValue(value)
+	}
+	return redacted
+}
+
+// maskFieldPath Replace every value matching the path segments in place
+func maskFieldPath(value interface{}, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if segments[0] != "*" && segments[0] != key {
+				continue
+			}
+			if len(segments) == 1 {
+				typed[key] = RedactedValue
+			} else {
+				maskFieldPath(child, segments[1:])
+			}
+		}
+	case []interface{}:
+		for _, item := range typed {
+			maskFieldPath(item, segments)
+		}
+	}
+}
+
+// redactingLogger Logger masking fields according to a RedactionConfig before delegating
+type redactingLogger struct {
+	next   Logger
+	config *RedactionConfig
+}
+
+// NewRedactingLogger wraps logger so every field passes through config first.
+// A nil config uses NewDefaultRedactionConfig.
+func NewRedactingLogger(logger Logger, config *RedactionConfig) Logger {
+	if config == nil {
+		config = NewDefaultRedactionConfig()
+	}
+	if wrapped, ok := logger.(*redactingLogger); ok {
+		logger = wrapped.next
+	}
+	logger = loggerOrNoop(logger)
+	if _, ok := logger.(noopLogger); ok {
+		return logger
+	}
+	return &redactingLogger{next: logger, config: config}
+}
+
+func (l *redactingLogger) Debug(msg string, fields map[string]interface{}) {
+	l.next.Debug(msg, l.config.redactFields(fields))
+}
+
+func (l *redactingLogger) Info(msg string, fields map[string]interface{}) {
+	l.next.Info(msg, l.config.redactFields(fields))
+}
+
+func (l *redactingLogger) Warn(msg string, fields map[string]interface{}) {
+	l.next.Warn(msg, l.config.redactFields(fields))
+}
+
+func (l *redactingLogger) Error(msg string, fields map[string]interface{}) {
+	l.next.Error(msg, l.config.redactFields(fields))
+}
@@ -0,0 +1,91 @@
+package complyancesdk
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRedactionMasksTaxIDsInLoggedRequests(t *testing.T) {
+	logger := &recordingLogger{}
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetLogger(logger)
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+	})
+
+	payload := testInvoicePayload("INV-PII")
+	invoiceData := payload["invoice_data"].(map[string]interface{})
+	invoiceData["customer"] = map[string]interface{}{"name": "Acme", "tax_id": "300000000000003"}
+	invoiceData["seller"] = map[string]interface{}{"vat_number": "311111111100003"}
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := logger.text()
+	for _, secret := range []string{"300000000000003", "311111111100003", "test-key"} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("%q reached the logger:\n%s", secret, output)
+		}
+	}
+	if !strings.Contains(output, "Acme") {
+		t.Fatalf("expected unmasked fields to still be logged:\n%s", output)
+	}
+}
+
+func TestRedactionConfigAppliesToRawJSONRequests(t *testing.T) {
+	logger := &recordingLogger{}
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	client.SetRedactionConfig(NewRedactionConfig("payload.contact.email"))
+	client.SetLogger(logger)
+
+	if _, err := client.SendRawJSONRequest(`{"apiKey":"raw-secret","payload":{"contact":{"email":"ops@example.com"}}}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := logger.text()
+	if strings.Contains(output, "raw-secret") || strings.Contains(output, "ops@example.com") {
+		t.Fatalf("masked values reached the logger:\n%s", output)
+	}
+	if !strings.Contains(output, RedactedValue) {
+		t.Fatalf("expected masked request body in logs:\n%s", output)
+	}
+}
+
+func TestRedactionConfigPathWildcardsAndArrays(t *testing.T) {
+	config := NewRedactionConfig("lines.*.tax_id", "parties.*.id")
+	document := map[string]interface{}{
+		"lines": []interface{}{
+			map[string]interface{}{"a": map[string]interface{}{"tax_id": "1"}},
+			map[string]interface{}{"b": map[string]interface{}{"tax_id": "2", "name": "keep"}},
+		},
+		"parties": map[string]interface{}{"buyer": map[string]interface{}{"id": "3"}},
+	}
+
+	redacted := config.Redact(document)
+
+	lines := redacted["lines"].([]interface{})
+	if lines[0].(map[string]interface{})["a"].(map[string]interface{})["tax_id"] != RedactedValue {
+		t.Fatalf("first line not masked: %v", lines[0])
+	}
+	second := lines[1].(map[string]interface{})["b"].(map[string]interface{})
+	if second["tax_id"] != RedactedValue || second["name"] != "keep" {
+		t.Fatalf("unexpected second line: %v", second)
+	}
+	if redacted["parties"].(map[string]interface{})["buyer"].(map[string]interface{})["id"] != RedactedValue {
+		t.Fatalf("party id not masked: %v", redacted["parties"])
+	}
+	if document["parties"].(map[string]interface{})["buyer"].(map[string]interface{})["id"] != "3" {
+		t.Fatalf("input was mutated: %v", document)
+	}
+}
+
+func TestRedactJSONReplacesUnparseableBodies(t *testing.T) {
+	if got := NewDefaultRedactionConfig().RedactJSON(`{"apiKey": "x"`); got != RedactedValue {
+		t.Fatalf("expected unparseable body to be replaced, got %q", got)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Normalization of tax authority rejection reasons across response shapes.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RejectionReasons Human-readable reasons the submission was rejected, as a
+// flat list without duplicates. Reasons come from the SDK-level Errors and from
+// the government response, which is read in the ZATCA shape
+// (validationResults.errorMessages), the LHDN MyInvois shapes
+// (rejectedDocuments[].error and validationResults.validationSteps) and the
+// generic errors/reason fields used by FTA and other authorities. Each reason is
+// "CODE: message" when the authority gives a code.
+func (s *SubmissionResponse) RejectionReasons() []string {
+	if s == nil {
+		return nil
+	}
+	reasons := &rejectionReasonList{seen: map[string]bool{}}
+	for _, submissionError := range s.Errors {
+		if submissionError == nil {
+			continue
+		}
+		code, message := "", ""
+		if submissionError.Code != nil {
+			code = *submissionError.Code
+		}
+		if submissionError.Message != nil {
+			message = *submissionError.Message
+		}
+		reasons.add(code, message)
+	}
+
+	government := s.GovernmentResponse
+	if government == nil {
+		return reasons.items
+	}
+
+	// ZATCA clearance and reporting
+	if results, ok := government["validationResults"].(map[string]interface{}); ok {
+		for _, entry := range asList(results["errorMessages"]) {
+			reasons.addEntry(entry, "message")
+		}
+		// LHDN document validation
+		for _, step := range asList(results["validationSteps"]) {
+			stepMap, ok := step.(map[string]interface{})
+			if !ok || strings.EqualFold(fmt.Sprint(stepMap["status"]), "valid") {
+				continue
+			}
+			stepError, ok := stepMap["error"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			inner := asList(stepError["innerError"])
+			for _, innerError := range inner {
+				reasons.addEntry(innerError, "error")
+			}
+			if len(inner) == 0 {
+				reasons.addEntry(stepError, "error")
+			}
+		}
+	}
+
+	// LHDN submission
+	for _, document := range asList(government["rejectedDocuments"]) {
+		documentMap, ok := document.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		documentError, ok := documentMap["error"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		details := asList(documentError["details"])
+		for _, detail := range details {
+			reasons.addEntry(detail, "message")
+		}
+		if len(details) == 0 {
+			reasons.addEntry(documentError, "message")
+		}
+	}
+
+	// FTA and other authorities
+	for _, entry := range asList(government["errors"]) {
+		reasons.addEntry(entry, "message")
+	}
+	for _, key := range []string{"rejectionReason", "rejection_reason", "reason"} {
+		if reason, ok := government[key].(string); ok {
+			reasons.add("", reason)
+		}
+	}
+	return reasons.items
+}
+
+// rejectionReasonList Ordered reasons with duplicates dropped
+type rejectionReasonList struct {
+	items []string
+	seen  map[string]bool
+}
+
+// add Append "code: message", or message alone when there is no code
+func (r *rejectionReasonList) add(code, message string) {
+	code, message = strings.TrimSpace(code), strings.TrimSpace(message)
+	if message == "" {
+		return
+	}
+	reason := message
+	if code != "" {
+		reason = code + ": " + message
+	}
+	if r.seen[reason] {
+		return
+	}
+	r.seen[reason] = true
+	r.items = append(r.items, reason)
+}
+
+// addEntry Add a reason given as a plain string or as an object holding the
+// message under messageKey and an optional code or errorCode
+func (r *rejectionReasonList) addEntry(entry interface{}, messageKey string) {
+	switch value := entry.(type) {
+	case string:
+		r.add("", value)
+	case map[string]interface{}:
+		message, _ := value[messageKey].(string)
+		code, _ := value["code"].(string)
+		if code == "" {
+			code, _ = value["errorCode"].(string)
+		}
+		r.add(code, message)
+	}
+}
+
+// asList Value as a slice, nil when it is not one
+func asList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
@@ -0,0 +1,108 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodeGovernmentResponse(t *testing.T, body string) map[string]interface{} {
+	t.Helper()
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return response
+}
+
+func TestRejectionReasonsFromZATCAResponse(t *testing.T) {
+	status := "REJECTED"
+	code, message := "INVALID_DOCUMENT", "Invoice was rejected by ZATCA"
+	response := &SubmissionResponse{
+		Status: &status,
+		Errors: []*SubmissionError{{Code: &code, Message: &message}},
+		GovernmentResponse: decodeGovernmentResponse(t, `{
+			"validationResults": {
+				"infoMessages": [{"type": "INFO", "code": "XSD_ZATCA_VALID", "message": "Complied with UBL 2.1 standards"}],
+				"warningMessages": [{"type": "WARNING", "code": "BR-KSA-08", "message": "Seller identification should be provided"}],
+				"errorMessages": [
+					{"type": "ERROR", "code": "BR-KSA-37", "category": "KSA", "message": "The seller address building number must contain 4 digits."},
+					{"type": "ERROR", "code": "BR-CO-15", "category": "EN", "message": "Invoice total amount with VAT = Invoice total amount without VAT + Invoice total VAT amount."}
+				],
+				"status": "ERROR"
+			},
+			"clearanceStatus": "NOT_CLEARED"
+		}`),
+	}
+
+	want := []string{
+		"INVALID_DOCUMENT: Invoice was rejected by ZATCA",
+		"BR-KSA-37: The seller address building number must contain 4 digits.",
+		"BR-CO-15: Invoice total amount with VAT = Invoice total amount without VAT + Invoice total VAT amount.",
+	}
+	if got := response.RejectionReasons(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected reasons:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestRejectionReasonsFromLHDNResponses(t *testing.T) {
+	submission := &SubmissionResponse{GovernmentResponse: decodeGovernmentResponse(t, `{
+		"submissionUid": "HJSD135P2S7D8IU",
+		"acceptedDocuments": [],
+		"rejectedDocuments": [{
+			"invoiceCodeNumber": "INV12345",
+			"error": {
+				"code": "ValidationError",
+				"message": "Validation Error",
+				"target": "INV12345",
+				"details": [
+					{"code": "CF321", "message": "Issuance date time value of the document is too old", "target": "DatetimeIssued"},
+					{"code": "CF321", "message": "Issuance date time value of the document is too old", "target": "DatetimeIssued"}
+				]
+			}
+		}]
+	}`)}
+	want := []string{"CF321: Issuance date time value of the document is too old"}
+	if got := submission.RejectionReasons(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected submission reasons: %q", got)
+	}
+
+	document := &SubmissionResponse{GovernmentResponse: decodeGovernmentResponse(t, `{
+		"validationResults": {
+			"status": "Invalid",
+			"validationSteps": [
+				{"status": "Valid", "name": "Step01-Structure Validator"},
+				{"status": "Invalid", "name": "Step03-Codes Validator", "error": {
+					"propertyName": null,
+					"errorCode": "DS302",
+					"error": "Validation failed",
+					"innerError": [{"propertyPath": "Invoice.TaxTotal", "errorCode": "CV302", "error": "Tax type code is not valid"}]
+				}},
+				{"status": "Invalid", "name": "Step05-Taxpayer Validator", "error": {"errorCode": "DS304", "error": "Taxpayer TIN is not registered"}}
+			]
+		}
+	}`)}
+	want = []string{"CV302: Tax type code is not valid", "DS304: Taxpayer TIN is not registered"}
+	if got := document.RejectionReasons(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected document reasons: %q", got)
+	}
+}
+
+func TestRejectionReasonsFromGenericResponse(t *testing.T) {
+	response := &SubmissionResponse{GovernmentResponse: decodeGovernmentResponse(t, `{
+		"errors": ["TRN is not valid", {"code": "UAE-12", "message": "Missing buyer endpoint"}],
+		"rejectionReason": "Document failed validation"
+	}`)}
+	want := []string{"TRN is not valid", "UAE-12: Missing buyer endpoint", "Document failed validation"}
+	if got := response.RejectionReasons(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected reasons: %q", got)
+	}
+
+	if reasons := (&SubmissionResponse{}).RejectionReasons(); len(reasons) != 0 {
+		t.Fatalf("expected no reasons, got %q", reasons)
+	}
+	var missing *SubmissionResponse
+	if reasons := missing.RejectionReasons(); reasons != nil {
+		t.Fatalf("expected nil for a nil response, got %q", reasons)
+	}
+}
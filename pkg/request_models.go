@@ -4,8 +4,12 @@ Request models for the Complyance SDK matching Python SDK exactly.
 package complyancesdk
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,6 +32,13 @@ type UnifyRequest struct {
 	CorrelationID      *string                `json:"correlation_id,omitempty"`
 	// SourceOrigin for Integration Engine payload filtering: "SDK" | "LOCAL"
 	SourceOrigin *string `json:"sourceOrigin,omitempty"`
+	// IdempotencyKey is sent as the Idempotency-Key header; generated from the
+	// source, country, purpose, operation, document type and document number
+	// when not set explicitly
+	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+	// Timeout bounds each HTTP attempt for this request. Zero leaves only the
+	// HTTP client timeout; when both apply the shorter one wins.
+	Timeout time.Duration `json:"timeout,omitempty"`
 }
 
 // NewUnifyRequest creates a new UnifyRequest
@@ -187,6 +198,15 @@ func (u *UnifyRequest) SetAPIKey(apiKey string) {
 	u.APIKey = &apiKey
 }
 
+// MarshalJSON Encode the request without its API key, so queued, logged or
+// recorded copies never hold the secret. The key is only sent by the API client.
+func (u *UnifyRequest) MarshalJSON() ([]byte, error) {
+	type unifyRequestJSON UnifyRequest
+	encoded := unifyRequestJSON(*u)
+	encoded.APIKey = nil
+	return json.Marshal(&encoded)
+}
+
 // SetRequestID setter for request ID
 func (u *UnifyRequest) SetRequestID(requestID string) {
 	u.RequestID = &requestID
@@ -212,6 +232,116 @@ func (u *UnifyRequest) SetCorrelationID(correlationID string) {
 	u.CorrelationID = &correlationID
 }
 
+// GetIdempotencyKey getter for idempotency key
+func (u *UnifyRequest) GetIdempotencyKey() *string {
+	return u.IdempotencyKey
+}
+
+// SetIdempotencyKey setter for idempotency key
+func (u *UnifyRequest) SetIdempotencyKey(idempotencyKey string) {
+	u.IdempotencyKey = &idempotencyKey
+}
+
+// GetTimeout getter for the per-request timeout
+func (u *UnifyRequest) GetTimeout() time.Duration {
+	return u.Timeout
+}
+
+// SetTimeout setter for the per-request timeout; zero disables it
+func (u *UnifyRequest) SetTimeout(timeout time.Duration) {
+	u.Timeout = timeout
+}
+
+// EnsureIdempotencyKey Return the idempotency key, generating and storing one
+// if none was set. The generated key is a hash of source, country, purpose,
+// operation, logical document type and document number, so resubmitting the
+// same document yields the same key while a validation or conversion of it, or
+// a credit note reusing an invoice number, does not. The document number is
+// read from DefaultDocumentIDPaths; when the payload carries none the request
+// ID is used instead.
+func (u *UnifyRequest) EnsureIdempotencyKey() string {
+	return u.ensureIdempotencyKey(DefaultDocumentIDPaths)
+}
+
+// ensureIdempotencyKey EnsureIdempotencyKey reading the document number from
+// documentIDPaths, which are relative to the serialized request
+func (u *UnifyRequest) ensureIdempotencyKey(documentIDPaths []string) string {
+	if u.IdempotencyKey != nil && strings.TrimSpace(*u.IdempotencyKey) != "" {
+		return *u.IdempotencyKey
+	}
+	return u.setIdempotencyKey(payloadDocumentID(u.Payload, documentIDPaths))
+}
+
+// ensureBulkIdempotencyKey ensureIdempotencyKey for a bulk request, keyed on
+// the document numbers of all of its documents in order. When a document has
+// none the request ID is used instead.
+func (u *UnifyRequest) ensureBulkIdempotencyKey(documentIDPaths []string) string {
+	if u.IdempotencyKey != nil && strings.TrimSpace(*u.IdempotencyKey) != "" {
+		return *u.IdempotencyKey
+	}
+	documents, _ := u.Payload["documents"].([]interface{})
+	documentNumbers := make([]string, 0, len(documents))
+	for _, document := range documents {
+		record, _ := document.(map[string]interface{})
+		documentNumber := payloadDocumentID(record, documentIDPaths)
+		if documentNumber == "" {
+			return u.setIdempotencyKey("")
+		}
+		documentNumbers = append(documentNumbers, documentNumber)
+	}
+	return u.setIdempotencyKey(strings.Join(documentNumbers, ","))
+}
+
+// payloadDocumentID Document number found in payload at the first of
+// documentIDPaths that holds one, empty when none does
+func payloadDocumentID(payload map[string]interface{}, documentIDPaths []string) string {
+	for _, path := range documentIDPaths {
+		if found, ok := lookupDocumentID(map[string]interface{}{"payload": payload}, path); ok {
+			return found
+		}
+	}
+	return ""
+}
+
+// setIdempotencyKey Set the key generated for documentNumber, or for the
+// request ID when documentNumber is empty
+func (u *UnifyRequest) setIdempotencyKey(documentNumber string) string {
+	sourceID := ""
+	if u.Source != nil {
+		sourceID = u.Source.GetID()
+	}
+	purpose, operation := "", ""
+	if u.Purpose != nil {
+		purpose = string(*u.Purpose)
+	}
+	if u.Operation != nil {
+		operation = string(*u.Operation)
+	}
+	if documentNumber == "" && u.RequestID != nil {
+		documentNumber = *u.RequestID
+	}
+
+	parts := []string{sourceID, u.Country, purpose, operation, u.logicalDocumentType(), documentNumber}
+	hash := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	key := "idem_" + hex.EncodeToString(hash[:])[:32]
+	u.IdempotencyKey = &key
+	return key
+}
+
+// logicalDocumentType Document type of the request including its modifiers:
+// the GETS V2 document type when set, otherwise the document type string or
+// base document type
+func (u *UnifyRequest) logicalDocumentType() string {
+	if u.DocumentTypeV2 != nil {
+		raw, _ := json.Marshal(u.DocumentTypeV2)
+		return string(raw)
+	}
+	if u.DocumentTypeString != nil {
+		return *u.DocumentTypeString
+	}
+	return string(u.DocumentType)
+}
+
 // UnifyRequestBuilder Builder for UnifyRequest matching Python SDK
 type UnifyRequestBuilder struct {
 	source             *Source
@@ -230,6 +360,8 @@ type UnifyRequestBuilder struct {
 	destinations       []*Destination
 	correlationID      *string
 	sourceOrigin       *string
+	idempotencyKey     *string
+	timeout            time.Duration
 }
 
 // Source setter for source
@@ -328,6 +460,18 @@ func (b *UnifyRequestBuilder) SourceOrigin(sourceOrigin string) *UnifyRequestBui
 	return b
 }
 
+// IdempotencyKey setter for idempotency key
+func (b *UnifyRequestBuilder) IdempotencyKey(idempotencyKey string) *UnifyRequestBuilder {
+	b.idempotencyKey = &idempotencyKey
+	return b
+}
+
+// Timeout setter for the per-request timeout
+func (b *UnifyRequestBuilder) Timeout(timeout time.Duration) *UnifyRequestBuilder {
+	b.timeout = timeout
+	return b
+}
+
 // Build builds the UnifyRequest
 func (b *UnifyRequestBuilder) Build() *UnifyRequest {
 	request := NewUnifyRequest()
@@ -353,12 +497,14 @@ func (b *UnifyRequestBuilder) Build() *UnifyRequest {
 	request.Env = b.env
 	request.Destinations = b.destinations
 	request.CorrelationID = b.correlationID
+	request.Timeout = b.timeout
 	if b.sourceOrigin != nil {
 		request.SourceOrigin = b.sourceOrigin
 	} else {
 		sdk := "SDK"
 		request.SourceOrigin = &sdk
 	}
+	request.IdempotencyKey = b.idempotencyKey
 
 	return request
 }
@@ -477,3 +623,12 @@ func (p *PolicyResult) GetDocumentType() string {
 func (p *PolicyResult) GetMetaConfigFlags() map[string]interface{} {
 	return p.MetaConfigFlags
 }
+
+// copy Copy of the policy with its own flags map
+func (p *PolicyResult) copy() *PolicyResult {
+	flags := make(map[string]interface{}, len(p.MetaConfigFlags))
+	for key, value := range p.MetaConfigFlags {
+		flags[key] = value
+	}
+	return NewPolicyResult(p.BaseType, p.DocumentType, flags)
+}
@@ -0,0 +1,67 @@
+/*
+Typed HTTP error responses.
+*/
+package complyancesdk
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ResponseError Non-2xx HTTP response from the Unify API. The SDKError
+// returned for such a response wraps a ResponseError, so the status can be
+// read with errors.As or HTTPStatusCode instead of from ErrorDetail.Context.
+type ResponseError struct {
+	statusCode int
+	message    string
+}
+
+// NewResponseError creates a new ResponseError
+func NewResponseError(statusCode int, message string) *ResponseError {
+	return &ResponseError{statusCode: statusCode, message: message}
+}
+
+// StatusCode getter for the HTTP status code
+func (e *ResponseError) StatusCode() int {
+	return e.statusCode
+}
+
+// Message getter for the error message
+func (e *ResponseError) Message() string {
+	return e.message
+}
+
+// Error implements the error interface
+func (e *ResponseError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("HTTP %d", e.statusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.statusCode, e.message)
+}
+
+// newResponseSDKError SDKError for an HTTP error response, wrapping a
+// ResponseError with its status
+func newResponseSDKError(errorDetail *ErrorDetail, statusCode int) *SDKError {
+	message := ""
+	if errorDetail != nil && errorDetail.Message != nil {
+		message = *errorDetail.Message
+	}
+	return &SDKError{ErrorDetail: errorDetail, cause: NewResponseError(statusCode, message)}
+}
+
+// HTTPStatusCode HTTP status of the response behind err, also when the retry
+// strategy gave up and wrapped it. Errors that did not come from an HTTP
+// response report false.
+func HTTPStatusCode(err error) (int, bool) {
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode(), true
+	}
+	var sdkErr *SDKError
+	if errors.As(err, &sdkErr) {
+		if status := extractHTTPStatus(rootSDKError(sdkErr)); status != nil {
+			return *status, true
+		}
+	}
+	return 0, false
+}
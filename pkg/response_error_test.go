@@ -0,0 +1,95 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseErrorCarriesStatusThroughRetries(t *testing.T) {
+	retry := NewNoRetryConfig()
+	retry.MaxAttempts = 2
+	retry.BaseDelayMs = 1
+	retry.MaxDelayMs = 1
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, retry), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	request, err := currentSDK().buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+		CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), nil)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	_, err = currentSDK().apiClient.SendUnifyRequest(request)
+	if code, _ := GetErrorCode(err); code != ErrorCodeMaxRetriesExceeded {
+		t.Fatalf("expected the retry strategy to give up, got %v", err)
+	}
+
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.StatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("expected a ResponseError with status 503, got %v", err)
+	}
+	if status, ok := HTTPStatusCode(err); !ok || status != http.StatusServiceUnavailable {
+		t.Fatalf("expected HTTPStatusCode 503, got %d, %v", status, ok)
+	}
+	if _, ok := HTTPStatusCode(NewSDKError(NewErrorDetailWithCode(ErrorCodeNetworkError, "offline"))); ok {
+		t.Fatalf("expected no status for a transport error")
+	}
+}
+
+func TestServerErrorIsQueuedWhenStatusWasDecodedFromJSON(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	// An error detail round-tripped through JSON holds the status as float64
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"httpStatus": 503}`), &values); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	detail := NewErrorDetailWithCode(ErrorCodeAPIError, "API request failed with HTTP 503")
+	detail.Context = values
+	if !currentSDK().isServerError(NewSDKError(detail)) {
+		t.Fatalf("expected a float64 503 status to be a server error")
+	}
+
+	// A ResponseError is classified without any context value
+	bare := newResponseSDKError(NewErrorDetailWithCode(ErrorCodeAPIError, "Bad gateway"), http.StatusBadGateway)
+	if !currentSDK().isServerError(bare) {
+		t.Fatalf("expected a 502 ResponseError to be a server error")
+	}
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+		PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{})
+	if err != nil || response.GetStatus() != "queued" {
+		t.Fatalf("expected the 502 to be queued, got %+v, %v", response, err)
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 1 {
+		t.Fatalf("expected one pending submission, got %d", pending)
+	}
+}
+
+func TestIsServerErrorReadsEveryNumericStatusType(t *testing.T) {
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(`{"httpStatus": 503}`), &decoded); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	statuses := []interface{}{503, int64(503), int32(503), decoded["httpStatus"], json.Number("503"), "503"}
+
+	sdk := &GETSUnifySDK{}
+	for _, status := range statuses {
+		// No error code and not retryable, so only the status can mark it as a server error
+		detail := NewErrorDetail()
+		detail.AddContextValue("httpStatus", status)
+		if !sdk.isServerError(NewSDKError(detail)) {
+			t.Fatalf("expected status %v (%T) to be a server error", status, status)
+		}
+	}
+
+	detail := NewErrorDetail()
+	detail.AddContextValue("httpStatus", float64(404))
+	if sdk.isServerError(NewSDKError(detail)) {
+		t.Fatalf("expected a 404 not to be a server error")
+	}
+}
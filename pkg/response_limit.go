@@ -0,0 +1,50 @@
+/*
+Response body size limits for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseBytes is the largest response body the client reads by default
+const DefaultMaxResponseBytes int64 = 16 << 20
+
+// SetMaxResponseBytes Limit how many bytes of a response body are read. Larger
+// responses fail with an error instead of being buffered in full. A limit of 0
+// or less restores DefaultMaxResponseBytes.
+func (a *APIClient) SetMaxResponseBytes(maxBytes int64) {
+	a.maxResponseBytes = maxBytes
+}
+
+// GetMaxResponseBytes Effective response body limit
+func (a *APIClient) GetMaxResponseBytes() int64 {
+	if a.maxResponseBytes <= 0 {
+		return DefaultMaxResponseBytes
+	}
+	return a.maxResponseBytes
+}
+
+// readResponseBody Read body up to the configured limit. Reading stops one byte
+// past the limit, so a runaway response is never buffered in full.
+func (a *APIClient) readResponseBody(body io.Reader) ([]byte, error) {
+	maxBytes := a.GetMaxResponseBytes()
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeAPIError,
+			fmt.Sprintf("Failed to read response body: %v", err),
+		))
+	}
+	if int64(len(data)) > maxBytes {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeAPIError,
+			fmt.Sprintf("Response body exceeds the %d byte limit", maxBytes),
+		).WithSuggestion("Raise MaxResponseBytes in SDKConfig if responses this large are expected")
+		detail.AddContextValue("maxResponseBytes", maxBytes)
+		detail.Retryable = false
+		return nil, NewSDKError(detail)
+	}
+	return data, nil
+}
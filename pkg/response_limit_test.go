@@ -0,0 +1,51 @@
+package complyancesdk
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResponseLargerThanLimitIsRejected(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"padding":"`))
+		chunk := bytes.Repeat([]byte("x"), 1024)
+		for i := 0; i < 1024; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		_, _ = w.Write([]byte(`"}}`))
+	})
+	client.SetMaxResponseBytes(4096)
+
+	_, err := client.SendUnifyRequest(newTestUnifyRequest("INV-HUGE"))
+	if err == nil {
+		t.Fatalf("expected oversized response to fail")
+	}
+	if IsRetryable(err) {
+		t.Fatalf("expected size limit error not to be retryable")
+	}
+	if root := rootSDKError(err); root == nil || !strings.Contains(*root.ErrorDetail.GetMessage(), "4096 byte limit") {
+		t.Fatalf("expected non-retryable size limit error, got %v", err)
+	}
+}
+
+func TestResponseWithinLimitIsRead(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	client.SetMaxResponseBytes(64)
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-SMALL")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := (&APIClient{}).GetMaxResponseBytes(); got != DefaultMaxResponseBytes {
+		t.Fatalf("expected default limit %d, got %d", DefaultMaxResponseBytes, got)
+	}
+}
@@ -4,6 +4,7 @@ Response models for the Complyance SDK matching Python SDK exactly.
 package complyancesdk
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ type ErrorDetail struct {
 	FieldValue         interface{}            `json:"field_value,omitempty"`
 	Context            map[string]interface{} `json:"context,omitempty"`
 	ValidationErrors   []map[string]string    `json:"validation_errors,omitempty"`
+	Validation         *ValidationResponse    `json:"validation,omitempty"`
 	Retryable          bool                   `json:"retryable"`
 	RetryAfterSeconds  *int                   `json:"retry_after_seconds,omitempty"`
 	Timestamp          *string                `json:"timestamp,omitempty"`
@@ -80,6 +82,38 @@ func (e *ErrorDetail) AddValidationError(field, message, code string) {
 	e.ValidationErrors = append(e.ValidationErrors, validationError)
 }
 
+// AddValidationErrorModel Add a typed validation error
+func (e *ErrorDetail) AddValidationErrorModel(model *ValidationErrorModel) {
+	if model == nil {
+		return
+	}
+	if e.Validation == nil {
+		e.Validation = &ValidationResponse{}
+	}
+	e.Validation.Errors = append(e.Validation.Errors, model)
+	if model.Method != nil && !containsString(e.Validation.Methods, *model.Method) {
+		e.Validation.Methods = append(e.Validation.Methods, *model.Method)
+	}
+}
+
+// GetValidationErrors Typed validation errors reported by the server, if any
+func (e *ErrorDetail) GetValidationErrors() []*ValidationErrorModel {
+	if e.Validation == nil {
+		return nil
+	}
+	return e.Validation.Errors
+}
+
+// containsString Check whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // GetContextValue Get context value
 func (e *ErrorDetail) GetContextValue(key string) interface{} {
 	if e.Context == nil {
@@ -284,6 +318,50 @@ func (t *TemplateResponse) GetAIMappingApplied() *bool {
 	return t.AIMappingApplied
 }
 
+// MappingCompletionPercent Share of mandatory fields that are mapped, from 0 to
+// 100. When the field counts are missing it is 100 if the server reported the
+// mapping as completed and 0 otherwise.
+func (t *TemplateResponse) MappingCompletionPercent() float64 {
+	if t == nil {
+		return 0
+	}
+	if t.TotalMandatoryFields == nil || *t.TotalMandatoryFields <= 0 {
+		if t.MappingCompleted || (t.TotalMandatoryFields != nil && *t.TotalMandatoryFields == 0) {
+			return 100
+		}
+		return 0
+	}
+	total := *t.TotalMandatoryFields
+	mapped := total - t.MissingMandatoryCount()
+	return float64(mapped) * 100 / float64(total)
+}
+
+// MissingMandatoryCount Number of mandatory fields not mapped yet; 0 when the
+// total is unknown
+func (t *TemplateResponse) MissingMandatoryCount() int {
+	if t == nil || t.TotalMandatoryFields == nil || *t.TotalMandatoryFields <= 0 {
+		return 0
+	}
+	total := *t.TotalMandatoryFields
+	mapped := 0
+	if t.MappedMandatoryFields != nil && *t.MappedMandatoryFields > 0 {
+		mapped = *t.MappedMandatoryFields
+	}
+	if mapped >= total {
+		return 0
+	}
+	return total - mapped
+}
+
+// IsFullyMapped True only when the field counts are present and every mandatory
+// field is mapped
+func (t *TemplateResponse) IsFullyMapped() bool {
+	if t == nil || t.TotalMandatoryFields == nil || t.MappedMandatoryFields == nil {
+		return false
+	}
+	return *t.MappedMandatoryFields >= *t.TotalMandatoryFields
+}
+
 // ConversionResponse model matching Python SDK
 type ConversionResponse struct {
 	Success        bool                   `json:"success"`
@@ -472,22 +550,27 @@ type SubmissionResponse struct {
 
 // IsAccepted Check if submission is accepted
 func (s *SubmissionResponse) IsAccepted() bool {
-	return s.Status != nil && *s.Status == "accepted"
+	return s.Status != nil && strings.EqualFold(*s.Status, "accepted")
 }
 
 // IsRejected Check if submission is rejected
 func (s *SubmissionResponse) IsRejected() bool {
-	return s.Status != nil && *s.Status == "rejected"
+	return s.Status != nil && strings.EqualFold(*s.Status, "rejected")
 }
 
 // IsFailed Check if submission failed
 func (s *SubmissionResponse) IsFailed() bool {
-	return s.Status != nil && *s.Status == "failed"
+	return s.Status != nil && strings.EqualFold(*s.Status, "failed")
 }
 
 // IsSubmitted Check if submission was submitted
 func (s *SubmissionResponse) IsSubmitted() bool {
-	return s.Status != nil && *s.Status == "submitted"
+	return s.Status != nil && strings.EqualFold(*s.Status, "submitted")
+}
+
+// IsTerminal Check if the submission has reached a final status
+func (s *SubmissionResponse) IsTerminal() bool {
+	return s.Status != nil && SubmissionStatus(strings.TrimSpace(*s.Status)).IsTerminal()
 }
 
 // GetSubmissionID getter for submission ID
@@ -539,8 +622,18 @@ type ProcessingResponse struct {
 	ProcessedAt           *string  `json:"processed_at,omitempty"`
 	RequestID             *string  `json:"request_id,omitempty"`
 	Status                *string  `json:"status,omitempty"`
+	// Metadata holds extra processing details, such as step timings under
+	// ProcessingMetadataKeyStepTimings
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// ProcessingPipelineSteps Steps a submission goes through on the server, in order
+var ProcessingPipelineSteps = []string{"source", "payload", "template", "conversion", "validation", "submission"}
+
+// ProcessingMetadataKeyStepTimings Processing metadata key holding the
+// milliseconds spent in each step
+const ProcessingMetadataKeyStepTimings = "step_timings"
+
 // IsInvoicingPurpose check if invoicing purpose
 func (p *ProcessingResponse) IsInvoicingPurpose() bool {
 	return p.Purpose != nil && *p.Purpose == "invoicing"
@@ -586,6 +679,62 @@ func (p *ProcessingResponse) GetStatus() *string {
 	return p.Status
 }
 
+// GetMetadata getter for metadata
+func (p *ProcessingResponse) GetMetadata() map[string]interface{} {
+	return p.Metadata
+}
+
+// StepDurations Milliseconds spent in each step, keyed by lower-case step
+// name, from the step timings in the metadata. Nil when the server sent none.
+func (p *ProcessingResponse) StepDurations() map[string]int {
+	if p == nil {
+		return nil
+	}
+	timings, ok := p.Metadata[ProcessingMetadataKeyStepTimings].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	durations := make(map[string]int, len(timings))
+	for step, value := range timings {
+		var ms float64
+		switch typed := value.(type) {
+		case float64:
+			ms = typed
+		case int:
+			ms = float64(typed)
+		case json.Number:
+			parsed, err := typed.Float64()
+			if err != nil {
+				continue
+			}
+			ms = parsed
+		default:
+			continue
+		}
+		durations[strings.ToLower(strings.TrimSpace(step))] = int(ms)
+	}
+	return durations
+}
+
+// FailedStep First step of ProcessingPipelineSteps missing from the completed
+// steps: the step that failed when processing failed, or the one still to run
+// otherwise. Empty once every step has completed, or without a response.
+func (p *ProcessingResponse) FailedStep() string {
+	if p == nil {
+		return ""
+	}
+	completed := make(map[string]bool, len(p.CompletedSteps))
+	for _, step := range p.CompletedSteps {
+		completed[strings.ToLower(strings.TrimSpace(step))] = true
+	}
+	for _, step := range ProcessingPipelineSteps {
+		if !completed[step] {
+			return step
+		}
+	}
+	return ""
+}
+
 // DestinationsResponse model matching Python SDK
 type DestinationsResponse struct {
 	Count  *int     `json:"count,omitempty"`
@@ -676,6 +825,7 @@ type UnifyResponseData struct {
 	Submission           *SubmissionResponse          `json:"submission,omitempty"`
 	Processing           *ProcessingResponse          `json:"processing,omitempty"`
 	Destinations         *DestinationsResponse        `json:"destinations,omitempty"`
+	BulkSubmission       *BulkSubmissionResponse      `json:"bulk_submission,omitempty"`
 }
 
 // GetSource getter for source
@@ -718,6 +868,11 @@ func (u *UnifyResponseData) GetSubmission() *SubmissionResponse {
 	return u.Submission
 }
 
+// GetBulkSubmission getter for the per-document results of an OperationBulk request
+func (u *UnifyResponseData) GetBulkSubmission() *BulkSubmissionResponse {
+	return u.BulkSubmission
+}
+
 // GetProcessing getter for processing
 func (u *UnifyResponseData) GetProcessing() *ProcessingResponse {
 	return u.Processing
@@ -728,6 +883,22 @@ func (u *UnifyResponseData) GetDestinations() *DestinationsResponse {
 	return u.Destinations
 }
 
+// Metadata keys populated with correlation identifiers on a UnifyResponse
+const (
+	MetadataKeyCorrelationID = "correlationId"
+	MetadataKeyTraceID       = "traceId"
+	MetadataKeyRequestID     = "requestId"
+
+	// MetadataKeyRequestJSON holds the serialized request that was sent, with the
+	// API key redacted, when SDKConfig.RecordRequestJSON is enabled
+	MetadataKeyRequestJSON = "requestJson"
+
+	// MetadataKeyRawBody and MetadataKeyContentType hold the body and its
+	// Content-Type when a successful response is not JSON
+	MetadataKeyRawBody     = "rawBody"
+	MetadataKeyContentType = "contentType"
+)
+
 // UnifyResponse model matching Python SDK
 type UnifyResponse struct {
 	Status   string                 `json:"status"`
@@ -772,6 +943,47 @@ func (u *UnifyResponse) GetError() *ErrorDetail {
 	return u.Error
 }
 
+// GetCorrelationID Correlation ID echoed by the server, or the one that was sent
+func (u *UnifyResponse) GetCorrelationID() *string {
+	return u.metadataString(MetadataKeyCorrelationID)
+}
+
+// GetTraceID Trace ID echoed by the server in the body or headers
+func (u *UnifyResponse) GetTraceID() *string {
+	return u.metadataString(MetadataKeyTraceID)
+}
+
+// GetRequestID Request ID echoed by the server in the body or headers
+func (u *UnifyResponse) GetRequestID() *string {
+	return u.metadataString(MetadataKeyRequestID)
+}
+
+// GetRequestJSON Serialized request that produced this response, with the API
+// key redacted, or nil unless SDKConfig.RecordRequestJSON is enabled
+func (u *UnifyResponse) GetRequestJSON() []byte {
+	if value := u.metadataString(MetadataKeyRequestJSON); value != nil {
+		return []byte(*value)
+	}
+	return nil
+}
+
+// GetRawBody Body of a successful response that was not JSON, e.g. a PDF, or
+// nil when the response was parsed as JSON
+func (u *UnifyResponse) GetRawBody() []byte {
+	if value := u.metadataString(MetadataKeyRawBody); value != nil {
+		return []byte(*value)
+	}
+	return nil
+}
+
+// metadataString Non-empty string metadata value, or nil
+func (u *UnifyResponse) metadataString(key string) *string {
+	if value, ok := u.Metadata[key].(string); ok && value != "" {
+		return &value
+	}
+	return nil
+}
+
 // SetStatus setter for status
 func (u *UnifyResponse) SetStatus(status string) {
 	u.Status = status
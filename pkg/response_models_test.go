@@ -0,0 +1,64 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTemplateResponseMappingCompleteness(t *testing.T) {
+	intPtr := func(value int) *int { return &value }
+	cases := []struct {
+		name     string
+		template *TemplateResponse
+		percent  float64
+		missing  int
+		complete bool
+	}{
+		{"partial", &TemplateResponse{TotalMandatoryFields: intPtr(8), MappedMandatoryFields: intPtr(6)}, 75, 2, false},
+		{"complete", &TemplateResponse{TotalMandatoryFields: intPtr(8), MappedMandatoryFields: intPtr(8), MappingCompleted: true}, 100, 0, true},
+		{"nothing mapped", &TemplateResponse{TotalMandatoryFields: intPtr(4)}, 0, 4, false},
+		{"no mandatory fields", &TemplateResponse{TotalMandatoryFields: intPtr(0), MappedMandatoryFields: intPtr(0)}, 100, 0, true},
+		{"counts missing", &TemplateResponse{MappingCompleted: true}, 100, 0, false},
+		{"counts missing and incomplete", &TemplateResponse{}, 0, 0, false},
+		{"nil template", nil, 0, 0, false},
+	}
+	for _, tc := range cases {
+		if got := tc.template.MappingCompletionPercent(); got != tc.percent {
+			t.Fatalf("%s: expected %v%%, got %v%%", tc.name, tc.percent, got)
+		}
+		if got := tc.template.MissingMandatoryCount(); got != tc.missing {
+			t.Fatalf("%s: expected %d missing, got %d", tc.name, tc.missing, got)
+		}
+		if got := tc.template.IsFullyMapped(); got != tc.complete {
+			t.Fatalf("%s: expected IsFullyMapped %v, got %v", tc.name, tc.complete, got)
+		}
+	}
+}
+
+func TestProcessingResponseTimeline(t *testing.T) {
+	var processing ProcessingResponse
+	body := `{"completed_steps":["source","payload","Template"],"status":"failed",` +
+		`"metadata":{"step_timings":{"source":12,"payload":40.6,"template":1500,"conversion":"slow"}}}`
+	if err := json.Unmarshal([]byte(body), &processing); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if step := processing.FailedStep(); step != "conversion" {
+		t.Fatalf("expected conversion to be the failed step, got %q", step)
+	}
+	durations := processing.StepDurations()
+	if len(durations) != 3 || durations["source"] != 12 || durations["payload"] != 40 || durations["template"] != 1500 {
+		t.Fatalf("unexpected step durations %v", durations)
+	}
+
+	processing.CompletedSteps = append(processing.CompletedSteps, "conversion", "validation", "submission")
+	if step := processing.FailedStep(); step != "" {
+		t.Fatalf("expected no failed step once all steps completed, got %q", step)
+	}
+	if (&ProcessingResponse{}).StepDurations() != nil {
+		t.Fatalf("expected nil durations without step timings")
+	}
+	if step := (&ProcessingResponse{}).FailedStep(); step != "source" {
+		t.Fatalf("expected source to be next when nothing completed, got %q", step)
+	}
+}
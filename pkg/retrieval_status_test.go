@@ -1,6 +1,13 @@
 package complyancesdk
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
 
 func TestGetDocumentStatusRequiresDocumentID(t *testing.T) {
 	cfg := NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, nil)
@@ -64,23 +71,270 @@ func TestSubmitPayloadRejectsUnknownSource(t *testing.T) {
 	}
 }
 
-func TestSubmitPayloadReturnsMockedSuccessWithValidInput(t *testing.T) {
+func TestSubmitPayloadSendsUnifyRequest(t *testing.T) {
 	sourceType := SourceTypeFirstParty
 	sources := []*Source{NewSource("src", "1", &sourceType)}
-	cfg := NewSDKConfig("test-key", EnvironmentSandbox, sources, nil)
-	if err := Configure(cfg); err != nil {
-		t.Fatalf("configure failed: %v", err)
-	}
+	var sent map[string]interface{}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, sources, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"sub-789","status":"accepted"}}}`))
+	})
 
-	response, err := SubmitPayload("{\"invoice\":\"ok\"}", "src:1", CountrySA, DocumentTypeTaxInvoice)
+	response, err := SubmitPayload(`{"invoice_data":{"invoice_number":"INV-1"}}`, "src:1", CountrySA, DocumentTypeTaxInvoice)
 	if err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
+	if response.GetSubmissionID() != "sub-789" {
+		t.Fatalf("expected the server's submission ID, got %s", response.GetSubmissionID())
+	}
+	if response.GetStatus() != SubmissionStatusAccepted {
+		t.Fatalf("expected ACCEPTED, got %s", response.GetStatus())
+	}
+	documentType, _ := sent["documentType"].(map[string]interface{})
+	if documentType["base"] != "tax_invoice" || sent["country"] != "SA" {
+		t.Fatalf("unexpected request %v", sent)
+	}
+	payload, _ := sent["payload"].(map[string]interface{})
+	invoiceData, _ := payload["invoice_data"].(map[string]interface{})
+	if invoiceData["invoice_number"] != "INV-1" {
+		t.Fatalf("expected the caller's payload to be sent, got %v", sent["payload"])
+	}
+	if invoiceData["document_type"] != "tax_invoice" || payload["meta"] == nil {
+		t.Fatalf("expected the payload to be prepared as PushToUnify prepares it, got %v", payload)
+	}
+}
+
+func TestSubmitPayloadMapsSimplifiedDocumentTypes(t *testing.T) {
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+	var sent map[string]interface{}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, sources, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"sub-790","status":"accepted"}}}`))
+	})
+
+	if _, err := SubmitPayload(`{"invoice_data":{"invoice_number":"INV-2","original_invoice_number":"INV-1"}}`, "src:1", CountrySA, DocumentTypeSimplifiedCreditNote); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	documentType, _ := sent["documentType"].(map[string]interface{})
+	modifiers, _ := documentType["modifiers"].([]interface{})
+	if documentType["base"] != "credit_note" || len(modifiers) != 1 || modifiers[0] != "b2c" {
+		t.Fatalf("expected a simplified credit note, got %v", sent["documentType"])
+	}
+}
+
+func TestSubmitPayloadContextQueuesOnServerError(t *testing.T) {
+	sourceType := SourceTypeFirstParty
+	sources := []*Source{NewSource("src", "1", &sourceType)}
+	attempts := 0
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, sources, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	submission, err := SubmitPayloadContext(context.Background(), `{"invoice_data":{"invoice_number":"INV-2","original_invoice_number":"INV-1"}}`, "src:1", CountrySA, DocumentTypeTaxInvoice)
+	if err != nil {
+		t.Fatalf("expected the failure to be queued, got error: %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected one attempt, got %d", attempts)
+	}
+	if submission.GetSubmissionID() == nil || submission.Status == nil || *submission.Status != string(SubmissionStatusQueued) {
+		t.Fatalf("expected a queued submission, got %+v", submission)
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 1 {
+		t.Fatalf("expected the submission to be queued, got %d pending", pending)
+	}
+}
+
+func TestGetStatusContextReturnsSubmissionStatus(t *testing.T) {
+	attempts := 0
+	var requestedPath string
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, NewDefaultRetryConfig())
+	cfg.RetryConfig.BaseDelayMs = 1
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		requestedPath = r.URL.Path
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"status": "success",
+			"data": {
+				"submission": {
+					"submission_id": "sub-123",
+					"status": "accepted",
+					"authority": "ZATCA",
+					"response": {
+						"clearance_status": "CLEARED",
+						"uuid": "3cf5ee18-ee25-44ea-a444-2c37ba7f28be",
+						"hash": "hash-value",
+						"qr_code": "qr-value"
+					}
+				}
+			}
+		}`))
+	})
+
+	status, err := GetStatusContext(context.Background(), "sub-123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestedPath != "/api/v3/submissions/sub-123/status" {
+		t.Fatalf("unexpected status path %q", requestedPath)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected the 503 to be retried, got %d attempts", attempts)
+	}
+	if !status.IsAccepted() || *status.GetSubmissionID() != "sub-123" {
+		t.Fatalf("unexpected submission status: %+v", status)
+	}
+	data := status.GetResponse()
+	if data == nil || *data.GetClearanceStatus() != "CLEARED" || *data.GetUUID() == "" || *data.GetHash() != "hash-value" || *data.GetQRCode() != "qr-value" {
+		t.Fatalf("clearance details not deserialized: %+v", data)
+	}
+}
+
+func TestGetStatusContextHonorsCancellation(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, nil)
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("no request expected after cancellation")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GetStatusContext(ctx, "sub-123")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWaitForTerminalStatusStopsAtTerminalState(t *testing.T) {
+	polls := 0
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		status := "PROCESSING"
+		if polls >= 3 {
+			status = "ACCEPTED"
+		}
+		_, _ = w.Write([]byte(`{"data":{"submission":{"submission_id":"sub-1","status":"` + status + `"}}}`))
+	})
+
+	opts := NewWaitOptions()
+	opts.PollInterval = time.Millisecond
+	opts.MaxPollInterval = 4 * time.Millisecond
+	status, err := WaitForTerminalStatus(context.Background(), "sub-1", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.IsAccepted() || polls != 3 {
+		t.Fatalf("expected ACCEPTED after 3 polls, got %v after %d", *status.GetStatus(), polls)
+	}
+}
 
-	if response.GetSubmissionID() != "mock-id" {
-		t.Fatalf("expected mock-id, got %s", response.GetSubmissionID())
+func TestWaitForTerminalStatusTimesOut(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"submission":{"submission_id":"sub-2","status":"PENDING"}}}`))
+	})
+
+	opts := NewWaitOptions()
+	opts.PollInterval = time.Millisecond
+	opts.MaxWait = 20 * time.Millisecond
+	status, err := WaitForTerminalStatus(context.Background(), "sub-2", opts)
+	sdkErr, ok := err.(*SDKError)
+	if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeSubmissionTimeout {
+		t.Fatalf("expected SUBMISSION_TIMEOUT, got %v", err)
+	}
+	if status == nil || *status.GetStatus() != "PENDING" {
+		t.Fatalf("expected last PENDING status, got %+v", status)
+	}
+}
+
+func TestWaitForTerminalStatusTimesOutOnClientClock(t *testing.T) {
+	polls := 0
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		_, _ = w.Write([]byte(`{"data":{"submission":{"submission_id":"sub-3","status":"PENDING"}}}`))
+	})
+	clock := NewFakeClock(time.Now())
+	globalSDK.apiClient.SetClock(clock)
+	t.Cleanup(func() { globalSDK.apiClient.SetClock(SystemClock) })
+
+	opts := NewWaitOptions()
+	opts.PollInterval = 10 * time.Second
+	opts.MaxPollInterval = 10 * time.Second
+	opts.MaxWait = time.Minute
+	done := make(chan error, 1)
+	go func() {
+		_, err := WaitForTerminalStatus(context.Background(), "sub-3", opts)
+		done <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			sdkErr, ok := err.(*SDKError)
+			if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeSubmissionTimeout {
+				t.Fatalf("expected SUBMISSION_TIMEOUT, got %v", err)
+			}
+			if polls != 6 {
+				t.Fatalf("expected 6 polls within a minute at 10s intervals, got %d", polls)
+			}
+			return
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("wait did not time out on the fake clock")
+		}
+		if clock.PendingTimers() > 0 {
+			clock.Advance(10 * time.Second)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGetSubmissionDocumentReturnsSignedXML(t *testing.T) {
+	const signedXML = `<?xml version="1.0" encoding="UTF-8"?><Invoice><ID>INV-1</ID></Invoice>`
+	attempts := 0
+	var requested, accept string
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, []*Source{}, NewDefaultRetryConfig())
+	cfg.RetryConfig.BaseDelayMs = 1
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		requested = r.URL.RequestURI()
+		accept = r.Header.Get("Accept")
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = w.Write([]byte(signedXML))
+	})
+
+	document, err := GetSubmissionDocument(context.Background(), "sub-123", DocumentFormatXML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if response.GetStatus() != SubmissionStatusSubmitted {
-		t.Fatalf("expected SUBMITTED, got %s", response.GetStatus())
+	if requested != "/api/v3/submissions/sub-123/document?format=xml" || accept != "application/xml" {
+		t.Fatalf("unexpected request %q with Accept %q", requested, accept)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected the 503 to be retried, got %d attempts", attempts)
+	}
+	if string(document.Content) != signedXML || document.ContentType != "application/xml; charset=utf-8" || document.Format != DocumentFormatXML {
+		t.Fatalf("unexpected document %+v", document)
+	}
+
+	if _, err := GetSubmissionDocument(context.Background(), "sub-123", "docx"); err == nil {
+		t.Fatalf("expected an error for an unknown format")
 	}
 }
@@ -37,8 +37,21 @@ type CircuitBreaker struct {
 	// lastStateChange is the time of the last state change
 	lastStateChange time.Time
 	
-	// mutex protects lastStateChange
+	// failureWindow limits the failures counted to those this recent; all
+	// consecutive failures count when 0
+	failureWindow time.Duration
+
+	// failureTimes holds the times of the failures inside failureWindow
+	failureTimes []time.Time
+
+	// now returns the current time
+	now func() time.Time
+
+	// mutex protects lastStateChange and failureTimes
 	mutex sync.RWMutex
+
+	// sink receives state transition metrics
+	sink MetricsSink
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -49,7 +62,47 @@ func NewCircuitBreaker(failureThreshold int, timeout time.Duration) *CircuitBrea
 		failureThreshold: int32(failureThreshold),
 		timeout:         timeout,
 		lastStateChange: time.Now(),
+		sink:            NoopMetricsSink{},
+		now:             time.Now,
+	}
+}
+
+// WithFailureWindow only counts failures within window towards the failure
+// threshold, so sporadic failures spread over a long time do not open the
+// circuit; 0 counts every consecutive failure
+func (cb *CircuitBreaker) WithFailureWindow(window time.Duration) *CircuitBreaker {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	cb.failureWindow = window
+	cb.failureTimes = nil
+	return cb
+}
+
+// WithClock sets the function used for the current time; nil restores time.Now
+func (cb *CircuitBreaker) WithClock(now func() time.Time) *CircuitBreaker {
+	if now == nil {
+		now = time.Now
 	}
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	cb.now = now
+	cb.lastStateChange = now()
+	return cb
+}
+
+// WithMetricsSink sets the sink notified of state transitions
+func (cb *CircuitBreaker) WithMetricsSink(sink MetricsSink) *CircuitBreaker {
+	cb.sink = SinkOrNoop(sink)
+	return cb
+}
+
+// recordTransition reports a state change to the metrics sink
+func (cb *CircuitBreaker) recordTransition(from, to CircuitState) {
+	cb.sink.IncrCounter(MetricCircuitTransitions, map[string]string{
+		LabelFrom: from.String(),
+		LabelTo:   to.String(),
+	})
+	cb.sink.Gauge(MetricCircuitState, float64(to), nil)
 }
 
 // IsOpen returns true if the circuit is open
@@ -59,7 +112,7 @@ func (cb *CircuitBreaker) IsOpen() bool {
 	// If circuit is open, check if timeout has elapsed
 	if state == CircuitOpen {
 		cb.mutex.RLock()
-		elapsed := time.Since(cb.lastStateChange)
+		elapsed := cb.now().Sub(cb.lastStateChange)
 		cb.mutex.RUnlock()
 		
 		// If timeout has elapsed, transition to half-open
@@ -78,7 +131,7 @@ func (cb *CircuitBreaker) RecordSuccess() {
 	state := CircuitState(atomic.LoadInt32(&cb.state))
 	
 	// Reset failure count
-	atomic.StoreInt32(&cb.failureCount, 0)
+	cb.clearFailures()
 	
 	// If circuit is half-open, close it
 	if state == CircuitHalfOpen {
@@ -91,7 +144,7 @@ func (cb *CircuitBreaker) RecordFailure() {
 	state := CircuitState(atomic.LoadInt32(&cb.state))
 	
 	// Increment failure count
-	newCount := atomic.AddInt32(&cb.failureCount, 1)
+	newCount := cb.addFailure()
 	
 	// If circuit is closed and failure threshold is reached, open it
 	if state == CircuitClosed && newCount >= cb.failureThreshold {
@@ -106,17 +159,56 @@ func (cb *CircuitBreaker) RecordFailure() {
 
 // Reset resets the circuit breaker to closed state
 func (cb *CircuitBreaker) Reset() {
-	atomic.StoreInt32(&cb.failureCount, 0)
+	cb.clearFailures()
 	cb.transitionToClosed()
 }
 
+// addFailure counts a failure, dropping those older than the failure window,
+// and returns the new count
+func (cb *CircuitBreaker) addFailure() int32 {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if cb.failureWindow <= 0 {
+		return atomic.AddInt32(&cb.failureCount, 1)
+	}
+	cb.failureTimes = append(cb.failureTimes, cb.now())
+	cb.expireFailures()
+	return atomic.LoadInt32(&cb.failureCount)
+}
+
+// expireFailures drops failures older than the failure window; callers hold mutex
+func (cb *CircuitBreaker) expireFailures() {
+	if cb.failureWindow <= 0 {
+		return
+	}
+	cutoff := cb.now().Add(-cb.failureWindow)
+	expired := 0
+	for expired < len(cb.failureTimes) && !cb.failureTimes[expired].After(cutoff) {
+		expired++
+	}
+	cb.failureTimes = cb.failureTimes[expired:]
+	atomic.StoreInt32(&cb.failureCount, int32(len(cb.failureTimes)))
+}
+
+// clearFailures resets the failure count
+func (cb *CircuitBreaker) clearFailures() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	cb.failureTimes = nil
+	atomic.StoreInt32(&cb.failureCount, 0)
+}
+
 // GetState returns the current state of the circuit breaker
 func (cb *CircuitBreaker) GetState() CircuitState {
 	return CircuitState(atomic.LoadInt32(&cb.state))
 }
 
-// GetFailureCount returns the current failure count
+// GetFailureCount returns the current failure count, only counting failures
+// inside the failure window when one is set
 func (cb *CircuitBreaker) GetFailureCount() int {
+	cb.mutex.Lock()
+	cb.expireFailures()
+	cb.mutex.Unlock()
 	return int(atomic.LoadInt32(&cb.failureCount))
 }
 
@@ -140,11 +232,16 @@ func (cb *CircuitBreaker) GetLastStateChange() time.Time {
 // transitionToOpen changes the circuit state to open
 func (cb *CircuitBreaker) transitionToOpen() {
 	// Only transition if not already open
-	if atomic.CompareAndSwapInt32(&cb.state, int32(CircuitClosed), int32(CircuitOpen)) ||
-	   atomic.CompareAndSwapInt32(&cb.state, int32(CircuitHalfOpen), int32(CircuitOpen)) {
+	if atomic.CompareAndSwapInt32(&cb.state, int32(CircuitClosed), int32(CircuitOpen)) {
+		cb.mutex.Lock()
+		cb.lastStateChange = cb.now()
+		cb.mutex.Unlock()
+		cb.recordTransition(CircuitClosed, CircuitOpen)
+	} else if atomic.CompareAndSwapInt32(&cb.state, int32(CircuitHalfOpen), int32(CircuitOpen)) {
 		cb.mutex.Lock()
-		cb.lastStateChange = time.Now()
+		cb.lastStateChange = cb.now()
 		cb.mutex.Unlock()
+		cb.recordTransition(CircuitHalfOpen, CircuitOpen)
 	}
 }
 
@@ -153,8 +250,9 @@ func (cb *CircuitBreaker) transitionToHalfOpen() {
 	// Only transition if currently open
 	if atomic.CompareAndSwapInt32(&cb.state, int32(CircuitOpen), int32(CircuitHalfOpen)) {
 		cb.mutex.Lock()
-		cb.lastStateChange = time.Now()
+		cb.lastStateChange = cb.now()
 		cb.mutex.Unlock()
+		cb.recordTransition(CircuitOpen, CircuitHalfOpen)
 	}
 }
 
@@ -164,7 +262,8 @@ func (cb *CircuitBreaker) transitionToClosed() {
 	oldState := atomic.SwapInt32(&cb.state, int32(CircuitClosed))
 	if oldState != int32(CircuitClosed) {
 		cb.mutex.Lock()
-		cb.lastStateChange = time.Now()
+		cb.lastStateChange = cb.now()
 		cb.mutex.Unlock()
+		cb.recordTransition(CircuitState(oldState), CircuitClosed)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,83 @@
+package retry
+
+import "time"
+
+// MetricsSink receives retry, circuit breaker and queue metrics so they can be
+// exported to Prometheus, statsd or any other backend
+type MetricsSink interface {
+	// IncrCounter increments the named counter by one
+	IncrCounter(name string, labels map[string]string)
+
+	// RecordDuration records how long the named operation took
+	RecordDuration(name string, duration time.Duration, labels map[string]string)
+
+	// Gauge sets the named gauge to value
+	Gauge(name string, value float64, labels map[string]string)
+}
+
+// Metric names emitted to a MetricsSink
+const (
+	// MetricRetryAttempts counts every attempt, including the first
+	MetricRetryAttempts = "complyance_retry_attempts_total"
+
+	// MetricRetrySuccesses counts operations that eventually succeeded
+	MetricRetrySuccesses = "complyance_retry_successes_total"
+
+	// MetricRetryFailures counts failed attempts
+	MetricRetryFailures = "complyance_retry_failures_total"
+
+	// MetricRetryDuration records the total time spent on an operation across attempts
+	MetricRetryDuration = "complyance_retry_duration"
+
+	// MetricCircuitTransitions counts circuit breaker state transitions
+	MetricCircuitTransitions = "complyance_circuit_breaker_transitions_total"
+
+	// MetricCircuitState reports the current circuit breaker state
+	// (0 closed, 1 open, 2 half-open)
+	MetricCircuitState = "complyance_circuit_breaker_state"
+
+	// MetricQueueDepth reports the number of queued submissions per state
+	MetricQueueDepth = "complyance_queue_depth"
+)
+
+// Metric label names
+const (
+	LabelOperation = "operation"
+	LabelOutcome   = "outcome"
+	LabelErrorCode = "error_code"
+	LabelFrom      = "from"
+	LabelTo        = "to"
+	LabelState     = "state"
+)
+
+// NoopMetricsSink is a MetricsSink that discards everything
+type NoopMetricsSink struct{}
+
+// IncrCounter discards the counter
+func (NoopMetricsSink) IncrCounter(string, map[string]string) {}
+
+// RecordDuration discards the duration
+func (NoopMetricsSink) RecordDuration(string, time.Duration, map[string]string) {}
+
+// Gauge discards the gauge
+func (NoopMetricsSink) Gauge(string, float64, map[string]string) {}
+
+// SinkOrNoop returns sink, or a NoopMetricsSink when sink is nil
+func SinkOrNoop(sink MetricsSink) MetricsSink {
+	if sink == nil {
+		return NoopMetricsSink{}
+	}
+	return sink
+}
+
+// String returns the lower-case name of the state used in metric labels
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
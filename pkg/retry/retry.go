@@ -27,6 +27,9 @@ type Strategy struct {
 	// Metrics tracks retry statistics
 	Metrics *Metrics
 
+	// Sink exports attempt, success and failure metrics
+	Sink MetricsSink
+
 	// IsRetryable is a function that determines if an error should be retried
 	IsRetryable IsRetryable
 }
@@ -48,13 +51,14 @@ func NewStrategy(cfg *config.RetryConfig) *Strategy {
 
 	var cb *CircuitBreaker
 	if cfg.CircuitBreakerEnabled {
-		cb = NewCircuitBreaker(cfg.FailureThreshold, cfg.CircuitBreakerTimeout)
+		cb = NewCircuitBreaker(cfg.FailureThreshold, cfg.CircuitBreakerTimeout).WithFailureWindow(cfg.FailureWindow)
 	}
 
 	return &Strategy{
 		Config:        cfg,
 		CircuitBreaker: cb,
 		Metrics:       NewMetrics(),
+		Sink:          NoopMetricsSink{},
 		IsRetryable:   errors.IsRetryableError,
 	}
 }
@@ -75,6 +79,8 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 
 	var err error
 	var attempt int
+	sink := SinkOrNoop(s.Sink)
+	start := time.Now()
 
 	// Initialize random number generator with current time
 	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -83,6 +89,7 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 	for attempt = 0; attempt <= s.Config.MaxRetries; attempt++ {
 		// Record attempt in metrics
 		s.Metrics.RecordAttempt()
+		sink.IncrCounter(MetricRetryAttempts, nil)
 
 		// Execute the function
 		err = fn(ctx)
@@ -90,6 +97,8 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 		// If successful, record success and return
 		if err == nil {
 			s.Metrics.RecordSuccess()
+			sink.IncrCounter(MetricRetrySuccesses, nil)
+			sink.RecordDuration(MetricRetryDuration, time.Since(start), map[string]string{LabelOutcome: "success"})
 			if s.CircuitBreaker != nil {
 				s.CircuitBreaker.RecordSuccess()
 			}
@@ -98,6 +107,7 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 
 		// Record failure in metrics
 		s.Metrics.RecordFailure()
+		sink.IncrCounter(MetricRetryFailures, map[string]string{LabelErrorCode: errorCodeLabel(err)})
 
 		// Record failure in circuit breaker if enabled
 		if s.CircuitBreaker != nil {
@@ -106,6 +116,7 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 
 		// Check if error is retryable
 		if !s.IsRetryable(err) {
+			sink.RecordDuration(MetricRetryDuration, time.Since(start), map[string]string{LabelOutcome: "failure"})
 			return err
 		}
 
@@ -136,6 +147,7 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 	}
 
 	// If we've exhausted all retries, return the last error
+	sink.RecordDuration(MetricRetryDuration, time.Since(start), map[string]string{LabelOutcome: "failure"})
 	return errors.NewNetworkError(
 		"all retry attempts failed",
 		err,
@@ -144,6 +156,14 @@ func (s *Strategy) Do(ctx context.Context, fn RetryableFunc) error {
 		AddContext("max_retries", s.Config.MaxRetries)
 }
 
+// errorCodeLabel returns the error code used to label failure metrics
+func errorCodeLabel(err error) string {
+	if sdkErr, ok := err.(*errors.SDKError); ok {
+		return string(sdkErr.Code)
+	}
+	return "unknown"
+}
+
 // calculateDelay computes the delay for the next retry attempt
 func (s *Strategy) calculateDelay(attempt int, rnd *rand.Rand) time.Duration {
 	// Calculate base delay with exponential backoff: baseDelay * 2^attempt
@@ -177,6 +197,15 @@ func (s *Strategy) WithCircuitBreaker(cb *CircuitBreaker) *Strategy {
 	return s
 }
 
+// WithMetricsSink sets the sink metrics are exported to, including the circuit breaker's
+func (s *Strategy) WithMetricsSink(sink MetricsSink) *Strategy {
+	s.Sink = SinkOrNoop(sink)
+	if s.CircuitBreaker != nil {
+		s.CircuitBreaker.WithMetricsSink(s.Sink)
+	}
+	return s
+}
+
 // WithMetrics sets the metrics collector
 func (s *Strategy) WithMetrics(metrics *Metrics) *Strategy {
 	s.Metrics = metrics
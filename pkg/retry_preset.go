@@ -0,0 +1,88 @@
+/*
+Named retry configuration presets, so config files can pick one by name.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the retry presets, used by SDKConfig.WithRetryPreset
+const (
+	RetryPresetDefault      = "default"
+	RetryPresetAggressive   = "aggressive"
+	RetryPresetConservative = "conservative"
+	RetryPresetNone         = "none"
+)
+
+// retryPresets Constructors of the retry presets by name
+var retryPresets = map[string]func() *RetryConfig{
+	RetryPresetDefault:      NewDefaultRetryConfig,
+	RetryPresetAggressive:   NewAggressiveRetryConfig,
+	RetryPresetConservative: NewConservativeRetryConfig,
+	RetryPresetNone:         NewNoRetryConfig,
+}
+
+// RetryConfigByName New retry configuration of the preset called name,
+// ignoring case, nil when there is none
+func RetryConfigByName(name string) *RetryConfig {
+	preset, ok := retryPresets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil
+	}
+	return preset()
+}
+
+// RetryPresetNames Names of the retry presets, sorted
+func RetryPresetNames() []string {
+	names := make([]string, 0, len(retryPresets))
+	for name := range retryPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithRetryPreset Replace the retry configuration with the preset called name:
+// "default", "aggressive", "conservative" or "none". An unknown name returns a
+// CONFIGURATION_ERROR and leaves the config unchanged. Config files select a
+// preset with retry_preset, and their retry_config fields then override the
+// preset's values.
+func (s *SDKConfig) WithRetryPreset(name string) error {
+	retryConfig := RetryConfigByName(name)
+	if retryConfig == nil {
+		field := "retry_preset"
+		detail := NewErrorDetailWithCode(
+			ErrorCodeConfigurationError,
+			fmt.Sprintf("Unknown retry preset %q", name),
+		).WithSuggestion(fmt.Sprintf("Use one of %s.", strings.Join(RetryPresetNames(), ", ")))
+		detail.Field = &field
+		detail.AddContextValue("preset", name)
+		return NewSDKError(detail)
+	}
+	s.RetryPreset = strings.ToLower(strings.TrimSpace(name))
+	s.RetryConfig = retryConfig
+	s.retryConfigDefaulted = false
+	return nil
+}
+
+// DefaultRetryPresetForEnvironment Name of the retry preset used when no retry
+// configuration is supplied: "conservative" for PRODUCTION and SIMULATION, so
+// failures do not hammer the tax authority, and "default" otherwise
+func DefaultRetryPresetForEnvironment(environment Environment) string {
+	switch Environment(strings.ToUpper(strings.TrimSpace(string(environment)))) {
+	case EnvironmentProduction, EnvironmentSimulation:
+		return RetryPresetConservative
+	default:
+		return RetryPresetDefault
+	}
+}
+
+// DefaultRetryConfigForEnvironment New retry configuration of the environment's
+// default preset. Supplying a RetryConfig, or choosing a preset with
+// WithRetryPreset, overrides it.
+func DefaultRetryConfigForEnvironment(environment Environment) *RetryConfig {
+	return RetryConfigByName(DefaultRetryPresetForEnvironment(environment))
+}
@@ -4,41 +4,102 @@ Retry Strategy implementation matching Python SDK exactly.
 package complyancesdk
 
 import (
-	"log"
+	"context"
 	"math"
-	"math/rand"
 	"strconv"
+	"sync/atomic"
 	"time"
+
+	"github.com/complyance-io/complyance-go-sdk/v3/pkg/retry"
 )
 
 // RetryStrategy Retry strategy implementation matching Python SDK
 type RetryStrategy struct {
-	config *RetryConfig
+	config  *RetryConfig
+	logger  Logger
+	metrics MetricsSink
+	clock   Clock
+
+	// lastRetryUnixNano Time the last retry was scheduled, read atomically
+	lastRetryUnixNano int64
 }
 
 // NewRetryStrategy creates a new retry strategy
 func NewRetryStrategy(config *RetryConfig) *RetryStrategy {
 	return &RetryStrategy{
-		config: config,
+		config:  config,
+		logger:  noopLogger{},
+		metrics: metricsSinkOrNoop(nil),
+		clock:   SystemClock,
+	}
+}
+
+// SetClock Set the clock used to wait between attempts; nil restores SystemClock
+func (r *RetryStrategy) SetClock(clock Clock) {
+	r.clock = clockOrSystem(clock)
+}
+
+// SetMetricsSink Set the sink notified of every attempt, success and failure
+func (r *RetryStrategy) SetMetricsSink(sink MetricsSink) {
+	r.metrics = metricsSinkOrNoop(sink)
+}
+
+// SetLogger Set the logger for retry attempts
+func (r *RetryStrategy) SetLogger(logger Logger) {
+	r.logger = loggerOrNoop(logger)
+}
+
+// LastRetryTime Time the last retry was scheduled, zero when nothing has been retried
+func (r *RetryStrategy) LastRetryTime() time.Time {
+	nanos := atomic.LoadInt64(&r.lastRetryUnixNano)
+	if nanos == 0 {
+		return time.Time{}
 	}
+	return time.Unix(0, nanos)
 }
 
 // Execute operation with retry logic
 func (r *RetryStrategy) Execute(operation func() (interface{}, error), operationName string) (interface{}, error) {
+	return r.ExecuteContext(context.Background(), operation, operationName)
+}
+
+// ExecuteContext operation with retry logic, giving up as soon as ctx is done
+func (r *RetryStrategy) ExecuteContext(ctx context.Context, operation func() (interface{}, error), operationName string) (interface{}, error) {
 	var lastError error
+	var previousDelayMs float64
+	start := r.clock.Now()
+	labels := map[string]string{retry.LabelOperation: operationName}
 
 	for attempt := 0; attempt < r.config.MaxAttempts; attempt++ {
-		log.Printf("Executing %s, attempt %d/%d", operationName, attempt+1, r.config.MaxAttempts)
+		if ctx.Err() != nil {
+			r.recordDuration(operationName, "canceled", start)
+			return nil, newContextSDKError(ctx.Err())
+		}
+		r.logger.Debug("Executing operation", map[string]interface{}{
+			"operation":   operationName,
+			"attempt":     attempt + 1,
+			"maxAttempts": r.config.MaxAttempts,
+		})
 
+		r.metrics.IncrCounter(retry.MetricRetryAttempts, labels)
 		result, err := operation()
 		if err == nil {
+			r.metrics.IncrCounter(retry.MetricRetrySuccesses, labels)
+			r.recordDuration(operationName, "success", start)
 			if attempt > 0 {
-				log.Printf("Operation %s succeeded after %d attempts", operationName, attempt+1)
+				r.logger.Info("Operation succeeded after retry", map[string]interface{}{
+					"operation": operationName,
+					"attempts":  attempt + 1,
+				})
 			}
 			return result, nil
 		}
 
 		lastError = err
+		r.metrics.IncrCounter(retry.MetricRetryFailures, map[string]string{
+			retry.LabelOperation: operationName,
+			retry.LabelErrorCode: errorCodeMetricLabel(err),
+		})
 
 		// Check if this error should be retried
 		shouldRetry := false
@@ -48,19 +109,36 @@ func (r *RetryStrategy) Execute(operation func() (interface{}, error), operation
 
 		// If this is the last attempt or error is not retryable, don't retry
 		if attempt == r.config.MaxAttempts-1 || !shouldRetry {
-			log.Printf("Operation %s failed after %d attempts: %v", operationName, attempt+1, err)
+			r.logger.Error("Operation failed", map[string]interface{}{
+				"operation": operationName,
+				"attempts":  attempt + 1,
+				"error":     err.Error(),
+			})
 			break
 		}
 
-		// Calculate delay for next attempt
-		delayMs := r.calculateDelay(attempt + 1)
-		log.Printf("Operation %s failed (attempt %d), retrying in %fms: %v", operationName, attempt+1, delayMs, err)
+		// Calculate delay for next attempt, never retrying sooner than the server asked
+		delayMs := r.nextDelay(attempt+1, previousDelayMs, err)
+		previousDelayMs = delayMs
+		atomic.StoreInt64(&r.lastRetryUnixNano, r.clock.Now().UnixNano())
+		r.logger.Warn("Operation failed, retrying", map[string]interface{}{
+			"operation": operationName,
+			"attempt":   attempt + 1,
+			"delayMs":   delayMs,
+			"error":     err.Error(),
+		})
 
 		// Sleep before retry
-		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			r.recordDuration(operationName, "canceled", start)
+			return nil, newContextSDKError(ctx.Err())
+		case <-r.clock.After(time.Duration(delayMs) * time.Millisecond):
+		}
 	}
 
 	// If we get here, all retries failed
+	r.recordDuration(operationName, "failure", start)
 	if sdkErr, ok := lastError.(*SDKError); ok {
 		// Create max retries exceeded error
 		maxRetriesError := NewErrorDetailWithCode(
@@ -70,29 +148,35 @@ func (r *RetryStrategy) Execute(operation func() (interface{}, error), operation
 		maxRetriesError.Suggestion = &[]string{"Maximum retry attempts exceeded. Check your network connection and try again later"}[0]
 		maxRetriesError.AddContextValue("maxAttempts", r.config.MaxAttempts)
 		maxRetriesError.AddContextValue("originalError", sdkErr.String())
-		return nil, NewSDKError(maxRetriesError)
+		return nil, &SDKError{ErrorDetail: maxRetriesError, cause: sdkErr}
 	} else {
 		return nil, lastError
 	}
 }
 
-// calculateDelay Calculate delay for retry attempt with exponential backoff and jitter
-func (r *RetryStrategy) calculateDelay(attempt int) float64 {
-	if attempt <= 0 {
-		return 0
-	}
-
-	// Calculate exponential backoff
-	delay := math.Min(
-		float64(r.config.MaxDelayMs),
-		float64(r.config.BaseDelayMs)*math.Pow(r.config.BackoffMultiplier, float64(attempt-1)),
-	)
+// recordDuration Report the total time spent on an operation across attempts
+func (r *RetryStrategy) recordDuration(operationName string, outcome string, start time.Time) {
+	r.metrics.RecordDuration(retry.MetricRetryDuration, r.clock.Now().Sub(start), map[string]string{
+		retry.LabelOperation: operationName,
+		retry.LabelOutcome:   outcome,
+	})
+}
 
-	// Add jitter
-	if r.config.JitterFactor > 0 {
-		jitter := (rand.Float64()*2 - 1) * r.config.JitterFactor // Random between -jitterFactor and +jitterFactor
-		delay = delay * (1 + jitter)
+// nextDelay Delay in milliseconds before the given attempt: the computed backoff,
+// or the error's Retry-After hint when that is longer, capped at MaxDelayMs so a
+// server hint cannot hold the caller longer than the configured backoff would
+func (r *RetryStrategy) nextDelay(attempt int, previousDelayMs float64, err error) float64 {
+	delayMs := r.calculateDelay(attempt, previousDelayMs)
+	if sdkErr, ok := err.(*SDKError); ok && sdkErr.ErrorDetail != nil && sdkErr.ErrorDetail.RetryAfterSeconds != nil {
+		retryAfterMs := math.Min(float64(*sdkErr.ErrorDetail.RetryAfterSeconds)*1000, float64(r.config.MaxDelayMs))
+		if retryAfterMs > delayMs {
+			delayMs = retryAfterMs
+		}
 	}
+	return delayMs
+}
 
-	return math.Max(0, delay)
+// calculateDelay Calculate delay for retry attempt with the configured backoff strategy
+func (r *RetryStrategy) calculateDelay(attempt int, previousDelayMs float64) float64 {
+	return backoffDelayMs(r.config, attempt, previousDelayMs)
 }
@@ -0,0 +1,74 @@
+package complyancesdk
+
+import (
+	"testing"
+	"time"
+)
+
+func rateLimitError(retryAfterSeconds int) *SDKError {
+	detail := NewErrorDetailWithCode(ErrorCodeRateLimitExceeded, "Too many requests")
+	detail.RetryAfterSeconds = &retryAfterSeconds
+	return NewSDKError(detail)
+}
+
+func TestRetryDelayHonorsLargerOfBackoffAndRetryAfter(t *testing.T) {
+	config := NewDefaultRetryConfig()
+	config.BaseDelayMs = 500
+	config.JitterFactor = 0
+	strategy := NewRetryStrategy(config)
+
+	if delay := strategy.nextDelay(1, 0, rateLimitError(2)); delay != 2000 {
+		t.Fatalf("expected Retry-After of 2s to win over 500ms backoff, got %fms", delay)
+	}
+	if delay := strategy.nextDelay(1, 0, rateLimitError(0)); delay != 500 {
+		t.Fatalf("expected computed backoff to win over a zero Retry-After, got %fms", delay)
+	}
+	config.MaxDelayMs = 5000
+	if delay := strategy.nextDelay(1, 0, rateLimitError(3600)); delay != 5000 {
+		t.Fatalf("expected Retry-After to be capped at the 5s max delay, got %fms", delay)
+	}
+	plain := NewSDKError(NewErrorDetailWithCode(ErrorCodeServiceUnavailable, "down"))
+	if delay := strategy.nextDelay(2, 0, plain); delay != 1000 {
+		t.Fatalf("expected exponential backoff without a Retry-After hint, got %fms", delay)
+	}
+}
+
+func TestRetryStrategyWaitsOnInjectedClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := NewDefaultRetryConfig()
+	config.MaxAttempts = 2
+	config.JitterFactor = 0
+	strategy := NewRetryStrategy(config)
+	strategy.SetClock(clock)
+
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		_, err := strategy.Execute(func() (interface{}, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, NewSDKError(NewErrorDetailWithCode(ErrorCodeServiceUnavailable, "unavailable"))
+			}
+			return "ok", nil
+		}, "test")
+		done <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.PendingTimers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("retry strategy never waited on the fake clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected the retry to wait for the clock, returned %v", err)
+	default:
+	}
+
+	clock.Advance(time.Duration(config.MaxDelayMs) * time.Millisecond)
+	if err := <-done; err != nil || attempts != 2 {
+		t.Fatalf("expected the second attempt to succeed, got %v after %d attempts", err, attempts)
+	}
+}
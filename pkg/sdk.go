@@ -6,10 +6,12 @@ This matches the Python SDK GETSUnifySDK class exactly.
 package complyancesdk
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"path/filepath"
 	"strings"
-	"time"
+	"sync"
 )
 
 // GETSUnifySDK Main entry point for the GETS Unify Go SDK
@@ -17,59 +19,278 @@ type GETSUnifySDK struct {
 	config       *SDKConfig
 	apiClient    *APIClient
 	queueManager *PersistentQueueManager
+
+	// configSnapshot Config the SDK was built from, set by Configure
+	configSnapshot *sdkConfigSnapshot
+	// closeOnce makes Close safe to call more than once
+	closeOnce sync.Once
+	// inFlight counts package-level calls still using the SDK after Configure
+	// or Close replaced it; the SDK is closed once they have finished
+	inFlight sync.WaitGroup
 }
 
-var globalSDK *GETSUnifySDK
+var (
+	// globalSDK SDK used by the package-level functions, set by Configure
+	globalSDK   *GETSUnifySDK
+	globalSDKMu sync.RWMutex
+)
 
-// Configure Configure the SDK with API key, environment, and sources
+// Configure Configure the SDK with API key, environment, and sources. The
+// package-level functions such as PushToUnify use the SDK configured here.
+// There is a single package-level SDK: calling Configure again replaces it for
+// every caller, while calls already in flight finish on the SDK they started
+// with. Use NewSDK to hold several independently configured SDKs instead.
+// Calling Configure with a config equivalent to the current one keeps the
+// current SDK and its queue; otherwise the previous SDK is closed once the
+// submissions and queue operations in flight on it have finished, and
+// Configure returns after that.
 func Configure(sdkConfig *SDKConfig) error {
+	globalSDKMu.Lock()
+	snapshot := newSDKConfigSnapshot(sdkConfig)
+	if globalSDK != nil && globalSDK.configSnapshot != nil && globalSDK.configSnapshot.equivalent(snapshot) {
+		globalSDKMu.Unlock()
+		return nil
+	}
+
+	previous := globalSDK
+	sdk, err := newSDK(sdkConfig, false)
+	if err != nil {
+		globalSDK = nil
+	} else {
+		sdk.configSnapshot = snapshot
+		globalSDK = sdk
+	}
+	globalSDKMu.Unlock()
+
+	_ = previous.closeWhenIdle()
+	return err
+}
+
+// currentSDK SDK set up by Configure, nil when it has not been configured
+func currentSDK() *GETSUnifySDK {
+	globalSDKMu.RLock()
+	defer globalSDKMu.RUnlock()
+	return globalSDK
+}
+
+// acquireSDK SDK set up by Configure, held until release is called so that
+// Configure and Close wait for the call using it before closing it
+func acquireSDK() (sdk *GETSUnifySDK, release func()) {
+	globalSDKMu.RLock()
+	defer globalSDKMu.RUnlock()
+	if globalSDK == nil {
+		return nil, func() {}
+	}
+	globalSDK.inFlight.Add(1)
+	return globalSDK, globalSDK.inFlight.Done
+}
+
+// closeWhenIdle Close the SDK once the package-level calls holding it have
+// finished. It must no longer be reachable through globalSDK.
+func (s *GETSUnifySDK) closeWhenIdle() error {
+	if s == nil {
+		return nil
+	}
+	s.inFlight.Wait()
+	return s.Close()
+}
+
+// NewSDK Create an SDK instance with its own API client, circuit breaker and
+// queue. Nothing is shared with the SDK set up by Configure or with other
+// instances, so an application can submit with several API keys at once, e.g.
+// to two tax authorities. Without a QueueBasePath each instance queues in its
+// own directory under ~/complyance-queue/instances, named by InstanceName or
+// derived from the API key and environment.
+func NewSDK(sdkConfig *SDKConfig) (*GETSUnifySDK, error) {
+	return newSDK(sdkConfig, true)
+}
+
+// newSDK Create an SDK; instance selects a per-instance default queue directory
+// instead of the ~/complyance-queue used by Configure
+func newSDK(sdkConfig *SDKConfig, instance bool) (*GETSUnifySDK, error) {
 	if sdkConfig == nil {
 		errorDetail := NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDKConfig is required",
 		)
 		errorDetail.Suggestion = &[]string{"Call GETSUnifySDK.Configure() with a valid SDKConfig."}[0]
-		return NewSDKError(errorDetail)
+		return nil, NewSDKError(errorDetail)
+	}
+
+	validation := sdkConfig.Validate()
+	if invalid := firstValidationError(validation); invalid != nil {
+		return nil, newConfigValidationError(invalid)
+	}
+
+	if sdkConfig.SigningEnabled && sdkConfig.SigningSecret == "" {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeConfigurationError,
+			"Signing secret is required when request signing is enabled",
+		).WithSuggestion("Set SigningSecret, or disable request signing."))
+	}
+
+	if mode := sdkConfig.GetQueueMode(); mode != QueueModeFile && mode != QueueModeMemory {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeConfigurationError,
+			fmt.Sprintf("Unknown queue mode %q", mode),
+		).WithSuggestion("Use QueueModeFile or QueueModeMemory."))
+	}
+
+	tlsConfig, err := sdkConfig.BuildTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil && sdkConfig.HTTPClient != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeConfigurationError,
+			"TLS settings cannot be combined with a custom HTTP client",
+		).WithSuggestion("Configure TLS on the transport of the injected HTTPClient instead."))
 	}
 
-	globalSDK = &GETSUnifySDK{
+	sdk := &GETSUnifySDK{
 		config: sdkConfig,
 	}
 
-	// Validate country restrictions for production environments
-	validateEnvironmentCountryRestrictions(sdkConfig.Environment)
+	logger := loggerOrNoop(sdkConfig.Logger)
+	for _, result := range validation.Results {
+		if result.IsWarning() {
+			logger.Warn("SDK configuration warning", map[string]interface{}{"field": result.Field, "message": result.Message})
+		}
+	}
+
+	// Log the country restrictions of the environment
+	validateEnvironmentCountryRestrictions(sdkConfig, logger)
 
-	globalSDK.apiClient = NewAPIClient(
+	sdk.apiClient = NewAPIClient(
 		sdkConfig.APIKey,
 		sdkConfig.Environment,
 		sdkConfig.RetryConfig,
 	)
+	sdk.apiClient.SetBaseURL(sdkConfig.GetBaseURL())
+	sdk.apiClient.SetRequestSigning(sdkConfig.SigningEnabled, sdkConfig.SigningSecret)
+	sdk.apiClient.SetCompression(sdkConfig.CompressionThresholdBytes, sdkConfig.ForceCompression)
+	sdk.apiClient.SetMaxResponseBytes(sdkConfig.MaxResponseBytes)
+	sdk.apiClient.SetMaxPayloadBytes(sdkConfig.MaxPayloadBytes, sdkConfig.MaxBulkPayloadBytes)
+	sdk.apiClient.SetRecordRequestJSON(sdkConfig.RecordRequestJSON)
+	if sdkConfig.HTTPClient != nil {
+		sdk.apiClient.SetHTTPClient(sdkConfig.HTTPClient)
+	} else if tlsConfig != nil {
+		sdk.apiClient.SetTLSConfig(tlsConfig)
+	}
+	sdk.apiClient.SetRedactionConfig(sdkConfig.Redaction)
+	sdk.apiClient.SetLogger(logger)
+	sdk.apiClient.SetTracerProvider(sdkConfig.TracerProvider)
+	sdk.apiClient.SetMetricsSink(sdkConfig.MetricsSink)
+	sdk.apiClient.SetClock(sdkConfig.Clock)
+	sdk.apiClient.SetRateLimit(sdkConfig.RateLimitPerSecond, sdkConfig.RateLimitBurst)
+	sdk.apiClient.SetRateLimitThrottleThreshold(sdkConfig.RateLimitThrottleThreshold)
 
 	// Initialize PersistentQueueManager for handling failed submissions with shared circuit breaker
-	globalSDK.queueManager = NewPersistentQueueManager(
+	var queueStore QueueStore
+	if sdkConfig.GetQueueMode() == QueueModeMemory {
+		queueStore = NewMemoryQueueStore()
+	} else if sdkConfig.QueueBasePath != "" || len(sdkConfig.QueueEncryptionKey) > 0 || instance {
+		basePath := sdkConfig.QueueBasePath
+		if basePath == "" {
+			basePath, _ = defaultQueueBasePath()
+			if instance {
+				basePath = filepath.Join(basePath, InstancesDir, sdkConfig.instanceQueueName())
+			}
+		}
+		fileStore, err := openFileQueueStore(basePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := fileStore.SetEncryptionKey(sdkConfig.QueueEncryptionKey); err != nil {
+			return nil, err
+		}
+		queueStore = fileStore
+	}
+	queueManager, err := NewPersistentQueueManager(
 		sdkConfig.APIKey,
 		sdkConfig.Environment == EnvironmentLocal,
-		globalSDK.apiClient.GetCircuitBreaker(),
+		sdk.apiClient.GetCircuitBreaker(),
+		queueStore,
 	)
-
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	sdk.queueManager = queueManager
+	sdk.queueManager.SetLogger(sdk.apiClient.GetLogger())
+	sdk.queueManager.SetMetricsSink(sdkConfig.MetricsSink)
+	sdk.queueManager.SetEventHandler(sdkConfig.QueueEventHandler)
+	sdk.queueManager.SetMaxAttempts(sdkConfig.QueueMaxAttempts)
+	sdk.queueManager.SetRetryConfig(sdkConfig.RetryConfig)
+	sdk.queueManager.SetDocumentIDPaths(sdkConfig.DocumentIDPaths)
+	sdk.queueManager.SetAPIClient(sdk.apiClient)
+	sdk.queueManager.SetClock(sdkConfig.Clock)
+
+	return sdk, nil
 }
 
-// validateEnvironmentCountryRestrictions Validate country restrictions based on environment
-func validateEnvironmentCountryRestrictions(environment Environment) {
-	if environment == EnvironmentSandbox || environment == EnvironmentSimulation || environment == EnvironmentProduction {
-		// For production environments, only SA, MY, and AE (UAE) are allowed
-		// This validation happens at configuration time, not at request time
-		log.Printf("Production environment detected: %s. Only SA (Saudi Arabia), MY (Malaysia), and AE (UAE) countries will be allowed.", environment)
-	} else {
-		// For development environments, all countries are allowed
-		log.Printf("Development environment detected: %s. All countries are allowed.", environment)
+// validateEnvironmentCountryRestrictions Log the countries the configured
+// environment allows, from its allow-list. The check itself happens per request.
+func validateEnvironmentCountryRestrictions(config *SDKConfig, logger Logger) {
+	environment := config.Environment
+	countries, restricted := config.AllowedCountriesFor(environment)
+	if !restricted {
+		logger.Info("All countries are allowed in this environment.", map[string]interface{}{"environment": string(environment)})
+		return
 	}
+	names := make([]string, 0, len(countries))
+	for _, country := range countries {
+		names = append(names, string(country))
+	}
+	allowed := strings.Join(names, ", ")
+	if allowed == "" {
+		allowed = "none"
+	}
+	logger.Info(fmt.Sprintf("Restricted environment detected. Only these countries will be allowed: %s.", allowed), map[string]interface{}{
+		"environment":       string(environment),
+		"allowed_countries": names,
+	})
 }
 
 // SubmitPayload Submit a payload to the GETS Unify API
+// Uses the SDK set up by Configure.
 func SubmitPayload(clientPayloadJSON string, sourceID string, country Country, documentType DocumentType) (*SubmissionResponseOld, error) {
-	if globalSDK == nil || globalSDK.config == nil {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.SubmitPayload(clientPayloadJSON, sourceID, country, documentType)
+}
+
+// SubmitPayload Submit a payload to the GETS Unify API. See SubmitPayloadContext.
+func (s *GETSUnifySDK) SubmitPayload(clientPayloadJSON string, sourceID string, country Country, documentType DocumentType) (*SubmissionResponseOld, error) {
+	submission, err := s.SubmitPayloadContext(context.Background(), clientPayloadJSON, sourceID, country, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SubmissionResponseOld{Status: SubmissionStatusSubmitted}
+	if submission.SubmissionID != nil {
+		response.SubmissionID = *submission.SubmissionID
+	}
+	if submission.Status != nil && strings.TrimSpace(*submission.Status) != "" {
+		response.Status = SubmissionStatus(strings.ToUpper(*submission.Status))
+	}
+	return response, nil
+}
+
+// SubmitPayloadContext Submit a payload to the GETS Unify API
+// Uses the SDK set up by Configure.
+func SubmitPayloadContext(ctx context.Context, clientPayloadJSON string, sourceID string, country Country, documentType DocumentType) (*SubmissionResponse, error) {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.SubmitPayloadContext(ctx, clientPayloadJSON, sourceID, country, documentType)
+}
+
+// SubmitPayloadContext Submit a JSON payload for a configured source as a single
+// document. documentType is mapped to its logical document type and the request
+// is built and sent as PushToUnify builds and sends it, with the same retry,
+// circuit breaker and queue handling; when it fails with a retryable error it is
+// queued and the returned submission has status QUEUED.
+func (s *GETSUnifySDK) SubmitPayloadContext(ctx context.Context, clientPayloadJSON string, sourceID string, country Country, documentType DocumentType) (*SubmissionResponse, error) {
+	if s == nil || s.config == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDK not configured",
@@ -103,12 +324,16 @@ func SubmitPayload(clientPayloadJSON string, sourceID string, country Country, d
 			"Document type is required",
 		))
 	}
+	parsedDocumentType, err := ParseDocumentType(string(documentType))
+	if err != nil {
+		return nil, err
+	}
 
 	// Find source by ID
 	var source *Source
-	for _, s := range globalSDK.config.Sources {
-		if s.GetID() == sourceID {
-			source = s
+	for _, candidate := range s.config.Sources {
+		if candidate.GetID() == sourceID {
+			source = candidate
 			break
 		}
 	}
@@ -121,55 +346,177 @@ func SubmitPayload(clientPayloadJSON string, sourceID string, country Country, d
 	}
 
 	// Validate country restrictions for current environment
-	if err := validateCountryForEnvironment(country, globalSDK.config.Environment); err != nil {
+	if err := validateCountryForEnvironment(country, s.config); err != nil {
+		return nil, err
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(clientPayloadJSON), &payload); err != nil || payload == nil {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeMalformedJSON,
+			"Payload must be a JSON object",
+		).WithSuggestion(`Ensure the payload is valid JSON. Example: '{"invoice_data":{"invoice_number":"INV-123"}}'`)
+		if err != nil {
+			detail.AddContextValue("parseError", err.Error())
+		}
+		return nil, NewSDKError(detail)
+	}
+
+	request, err := s.buildLogicalRequest(
+		source.GetName(), source.GetVersion(), documentTypeLogicalTypes[parsedDocumentType],
+		country, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.sendUnifyRequest(ctx, request)
+	if err != nil {
 		return nil, err
 	}
 
-	return globalSDK.apiClient.SendPayload(clientPayloadJSON, source, country, documentType)
+	if response.GetData() != nil && response.GetData().GetSubmission() != nil {
+		submission := response.GetData().GetSubmission()
+		if response.GetStatus() == "queued" {
+			submission.Status = &[]string{string(SubmissionStatusQueued)}[0]
+		}
+		return submission, nil
+	}
+	return &SubmissionResponse{
+		SubmissionID: request.GetRequestID(),
+		Status:       &[]string{string(SubmissionStatusSubmitted)}[0],
+	}, nil
+}
+
+// documentTypeLogicalTypes Logical document type SubmitPayloadContext submits
+// each DocumentType as
+var documentTypeLogicalTypes = map[DocumentType]LogicalDocType{
+	DocumentTypeTaxInvoice:                          LogicalDocTypeTaxInvoice,
+	DocumentTypeSimplifiedInvoice:                   LogicalDocTypeSimplifiedTaxInvoice,
+	DocumentTypeCreditNote:                          LogicalDocTypeTaxInvoiceCreditNote,
+	DocumentTypeSimplifiedCreditNote:                LogicalDocTypeSimplifiedTaxInvoiceCreditNote,
+	DocumentTypeDebitNote:                           LogicalDocTypeTaxInvoiceDebitNote,
+	DocumentTypeSimplifiedDebitNote:                 LogicalDocTypeSimplifiedTaxInvoiceDebitNote,
+	DocumentTypePrepaymentInvoice:                   LogicalDocTypeTaxInvoicePrepayment,
+	DocumentTypeSimplifiedPrepaymentInvoice:         LogicalDocTypeSimplifiedTaxInvoicePrepayment,
+	DocumentTypePrepaymentAdjustedInvoice:           LogicalDocTypeTaxInvoicePrepaymentAdjusted,
+	DocumentTypeSimplifiedPrepaymentAdjustedInvoice: LogicalDocTypeSimplifiedTaxInvoicePrepaymentAdjusted,
 }
 
 // GetDocumentStatus gets retrieval status by documentId.
+// Uses the SDK set up by Configure.
 func GetDocumentStatus(documentID string) (map[string]interface{}, error) {
-	if globalSDK == nil || globalSDK.apiClient == nil {
+	return currentSDK().GetDocumentStatus(documentID)
+}
+
+// GetDocumentStatus gets retrieval status by documentId.
+func (s *GETSUnifySDK) GetDocumentStatus(documentID string) (map[string]interface{}, error) {
+	if s == nil || s.apiClient == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDK not configured",
 		).WithSuggestion("Call Configure() first."))
 	}
 
-	return globalSDK.apiClient.GetDocumentStatus(documentID)
+	return s.apiClient.GetDocumentStatus(documentID)
 }
 
 // GetSubmissionStatus is deprecated and intentionally blocked.
+// Uses the SDK set up by Configure.
 func GetSubmissionStatus(submissionID string) (map[string]interface{}, error) {
-	if globalSDK == nil || globalSDK.apiClient == nil {
+	return currentSDK().GetSubmissionStatus(submissionID)
+}
+
+// GetSubmissionStatus is deprecated and intentionally blocked.
+func (s *GETSUnifySDK) GetSubmissionStatus(submissionID string) (map[string]interface{}, error) {
+	if s == nil || s.apiClient == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDK not configured",
 		).WithSuggestion("Call Configure() first."))
 	}
 
-	return globalSDK.apiClient.GetSubmissionStatus(submissionID)
+	return s.apiClient.GetSubmissionStatus(submissionID)
+}
+
+// GetStatusContext gets the current status of a submission by its submission ID,
+// including clearance status, UUID, hash and QR code once available.
+// Uses the SDK set up by Configure.
+func GetStatusContext(ctx context.Context, submissionID string) (*SubmissionResponse, error) {
+	return currentSDK().GetStatusContext(ctx, submissionID)
+}
+
+// GetStatusContext gets the current status of a submission by its submission ID,
+// including clearance status, UUID, hash and QR code once available.
+func (s *GETSUnifySDK) GetStatusContext(ctx context.Context, submissionID string) (*SubmissionResponse, error) {
+	if s == nil || s.apiClient == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+
+	return s.apiClient.GetStatus(ctx, submissionID)
+}
+
+// WaitForTerminalStatus polls the submission status until it is ACCEPTED, REJECTED
+// or FAILED, the wait options' MaxWait elapses, or ctx is done.
+// Uses the SDK set up by Configure.
+func WaitForTerminalStatus(ctx context.Context, submissionID string, opts *WaitOptions) (*SubmissionResponse, error) {
+	return currentSDK().WaitForTerminalStatus(ctx, submissionID, opts)
+}
+
+// WaitForTerminalStatus polls the submission status until it is ACCEPTED, REJECTED
+// or FAILED, the wait options' MaxWait elapses, or ctx is done.
+func (s *GETSUnifySDK) WaitForTerminalStatus(ctx context.Context, submissionID string, opts *WaitOptions) (*SubmissionResponse, error) {
+	if s == nil || s.apiClient == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+
+	return s.apiClient.WaitForTerminalStatus(ctx, submissionID, opts)
 }
 
 // GetStatus is deprecated and forwards to the deprecated submissionId endpoint behavior.
+// Use GetStatusContext to query the submission status endpoint.
+// Uses the SDK set up by Configure.
 func GetStatus(submissionID string) (map[string]interface{}, error) {
-	return GetSubmissionStatus(submissionID)
+	return currentSDK().GetStatus(submissionID)
+}
+
+// GetStatus is deprecated and forwards to the deprecated submissionId endpoint behavior.
+// Use GetStatusContext to query the submission status endpoint.
+func (s *GETSUnifySDK) GetStatus(submissionID string) (map[string]interface{}, error) {
+	return s.GetSubmissionStatus(submissionID)
 }
 
 // GetQueueStatus Get queue status and statistics
+// Uses the SDK set up by Configure.
 func GetQueueStatus() string {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		status := globalSDK.queueManager.GetQueueStatus()
+	return currentSDK().GetQueueStatus()
+}
+
+// GetQueueStatus Get queue status and statistics
+func (s *GETSUnifySDK) GetQueueStatus() string {
+	if s != nil && s.queueManager != nil {
+		status := s.queueManager.GetQueueStatus()
 		return fmt.Sprintf("Persistent Queue Status: %s", status.String())
 	}
 	return "Queue Manager is not initialized"
 }
 
 // GetDetailedQueueStatus Get detailed queue status
+// Uses the SDK set up by Configure.
 func GetDetailedQueueStatus() *QueueStatus {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		return globalSDK.queueManager.GetQueueStatus()
+	return currentSDK().GetDetailedQueueStatus()
+}
+
+// GetDetailedQueueStatus Get detailed queue status
+func (s *GETSUnifySDK) GetDetailedQueueStatus() *QueueStatus {
+	if s != nil && s.queueManager != nil {
+		return s.queueManager.GetQueueStatus()
 	}
 	// Return a QueueStatus object with zeros
 	return &QueueStatus{
@@ -182,8 +529,12 @@ func GetDetailedQueueStatus() *QueueStatus {
 }
 
 func GetQueueStatusDetailed() *QueueStatusDetailed {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		return globalSDK.queueManager.GetQueueStatusDetailed()
+	return currentSDK().GetQueueStatusDetailed()
+}
+
+func (s *GETSUnifySDK) GetQueueStatusDetailed() *QueueStatusDetailed {
+	if s != nil && s.queueManager != nil {
+		return s.queueManager.GetQueueStatusDetailed()
 	}
 	return &QueueStatusDetailed{
 		PendingCount:    0,
@@ -198,120 +549,296 @@ func GetQueueStatusDetailed() *QueueStatusDetailed {
 }
 
 // RetryFailedSubmissions Retry failed submissions
+// Uses the SDK set up by Configure.
 func RetryFailedSubmissions() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.RetryFailedSubmissions()
+	sdk, release := acquireSDK()
+	defer release()
+	sdk.RetryFailedSubmissions()
+}
+
+// RetryFailedSubmissions Retry failed submissions
+func (s *GETSUnifySDK) RetryFailedSubmissions() {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.RetryFailedSubmissions()
 	}
 }
 
+// GetDeadLetterCount Number of queued submissions moved to dead-letter
+// Uses the SDK set up by Configure.
+func GetDeadLetterCount() int {
+	return currentSDK().GetDeadLetterCount()
+}
+
+// GetDeadLetterCount Number of queued submissions moved to dead-letter
+func (s *GETSUnifySDK) GetDeadLetterCount() int {
+	if s != nil && s.queueManager != nil {
+		return s.queueManager.GetDeadLetterCount()
+	}
+	return 0
+}
+
+// ListDeadLetters Queued submissions moved to dead-letter, for manual inspection
+// Uses the SDK set up by Configure.
+func ListDeadLetters() ([]*PersistentSubmissionRecord, error) {
+	return currentSDK().ListDeadLetters()
+}
+
+// ListDeadLetters Queued submissions moved to dead-letter, for manual inspection
+func (s *GETSUnifySDK) ListDeadLetters() ([]*PersistentSubmissionRecord, error) {
+	if s != nil && s.queueManager != nil {
+		return s.queueManager.ListDeadLetters()
+	}
+	return nil, nil
+}
+
 func RetryFailed(queueItemID string) bool {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		return globalSDK.queueManager.RetryFailed(queueItemID)
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.RetryFailed(queueItemID)
+}
+
+func (s *GETSUnifySDK) RetryFailed(queueItemID string) bool {
+	if s != nil && s.queueManager != nil {
+		return s.queueManager.RetryFailed(queueItemID)
 	}
 	return false
 }
 
 // CleanupOldSuccessFiles Clean up old success files
+// Uses the SDK set up by Configure.
 func CleanupOldSuccessFiles(daysToKeep int) {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.CleanupOldSuccessFiles(daysToKeep)
+	currentSDK().CleanupOldSuccessFiles(daysToKeep)
+}
+
+// CleanupOldSuccessFiles Clean up old success files
+func (s *GETSUnifySDK) CleanupOldSuccessFiles(daysToKeep int) {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.CleanupOldSuccessFiles(daysToKeep)
 	}
 }
 
 // ClearAllQueues Clear all files from the queue (emergency cleanup)
+// Uses the SDK set up by Configure.
 func ClearAllQueues() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.ClearAllQueues()
-	} else {
-		log.Println("Queue Manager is not initialized")
+	currentSDK().ClearAllQueues()
+}
+
+// ClearAllQueues Clear all files from the queue (emergency cleanup)
+func (s *GETSUnifySDK) ClearAllQueues() {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.ClearAllQueues()
+	} else if s != nil && s.apiClient != nil {
+		s.apiClient.GetLogger().Warn("Queue Manager is not initialized", nil)
 	}
 }
 
 // CleanupDuplicateFiles Clean up duplicate files across queue directories
+// Uses the SDK set up by Configure.
 func CleanupDuplicateFiles() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.CleanupDuplicateFiles()
-	} else {
-		log.Println("Queue Manager is not initialized")
+	currentSDK().CleanupDuplicateFiles()
+}
+
+// CleanupDuplicateFiles Clean up duplicate files across queue directories
+func (s *GETSUnifySDK) CleanupDuplicateFiles() {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.CleanupDuplicateFiles()
+	} else if s != nil && s.apiClient != nil {
+		s.apiClient.GetLogger().Warn("Queue Manager is not initialized", nil)
 	}
 }
 
 // ProcessPendingSubmissions Process pending submissions
+// Uses the SDK set up by Configure.
 func ProcessPendingSubmissions() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.ProcessPendingSubmissionsNow()
+	sdk, release := acquireSDK()
+	defer release()
+	sdk.ProcessPendingSubmissions()
+}
+
+// ProcessPendingSubmissions Process pending submissions
+func (s *GETSUnifySDK) ProcessPendingSubmissions() {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.ProcessPendingSubmissionsNow()
 	}
 }
 
 func PauseQueueProcessing() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.PauseProcessing()
+	currentSDK().PauseQueueProcessing()
+}
+
+func (s *GETSUnifySDK) PauseQueueProcessing() {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.PauseProcessing()
 	}
 }
 
 func ResumeQueueProcessing() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		globalSDK.queueManager.ResumeProcessing()
+	currentSDK().ResumeQueueProcessing()
+}
+
+func (s *GETSUnifySDK) ResumeQueueProcessing() {
+	if s != nil && s.queueManager != nil {
+		s.queueManager.ResumeProcessing()
+	}
+}
+
+// Close Close the SDK set up by Configure, see GETSUnifySDK.Close, once the
+// submissions and queue operations in flight on it have finished. The
+// package-level functions report that the SDK is not configured until
+// Configure is called again.
+func Close() error {
+	globalSDKMu.Lock()
+	previous := globalSDK
+	globalSDK = nil
+	globalSDKMu.Unlock()
+
+	return previous.closeWhenIdle()
+}
+
+// Close Release the SDK's resources: queue processing is stopped after any
+// pass already sending submissions has finished, and idle HTTP connections
+// of the SDK's own HTTP client are closed. Submissions still waiting stay in a
+// file queue for the next start; a memory queue loses them, so call DrainQueue
+// first to send them. The SDK must not be used after Close; calling Close
+// again does nothing.
+func (s *GETSUnifySDK) Close() error {
+	if s == nil {
+		return nil
 	}
+	s.closeOnce.Do(func() {
+		if s.queueManager != nil {
+			s.queueManager.shutdown()
+			if _, memory := s.queueManager.store.(*MemoryQueueStore); memory {
+				if remaining := s.queueManager.remainingCount(); remaining > 0 {
+					s.queueManager.logger.Warn("Closing SDK with submissions left in the memory queue", map[string]interface{}{"remaining": remaining})
+				}
+			}
+		}
+		if s.apiClient != nil {
+			s.apiClient.CloseIdleConnections()
+		}
+	})
+	return nil
+}
+
+// ResetCircuitBreaker Close the circuit breaker after an outage has been fixed,
+// so queued submissions are sent on the next pass instead of after the open
+// timeout.
+// Uses the SDK set up by Configure.
+func ResetCircuitBreaker() {
+	currentSDK().ResetCircuitBreaker()
+}
+
+// ResetCircuitBreaker Close the circuit breaker after an outage has been fixed,
+// so queued submissions are sent on the next pass instead of after the open
+// timeout.
+func (s *GETSUnifySDK) ResetCircuitBreaker() {
+	if s != nil && s.apiClient != nil {
+		s.apiClient.GetCircuitBreaker().Reset()
+	}
+}
+
+// DrainQueue Send queued submissions until the queue is empty or ctx is done,
+// returning how many are still waiting. Call it before shutdown, e.g. from a
+// Kubernetes preStop hook, with a context bounded by the grace period.
+// Uses the SDK set up by Configure.
+func DrainQueue(ctx context.Context) (int, error) {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.DrainQueue(ctx)
 }
 
-func DrainQueue(timeout time.Duration) bool {
-	if globalSDK != nil && globalSDK.queueManager != nil {
-		return globalSDK.queueManager.DrainQueue(timeout)
+// DrainQueue Send queued submissions until the queue is empty or ctx is done,
+// returning how many are still waiting. Call it before shutdown, e.g. from a
+// Kubernetes preStop hook, with a context bounded by the grace period.
+func (s *GETSUnifySDK) DrainQueue(ctx context.Context) (int, error) {
+	if s != nil && s.queueManager != nil {
+		return s.queueManager.DrainQueue(ctx)
 	}
-	return true
+	return 0, nil
+}
+
+// EnqueueBatch Queue several payload submissions at once, skipping ones
+// already queued; see PersistentQueueManager.EnqueueBatch.
+// Uses the SDK set up by Configure.
+func EnqueueBatch(submissions []*PayloadSubmission) error {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.EnqueueBatch(submissions)
+}
+
+// EnqueueBatch Queue several payload submissions at once, skipping ones
+// already queued; see PersistentQueueManager.EnqueueBatch.
+func (s *GETSUnifySDK) EnqueueBatch(submissions []*PayloadSubmission) error {
+	if s == nil || s.queueManager == nil {
+		return NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeQueueError,
+			"Queue Manager is not initialized",
+		).WithSuggestion("Call Configure before queueing submissions"))
+	}
+	return s.queueManager.EnqueueBatch(submissions)
 }
 
 // ProcessQueuedSubmissionsFirst Process queued submissions before handling new requests
+// Uses the SDK set up by Configure.
 func ProcessQueuedSubmissionsFirst() {
-	if globalSDK != nil && globalSDK.queueManager != nil {
+	sdk, release := acquireSDK()
+	defer release()
+	sdk.ProcessQueuedSubmissionsFirst()
+}
+
+// ProcessQueuedSubmissionsFirst Process queued submissions before handling new requests
+func (s *GETSUnifySDK) ProcessQueuedSubmissionsFirst() {
+	if s != nil && s.queueManager != nil {
 		// Processing queued submissions
-		globalSDK.queueManager.ProcessPendingSubmissionsNow()
+		s.queueManager.ProcessPendingSubmissionsNow()
 	}
 }
 
-// validateCountryForEnvironment Validate country restrictions based on current environment
-// Implements the three-tier country access control:
+// defaultAllowedCountries Countries allowed per restricted environment when
+// SDKConfig.AllowedCountries has no entry for it:
 // - SA: Allowed in all production environments (SANDBOX, SIMULATION, PRODUCTION)
-// - MY: Allowed in SANDBOX and PRODUCTION only (blocked in SIMULATION)
-// - AE: Allowed in SANDBOX and PRODUCTION only (blocked in SIMULATION)
+// - MY, AE, EG, IN: Allowed in SANDBOX and PRODUCTION only (blocked in SIMULATION)
 // - Others: Blocked in all production environments
-func validateCountryForEnvironment(country Country, environment Environment) error {
-	if environment == EnvironmentSandbox || environment == EnvironmentSimulation || environment == EnvironmentProduction {
-		// SA is allowed in all production environments
-		if country == CountrySA {
-			return nil // SA is always allowed
-		}
+// DEV/TEST/STAGE/LOCAL are unrestricted.
+var defaultAllowedCountries = map[Environment][]Country{
+	EnvironmentSandbox:    {CountrySA, CountryMY, CountryAE, CountryEG, CountryIN},
+	EnvironmentSimulation: {CountrySA},
+	EnvironmentProduction: {CountrySA, CountryMY, CountryAE, CountryEG, CountryIN},
+}
 
-		// MY is only allowed in SANDBOX and PRODUCTION (not SIMULATION)
-		if country == CountryMY {
-			if environment == EnvironmentSimulation {
-				return NewSDKError(NewErrorDetailWithCode(
-					ErrorCodeInvalidArgument,
-					"Country not allowed for simulation environment. MY (Malaysia) is not allowed in SIMULATION environment. Use SANDBOX or PRODUCTION.",
-				))
-			}
-			return nil // MY is allowed in SANDBOX and PRODUCTION
-		}
+// DefaultAllowedCountries Built-in allow-list for an environment. ok is false
+// for environments that allow every country.
+func DefaultAllowedCountries(environment Environment) (countries []Country, ok bool) {
+	countries, ok = defaultAllowedCountries[environment]
+	if !ok {
+		return nil, false
+	}
+	return append([]Country(nil), countries...), true
+}
 
-		// AE (UAE) is only allowed in SANDBOX and PRODUCTION (not SIMULATION)
-		if country == CountryAE {
-			if environment == EnvironmentSimulation {
-				return NewSDKError(NewErrorDetailWithCode(
-					ErrorCodeInvalidArgument,
-					"Country not allowed for simulation environment. AE (UAE) is not allowed in SIMULATION environment. Use SANDBOX or PRODUCTION.",
-				))
-			}
-			return nil // AE is allowed in SANDBOX and PRODUCTION
+// validateCountryForEnvironment Validate country restrictions for the configured
+// environment, using the SDKConfig.AllowedCountries override when one is set
+func validateCountryForEnvironment(country Country, config *SDKConfig) error {
+	allowed, restricted := config.AllowedCountriesFor(config.Environment)
+	if !restricted {
+		return nil
+	}
+	for _, candidate := range allowed {
+		if candidate == country {
+			return nil
 		}
-
-		// All other countries are blocked in production environments
-		return NewSDKError(NewErrorDetailWithCode(
-			ErrorCodeInvalidArgument,
-			fmt.Sprintf("Country not allowed for production environment. Only SA, MY, and AE are allowed for %s. Use DEV/TEST/STAGE for other countries.", environment),
-		))
 	}
 
-	// For DEV/TEST/STAGE/LOCAL, all countries are allowed
-	return nil
+	names := make([]string, len(allowed))
+	for i, candidate := range allowed {
+		names[i] = string(candidate)
+	}
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		fmt.Sprintf("Country %s is not allowed in %s environment. Allowed countries: %s.", country, config.Environment, strings.Join(names, ", ")),
+	).WithSuggestion("Use DEV/TEST/STAGE for other countries, or add the country to SDKConfig.AllowedCountries for this environment once certified.")
+	detail.AddContextValue("country", string(country))
+	detail.AddContextValue("environment", string(config.Environment))
+	return NewSDKError(detail)
 }
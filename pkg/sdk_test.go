@@ -0,0 +1,441 @@
+package complyancesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newInstanceTestSDK SDK created with NewSDK whose queue lives in its own
+// directory and whose requests go to a test server recording the bearer token
+func newInstanceTestSDK(t *testing.T, apiKey string) (*GETSUnifySDK, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submissionId":"sub-` + apiKey + `"}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := NewSDKConfig(apiKey, EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueBasePath(filepath.Join(t.TempDir(), apiKey))
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+	sdk.apiClient.baseURL = server.URL
+	return sdk, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), tokens...)
+	}
+}
+
+func TestNewSDKInstancesDoNotShareState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	previous := globalSDK
+	globalSDK = nil
+	t.Cleanup(func() { globalSDK = previous })
+
+	first, firstTokens := newInstanceTestSDK(t, "key-one")
+	second, secondTokens := newInstanceTestSDK(t, "key-two")
+
+	if globalSDK != nil {
+		t.Fatalf("expected NewSDK to leave the package-level SDK unconfigured")
+	}
+	if first.apiClient == second.apiClient || first.queueManager == second.queueManager {
+		t.Fatalf("expected each SDK to own its client and queue")
+	}
+	if first.apiClient.GetCircuitBreaker() == second.apiClient.GetCircuitBreaker() {
+		t.Fatalf("expected each SDK to own its circuit breaker")
+	}
+
+	for _, sdk := range []*GETSUnifySDK{first, second} {
+		response, err := sdk.PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA,
+			OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{})
+		if err != nil {
+			t.Fatalf("push failed: %v", err)
+		}
+		if response.GetStatus() != "success" {
+			t.Fatalf("expected the push to succeed, got %q", response.GetStatus())
+		}
+	}
+	if tokens := firstTokens(); len(tokens) != 1 || tokens[0] != "Bearer key-one" {
+		t.Fatalf("expected one request with the first key, got %v", tokens)
+	}
+	if tokens := secondTokens(); len(tokens) != 1 || tokens[0] != "Bearer key-two" {
+		t.Fatalf("expected one request with the second key, got %v", tokens)
+	}
+
+	request := NewUnifyRequestBuilder().
+		Source(NewSource("src", "1", nil)).
+		Country("SA").
+		DocumentType(DocumentTypeTaxInvoice).
+		Payload(testInvoicePayload("INV-2")).
+		RequestID("req-second").
+		APIKey("key-two").
+		Build()
+	if err := second.queueManager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	if pending := first.GetDetailedQueueStatus().PendingCount; pending != 0 {
+		t.Fatalf("expected the first SDK's queue to stay empty, got %d pending", pending)
+	}
+	if pending := second.GetDetailedQueueStatus().PendingCount; pending != 1 {
+		t.Fatalf("expected the second SDK's queue to hold its submission, got %d pending", pending)
+	}
+	if first.queueManager.queueBasePath == second.queueManager.queueBasePath {
+		t.Fatalf("expected separate queue directories, both use %q", first.queueManager.queueBasePath)
+	}
+
+	second.ProcessPendingSubmissions()
+	if tokens := secondTokens(); len(tokens) != 2 || tokens[1] != "Bearer key-two" {
+		t.Fatalf("expected the queued submission to be resent to the second SDK's server, got %v", tokens)
+	}
+	if tokens := firstTokens(); len(tokens) != 1 {
+		t.Fatalf("expected no further requests with the first key, got %v", tokens)
+	}
+}
+
+func TestPackageFunctionsRequireConfigure(t *testing.T) {
+	previous := globalSDK
+	globalSDK = nil
+	t.Cleanup(func() { globalSDK = previous })
+
+	_, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA,
+		OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), nil)
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeMissingField {
+		t.Fatalf("expected an unconfigured SDK error, got %v", err)
+	}
+	if status := GetQueueStatus(); status != "Queue Manager is not initialized" {
+		t.Fatalf("unexpected queue status %q", status)
+	}
+}
+
+func TestConcurrentConfigureAndPackageFunctions(t *testing.T) {
+	withoutEnvOverride(t)
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submissionId":"sub-1"}}}`))
+	}))
+	t.Cleanup(server.Close)
+	previous := currentSDK()
+	t.Cleanup(func() { globalSDK = previous })
+
+	newConfig := func() *SDKConfig {
+		cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()).
+			WithEnvironmentURLs(map[Environment]string{EnvironmentSandbox: server.URL})
+		cfg.SetQueueMode(QueueModeMemory)
+		return cfg
+	}
+	if err := Configure(newConfig()); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := Configure(newConfig()); err != nil {
+				t.Errorf("configure failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA,
+				OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{}); err != nil {
+				t.Errorf("push failed: %v", err)
+			}
+			GetQueueStatus()
+		}()
+		go func() {
+			defer wg.Done()
+			PauseQueueProcessing()
+			ProcessPendingSubmissions()
+			GetQueueStatusDetailed()
+			ResumeQueueProcessing()
+			HealthSnapshot()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigureWaitsForSubmissionsInFlightBeforeClosing(t *testing.T) {
+	withoutEnvOverride(t)
+	t.Setenv("HOME", t.TempDir())
+	arrived := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	var unblockOnce sync.Once
+	release := func() { unblockOnce.Do(func() { close(unblock) }) }
+	t.Cleanup(release)
+	previous := currentSDK()
+	t.Cleanup(func() { globalSDK = previous })
+
+	newConfig := func(apiKey string) *SDKConfig {
+		cfg := NewSDKConfig(apiKey, EnvironmentSandbox, nil, NewNoRetryConfig()).
+			WithEnvironmentURLs(map[Environment]string{EnvironmentSandbox: server.URL})
+		cfg.SetQueueMode(QueueModeMemory)
+		return cfg
+	}
+	if err := Configure(newConfig("first-key")); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	first := currentSDK()
+
+	pushed := make(chan *UnifyResponse, 1)
+	go func() {
+		response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA,
+			OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), []*Destination{})
+		if err != nil {
+			t.Errorf("push failed: %v", err)
+		}
+		pushed <- response
+	}()
+	<-arrived
+
+	configured := make(chan error, 1)
+	go func() { configured <- Configure(newConfig("second-key")) }()
+	select {
+	case <-configured:
+		t.Fatalf("expected Configure to wait for the submission in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if currentSDK() == first {
+		t.Fatalf("expected new calls to use the new SDK while the old one finishes")
+	}
+
+	release()
+	if response := <-pushed; response == nil || response.Status != "queued" {
+		t.Fatalf("expected the failed submission to be queued, got %+v", response)
+	}
+	if remaining := first.queueManager.remainingCount(); remaining != 1 {
+		t.Fatalf("expected the submission in the old SDK's queue, got %d", remaining)
+	}
+	if err := <-configured; err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	if first.queueManager.running() {
+		t.Fatalf("expected the old SDK to be closed once the submission finished")
+	}
+}
+
+func TestConfigureReusesEquivalentConfig(t *testing.T) {
+	previous := globalSDK
+	t.Cleanup(func() { globalSDK = previous })
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		seen[fmt.Sprintf("%v", body["requestId"])]++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	newConfig := func(apiKey string) *SDKConfig {
+		cfg := NewSDKConfig(apiKey, EnvironmentSandbox, nil, NewNoRetryConfig())
+		cfg.WithEnvironmentURLs(map[Environment]string{EnvironmentSandbox: server.URL})
+		return cfg
+	}
+	configure := func(cfg *SDKConfig) *GETSUnifySDK {
+		if err := Configure(cfg); err != nil {
+			t.Fatalf("configure failed: %v", err)
+		}
+		return currentSDK()
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	first := configure(newConfig("key-one"))
+	if again := configure(newConfig("key-one")); again != first {
+		t.Fatalf("expected an equivalent config to keep the configured SDK and its queue")
+	}
+
+	replaced := configure(newConfig("key-two"))
+	if replaced == first || replaced.queueManager == first.queueManager {
+		t.Fatalf("expected a different config to build a new SDK")
+	}
+	if first.queueManager.GetQueueStatus().IsRunning || !replaced.queueManager.GetQueueStatus().IsRunning {
+		t.Fatalf("expected only the new queue manager to be processing")
+	}
+
+	const total = 5
+	for i := 0; i < total; i++ {
+		writePendingRecord(t, replaced.queueManager.queueBasePath, fmt.Sprintf("req-%d", i))
+	}
+	first.queueManager.ProcessPendingSubmissionsNow()
+	replaced.queueManager.ProcessPendingSubmissionsNow()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != total {
+		t.Fatalf("expected %d distinct submissions, got %v", total, seen)
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Fatalf("submission %s processed %d times", id, count)
+		}
+	}
+}
+
+func TestConcurrentInstancesKeepQueuesAndBreakersApart(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// newAuthorityServer Test server recording the country of every request,
+	// answering with status
+	newAuthorityServer := func(status int) (string, func() []string) {
+		var mu sync.Mutex
+		var countries []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			countries = append(countries, fmt.Sprint(body["country"]))
+			mu.Unlock()
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submissionId":"sub-1"}}}`))
+		}))
+		t.Cleanup(server.Close)
+		return server.URL, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), countries...)
+		}
+	}
+	newAuthoritySDK := func(name, apiKey, serverURL string) *GETSUnifySDK {
+		cfg := NewSDKConfig(apiKey, EnvironmentSandbox, nil, NewNoRetryConfig())
+		cfg.SetInstanceName(name)
+		sdk, err := NewSDK(cfg)
+		if err != nil {
+			t.Fatalf("NewSDK failed: %v", err)
+		}
+		sdk.apiClient.baseURL = serverURL
+		return sdk
+	}
+
+	zatcaURL, zatcaCountries := newAuthorityServer(http.StatusOK)
+	lhdnURL, lhdnCountries := newAuthorityServer(http.StatusServiceUnavailable)
+	zatca := newAuthoritySDK("zatca", "key-sa", zatcaURL)
+	lhdn := newAuthoritySDK("lhdn", "key-my", lhdnURL)
+
+	if zatca.queueManager.queueBasePath != filepath.Join(home, QueueDir, InstancesDir, "zatca") ||
+		lhdn.queueManager.queueBasePath != filepath.Join(home, QueueDir, InstancesDir, "lhdn") {
+		t.Fatalf("expected queues named after the instances, got %q and %q", zatca.queueManager.queueBasePath, lhdn.queueManager.queueBasePath)
+	}
+
+	const submissions = 10
+	var wg sync.WaitGroup
+	for i := 0; i < submissions; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := zatca.PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments,
+				PurposeInvoicing, testInvoicePayload(fmt.Sprintf("SA-%d", i)), []*Destination{}); err != nil {
+				t.Errorf("SA push failed: %v", err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			// Failures are queued by the MY instance rather than returned
+			_, _ = lhdn.PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountryMY, OperationSingle, ModeDocuments,
+				PurposeInvoicing, testInvoicePayload(fmt.Sprintf("MY-%d", i)), []*Destination{})
+			_, _ = lhdn.apiClient.GetCircuitBreaker().Execute(func() (interface{}, error) {
+				return nil, fmt.Errorf("MY outage")
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for _, country := range zatcaCountries() {
+		if country != string(CountrySA) {
+			t.Fatalf("expected only SA submissions at the SA server, got %v", zatcaCountries())
+		}
+	}
+	for _, country := range lhdnCountries() {
+		if country != string(CountryMY) {
+			t.Fatalf("expected only MY submissions at the MY server, got %v", lhdnCountries())
+		}
+	}
+	if len(zatcaCountries()) != submissions {
+		t.Fatalf("expected %d SA submissions, got %d", submissions, len(zatcaCountries()))
+	}
+	if !lhdn.apiClient.GetCircuitBreaker().IsOpen() || !zatca.apiClient.GetCircuitBreaker().IsClosed() {
+		t.Fatalf("expected only the MY circuit breaker to open, got %s and %s",
+			lhdn.apiClient.GetCircuitBreaker().GetState(), zatca.apiClient.GetCircuitBreaker().GetState())
+	}
+	if status := zatca.GetDetailedQueueStatus(); status.PendingCount+status.FailedCount != 0 {
+		t.Fatalf("expected nothing queued for SA, got %s", status.String())
+	}
+	if status := lhdn.GetDetailedQueueStatus(); status.PendingCount+status.ProcessingCount+status.FailedCount == 0 {
+		t.Fatalf("expected the failed MY submissions in the MY queue, got %s", status.String())
+	}
+}
+
+func TestCloseReleasesConnectionsAndGoroutines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer server.Close()
+	baseline := settledGoroutineCount()
+
+	cfg := NewSDKConfig("key-close", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+	sdk.apiClient.baseURL = server.URL
+	for i := 0; i < 3; i++ {
+		if _, err := sdk.PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload(fmt.Sprintf("INV-%d", i)), nil); err != nil {
+			t.Fatalf("push %d failed: %v", i, err)
+		}
+	}
+
+	if err := sdk.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := sdk.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected goroutines to return to %d after Close, got %d", baseline, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// settledGoroutineCount Goroutine count once goroutines left by earlier tests
+// have exited
+func settledGoroutineCount() int {
+	count := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+		next := runtime.NumGoroutine()
+		if next == count {
+			return count
+		}
+		count = next
+	}
+	return count
+}
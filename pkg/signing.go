@@ -0,0 +1,40 @@
+/*
+HMAC request signing for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Request signing headers
+const (
+	// HeaderSignature carries the hex encoded HMAC-SHA256 signature
+	HeaderSignature = "X-Signature"
+
+	// HeaderSignatureTimestamp carries the Unix timestamp (seconds) included in the signature
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+)
+
+// SignRequestBody Compute the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+// with secret. Including the timestamp lets the receiver reject replayed requests.
+func SignRequestBody(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest Add signature headers for body when request signing is enabled
+func (a *APIClient) signRequest(headers map[string]string, body []byte) {
+	if !a.signingEnabled {
+		return
+	}
+	timestamp := strconv.FormatInt(a.clock.Now().Unix(), 10)
+	headers[HeaderSignatureTimestamp] = timestamp
+	headers[HeaderSignature] = SignRequestBody(a.signingSecret, timestamp, body)
+}
@@ -0,0 +1,61 @@
+package complyancesdk
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSignRequestBodyIsDeterministic(t *testing.T) {
+	body := []byte(`{"invoice_number":"INV-1"}`)
+	first := SignRequestBody("secret", "1700000000", body)
+	if first != SignRequestBody("secret", "1700000000", body) {
+		t.Fatalf("expected identical signatures for the same input")
+	}
+	if len(first) != 64 {
+		t.Fatalf("expected hex encoded SHA-256, got %q", first)
+	}
+	if first == SignRequestBody("secret", "1700000000", []byte(`{"invoice_number":"INV-2"}`)) {
+		t.Fatalf("expected signature to change with the body")
+	}
+	if first == SignRequestBody("secret", "1700000001", body) {
+		t.Fatalf("expected signature to change with the timestamp")
+	}
+	if first == SignRequestBody("other", "1700000000", body) {
+		t.Fatalf("expected signature to change with the secret")
+	}
+}
+
+func TestPushToUnifySignsRequestBody(t *testing.T) {
+	var signature, timestamp string
+	var body []byte
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetRequestSigning(true, "signing-secret")
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(HeaderSignature)
+		timestamp = r.Header.Get(HeaderSignatureTimestamp)
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	globalSDK.apiClient.SetClock(NewFakeClock(time.Unix(1700000000, 0)))
+	t.Cleanup(func() { globalSDK.apiClient.SetClock(SystemClock) })
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-SIGNED"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timestamp != "1700000000" {
+		t.Fatalf("expected the timestamp from the client's clock, got %q", timestamp)
+	}
+	if signature != SignRequestBody("signing-secret", timestamp, body) {
+		t.Fatalf("signature %q does not match body signed at %q", signature, timestamp)
+	}
+}
+
+func TestConfigureRequiresSigningSecret(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil)
+	cfg.SetRequestSigning(true, "")
+	if err := Configure(cfg); err == nil {
+		t.Fatalf("expected error when signing is enabled without a secret")
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Source registry for looking up configured sources by name and version.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SourceRegistry Lookup of configured sources by name and version
+type SourceRegistry struct {
+	sources []*Source
+}
+
+// NewSourceRegistry creates a registry over the given sources
+func NewSourceRegistry(sources []*Source) *SourceRegistry {
+	registry := &SourceRegistry{}
+	for _, source := range sources {
+		if source != nil {
+			registry.sources = append(registry.sources, source)
+		}
+	}
+	return registry
+}
+
+// GetSources getter for the registered sources
+func (r *SourceRegistry) GetSources() []*Source {
+	return append([]*Source(nil), r.sources...)
+}
+
+// Resolve Find the source registered under name and version. Names and versions
+// match case-insensitively, with an exact-case match preferred. An empty version
+// resolves to the highest registered version of the name, comparing numeric
+// segments numerically ("1.10" is later than "1.9"). Returns an error when no
+// source matches or when the match is ambiguous.
+func (r *SourceRegistry) Resolve(name, version string) (*Source, error) {
+	name = strings.TrimSpace(name)
+	version = strings.TrimSpace(version)
+	if name == "" {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Source name is required",
+		))
+	}
+
+	var candidates []*Source
+	for _, source := range r.sources {
+		if !strings.EqualFold(source.GetName(), name) {
+			continue
+		}
+		if version != "" && !strings.EqualFold(source.GetVersion(), version) {
+			continue
+		}
+		candidates = append(candidates, source)
+	}
+
+	if len(candidates) == 0 {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeInvalidSource,
+			"Source not found",
+		).WithSuggestion("Add the source to SDKConfig.Sources, or check its name and version.")
+		detail.AddContextValue("sourceName", name)
+		if version != "" {
+			detail.AddContextValue("sourceVersion", version)
+		}
+		return nil, NewSDKError(detail)
+	}
+
+	if version != "" {
+		return pickSourceCandidate(candidates, name, version)
+	}
+
+	var latest []*Source
+	for _, source := range candidates {
+		if len(latest) == 0 {
+			latest = []*Source{source}
+			continue
+		}
+		switch compareSourceVersions(source.GetVersion(), latest[0].GetVersion()) {
+		case 1:
+			latest = []*Source{source}
+		case 0:
+			latest = append(latest, source)
+		}
+	}
+	return pickSourceCandidate(latest, name, latest[0].GetVersion())
+}
+
+// pickSourceCandidate Single source among case-insensitive matches, preferring
+// the one whose name and version match exactly
+func pickSourceCandidate(candidates []*Source, name, version string) (*Source, error) {
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	var exact []*Source
+	for _, source := range candidates {
+		if source.GetName() == name && source.GetVersion() == version {
+			exact = append(exact, source)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+
+	identities := make([]string, len(candidates))
+	for i, source := range candidates {
+		identities[i] = source.GetIdentity()
+	}
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidSource,
+		fmt.Sprintf("Source %s matches several configured sources: %s", name, strings.Join(identities, ", ")),
+	).WithSuggestion("Pass the exact source name and version, or remove the duplicate sources from SDKConfig.Sources.")
+	detail.AddContextValue("sourceName", name)
+	detail.AddContextValue("candidates", identities)
+	return nil, NewSDKError(detail)
+}
+
+// compareSourceVersions Compare two versions segment by segment, split on "."
+// and "-". Numeric segments compare numerically, others case-insensitively;
+// returns -1, 0 or 1.
+func compareSourceVersions(a, b string) int {
+	split := func(version string) []string {
+		return strings.FieldsFunc(strings.ToLower(version), func(r rune) bool {
+			return r == '.' || r == '-'
+		})
+	}
+	left, right := split(a), split(b)
+	for i := 0; i < len(left) || i < len(right); i++ {
+		if i >= len(left) {
+			return -1
+		}
+		if i >= len(right) {
+			return 1
+		}
+		leftNumber, leftErr := strconv.Atoi(left[i])
+		rightNumber, rightErr := strconv.Atoi(right[i])
+		switch {
+		case leftErr == nil && rightErr == nil:
+			if leftNumber != rightNumber {
+				if leftNumber < rightNumber {
+					return -1
+				}
+				return 1
+			}
+		case leftErr == nil:
+			// Numeric segments sort after textual ones
+			return 1
+		case rightErr == nil:
+			return -1
+		default:
+			if cmp := strings.Compare(left[i], right[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package complyancesdk
+
+import "testing"
+
+func TestSourceRegistryResolvesVersions(t *testing.T) {
+	registry := NewSourceRegistry([]*Source{
+		NewSource("erp", "1.9", nil),
+		NewSource("erp", "1.10", nil),
+		NewSource("erp", "1.2", nil),
+		NewSource("pos", "2.0", nil),
+	})
+
+	source, err := registry.Resolve("erp", "1.9")
+	if err != nil || source.GetVersion() != "1.9" {
+		t.Fatalf("expected the exact version, got %v, %v", source, err)
+	}
+
+	source, err = registry.Resolve("ERP", "")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if source.GetName() != "erp" || source.GetVersion() != "1.10" {
+		t.Fatalf("expected the latest version 1.10, got %s", source.GetIdentity())
+	}
+
+	if _, err := registry.Resolve("erp", "3.0"); err == nil {
+		t.Fatalf("expected an unknown version to fail")
+	} else if code, ok := GetErrorCode(err); !ok || code != ErrorCodeInvalidSource {
+		t.Fatalf("expected INVALID_SOURCE, got %v", err)
+	}
+}
+
+func TestSourceRegistryReportsAmbiguousMatches(t *testing.T) {
+	registry := NewSourceRegistry([]*Source{
+		NewSource("ERP", "2.0", nil),
+		NewSource("erp", "2.0", nil),
+		NewSource("erp", "1.0", nil),
+	})
+
+	source, err := registry.Resolve("erp", "")
+	if err != nil || source.GetName() != "erp" || source.GetVersion() != "2.0" {
+		t.Fatalf("expected the exact-case match to win, got %v, %v", source, err)
+	}
+
+	_, err = registry.Resolve("Erp", "")
+	if err == nil {
+		t.Fatalf("expected two equally matching sources to be ambiguous")
+	}
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeInvalidSource {
+		t.Fatalf("expected INVALID_SOURCE, got %v", err)
+	}
+}
+
+func TestCompareSourceVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10", "1.9", 1},
+		{"2", "2.0", -1},
+		{"1.0.0", "1.0.0", 0},
+		{"1.0-rc1", "1.0-RC1", 0},
+		{"1.0.1", "1.0.beta", 1},
+	}
+	for _, tc := range cases {
+		if got := compareSourceVersions(tc.a, tc.b); got != tc.want {
+			t.Fatalf("compareSourceVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
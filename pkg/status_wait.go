@@ -0,0 +1,127 @@
+/*
+Submission status polling for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitOptions controls how WaitForTerminalStatus polls the submission status
+type WaitOptions struct {
+	// PollInterval is the delay before the second poll
+	PollInterval time.Duration `json:"poll_interval"`
+
+	// MaxPollInterval caps the delay between polls as it backs off
+	MaxPollInterval time.Duration `json:"max_poll_interval"`
+
+	// Multiplier grows the delay after every poll; values below 1 keep it fixed
+	Multiplier float64 `json:"multiplier"`
+
+	// MaxWait bounds the total time spent waiting; zero waits until ctx is done
+	MaxWait time.Duration `json:"max_wait"`
+}
+
+// NewWaitOptions creates wait options polling every 2s, backing off to 30s, for up to 5 minutes
+func NewWaitOptions() *WaitOptions {
+	return &WaitOptions{
+		PollInterval:    2 * time.Second,
+		MaxPollInterval: 30 * time.Second,
+		Multiplier:      2.0,
+		MaxWait:         5 * time.Minute,
+	}
+}
+
+// nextInterval Delay to use after interval, bounded by MaxPollInterval
+func (o *WaitOptions) nextInterval(interval time.Duration) time.Duration {
+	if o.Multiplier > 1 {
+		interval = time.Duration(float64(interval) * o.Multiplier)
+	}
+	if o.MaxPollInterval > 0 && interval > o.MaxPollInterval {
+		interval = o.MaxPollInterval
+	}
+	return interval
+}
+
+// WaitForTerminalStatus polls GetStatus with exponential backoff until the
+// submission is ACCEPTED, REJECTED or FAILED. When MaxWait elapses first a
+// SUBMISSION_TIMEOUT error is returned together with the last status seen; when
+// ctx is done the context error is returned instead. Polls are paced and MaxWait
+// is measured on the client's clock. nil opts uses NewWaitOptions.
+func (a *APIClient) WaitForTerminalStatus(ctx context.Context, submissionID string, opts *WaitOptions) (*SubmissionResponse, error) {
+	if opts == nil {
+		opts = NewWaitOptions()
+	}
+
+	waitCtx := ctx
+	var deadline time.Time
+	if opts.MaxWait > 0 {
+		deadline = a.clock.Now().Add(opts.MaxWait)
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, opts.MaxWait)
+		defer cancel()
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = NewWaitOptions().PollInterval
+	}
+
+	var last *SubmissionResponse
+	polls := 0
+	for {
+		status, err := a.GetStatus(waitCtx, submissionID)
+		polls++
+		if err == nil {
+			last = status
+			if status.IsTerminal() {
+				return status, nil
+			}
+		} else if waitCtx.Err() == nil {
+			return last, err
+		}
+
+		if wait, ok := pollWait(interval, deadline, a.clock.Now()); ok && waitCtx.Err() == nil {
+			select {
+			case now := <-a.clock.After(wait):
+				if deadline.IsZero() || now.Before(deadline) {
+					interval = opts.nextInterval(interval)
+					continue
+				}
+			case <-waitCtx.Done():
+			}
+		}
+
+		if ctx.Err() != nil {
+			return last, newContextSDKError(ctx.Err())
+		}
+		errorDetail := NewErrorDetailWithCode(
+			ErrorCodeSubmissionTimeout,
+			fmt.Sprintf("Submission %s did not reach a terminal status within %s", submissionID, opts.MaxWait),
+		).WithSuggestion("Increase WaitOptions.MaxWait or check the submission later with GetStatusContext.")
+		errorDetail.AddContextValue("submissionId", submissionID)
+		errorDetail.AddContextValue("polls", polls)
+		if last != nil && last.GetStatus() != nil {
+			errorDetail.AddContextValue("lastStatus", *last.GetStatus())
+		}
+		return last, NewSDKError(errorDetail)
+	}
+}
+
+// pollWait Time to sleep before the next poll: interval, shortened so the sleep
+// ends at deadline. false when deadline has passed; a zero deadline never passes.
+func pollWait(interval time.Duration, deadline, now time.Time) (time.Duration, bool) {
+	if deadline.IsZero() {
+		return interval, true
+	}
+	remaining := deadline.Sub(now)
+	if remaining <= 0 {
+		return 0, false
+	}
+	if remaining < interval {
+		return remaining, true
+	}
+	return interval, true
+}
@@ -0,0 +1,148 @@
+/*
+Retrieval of the signed or cleared document of a submission.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DocumentFormat Format of a document fetched with GetSubmissionDocument
+type DocumentFormat string
+
+const (
+	// DocumentFormatXML The signed or cleared XML, e.g. ZATCA's signed UBL invoice
+	DocumentFormatXML DocumentFormat = "XML"
+	// DocumentFormatJSON The validated document as JSON, e.g. from LHDN
+	DocumentFormatJSON DocumentFormat = "JSON"
+	// DocumentFormatPDF A PDF rendering of the cleared document
+	DocumentFormatPDF DocumentFormat = "PDF"
+)
+
+// documentFormatContentTypes Content type requested for each format
+var documentFormatContentTypes = map[DocumentFormat]string{
+	DocumentFormatXML:  "application/xml",
+	DocumentFormatJSON: "application/json",
+	DocumentFormatPDF:  "application/pdf",
+}
+
+// SubmissionDocument Document of a submission as returned by the API
+type SubmissionDocument struct {
+	SubmissionID string
+	Format       DocumentFormat
+	// ContentType is the response's Content-Type, or the format's usual content
+	// type when the response has none
+	ContentType string
+	Content     []byte
+}
+
+// GetSubmissionDocument gets the signed or cleared document of a submission in
+// format. Calls GET /api/v3/submissions/{submissionId}/document through the
+// retry strategy.
+func (a *APIClient) GetSubmissionDocument(ctx context.Context, submissionID string, format DocumentFormat) (*SubmissionDocument, error) {
+	normalized := strings.TrimSpace(submissionID)
+	if normalized == "" {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			"Submission ID is required",
+		).WithSuggestion("Provide the submissionId returned by PushToUnify."))
+	}
+	format = DocumentFormat(strings.ToUpper(strings.TrimSpace(string(format))))
+	if _, ok := documentFormatContentTypes[format]; !ok {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			fmt.Sprintf("Unknown document format %q", format),
+		).WithSuggestion("Use DocumentFormatXML, DocumentFormatJSON or DocumentFormatPDF.")
+		detail.AddContextValue("format", string(format))
+		return nil, NewSDKError(detail)
+	}
+
+	result, err := a.retryStrategy.ExecuteContext(
+		ctx,
+		func() (interface{}, error) {
+			return a.getSubmissionDocumentInternal(ctx, normalized, format)
+		},
+		fmt.Sprintf("submission-document-%s", normalized),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*SubmissionDocument), nil
+}
+
+// getSubmissionDocumentInternal Internal method to fetch a submission document
+func (a *APIClient) getSubmissionDocumentInternal(ctx context.Context, submissionID string, format DocumentFormat) (*SubmissionDocument, error) {
+	fullURL := a.serviceURL(fmt.Sprintf("/api/v3/submissions/%s/document?format=%s",
+		url.PathEscape(submissionID), url.QueryEscape(strings.ToLower(string(format)))))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeNetworkError,
+			fmt.Sprintf("Failed to create HTTP request: %v", err),
+		))
+	}
+
+	req.Header.Set("Accept", documentFormatContentTypes[format])
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	req.Header.Set("X-API-Key", a.apiKey)
+
+	resp, err := a.doRequest(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, newContextSDKError(ctx.Err())
+		}
+		errorDetail := NewErrorDetailWithCode(
+			ErrorCodeNetworkError,
+			fmt.Sprintf("Network error: %v", err),
+		).WithSuggestion("Check your network connection and try again")
+		errorDetail.Retryable = true
+		return nil, NewSDKError(errorDetail)
+	}
+	defer resp.Body.Close()
+
+	body, err := a.readResponseBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, err := a.handleErrorResponse(resp.StatusCode, string(body), resp)
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = documentFormatContentTypes[format]
+	}
+	return &SubmissionDocument{
+		SubmissionID: submissionID,
+		Format:       format,
+		ContentType:  contentType,
+		Content:      body,
+	}, nil
+}
+
+// GetSubmissionDocument gets the signed or cleared document of a submission,
+// e.g. ZATCA's signed XML, for archiving.
+// Uses the SDK set up by Configure.
+func GetSubmissionDocument(ctx context.Context, submissionID string, format DocumentFormat) (*SubmissionDocument, error) {
+	return currentSDK().GetSubmissionDocument(ctx, submissionID, format)
+}
+
+// GetSubmissionDocument gets the signed or cleared document of a submission,
+// e.g. ZATCA's signed XML, for archiving.
+func (s *GETSUnifySDK) GetSubmissionDocument(ctx context.Context, submissionID string, format DocumentFormat) (*SubmissionDocument, error) {
+	if s == nil || s.apiClient == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+
+	return s.apiClient.GetSubmissionDocument(ctx, submissionID, format)
+}
@@ -0,0 +1,144 @@
+/*
+Lookup of a submission in the local persistent queue or the Unify API.
+*/
+package complyancesdk
+
+import (
+	"context"
+	"strings"
+)
+
+// SubmissionLocation Where a submission currently is. When the submission is
+// held in the local persistent queue State is its queue state; otherwise State
+// is empty and Remote carries the status reported by the API.
+type SubmissionLocation struct {
+	SubmissionID string                      `json:"submission_id"`
+	State        QueueState                  `json:"state,omitempty"`
+	QueueItemID  string                      `json:"queue_item_id,omitempty"`
+	Attempts     int                         `json:"attempts"`
+	LastError    string                      `json:"last_error,omitempty"`
+	Record       *PersistentSubmissionRecord `json:"record,omitempty"`
+	Remote       *SubmissionResponse         `json:"remote,omitempty"`
+}
+
+// GetSubmissionID getter for submission ID
+func (s *SubmissionLocation) GetSubmissionID() string {
+	return s.SubmissionID
+}
+
+// GetState getter for the queue state; empty when the submission is not queued locally
+func (s *SubmissionLocation) GetState() QueueState {
+	return s.State
+}
+
+// GetQueueItemID getter for queue item ID
+func (s *SubmissionLocation) GetQueueItemID() string {
+	return s.QueueItemID
+}
+
+// GetAttempts getter for the number of failed send attempts from the queue
+func (s *SubmissionLocation) GetAttempts() int {
+	return s.Attempts
+}
+
+// GetLastError getter for the error from the last failed queue attempt
+func (s *SubmissionLocation) GetLastError() string {
+	return s.LastError
+}
+
+// GetRecord getter for the queued record
+func (s *SubmissionLocation) GetRecord() *PersistentSubmissionRecord {
+	return s.Record
+}
+
+// GetRemote getter for the status reported by the API
+func (s *SubmissionLocation) GetRemote() *SubmissionResponse {
+	return s.Remote
+}
+
+// IsQueued reports whether the submission was found in the local queue
+func (s *SubmissionLocation) IsQueued() bool {
+	return s.State != ""
+}
+
+// LocateSubmission Find a submission in the local queue by its submission (request)
+// ID or queue item ID
+func (p *PersistentQueueManager) LocateSubmission(submissionID string) (*SubmissionLocation, bool) {
+	normalized := strings.TrimSpace(submissionID)
+	if normalized == "" {
+		return nil, false
+	}
+
+	// Queue items are stored under an ID derived from the request ID, so try that first
+	queueItemID := p.buildQueueItemID(&normalized, "", "", "")
+	for _, state := range queueStates {
+		if raw, err := p.store.Get(state, queueItemID); err == nil {
+			return newSubmissionLocation(normalized, state, decodeSubmissionRecord(raw, queueItemID)), true
+		}
+	}
+
+	for _, state := range queueStates {
+		ids, err := p.store.List(state)
+		if err != nil {
+			continue
+		}
+		for _, storedID := range ids {
+			raw, err := p.store.Get(state, storedID)
+			if err != nil {
+				continue
+			}
+			record := decodeSubmissionRecord(raw, storedID)
+			if record.GetRequestID() == normalized || record.GetQueueItemID() == normalized {
+				return newSubmissionLocation(normalized, state, record), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// newSubmissionLocation Location of a record found in the local queue
+func newSubmissionLocation(submissionID string, state QueueState, record *PersistentSubmissionRecord) *SubmissionLocation {
+	return &SubmissionLocation{
+		SubmissionID: submissionID,
+		State:        state,
+		QueueItemID:  record.GetQueueItemID(),
+		Attempts:     record.GetAttempts(),
+		LastError:    record.GetLastError(),
+		Record:       record,
+	}
+}
+
+// GetSubmissionLocation Look up a submission, such as the one returned in a
+// queued response, in the local persistent queue; when it is not queued locally
+// the API status endpoint is queried instead.
+// Uses the SDK set up by Configure.
+func GetSubmissionLocation(ctx context.Context, submissionID string) (*SubmissionLocation, error) {
+	return currentSDK().GetSubmissionLocation(ctx, submissionID)
+}
+
+// GetSubmissionLocation Look up a submission, such as the one returned in a
+// queued response, in the local persistent queue; when it is not queued locally
+// the API status endpoint is queried instead.
+func (s *GETSUnifySDK) GetSubmissionLocation(ctx context.Context, submissionID string) (*SubmissionLocation, error) {
+	if s == nil || s.apiClient == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+
+	if s.queueManager != nil {
+		if location, ok := s.queueManager.LocateSubmission(submissionID); ok {
+			return location, nil
+		}
+	}
+
+	remote, err := s.apiClient.GetStatus(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	return &SubmissionLocation{
+		SubmissionID: strings.TrimSpace(submissionID),
+		Remote:       remote,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetSubmissionLocationFollowsQueueTransitions(t *testing.T) {
+	sendFails := true
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/status") {
+			t.Fatalf("queued submissions should be resolved locally, got %s", r.URL.Path)
+		}
+		if sendFails {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+	manager := globalSDK.queueManager
+	clock := useFakeQueueClock(manager)
+
+	locate := func(id string) *SubmissionLocation {
+		t.Helper()
+		location, err := GetSubmissionLocation(context.Background(), id)
+		if err != nil {
+			t.Fatalf("locate %s: %v", id, err)
+		}
+		return location
+	}
+
+	request := newTestUnifyRequest("INV-LOC")
+	request.SetRequestID("req-loc")
+	if err := manager.EnqueueForRetry(request, "push_to_unify", nil, nil); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	if location := locate("req-loc"); location.GetState() != QueueStatePending || !location.IsQueued() {
+		t.Fatalf("expected pending, got %+v", location)
+	}
+
+	// A claimed item is found in processing
+	if _, err := manager.GetStore().Claim("req-loc"); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if location := locate("req-loc"); location.GetState() != QueueStateProcessing {
+		t.Fatalf("expected processing, got %+v", location)
+	}
+	if err := manager.GetStore().MarkFailed("req-loc", mustQueueRecord(t, manager, QueueStateProcessing, "req-loc")); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	clock.Advance(time.Minute)
+	manager.RetryFailedSubmissions()
+
+	manager.ProcessPendingSubmissionsNow()
+	location := locate("req-loc")
+	if location.GetState() != QueueStateFailed || location.GetAttempts() != 1 || location.GetLastError() == "" {
+		t.Fatalf("expected failed with last error, got %+v", location)
+	}
+
+	manager.SetMaxAttempts(1)
+	manager.RetryFailedSubmissions()
+	if location := locate("req-loc"); location.GetState() != QueueStateDeadLetter {
+		t.Fatalf("expected dead-letter, got %+v", location)
+	}
+
+	sendFails = false
+	success := newTestUnifyRequest("INV-LOC-2")
+	success.SetRequestID("req-loc-2")
+	_ = manager.EnqueueForRetry(success, "push_to_unify", nil, nil)
+	manager.ProcessPendingSubmissionsNow()
+	if location := locate("req-loc-2"); location.GetState() != QueueStateSuccess || location.GetQueueItemID() != "req-loc-2" {
+		t.Fatalf("expected success, got %+v", location)
+	}
+}
+
+func TestGetSubmissionLocationFallsBackToAPI(t *testing.T) {
+	var requestedPath string
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"submission_id":"sub-remote","status":"accepted"}}}`))
+	})
+
+	location, err := GetSubmissionLocation(context.Background(), "sub-remote")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestedPath != "/api/v3/submissions/sub-remote/status" {
+		t.Fatalf("unexpected status path %q", requestedPath)
+	}
+	if location.IsQueued() || location.GetRemote() == nil || !location.GetRemote().IsAccepted() {
+		t.Fatalf("expected remote accepted status, got %+v", location)
+	}
+}
+
+func mustQueueRecord(t *testing.T, manager *PersistentQueueManager, state QueueState, id string) []byte {
+	t.Helper()
+	raw, err := manager.GetStore().Get(state, id)
+	if err != nil {
+		t.Fatalf("read %s/%s: %v", state, id, err)
+	}
+	return raw
+}
@@ -0,0 +1,108 @@
+/*
+UTF-8 validation and Unicode normalization of payload text.
+*/
+package complyancesdk
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextNormalizer Normalize one payload string, e.g. to NFKC for a tax authority that expects it
+type TextNormalizer func(text string) string
+
+// NormalizeNFC Compose text to Unicode normalization form C
+func NormalizeNFC(text string) string {
+	return norm.NFC.String(text)
+}
+
+// normalizePayloadText Check that every string in payload, a request copy, is
+// valid UTF-8 and normalize it in place with normalizer. Invalid text is an
+// INVALID_ARGUMENT error naming the field and byte offset.
+func normalizePayloadText(payload map[string]interface{}, normalizer TextNormalizer) error {
+	_, err := normalizeTextValue(payload, "payload", normalizer)
+	return err
+}
+
+// normalizeTextValue Normalized value, recursing into maps and slices
+func normalizeTextValue(value interface{}, path string, normalizer TextNormalizer) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		return normalizeTextField(typed, path, normalizer)
+	case map[string]interface{}:
+		for key, child := range typed {
+			normalized, err := normalizeTextValue(child, path+"."+key, normalizer)
+			if err != nil {
+				return nil, err
+			}
+			typed[key] = normalized
+		}
+	case []interface{}:
+		for i, child := range typed {
+			normalized, err := normalizeTextValue(child, fmt.Sprintf("%s[%d]", path, i), normalizer)
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = normalized
+		}
+	case []map[string]interface{}:
+		for i, child := range typed {
+			if _, err := normalizeTextValue(child, fmt.Sprintf("%s[%d]", path, i), normalizer); err != nil {
+				return nil, err
+			}
+		}
+	case []string:
+		// Not copied by deepCopyPayload, so build a new slice
+		normalized := make([]string, len(typed))
+		for i, child := range typed {
+			text, err := normalizeTextField(child, fmt.Sprintf("%s[%d]", path, i), normalizer)
+			if err != nil {
+				return nil, err
+			}
+			normalized[i] = text
+		}
+		return normalized, nil
+	}
+	return value, nil
+}
+
+// normalizeTextField Normalized text, or an error when it is not valid UTF-8
+func normalizeTextField(text, path string, normalizer TextNormalizer) (string, error) {
+	if utf8.ValidString(text) {
+		return normalizer(text), nil
+	}
+	offset := 0
+	for offset < len(text) {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+		offset += size
+	}
+	detail := NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		fmt.Sprintf("Field %s is not valid UTF-8 (invalid byte 0x%02X at offset %d)", path, text[offset], offset),
+	).WithSuggestion("Convert the text to UTF-8 before submitting, e.g. from Windows-1256 for Arabic.")
+	detail.Field = &path
+	detail.AddContextValue("offset", offset)
+	return "", NewSDKError(detail)
+}
+
+// textNormalizerFor Normalizer for payloads sent to country, nil when text
+// normalization is off for it
+func (s *SDKConfig) textNormalizerFor(country Country) TextNormalizer {
+	enabled := false
+	for _, candidate := range s.TextNormalizationCountries {
+		enabled = enabled || strings.EqualFold(string(candidate), string(country))
+	}
+	if !enabled {
+		return nil
+	}
+	if s.TextNormalizer != nil {
+		return s.TextNormalizer
+	}
+	return NormalizeNFC
+}
@@ -0,0 +1,80 @@
+package complyancesdk
+
+import (
+	"testing"
+)
+
+func TestNormalizeNFCComposesAllScripts(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", "INV-001", "INV-001"},
+		{"already composed arabic", "\u0634\u0631\u0643\u0629 \u0622\u0645\u0646\u0629", "\u0634\u0631\u0643\u0629 \u0622\u0645\u0646\u0629"},
+		{"alef with madda", "\u0627\u0653", "\u0622"},
+		{"yeh with hamza", "\u064A\u0654", "\u0626"},
+		{"alef with hamza below and kasra", "\u0627\u0650\u0655", "\u0625\u0650"},
+		{"shadda reordered after fatha", "\u0628\u0651\u064E", "\u0628\u064E\u0651"},
+		{"mixed scripts", "Cafe\u0301 \u0634\u0631\u0643\u0629 \u0627\u0653\u0645\u0646\u0629", "Caf\u00E9 \u0634\u0631\u0643\u0629 \u0622\u0645\u0646\u0629"},
+		{"vietnamese", "Vie\u0323\u0302t Nam", "Vi\u1EC7t Nam"},
+		{"greek", "\u03B1\u0301\u03B8\u03AE\u03BD\u03B1", "\u03AC\u03B8\u03AE\u03BD\u03B1"},
+		{"devanagari nukta", "\u0915\u093C", "\u0915\u093C"},
+		{"hangul jamo", "\u1112\u1161\u11AB", "\uD55C"},
+		{"latin and arabic with hindi", "Re\u0301sume\u0301 \u064A\u0654 \u0928\u093C", "R\u00E9sum\u00E9 \u0626 \u0929"},
+	}
+	for _, tc := range cases {
+		if got := NormalizeNFC(tc.input); got != tc.want {
+			t.Fatalf("%s: got %+q, want %+q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTextNormalizationIsAppliedPerCountry(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	cfg.SetTextNormalizationCountries(CountrySA)
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+
+	build := func(country Country, sellerName string) (*UnifyRequest, error) {
+		payload := testInvoicePayload("INV-1")
+		payload["seller_info"] = map[string]interface{}{
+			"name":    sellerName,
+			"aliases": []string{sellerName},
+		}
+		return sdk.buildUnifyRequestV2("src", "1", MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+			country, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil)
+	}
+	sellerName := func(request *UnifyRequest) (string, string) {
+		seller := request.GetPayload()["seller_info"].(map[string]interface{})
+		return seller["name"].(string), seller["aliases"].([]string)[0]
+	}
+
+	request, err := build(CountrySA, "\u0634\u0631\u0643\u0629 \u0627\u0653\u0645\u0646\u0629 Cafe\u0301")
+	if err != nil {
+		t.Fatalf("expected valid Arabic to be accepted, got %v", err)
+	}
+	if name, alias := sellerName(request); name != "\u0634\u0631\u0643\u0629 \u0622\u0645\u0646\u0629 Caf\u00E9" || alias != name {
+		t.Fatalf("expected the seller name to be NFC normalized, got %+q and %+q", name, alias)
+	}
+
+	_, err = build(CountrySA, "\u0634\u0631\u0643\u0629 \xff\xfe")
+	sdkErr, ok := err.(*SDKError)
+	if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeInvalidArgument || sdkErr.ErrorDetail.Field == nil || *sdkErr.ErrorDetail.Field != "payload.seller_info.aliases[0]" && *sdkErr.ErrorDetail.Field != "payload.seller_info.name" {
+		t.Fatalf("expected INVALID_ARGUMENT naming the seller field, got %v", err)
+	}
+	if offset := sdkErr.ErrorDetail.Context["offset"]; offset != 9 {
+		t.Fatalf("expected the invalid byte at offset 9, got %v", offset)
+	}
+
+	request, err = build(CountryMY, "Cafe\u0301 \xff")
+	if err != nil {
+		t.Fatalf("expected countries without normalization to be left unchecked, got %v", err)
+	}
+	if name, _ := sellerName(request); name != "Cafe\u0301 \xff" {
+		t.Fatalf("expected the seller name to be left as is, got %+q", name)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+TLS and mutual TLS configuration for the Complyance SDK HTTP client.
+*/
+package complyancesdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// BuildTLSConfig TLS configuration for the API client assembled from TLSConfig,
+// the client certificate files and the pinned CA file. Returns nil when none of
+// them is set, leaving Go's default TLS settings in place.
+func (s *SDKConfig) BuildTLSConfig() (*tls.Config, error) {
+	if s.TLSConfig == nil && s.ClientCertFile == "" && s.ClientKeyFile == "" && s.CACertFile == "" {
+		return nil, nil
+	}
+
+	var tlsConfig *tls.Config
+	if s.TLSConfig != nil {
+		tlsConfig = s.TLSConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if s.ClientCertFile != "" || s.ClientKeyFile != "" {
+		if s.ClientCertFile == "" || s.ClientKeyFile == "" {
+			return nil, NewSDKError(NewErrorDetailWithCode(
+				ErrorCodeConfigurationError,
+				"Client certificate and key files must be set together",
+			).WithSuggestion("Set both ClientCertFile and ClientKeyFile for mutual TLS."))
+		}
+		certificate, err := tls.LoadX509KeyPair(s.ClientCertFile, s.ClientKeyFile)
+		if err != nil {
+			return nil, NewSDKError(NewErrorDetailWithCode(
+				ErrorCodeConfigurationError,
+				fmt.Sprintf("Failed to load client certificate: %v", err),
+			).WithSuggestion("Check that the certificate and key files are PEM encoded and belong together."))
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, certificate)
+	}
+
+	if s.CACertFile != "" {
+		pem, err := os.ReadFile(s.CACertFile)
+		if err != nil {
+			return nil, NewSDKError(NewErrorDetailWithCode(
+				ErrorCodeConfigurationError,
+				fmt.Sprintf("Failed to read CA certificate file: %v", err),
+			).WithSuggestion("Check that the CA file exists and is readable."))
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, NewSDKError(NewErrorDetailWithCode(
+				ErrorCodeConfigurationError,
+				"CA certificate file contains no PEM certificates",
+			).WithSuggestion("Provide the server's CA certificate in PEM format."))
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// SetTLSConfig Use tlsConfig for connections to the API, e.g. to present a client
+// certificate or trust a private CA. nil restores the default TLS settings. The
+// current HTTP client is copied rather than modified.
+func (a *APIClient) SetTLSConfig(tlsConfig *tls.Config) {
+	client := *a.httpClient
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	client.Transport = transport
+	a.httpClient = &client
+}
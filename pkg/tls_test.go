@@ -0,0 +1,119 @@
+package complyancesdk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newClientCertificate creates a CA and a client certificate it signed, writing
+// the client certificate and key as PEM files
+func newClientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	clientKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "sdk-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create client certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(clientKey)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientDER}), 0600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool, certFile, keyFile
+}
+
+func TestMutualTLSHandshake(t *testing.T) {
+	clientCAs, certFile, keyFile := newClientCertificate(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	serverCAFile := filepath.Join(t.TempDir(), "server-ca.pem")
+	_ = os.WriteFile(serverCAFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	send := func(cfg *SDKConfig) error {
+		tlsConfig, err := cfg.BuildTLSConfig()
+		if err != nil {
+			t.Fatalf("build TLS config: %v", err)
+		}
+		client := NewAPIClient("test-key", EnvironmentSandbox, NewNoRetryConfig())
+		client.SetBaseURL(server.URL)
+		client.SetTLSConfig(tlsConfig)
+		_, err = client.SendUnifyRequest(newTestUnifyRequest("INV-MTLS"))
+		return err
+	}
+
+	configured := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil)
+	configured.SetClientCertificate(certFile, keyFile)
+	configured.SetCACertFile(serverCAFile)
+	if err := send(configured); err != nil {
+		t.Fatalf("expected handshake with client certificate to succeed: %v", err)
+	}
+
+	withoutCertificate := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil)
+	withoutCertificate.SetCACertFile(serverCAFile)
+	if err := send(withoutCertificate); err == nil {
+		t.Fatalf("expected handshake without client certificate to fail")
+	}
+
+	withoutPinnedCA := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil)
+	withoutPinnedCA.SetClientCertificate(certFile, keyFile)
+	if err := send(withoutPinnedCA); err == nil {
+		t.Fatalf("expected handshake without the server CA to fail")
+	}
+}
+
+func TestBuildTLSConfigRejectsIncompleteClientCertificate(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil)
+	cfg.SetClientCertificate("client.pem", "")
+	if _, err := cfg.BuildTLSConfig(); err == nil {
+		t.Fatalf("expected error when the key file is missing")
+	}
+	if tlsConfig, err := NewSDKConfig("test-key", EnvironmentSandbox, nil, nil).BuildTLSConfig(); tlsConfig != nil || err != nil {
+		t.Fatalf("expected no TLS config by default, got %v, %v", tlsConfig, err)
+	}
+}
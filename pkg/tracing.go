@@ -0,0 +1,73 @@
+/*
+OpenTelemetry tracing for the Complyance SDK.
+*/
+package complyancesdk
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName Instrumentation name reported on SDK spans
+const TracerName = "github.com/complyance-io/complyance-go-sdk/v3"
+
+// Span names for the Unify request lifecycle
+const (
+	SpanUnifyRequest     = "complyance.unify.request"
+	SpanUnifySerialize   = "complyance.unify.serialize"
+	SpanUnifyHTTP        = "complyance.unify.http"
+	SpanUnifyDeserialize = "complyance.unify.deserialize"
+)
+
+// Span attribute keys
+const (
+	AttributeCountry        = attribute.Key("complyance.country")
+	AttributeDocumentType   = attribute.Key("complyance.document_type")
+	AttributeOperation      = attribute.Key("complyance.operation")
+	AttributeRequestID      = attribute.Key("complyance.request_id")
+	AttributeHTTPStatusCode = attribute.Key("http.status_code")
+)
+
+// newTracer Tracer from the provider, or a no-op tracer when none is configured
+func newTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = trace.NewNoopTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
+
+// unifyRequestAttributes Attributes describing a Unify request
+func unifyRequestAttributes(request *UnifyRequest) []attribute.KeyValue {
+	documentType := string(request.GetDocumentType())
+	if request.GetDocumentTypeString() != nil {
+		documentType = *request.GetDocumentTypeString()
+	}
+	attributes := []attribute.KeyValue{
+		AttributeCountry.String(request.GetCountry()),
+		AttributeDocumentType.String(documentType),
+	}
+	if request.GetOperation() != nil {
+		attributes = append(attributes, AttributeOperation.String(string(*request.GetOperation())))
+	}
+	if request.GetRequestID() != nil {
+		attributes = append(attributes, AttributeRequestID.String(*request.GetRequestID()))
+	}
+	return attributes
+}
+
+// endSpan Record err on the span, if any, and end it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startSpan Start a child span of whatever span ctx carries
+func (a *APIClient) startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return a.tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
@@ -0,0 +1,98 @@
+package complyancesdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecordingTracerProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestSendUnifyRequestRecordsSpanHierarchy(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+	})
+	provider, recorder := newRecordingTracerProvider()
+	client.SetTracerProvider(provider)
+
+	request := newTestUnifyRequest("INV-TRACE")
+	request.SetOperation(OperationSingle)
+	if _, err := client.SendUnifyRequestContext(context.Background(), request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	root, ok := spans[SpanUnifyRequest]
+	if !ok || len(spans) != 4 {
+		t.Fatalf("expected request span with three children, got %v", spans)
+	}
+	for _, name := range []string{SpanUnifySerialize, SpanUnifyHTTP, SpanUnifyDeserialize} {
+		child, ok := spans[name]
+		if !ok {
+			t.Fatalf("missing span %s", name)
+		}
+		if child.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Fatalf("span %s is not a child of the request span", name)
+		}
+	}
+
+	attributes := spanAttributes(root)
+	if attributes[AttributeCountry].AsString() != "SA" ||
+		attributes[AttributeDocumentType].AsString() != string(DocumentTypeTaxInvoice) ||
+		attributes[AttributeOperation].AsString() != string(OperationSingle) ||
+		attributes[AttributeRequestID].AsString() != *request.GetRequestID() {
+		t.Fatalf("unexpected request span attributes: %v", attributes)
+	}
+	if attributes[AttributeHTTPStatusCode].AsInt64() != http.StatusOK {
+		t.Fatalf("expected HTTP status on request span, got %v", attributes[AttributeHTTPStatusCode])
+	}
+	if spanAttributes(spans[SpanUnifyHTTP])[AttributeHTTPStatusCode].AsInt64() != http.StatusOK {
+		t.Fatalf("expected HTTP status on http span")
+	}
+}
+
+func TestSendUnifyRequestRecordsErrorOnSpan(t *testing.T) {
+	client := newQueueTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad payload"}}`))
+	})
+	provider, recorder := newRecordingTracerProvider()
+	client.SetTracerProvider(provider)
+
+	if _, err := client.SendUnifyRequest(newTestUnifyRequest("INV-TRACE-ERR")); err == nil {
+		t.Fatalf("expected error for 400 response")
+	}
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != SpanUnifyRequest {
+			continue
+		}
+		if span.Status().Code != codes.Error {
+			t.Fatalf("expected error status on request span, got %v", span.Status())
+		}
+		if spanAttributes(span)[AttributeHTTPStatusCode].AsInt64() != http.StatusBadRequest {
+			t.Fatalf("expected 400 on request span")
+		}
+		return
+	}
+	t.Fatalf("request span not recorded")
+}
@@ -6,7 +6,9 @@ This contains the logical document type processing functionality.
 package complyancesdk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -15,6 +17,7 @@ import (
 )
 
 // PushToUnify Push to Unify API with logical document types but full control over operation, mode, and purpose
+// Uses the SDK set up by Configure.
 func PushToUnify(
 	sourceName string,
 	sourceVersion string,
@@ -26,25 +29,118 @@ func PushToUnify(
 	payload map[string]interface{},
 	destinations []*Destination,
 ) (*UnifyResponse, error) {
-	policy := CountryPolicyRegistryInstance.Evaluate(country, logicalType)
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.PushToUnify(sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payload, destinations)
+}
+
+// PushToUnify Push to Unify API with logical document types but full control over operation, mode, and purpose
+func (s *GETSUnifySDK) PushToUnify(
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyResponse, error) {
+	return s.pushToUnifyContext(context.Background(), sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payload, destinations)
+}
+
+// pushToUnifyContext PushToUnify, sending the request with ctx
+func (s *GETSUnifySDK) pushToUnifyContext(
+	ctx context.Context,
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyResponse, error) {
+	if s == nil || s.config == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		))
+	}
+
+	request, err := s.buildLogicalRequest(sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payload, destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Process queued submissions first before handling new requests
+	s.ProcessQueuedSubmissionsFirst()
+
+	return s.sendUnifyRequest(ctx, request)
+}
+
+// buildLogicalRequest Validate the logical document type and original
+// reference, merge the country policy and build the request PushToUnify sends.
+// BuildSerializedRequest, ValidateDocument and Convert use it too, so they
+// accept and reject exactly what PushToUnify does.
+func (s *GETSUnifySDK) buildLogicalRequest(
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyRequest, error) {
+	if err := validateLogicalDocType(logicalType, country); err != nil {
+		return nil, err
+	}
+	if err := s.config.validateOriginalReference(logicalType, payload); err != nil {
+		return nil, err
+	}
+	mergedPayload, documentTypeV2 := applyCountryPolicy(logicalType, country, payload)
+
+	return s.buildUnifyRequestV2(
+		sourceName, sourceVersion, documentTypeV2,
+		country, operation, mode, purpose, mergedPayload, destinations,
+	)
+}
+
+// validateLogicalDocType Reject a logical type that is neither a defined
+// LogicalDocType nor registered as a custom policy for country
+func validateLogicalDocType(logicalType LogicalDocType, country Country) error {
+	if logicalType.IsValid() {
+		return nil
+	}
+	if _, custom := CountryPolicyRegistryInstance.customPolicy(country, logicalType); custom {
+		return nil
+	}
+	return invalidLogicalDocTypeError(string(logicalType))
+}
+
+// applyCountryPolicy Merge the country policy's meta config flags into the payload
+// and resolve the GETS document type. A custom policy's base type overrides the
+// base derived from the logical type. invoice_data.document_type is filled in
+// from the resolved base by buildUnifyRequestV2.
+func applyCountryPolicy(logicalType LogicalDocType, country Country, payload map[string]interface{}) (map[string]interface{}, *GetsDocumentTypeV2) {
+	policy, custom := CountryPolicyRegistryInstance.customPolicy(country, logicalType)
+	if !custom {
+		policy = CountryPolicyRegistryInstance.evaluateBuiltIn(country, logicalType)
+	}
 	mergedPayload := deepMergeIntoMetaConfig(payload, policy.GetMetaConfigFlags())
-	setInvoiceDataDocumentType(mergedPayload, policy.GetDocumentType())
 
 	documentTypeV2 := MapLogicalDocTypeToGetsV2(logicalType)
-	return PushToUnifyV2(
-		sourceName,
-		sourceVersion,
-		documentTypeV2,
-		country,
-		operation,
-		mode,
-		purpose,
-		mergedPayload,
-		destinations,
-	)
+	if custom {
+		documentTypeV2.Base = strings.ToLower(string(policy.GetBaseType()))
+	}
+	return mergedPayload, documentTypeV2
 }
 
 // PushToUnifyV2 Push to Unify API using GETS V2 document type model
+// Uses the SDK set up by Configure.
 func PushToUnifyV2(
 	sourceName string,
 	sourceVersion string,
@@ -56,7 +152,40 @@ func PushToUnifyV2(
 	payload map[string]interface{},
 	destinations []*Destination,
 ) (*UnifyResponse, error) {
-	if globalSDK == nil || globalSDK.config == nil {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.PushToUnifyV2(sourceName, sourceVersion, documentTypeV2, country, operation, mode, purpose, payload, destinations)
+}
+
+// PushToUnifyV2 Push to Unify API using GETS V2 document type model
+func (s *GETSUnifySDK) PushToUnifyV2(
+	sourceName string,
+	sourceVersion string,
+	documentTypeV2 *GetsDocumentTypeV2,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyResponse, error) {
+	return s.pushToUnifyV2Context(context.Background(), sourceName, sourceVersion, documentTypeV2, country, operation, mode, purpose, payload, destinations)
+}
+
+// pushToUnifyV2Context PushToUnifyV2, sending the request with ctx
+func (s *GETSUnifySDK) pushToUnifyV2Context(
+	ctx context.Context,
+	sourceName string,
+	sourceVersion string,
+	documentTypeV2 *GetsDocumentTypeV2,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyResponse, error) {
+	if s == nil || s.config == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
 			"SDK not configured",
@@ -64,8 +193,205 @@ func PushToUnifyV2(
 	}
 
 	// Process queued submissions first before handling new requests
-	ProcessQueuedSubmissionsFirst()
+	s.ProcessQueuedSubmissionsFirst()
+
+	request, err := s.buildUnifyRequestV2(
+		sourceName, sourceVersion, documentTypeV2,
+		country, operation, mode, purpose, payload, destinations,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.sendUnifyRequest(ctx, request)
+}
+
+// BuildSerializedRequest Run the full PushToUnify pipeline (validation, policy
+// merging, flag injection, destination generation, the BeforeSend hook and
+// serialization) and return the JSON that would be sent, without sending it.
+// The API key is redacted.
+// Uses the SDK set up by Configure.
+func BuildSerializedRequest(
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) ([]byte, error) {
+	return currentSDK().BuildSerializedRequest(sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payload, destinations)
+}
+
+// BuildSerializedRequest Run the full PushToUnify pipeline (validation, policy
+// merging, flag injection, destination generation, the BeforeSend hook and
+// serialization) and return the JSON that would be sent, without sending it.
+// The API key is redacted.
+func (s *GETSUnifySDK) BuildSerializedRequest(
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) ([]byte, error) {
+	if s == nil || s.config == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		))
+	}
+
+	request, err := s.buildLogicalRequest(sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payload, destinations)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runBeforeSend(request); err != nil {
+		return nil, err
+	}
+
+	serialized, err := auditRequestJSON(s.apiClient.serializeRequest(request))
+	if err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeAPIError,
+			fmt.Sprintf("Failed to serialize request: %v", err),
+		))
+	}
+	return serialized, nil
+}
+
+// ValidateDocument Run a payload through mapping and validation without creating a
+// submission. The request is sent with PurposeValidation and no destinations, and
+// is never queued for retry.
+// Uses the SDK set up by Configure.
+func ValidateDocument(ctx context.Context, source *Source, logicalType LogicalDocType, country Country, payload map[string]interface{}) (*ValidationResponse, error) {
+	return currentSDK().ValidateDocument(ctx, source, logicalType, country, payload)
+}
+
+// ValidateDocument Run a payload through mapping and validation without creating a
+// submission. The request is sent with PurposeValidation and no destinations, and
+// is never queued for retry.
+func (s *GETSUnifySDK) ValidateDocument(ctx context.Context, source *Source, logicalType LogicalDocType, country Country, payload map[string]interface{}) (*ValidationResponse, error) {
+	if s == nil || s.config == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+	if source == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Source is required",
+		))
+	}
+
+	request, err := s.buildLogicalRequest(
+		source.GetName(), source.GetVersion(), logicalType,
+		country, OperationSingle, ModeDocuments, PurposeValidation, payload, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runBeforeSend(request); err != nil {
+		return nil, err
+	}
+
+	response, err := s.apiClient.SendUnifyRequestContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetData() == nil || response.GetData().GetValidation() == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeAPIError,
+			"Validation response did not include validation results",
+		).WithSuggestion("Check that the environment supports validate-only requests."))
+	}
+	return response.GetData().GetValidation(), nil
+}
+
+// Convert Run a payload through conversion to a GETS document without
+// submitting it. The request is sent with PurposeConversion and no destinations,
+// and is never queued for retry. When the conversion fails, the
+// ConversionResponse is returned together with a CONVERSION_ERROR so its errors
+// can be shown to the user.
+// Uses the SDK set up by Configure.
+func Convert(ctx context.Context, source *Source, logicalType LogicalDocType, country Country, payload map[string]interface{}) (*ConversionResponse, error) {
+	return currentSDK().Convert(ctx, source, logicalType, country, payload)
+}
+
+// Convert Run a payload through conversion to a GETS document without
+// submitting it. The request is sent with PurposeConversion and no destinations,
+// and is never queued for retry. When the conversion fails, the
+// ConversionResponse is returned together with a CONVERSION_ERROR so its errors
+// can be shown to the user.
+func (s *GETSUnifySDK) Convert(ctx context.Context, source *Source, logicalType LogicalDocType, country Country, payload map[string]interface{}) (*ConversionResponse, error) {
+	if s == nil || s.config == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"SDK not configured",
+		).WithSuggestion("Call Configure() first."))
+	}
+	if source == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Source is required",
+		))
+	}
+
+	request, err := s.buildLogicalRequest(
+		source.GetName(), source.GetVersion(), logicalType,
+		country, OperationSingle, ModeDocuments, PurposeConversion, payload, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runBeforeSend(request); err != nil {
+		return nil, err
+	}
+
+	response, err := s.apiClient.SendUnifyRequestContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetData() == nil || response.GetData().GetConversion() == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeAPIError,
+			"Conversion response did not include conversion results",
+		).WithSuggestion("Check that the environment supports conversion-only requests."))
+	}
+
+	conversion := response.GetData().GetConversion()
+	if !conversion.IsSuccess() {
+		message := "Conversion failed"
+		if len(conversion.GetErrors()) > 0 {
+			message = fmt.Sprintf("Conversion failed: %s", strings.Join(conversion.GetErrors(), "; "))
+		}
+		detail := NewErrorDetailWithCode(
+			ErrorCodeConversionError,
+			message,
+		).WithSuggestion("Fix the reported fields in the payload and convert again.")
+		detail.AddContextValue("errors", conversion.GetErrors())
+		return conversion, NewSDKError(detail)
+	}
+	return conversion, nil
+}
 
+// buildUnifyRequestV2 Validate the inputs and build the UnifyRequest for a V2 document type
+func (s *GETSUnifySDK) buildUnifyRequestV2(
+	sourceName string,
+	sourceVersion string,
+	documentTypeV2 *GetsDocumentTypeV2,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyRequest, error) {
 	// Validate required parameters
 	// Handle sourceName and sourceVersion based on purpose
 	var finalSourceName, finalSourceVersion string
@@ -82,18 +408,22 @@ func PushToUnifyV2(
 			finalSourceVersion = sourceVersion
 		}
 	} else {
-		// For all other purposes, sourceName and sourceVersion are mandatory
+		// For all other purposes, sourceName and sourceVersion are mandatory,
+		// taken from the configured default source when both are omitted
+		if defaultSource := s.config.DefaultSource; defaultSource != nil && strings.TrimSpace(sourceName) == "" && strings.TrimSpace(sourceVersion) == "" {
+			sourceName, sourceVersion = defaultSource.GetName(), defaultSource.GetVersion()
+		}
 		if strings.TrimSpace(sourceName) == "" {
 			return nil, NewSDKError(NewErrorDetailWithCode(
 				ErrorCodeMissingField,
 				"Source name is required",
-			))
+			).WithSuggestion("Pass the source name and version, or set SDKConfig.DefaultSource."))
 		}
 		if strings.TrimSpace(sourceVersion) == "" {
 			return nil, NewSDKError(NewErrorDetailWithCode(
 				ErrorCodeMissingField,
 				"Source version is required",
-			))
+			).WithSuggestion("Pass the source name and version, or set SDKConfig.DefaultSource."))
 		}
 		finalSourceName = sourceName
 		finalSourceVersion = sourceVersion
@@ -134,6 +464,10 @@ func PushToUnifyV2(
 		))
 	}
 
+	if err := ValidateModePurpose(mode, purpose); err != nil {
+		return nil, err
+	}
+
 	if payload == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
 			ErrorCodeMissingField,
@@ -142,7 +476,7 @@ func PushToUnifyV2(
 	}
 
 	// Validate country restrictions for current environment
-	if err := validateCountryForEnvironment(country, globalSDK.config.Environment); err != nil {
+	if err := validateCountryForEnvironment(country, s.config); err != nil {
 		return nil, err
 	}
 
@@ -151,34 +485,93 @@ func PushToUnifyV2(
 		return nil, err
 	}
 
-	// Keep V2 payload free of meta.config injection, but enforce V2 shape markers
-	// so backend does not downgrade to schema v1.
-	requestPayload := payload
-	setPayloadDocumentTypeV2(requestPayload, normalizedDocumentTypeV2)
-	setInvoiceDataDocumentTypeFromV2(requestPayload, normalizedDocumentTypeV2.Base)
+	requestPayload, err := s.prepareRequestPayload(payload, country, purpose, normalizedDocumentTypeV2)
+	if err != nil {
+		return nil, err
+	}
 
 	baseDocumentType := resolveBaseDocumentTypeFromV2(normalizedDocumentTypeV2.Base)
 
 	// Create source reference
 	sourceRef := NewSourceRef(finalSourceName, finalSourceVersion)
 
-	// Auto-generate destinations if none provided and auto-generation is enabled
+	// Auto-generate destinations if none provided and auto-generation is enabled,
+	// or merge them with the provided ones when asked to.
+	// Validate-only requests never reach a tax authority, so they get none.
+	explicitDestinations, mergeGenerated := splitGeneratedDestinationsMarker(destinations)
+	generate := s.config.AutoGenerateTaxDestination && purpose != PurposeValidation && purpose != PurposeConversion
 	var finalDestinations []*Destination
-	if destinations == nil && globalSDK.config.AutoGenerateTaxDestination {
-		finalDestinations = generateDefaultDestinations(string(country), normalizedDocumentTypeV2.Base)
+	if generate && explicitDestinations == nil {
+		finalDestinations = s.generateDestinations(country, normalizedDocumentTypeV2.Base, purpose)
+	} else if generate && (mergeGenerated || s.config.MergeDestinations) {
+		finalDestinations = mergeDestinations(s.generateDestinations(country, normalizedDocumentTypeV2.Base, purpose), explicitDestinations)
 	} else {
-		finalDestinations = destinations
+		finalDestinations = explicitDestinations
 		if finalDestinations == nil {
 			finalDestinations = []*Destination{}
 		}
 	}
 
-	// Build and send request using the resolved base document type
-	return pushToUnifyInternalWithDocumentType(
+	// Reject incomplete destinations before they reach the server
+	for i, destination := range finalDestinations {
+		if destination == nil {
+			return nil, NewSDKError(NewErrorDetailWithCode(
+				ErrorCodeValidationFailed,
+				fmt.Sprintf("Destination %d is nil", i),
+			))
+		}
+		if err := destination.Validate(); err != nil {
+			if sdkErr, ok := err.(*SDKError); ok && sdkErr.ErrorDetail != nil {
+				sdkErr.ErrorDetail.AddContextValue("destinationIndex", i)
+			}
+			return nil, err
+		}
+	}
+
+	// Build request using the resolved base document type
+	request := s.buildUnifyRequest(
 		sourceRef, baseDocumentType,
 		normalizedDocumentTypeV2.Base,
 		country, operation, mode, purpose, requestPayload, finalDestinations, normalizedDocumentTypeV2,
 	)
+	request.ensureIdempotencyKey(s.config.documentIDPathsFor(baseDocumentType))
+	return request, nil
+}
+
+// prepareRequestPayload Copy of payload as it is sent: text normalized for
+// country, monetary fields formatted and, for a GETS V2 document type, the V2
+// shape markers and invoice_data.document_type set. The caller's payload is
+// left untouched so it can be submitted again.
+func (s *GETSUnifySDK) prepareRequestPayload(
+	payload map[string]interface{},
+	country Country,
+	purpose Purpose,
+	documentTypeV2 *GetsDocumentTypeV2,
+) (map[string]interface{}, error) {
+	requestPayload := deepCopyPayload(payload)
+	if normalizer := s.config.textNormalizerFor(country); normalizer != nil {
+		if err := normalizePayloadText(requestPayload, normalizer); err != nil {
+			return nil, err
+		}
+	}
+	if err := formatMonetaryFields(requestPayload, s.config.MonetaryFieldPaths, s.config.GetMonetaryPrecision()); err != nil {
+		return nil, err
+	}
+	if documentTypeV2 == nil {
+		return requestPayload, nil
+	}
+
+	// Keep V2 payload free of meta.config injection, but enforce V2 shape markers
+	// so backend does not downgrade to schema v1
+	setPayloadDocumentTypeV2(requestPayload, documentTypeV2)
+	// Mapping payloads are still in the source's own shape
+	if purpose != PurposeMapping {
+		invoiceDataDocumentType := invoiceDataDocumentTypeFromV2(documentTypeV2.Base)
+		if err := SetInvoiceDataDocumentType(requestPayload, s.config.GetInvoiceDataPath(), invoiceDataDocumentType); err != nil {
+			return nil, err
+		}
+	}
+	return requestPayload, nil
 }
 
 func PushToUnifyWithDocumentType(
@@ -192,7 +585,23 @@ func PushToUnifyWithDocumentType(
 	payload map[string]interface{},
 	destinations []*Destination,
 ) (*UnifyResponse, error) {
-	return PushToUnifyV2(
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.PushToUnifyWithDocumentType(sourceName, sourceVersion, documentType, country, operation, mode, purpose, payload, destinations)
+}
+
+func (s *GETSUnifySDK) PushToUnifyWithDocumentType(
+	sourceName string,
+	sourceVersion string,
+	documentType *GetsDocumentType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payload map[string]interface{},
+	destinations []*Destination,
+) (*UnifyResponse, error) {
+	return s.PushToUnifyV2(
 		sourceName,
 		sourceVersion,
 		documentType,
@@ -205,25 +614,53 @@ func PushToUnifyWithDocumentType(
 	)
 }
 
-func setInvoiceDataDocumentType(payload map[string]interface{}, documentType string) {
-	if payload == nil {
-		return
+// DefaultInvoiceDataPath is where the invoice data object sits in a payload
+const DefaultInvoiceDataPath = "invoice_data"
+
+// SetInvoiceDataDocumentType Set document_type on the invoice data object found at
+// the dot-separated path (DefaultInvoiceDataPath when empty), e.g.
+// "document.invoice_data" for payloads that nest it further. A non-empty
+// document_type the caller already set is kept. Returns an error when the path
+// is missing or does not lead to an object.
+func SetInvoiceDataDocumentType(payload map[string]interface{}, path string, documentType string) error {
+	if strings.TrimSpace(path) == "" {
+		path = DefaultInvoiceDataPath
 	}
 
-	invoiceDataRaw, exists := payload["invoice_data"]
-	if !exists {
-		return
+	invoiceData := payload
+	walked := "payload"
+	for _, segment := range strings.Split(path, ".") {
+		walked += "." + segment
+		raw, exists := invoiceData[segment]
+		if !exists || raw == nil {
+			detail := NewErrorDetailWithCode(
+				ErrorCodeInvalidPayloadFormat,
+				fmt.Sprintf("%s is missing", walked),
+			).WithSuggestion("Include the invoice data object in the payload, or set SDKConfig.InvoiceDataPath to where it is nested.")
+			detail.AddContextValue("path", path)
+			return NewSDKError(detail)
+		}
+		nested, ok := raw.(map[string]interface{})
+		if !ok {
+			detail := NewErrorDetailWithCode(
+				ErrorCodeInvalidPayloadFormat,
+				fmt.Sprintf("%s must be an object, got %T", walked, raw),
+			).WithSuggestion("Check the payload shape, or set SDKConfig.InvoiceDataPath to where the invoice data object is nested.")
+			detail.AddContextValue("path", path)
+			return NewSDKError(detail)
+		}
+		invoiceData = nested
 	}
 
-	invoiceData, ok := invoiceDataRaw.(map[string]interface{})
-	if !ok {
-		return
+	if existing, ok := invoiceData["document_type"].(string); ok && strings.TrimSpace(existing) != "" {
+		return nil
 	}
-
 	invoiceData["document_type"] = strings.ToLower(strings.TrimSpace(documentType))
+	return nil
 }
 
 // PushToUnifyFromJSON Push to Unify API with logical document types using JSON string payload
+// Uses the SDK set up by Configure.
 func PushToUnifyFromJSON(
 	sourceName string,
 	sourceVersion string,
@@ -234,6 +671,23 @@ func PushToUnifyFromJSON(
 	purpose Purpose,
 	jsonPayload string,
 	destinations []*Destination,
+) (*UnifyResponse, error) {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.PushToUnifyFromJSON(sourceName, sourceVersion, logicalType, country, operation, mode, purpose, jsonPayload, destinations)
+}
+
+// PushToUnifyFromJSON Push to Unify API with logical document types using JSON string payload
+func (s *GETSUnifySDK) PushToUnifyFromJSON(
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	jsonPayload string,
+	destinations []*Destination,
 ) (*UnifyResponse, error) {
 	if strings.TrimSpace(jsonPayload) == "" {
 		return nil, NewSDKError(NewErrorDetailWithCode(
@@ -267,13 +721,14 @@ func PushToUnifyFromJSON(
 		).WithSuggestion(`Ensure the payload is valid JSON and represents an object structure. Example: '{"invoiceNumber":"INV-123"}'`))
 	}
 
-	return PushToUnify(
+	return s.PushToUnify(
 		sourceName, sourceVersion, logicalType, country,
 		operation, mode, purpose, payloadMap, destinations,
 	)
 }
 
 // PushToUnifyFromStruct Push to Unify API with logical document types using struct payload
+// Uses the SDK set up by Configure.
 func PushToUnifyFromStruct(
 	sourceName string,
 	sourceVersion string,
@@ -284,6 +739,39 @@ func PushToUnifyFromStruct(
 	purpose Purpose,
 	payloadStruct interface{},
 	destinations []*Destination,
+) (*UnifyResponse, error) {
+	sdk, release := acquireSDK()
+	defer release()
+	return sdk.PushToUnifyFromStruct(sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payloadStruct, destinations)
+}
+
+// PushToUnifyFromStruct Push to Unify API with logical document types using struct payload
+func (s *GETSUnifySDK) PushToUnifyFromStruct(
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payloadStruct interface{},
+	destinations []*Destination,
+) (*UnifyResponse, error) {
+	return s.pushToUnifyFromStructContext(context.Background(), sourceName, sourceVersion, logicalType, country, operation, mode, purpose, payloadStruct, destinations)
+}
+
+// pushToUnifyFromStructContext PushToUnifyFromStruct, sending the request with ctx
+func (s *GETSUnifySDK) pushToUnifyFromStructContext(
+	ctx context.Context,
+	sourceName string,
+	sourceVersion string,
+	logicalType LogicalDocType,
+	country Country,
+	operation Operation,
+	mode Mode,
+	purpose Purpose,
+	payloadStruct interface{},
+	destinations []*Destination,
 ) (*UnifyResponse, error) {
 	if payloadStruct == nil {
 		return nil, NewSDKError(NewErrorDetailWithCode(
@@ -332,8 +820,8 @@ func PushToUnifyFromStruct(
 			"The struct should be convertible to a map structure."))
 	}
 
-	return PushToUnify(
-		sourceName, sourceVersion, logicalType, country,
+	return s.pushToUnifyContext(
+		ctx, sourceName, sourceVersion, logicalType, country,
 		operation, mode, purpose, payloadMap, destinations,
 	)
 }
@@ -437,28 +925,15 @@ func setPayloadDocumentTypeV2(payload map[string]interface{}, documentTypeV2 *Ge
 	}
 }
 
-func setInvoiceDataDocumentTypeFromV2(payload map[string]interface{}, base string) {
-	if payload == nil {
-		return
-	}
-
-	invoiceDataRaw, exists := payload["invoice_data"]
-	if !exists {
-		return
-	}
-
-	invoiceData, ok := invoiceDataRaw.(map[string]interface{})
-	if !ok {
-		return
-	}
-
+// invoiceDataDocumentTypeFromV2 invoice_data.document_type for a GETS V2 base
+func invoiceDataDocumentTypeFromV2(base string) string {
 	switch base {
 	case string(GetsDocumentBaseCreditNote):
-		invoiceData["document_type"] = "credit_note"
+		return "credit_note"
 	case string(GetsDocumentBaseDebitNote):
-		invoiceData["document_type"] = "debit_note"
+		return "debit_note"
 	default:
-		invoiceData["document_type"] = "tax_invoice"
+		return "tax_invoice"
 	}
 }
 
@@ -475,11 +950,8 @@ func resolveBaseDocumentTypeFromV2(base string) DocumentType {
 
 // deepMergeIntoMetaConfig Deep merge meta.config flags into payload. User values take precedence over policy defaults
 func deepMergeIntoMetaConfig(payload map[string]interface{}, configFlags map[string]interface{}) map[string]interface{} {
-	// Create a deep copy of the payload
-	merged := make(map[string]interface{})
-	for k, v := range payload {
-		merged[k] = v
-	}
+	// Work on a deep copy so nested maps shared with the caller are never modified
+	merged := deepCopyPayload(payload)
 
 	metaRaw, exists := merged["meta"]
 	var meta map[string]interface{}
@@ -520,6 +992,58 @@ func deepMergeIntoMetaConfig(payload map[string]interface{}, configFlags map[str
 	return merged
 }
 
+// deepCopyPayload Recursive copy of a payload's maps and slices
+func deepCopyPayload(payload map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(payload))
+	for key, value := range payload {
+		copied[key] = deepCopyValue(value)
+	}
+	return copied
+}
+
+// deepCopyValue Recursive copy of a JSON-like value; scalars are returned as-is.
+// Nil maps and slices stay nil so they still serialize as null.
+func deepCopyValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if typed == nil {
+			return typed
+		}
+		return deepCopyPayload(typed)
+	case []interface{}:
+		if typed == nil {
+			return typed
+		}
+		items := make([]interface{}, len(typed))
+		for i, item := range typed {
+			items[i] = deepCopyValue(item)
+		}
+		return items
+	case []map[string]interface{}:
+		if typed == nil {
+			return typed
+		}
+		items := make([]map[string]interface{}, len(typed))
+		for i, item := range typed {
+			items[i] = deepCopyPayload(item)
+		}
+		return items
+	case map[string]string:
+		if typed == nil {
+			return typed
+		}
+		copied := make(map[string]string, len(typed))
+		for key, item := range typed {
+			copied[key] = item
+		}
+		return copied
+	case []string:
+		return append([]string(nil), typed...)
+	default:
+		return value
+	}
+}
+
 // generateDefaultDestinations Generate default destinations for a country and document type
 func generateDefaultDestinations(country string, documentType string) []*Destination {
 	destinations := []*Destination{}
@@ -535,25 +1059,24 @@ func generateDefaultDestinations(country string, documentType string) []*Destina
 	return destinations
 }
 
+// defaultTaxAuthorities Default tax authority for each country, used to
+// auto-generate the tax authority destination
+var defaultTaxAuthorities = map[Country]string{
+	CountrySA: "ZATCA",
+	CountryMY: "LHDN",
+	CountryAE: "FTA",
+	CountrySG: "IRAS",
+	CountryEG: "ETA",
+	CountryIN: "IRP", // Invoice Registration Portal operated by GSTN
+}
+
 // getDefaultTaxAuthority Get default tax authority for a country
 func getDefaultTaxAuthority(country string) string {
-	countryUpper := strings.ToUpper(country)
-	switch countryUpper {
-	case "SA":
-		return "ZATCA"
-	case "MY":
-		return "LHDN"
-	case "AE":
-		return "FTA"
-	case "SG":
-		return "IRAS"
-	default:
-		return ""
-	}
+	return defaultTaxAuthorities[Country(strings.ToUpper(country))]
 }
 
-// pushToUnifyInternalWithDocumentType Internal method to push to Unify API with custom document type string
-func pushToUnifyInternalWithDocumentType(
+// buildUnifyRequest Internal method to build a UnifyRequest with custom document type string
+func (s *GETSUnifySDK) buildUnifyRequest(
 	sourceRef *SourceRef,
 	baseDocumentType DocumentType,
 	documentTypeString string,
@@ -564,13 +1087,13 @@ func pushToUnifyInternalWithDocumentType(
 	payload map[string]interface{},
 	destinations []*Destination,
 	documentTypeV2 *GetsDocumentTypeV2,
-) (*UnifyResponse, error) {
+) *UnifyRequest {
 	// Build UnifyRequest with custom document type string
 	now := time.Now().UTC().Format(time.RFC3339)
 	requestID := fmt.Sprintf("req_%d_%f", time.Now().UnixNano()/int64(time.Millisecond), rand.Float64())
 
 	requestBuilder := NewUnifyRequestBuilder().
-		Source(buildSourceObject(sourceRef)).
+		Source(s.buildSourceObject(sourceRef)).
 		DocumentType(baseDocumentType).
 		DocumentTypeString(documentTypeString).
 		Country(string(country)).
@@ -579,10 +1102,10 @@ func pushToUnifyInternalWithDocumentType(
 		Purpose(purpose).
 		Payload(payload).
 		Destinations(destinations).
-		APIKey(globalSDK.config.APIKey).
+		APIKey(s.config.APIKey).
 		RequestID(requestID).
 		Timestamp(now).
-		Env(mapEnvironmentToAPIValue(globalSDK.config.Environment)).
+		Env(mapEnvironmentToAPIValue(s.config.Environment)).
 		SourceOrigin("SDK")
 
 	if documentTypeV2 != nil {
@@ -596,63 +1119,178 @@ func pushToUnifyInternalWithDocumentType(
 	request := requestBuilder.Build()
 
 	// Handle correlation ID
-	if globalSDK.config.CorrelationID != nil {
-		request.SetCorrelationID(*globalSDK.config.CorrelationID)
+	if s.config.CorrelationID != nil {
+		request.SetCorrelationID(*s.config.CorrelationID)
+	}
+
+	return request
+}
+
+// sendUnifyRequest Send a built request, queueing it for retry on retryable
+// failures, and give the RejectionCorrector its chance if it is rejected
+func (s *GETSUnifySDK) sendUnifyRequest(ctx context.Context, request *UnifyRequest) (*UnifyResponse, error) {
+	response, err := s.sendOrQueueUnifyRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return s.resubmitCorrectedRejection(ctx, request, response)
+}
+
+// sendOrQueueUnifyRequest Send a built request after the BeforeSend hook,
+// queueing it for retry on retryable failures
+func (s *GETSUnifySDK) sendOrQueueUnifyRequest(ctx context.Context, request *UnifyRequest) (*UnifyResponse, error) {
+	response, retry, err := s.sendUnifyRequestOnce(ctx, request)
+	if retry != nil {
+		_ = s.queueManager.EnqueueForRetry(retry.request, retry.operationName, retry.errorCode, retry.httpStatus)
+		return queuedUnifyResponse(request), nil
 	}
+	return response, err
+}
 
-	response, err := globalSDK.apiClient.SendUnifyRequest(request)
+// sendUnifyRequestOnce Send a built request after the BeforeSend hook. On a
+// retryable failure with a queue available, the returned queuedRetry describes
+// what to enqueue; the caller decides when to store it.
+func (s *GETSUnifySDK) sendUnifyRequestOnce(ctx context.Context, request *UnifyRequest) (*UnifyResponse, *queuedRetry, error) {
+	if err := s.runBeforeSend(request); err != nil {
+		return nil, nil, err
+	}
+	response, err := s.apiClient.SendUnifyRequestContext(ctx, request)
 	if err != nil {
+		// A caller that cancelled the submission gave it up; it is not queued
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, nil, err
+		}
 		if sdkErr, ok := err.(*SDKError); ok {
-			if shouldEnqueueForRetry(sdkErr) && globalSDK.queueManager != nil {
+			// Classify by the last attempt's failure, not the retry strategy's
+			// MAX_RETRIES_EXCEEDED wrapper, which carries no HTTP status
+			cause := rootSDKError(sdkErr)
+			if s.shouldEnqueueForRetry(cause) && s.queueManager != nil {
 				errorCode := ""
-				if sdkErr.ErrorDetail != nil && sdkErr.ErrorDetail.Code != nil {
-					errorCode = string(*sdkErr.ErrorDetail.Code)
-				}
-				_ = globalSDK.queueManager.EnqueueForRetry(
-					request,
-					"push_to_unify",
-					&errorCode,
-					extractHTTPStatus(sdkErr),
-				)
-
-				// Return a response indicating the submission was queued
-				queuedResponse := &UnifyResponse{
-					Status:  "queued",
-					Message: &[]string{fmt.Sprintf("Request failed but has been queued for retry. Submission ID: %s", *request.GetRequestID())}[0],
-					Data: &UnifyResponseData{
-						Submission: &SubmissionResponse{
-							SubmissionID: request.GetRequestID(),
-						},
-					},
+				if cause.ErrorDetail != nil && cause.ErrorDetail.Code != nil {
+					errorCode = string(*cause.ErrorDetail.Code)
 				}
-
-				return queuedResponse, nil
+				return nil, &queuedRetry{
+					request:       request,
+					operationName: "push_to_unify",
+					errorCode:     &errorCode,
+					httpStatus:    extractHTTPStatus(cause),
+				}, sdkErr
 			}
 
 			// If not a server error or queue not available, re-throw the exception
-			return nil, sdkErr
+			return nil, nil, sdkErr
 		}
+		return nil, nil, err
+	}
+
+	return response, nil, nil
+}
+
+// queuedUnifyResponse Response indicating the submission was queued for retry
+func queuedUnifyResponse(request *UnifyRequest) *UnifyResponse {
+	return &UnifyResponse{
+		Status:  "queued",
+		Message: &[]string{fmt.Sprintf("Request failed but has been queued for retry. Submission ID: %s", *request.GetRequestID())}[0],
+		Data: &UnifyResponseData{
+			Submission: &SubmissionResponse{
+				SubmissionID: request.GetRequestID(),
+			},
+		},
+	}
+}
+
+// resubmitCorrectedRejection Give the configured RejectionCorrector a single chance
+// to fix a rejected submission. The corrected payload is prepared like the
+// original one and sent once with ctx under a fresh request ID, queued for
+// retry on retryable failures; whatever comes back is returned without further
+// correction.
+func (s *GETSUnifySDK) resubmitCorrectedRejection(ctx context.Context, request *UnifyRequest, response *UnifyResponse) (*UnifyResponse, error) {
+	corrector := s.config.GetRejectionCorrector()
+	if corrector == nil || response == nil || response.GetData() == nil {
+		return response, nil
+	}
+	submission := response.GetData().GetSubmission()
+	if submission == nil || !submission.IsRejected() {
+		return response, nil
+	}
+
+	corrected, retry := corrector(submission, request.GetPayload())
+	if !retry || corrected == nil {
+		return response, nil
+	}
+
+	purpose := PurposeInvoicing
+	if request.GetPurpose() != nil {
+		purpose = *request.GetPurpose()
+	}
+	correctedPayload, err := s.prepareRequestPayload(corrected, Country(request.GetCountry()), purpose, requestDocumentTypeV2(request))
+	if err != nil {
 		return nil, err
 	}
 
-	return response, nil
+	s.apiClient.GetLogger().Info("Submission was rejected; resubmitting corrected payload", map[string]interface{}{"requestId": *request.GetRequestID()})
+	request.SetPayload(correctedPayload)
+	// The corrected document is a new submission as far as the server is concerned
+	request.SetIdempotencyKey(request.EnsureIdempotencyKey() + "-corrected")
+	request.SetRequestID(fmt.Sprintf("req_%d_%f", time.Now().UnixNano()/int64(time.Millisecond), rand.Float64()))
+	return s.sendOrQueueUnifyRequest(ctx, request)
+}
+
+// requestDocumentTypeV2 GETS V2 document type a request was built with, nil
+// when it has none
+func requestDocumentTypeV2(request *UnifyRequest) *GetsDocumentTypeV2 {
+	documentTypeV2 := request.GetDocumentTypeV2()
+	if documentTypeV2 == nil {
+		return nil
+	}
+	base, _ := documentTypeV2["base"].(string)
+	modifiers, _ := documentTypeV2["modifiers"].([]string)
+	variant, _ := documentTypeV2["variant"].(*string)
+	return NewGetsDocumentTypeV2(base, modifiers, variant)
+}
+
+// runBeforeSend Call the configured BeforeSendHook. An SDKError from the hook
+// is returned as is; any other error aborts the submission with
+// SUBMISSION_ABORTED wrapping it.
+func (s *GETSUnifySDK) runBeforeSend(request *UnifyRequest) error {
+	hook := s.config.GetBeforeSend()
+	if hook == nil {
+		return nil
+	}
+	err := hook(request)
+	if err == nil {
+		return nil
+	}
+	var sdkErr *SDKError
+	if errors.As(err, &sdkErr) {
+		return err
+	}
+	detail := NewErrorDetailWithCode(
+		ErrorCodeSubmissionAborted,
+		fmt.Sprintf("Submission aborted by the before-send hook: %v", err),
+	)
+	if request.GetRequestID() != nil {
+		detail.AddContextValue("requestId", *request.GetRequestID())
+	}
+	detail.Retryable = false
+	return &SDKError{ErrorDetail: detail, cause: err}
 }
 
 // isServerError determines if an SDK error represents a server error (500-range HTTP status codes).
 // Only 500-range errors (500-599) should trigger queue access.
-func isServerError(sdkErr *SDKError) bool {
-	return shouldEnqueueForRetry(sdkErr)
+func (s *GETSUnifySDK) isServerError(sdkErr *SDKError) bool {
+	return s.shouldEnqueueForRetry(sdkErr)
 }
 
-func shouldEnqueueForRetry(sdkErr *SDKError) bool {
+func (s *GETSUnifySDK) shouldEnqueueForRetry(sdkErr *SDKError) bool {
 	if sdkErr.ErrorDetail == nil {
 		return false
 	}
 
 	statusCode := extractHTTPStatus(sdkErr)
 	retryableStatusCodes := []int{408, 429, 500, 502, 503, 504}
-	if globalSDK != nil && globalSDK.config != nil && globalSDK.config.RetryConfig != nil && len(globalSDK.config.RetryConfig.RetryableHTTPCodes) > 0 {
-		retryableStatusCodes = globalSDK.config.RetryConfig.RetryableHTTPCodes
+	if s != nil && s.config != nil && s.config.RetryConfig != nil && len(s.config.RetryConfig.RetryableHTTPCodes) > 0 {
+		retryableStatusCodes = s.config.RetryConfig.RetryableHTTPCodes
 	}
 	if statusCode != nil {
 		for _, code := range retryableStatusCodes {
@@ -676,8 +1314,20 @@ func shouldEnqueueForRetry(sdkErr *SDKError) bool {
 		code == ErrorCodeRateLimitExceeded
 }
 
+// extractHTTPStatus HTTP status of the response behind sdkErr, from its
+// ResponseError or, for errors built without one, the httpStatus context value.
+// The context value may be any integer type, or a float64 or json.Number when
+// the error detail was decoded from JSON.
 func extractHTTPStatus(sdkErr *SDKError) *int {
-	if sdkErr == nil || sdkErr.ErrorDetail == nil {
+	if sdkErr == nil {
+		return nil
+	}
+	var responseErr *ResponseError
+	if errors.As(sdkErr, &responseErr) {
+		status := responseErr.StatusCode()
+		return &status
+	}
+	if sdkErr.ErrorDetail == nil {
 		return nil
 	}
 	httpStatusObj := sdkErr.ErrorDetail.GetContextValue("httpStatus")
@@ -687,9 +1337,19 @@ func extractHTTPStatus(sdkErr *SDKError) *int {
 	switch v := httpStatusObj.(type) {
 	case int:
 		return &v
+	case int64:
+		value := int(v)
+		return &value
+	case int32:
+		value := int(v)
+		return &value
 	case float64:
 		value := int(v)
 		return &value
+	case json.Number:
+		if parsed, err := strconv.Atoi(v.String()); err == nil {
+			return &parsed
+		}
 	case string:
 		if parsed, err := strconv.Atoi(v); err == nil {
 			return &parsed
@@ -699,11 +1359,11 @@ func extractHTTPStatus(sdkErr *SDKError) *int {
 }
 
 // buildSourceObject Build source object from SourceRef for the request
-func buildSourceObject(sourceRef *SourceRef) *Source {
+func (s *GETSUnifySDK) buildSourceObject(sourceRef *SourceRef) *Source {
 	source := NewSource(sourceRef.GetName(), sourceRef.GetVersion(), nil)
 
 	// Add type if available from registry
-	sourceType := getSourceTypeFromRegistry(sourceRef.GetName(), sourceRef.GetVersion())
+	sourceType := s.getSourceTypeFromRegistry(sourceRef.GetName(), sourceRef.GetVersion())
 	if sourceType != nil {
 		source = NewSource(sourceRef.GetName(), sourceRef.GetVersion(), sourceType)
 	}
@@ -712,15 +1372,15 @@ func buildSourceObject(sourceRef *SourceRef) *Source {
 }
 
 // getSourceTypeFromRegistry Get source type from registry by name and version
-func getSourceTypeFromRegistry(name, version string) *SourceType {
-	if globalSDK != nil && globalSDK.config != nil && globalSDK.config.Sources != nil {
-		for _, s := range globalSDK.config.Sources {
-			if s.GetName() == name && s.GetVersion() == version {
-				return s.GetSourceTypeEnum()
-			}
-		}
+func (s *GETSUnifySDK) getSourceTypeFromRegistry(name, version string) *SourceType {
+	if s == nil || s.config == nil {
+		return nil
 	}
-	return nil
+	source, err := s.config.GetSourceRegistry().Resolve(name, version)
+	if err != nil {
+		return nil
+	}
+	return source.GetSourceTypeEnum()
 }
 
 // mapEnvironmentToAPIValue Map Environment enum to API-expected string values
@@ -0,0 +1,691 @@
+package complyancesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// configureTestSDK configures the global SDK against an isolated queue directory
+// and points its API client at an httptest server backed by handler.
+func configureTestSDK(t *testing.T, cfg *SDKConfig, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if err := Configure(cfg); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	globalSDK.apiClient.baseURL = server.URL
+	return server
+}
+
+func testInvoicePayload(invoiceNumber string) map[string]interface{} {
+	return map[string]interface{}{
+		"invoice_data": map[string]interface{}{
+			"invoice_number": invoiceNumber,
+			"currency":       "SAR",
+		},
+	}
+}
+
+func TestRejectionCorrectorResubmitsCorrectedPayloadOnce(t *testing.T) {
+	var mu sync.Mutex
+	var currencies []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		payload, _ := body["payload"].(map[string]interface{})
+		invoiceData, _ := payload["invoice_data"].(map[string]interface{})
+		currency, _ := invoiceData["currency"].(string)
+
+		mu.Lock()
+		currencies = append(currencies, currency)
+		mu.Unlock()
+
+		status := "REJECTED"
+		if currency == "SAR" {
+			status = "ACCEPTED"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"submission": map[string]interface{}{
+					"submission_id": "sub-1",
+					"status":        status,
+					"errors":        []map[string]interface{}{{"code": "BR-KSA-CURRENCY", "message": "currency must be upper case"}},
+				},
+			},
+		})
+	}
+
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	calls := 0
+	cfg.SetRejectionCorrector(func(resp *SubmissionResponse, payload map[string]interface{}) (map[string]interface{}, bool) {
+		calls++
+		if len(resp.GetErrors()) == 0 {
+			t.Fatalf("expected rejection errors to be passed to the corrector")
+		}
+		invoiceData := payload["invoice_data"].(map[string]interface{})
+		invoiceData["currency"] = "SAR"
+		return payload, true
+	})
+	configureTestSDK(t, cfg, handler)
+
+	payload := testInvoicePayload("INV-1")
+	payload["invoice_data"].(map[string]interface{})["currency"] = "sar"
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected corrector to run once, ran %d times", calls)
+	}
+	if len(currencies) != 2 || currencies[0] != "sar" || currencies[1] != "SAR" {
+		t.Fatalf("unexpected submissions: %v", currencies)
+	}
+	if !response.GetData().GetSubmission().IsAccepted() {
+		t.Fatalf("expected resubmission to be accepted, got %v", *response.GetData().GetSubmission().GetStatus())
+	}
+}
+
+func TestRejectionCorrectorRunsAtMostOnce(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"REJECTED"}}}`))
+	}
+
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetRejectionCorrector(func(resp *SubmissionResponse, payload map[string]interface{}) (map[string]interface{}, bool) {
+		return payload, true
+	})
+	configureTestSDK(t, cfg, handler)
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-2"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected exactly one resubmission, got %d requests", requests)
+	}
+	if !response.GetData().GetSubmission().IsRejected() {
+		t.Fatalf("expected final response to remain rejected")
+	}
+}
+
+func TestRejectionCorrectorResubmissionIsPreparedAndQueued(t *testing.T) {
+	var bodies []map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if len(bodies) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"REJECTED"}}}`))
+	}
+
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	cfg.SetMonetaryFields(2, "invoice_data.total_amount")
+	cfg.SetRejectionCorrector(func(resp *SubmissionResponse, payload map[string]interface{}) (map[string]interface{}, bool) {
+		corrected := testInvoicePayload("INV-3")
+		corrected["invoice_data"].(map[string]interface{})["total_amount"] = 115.005
+		return corrected, true
+	})
+	configureTestSDK(t, cfg, handler)
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeCreditNote, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing,
+		map[string]interface{}{"invoice_data": map[string]interface{}{"invoice_number": "INV-3", "original_invoice_number": "INV-0"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.GetStatus() != "queued" {
+		t.Fatalf("expected the failed resubmission to be queued, got %q", response.GetStatus())
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected exactly one resubmission, got %d requests", len(bodies))
+	}
+	invoiceData := bodies[1]["payload"].(map[string]interface{})["invoice_data"].(map[string]interface{})
+	if invoiceData["total_amount"] != "115.01" || invoiceData["document_type"] != "credit_note" {
+		t.Fatalf("expected the corrected payload to be prepared like the original, got %v", invoiceData)
+	}
+	if GetDetailedQueueStatus().PendingCount != 1 {
+		t.Fatalf("expected the corrected submission in the queue")
+	}
+}
+
+func TestBeforeSendHookModifiesSentRequest(t *testing.T) {
+	var sent map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetBeforeSend(func(request *UnifyRequest) error {
+		meta, _ := request.GetPayload()["meta"].(map[string]interface{})
+		if _, merged := meta["config"]; !merged {
+			t.Fatalf("expected meta.config to be merged before the hook runs, got %v", request.GetPayload())
+		}
+		request.GetPayload()["audit"] = map[string]interface{}{"approved_by": "auditor-1"}
+		return nil
+	})
+	configureTestSDK(t, cfg, handler)
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-HOOK"), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, _ := sent["payload"].(map[string]interface{})
+	audit, _ := payload["audit"].(map[string]interface{})
+	if audit["approved_by"] != "auditor-1" {
+		t.Fatalf("expected the hook's audit metadata in the sent body, got %v", payload)
+	}
+}
+
+func TestBeforeSendHookVetoesSubmission(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}
+	veto := errors.New("missing approval")
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetBeforeSend(func(request *UnifyRequest) error {
+		return veto
+	})
+	configureTestSDK(t, cfg, handler)
+
+	_, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-VETO"), nil)
+	if !errors.Is(err, veto) {
+		t.Fatalf("expected the hook's error to be wrapped, got %v", err)
+	}
+	sdkErr := rootSDKError(err)
+	if sdkErr == nil || *sdkErr.ErrorDetail.Code != ErrorCodeSubmissionAborted || sdkErr.ErrorDetail.Retryable {
+		t.Fatalf("expected a non-retryable SUBMISSION_ABORTED error, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("expected nothing to be sent, got %d requests", requests)
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 0 {
+		t.Fatalf("expected nothing to be queued, got %d", pending)
+	}
+}
+
+func TestBuildSerializedRequestMatchesLiveSubmission(t *testing.T) {
+	var live map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&live)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}
+	cfg := NewSDKConfig("ak_secret_key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	configureTestSDK(t, cfg, handler)
+
+	serialized, err := BuildSerializedRequest("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-3"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if live != nil {
+		t.Fatalf("BuildSerializedRequest must not send the request")
+	}
+	if strings.Contains(string(serialized), "ak_secret_key") {
+		t.Fatalf("API key leaked into serialized request: %s", serialized)
+	}
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-3"), nil); err != nil {
+		t.Fatalf("unexpected push error: %v", err)
+	}
+
+	var built map[string]interface{}
+	if err := json.Unmarshal(serialized, &built); err != nil {
+		t.Fatalf("serialized request is not valid JSON: %v", err)
+	}
+	if built["apiKey"] != RedactedValue || live["apiKey"] != "ak_secret_key" {
+		t.Fatalf("expected only the built request to be redacted, got %v / %v", built["apiKey"], live["apiKey"])
+	}
+	// Per-call identifiers and the key are the only expected differences
+	for _, key := range []string{"apiKey", "requestId", "timestamp"} {
+		delete(built, key)
+		delete(live, key)
+	}
+	if !reflect.DeepEqual(built, live) {
+		builtJSON, _ := json.MarshalIndent(built, "", "  ")
+		liveJSON, _ := json.MarshalIndent(live, "", "  ")
+		t.Fatalf("serialized request differs from live submission:\nbuilt: %s\nlive: %s", builtJSON, liveJSON)
+	}
+}
+
+func TestBuildSerializedRequestRejectsWhatPushToUnifyRejects(t *testing.T) {
+	requests := 0
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetBeforeSend(func(request *UnifyRequest) error {
+		request.GetPayload()["audit"] = "previewed"
+		return nil
+	})
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	creditNote := testInvoicePayload("CN-1")
+	for _, logicalType := range []LogicalDocType{LogicalDocTypeCreditNote, LogicalDocType("NOT_A_TYPE")} {
+		_, previewErr := BuildSerializedRequest("src", "1", logicalType, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, creditNote, nil)
+		_, pushErr := PushToUnify("src", "1", logicalType, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, creditNote, nil)
+		if previewErr == nil || pushErr == nil || previewErr.Error() != pushErr.Error() {
+			t.Fatalf("%s: expected the preview to fail like the push, got %v / %v", logicalType, previewErr, pushErr)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("expected nothing to be sent, got %d requests", requests)
+	}
+
+	serialized, err := BuildSerializedRequest("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-PREVIEW"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(serialized, []byte(`"audit":"previewed"`)) {
+		t.Fatalf("expected the before-send hook to run for the preview, got %s", serialized)
+	}
+}
+
+func TestPushToUnifyGeneratesTaxAuthorityDestinationForNewCountries(t *testing.T) {
+	for country, authority := range map[Country]string{CountryEG: "ETA", CountryIN: "IRP"} {
+		var body map[string]interface{}
+		configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+		})
+
+		if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, country, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-"+string(country)), nil); err != nil {
+			t.Fatalf("%s: unexpected error: %v", country, err)
+		}
+
+		destinations, _ := body["destinations"].([]interface{})
+		if len(destinations) != 1 {
+			t.Fatalf("%s: expected one generated destination, got %v", country, body["destinations"])
+		}
+		destination, _ := destinations[0].(map[string]interface{})
+		details, _ := destination["details"].(map[string]interface{})
+		if destination["type"] != strings.ToUpper(string(DestinationTypeTaxAuthority)) || details["authority"] != authority || details["country"] != string(country) {
+			t.Fatalf("%s: unexpected destination %v", country, destination)
+		}
+	}
+}
+
+func TestRecordRequestJSONMatchesSentBody(t *testing.T) {
+	var sent []byte
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}
+	cfg := NewSDKConfig("ak_secret_key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.AutoGenerateTaxDestination = true
+	cfg.SetRecordRequestJSON(true)
+	configureTestSDK(t, cfg, handler)
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-AUDIT"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorded := response.GetRequestJSON()
+	expected := bytes.Replace(sent, []byte(`"apiKey":"ak_secret_key"`), []byte(`"apiKey":"`+RedactedValue+`"`), 1)
+	if !bytes.Equal(recorded, expected) {
+		t.Fatalf("recorded request does not match the sent body:\nrecorded %s\nsent     %s", recorded, sent)
+	}
+	if !bytes.Contains(recorded, []byte(`"destinations"`)) || !bytes.Contains(recorded, []byte(`"isExport"`)) {
+		t.Fatalf("expected generated destinations and meta.config flags in %s", recorded)
+	}
+}
+
+func TestRequestJSONIsNotRecordedByDefault(t *testing.T) {
+	cfg := NewSDKConfig("ak_secret_key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	response, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-AUDIT"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.GetRequestJSON() != nil {
+		t.Fatalf("expected no request JSON unless RecordRequestJSON is enabled")
+	}
+}
+
+func TestSetInvoiceDataDocumentType(t *testing.T) {
+	present := map[string]interface{}{"invoice_data": map[string]interface{}{}}
+	if err := SetInvoiceDataDocumentType(present, "", "Credit_Note"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := present["invoice_data"].(map[string]interface{})["document_type"]; got != "credit_note" {
+		t.Fatalf("expected document_type to be set, got %v", got)
+	}
+
+	overridden := map[string]interface{}{"invoice_data": map[string]interface{}{"document_type": "self_billed_invoice"}}
+	if err := SetInvoiceDataDocumentType(overridden, "", "tax_invoice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := overridden["invoice_data"].(map[string]interface{})["document_type"]; got != "self_billed_invoice" {
+		t.Fatalf("expected user-provided document_type to be kept, got %v", got)
+	}
+
+	nested := map[string]interface{}{"document": map[string]interface{}{"invoice": map[string]interface{}{}}}
+	if err := SetInvoiceDataDocumentType(nested, "document.invoice", "tax_invoice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := nested["document"].(map[string]interface{})["invoice"].(map[string]interface{})["document_type"]; got != "tax_invoice" {
+		t.Fatalf("expected nested document_type to be set, got %v", got)
+	}
+
+	for name, payload := range map[string]map[string]interface{}{
+		"absent":     {"lines": []interface{}{}},
+		"wrong type": {"invoice_data": "INV-1"},
+	} {
+		err := SetInvoiceDataDocumentType(payload, "", "tax_invoice")
+		sdkErr, ok := err.(*SDKError)
+		if !ok || *sdkErr.ErrorDetail.Code != ErrorCodeInvalidPayloadFormat || !strings.Contains(sdkErr.Error(), "payload.invoice_data") {
+			t.Fatalf("%s: expected invalid payload error naming the path, got %v", name, err)
+		}
+	}
+}
+
+func TestPushToUnifyUsesConfiguredInvoiceDataPath(t *testing.T) {
+	var body map[string]interface{}
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetInvoiceDataPath("document.invoice_data")
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoice, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-FLAT"), nil); err == nil || body != nil {
+		t.Fatalf("expected a payload without document.invoice_data to be rejected before sending")
+	}
+
+	payload := map[string]interface{}{"document": testInvoicePayload("INV-NESTED")}
+	payload["document"].(map[string]interface{})["invoice_data"].(map[string]interface{})["original_invoice_number"] = "INV-1"
+	if _, err := PushToUnify("src", "1", LogicalDocTypeTaxInvoiceCreditNote, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sent, _ := body["payload"].(map[string]interface{})
+	document, _ := sent["document"].(map[string]interface{})
+	invoiceData, _ := document["invoice_data"].(map[string]interface{})
+	if invoiceData["document_type"] != "credit_note" {
+		t.Fatalf("expected nested document_type, got %v", sent)
+	}
+}
+
+func TestPushToUnifyDoesNotMutateCallerPayload(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	payload := map[string]interface{}{
+		"invoice_data": map[string]interface{}{"invoice_number": "INV-REUSED", "original_invoice_number": "INV-1"},
+		"meta":         map[string]interface{}{"config": map[string]interface{}{"isExport": true}},
+		"header":       map[string]interface{}{"issuer": "acme"},
+		"line_items":   []interface{}{map[string]interface{}{"quantity": 1}},
+	}
+	original, _ := json.Marshal(payload)
+
+	for _, logicalType := range []LogicalDocType{LogicalDocTypeTaxInvoice, LogicalDocTypeSimplifiedTaxInvoiceCreditNote} {
+		if _, err := PushToUnify("src", "1", logicalType, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil); err != nil {
+			t.Fatalf("%s: unexpected error: %v", logicalType, err)
+		}
+		if after, _ := json.Marshal(payload); !bytes.Equal(after, original) {
+			t.Fatalf("%s: caller payload was modified:\nbefore %s\nafter  %s", logicalType, original, after)
+		}
+	}
+}
+
+func TestValidateCountryForEnvironmentNewCountries(t *testing.T) {
+	for _, country := range []Country{CountryEG, CountryIN} {
+		for _, environment := range []Environment{EnvironmentSandbox, EnvironmentProduction, EnvironmentDev} {
+			if err := validateCountryForEnvironment(country, NewSDKConfig("key", environment, nil, nil)); err != nil {
+				t.Fatalf("expected %s to be allowed in %s: %v", country, environment, err)
+			}
+		}
+		if err := validateCountryForEnvironment(country, NewSDKConfig("key", EnvironmentSimulation, nil, nil)); err == nil {
+			t.Fatalf("expected %s to be blocked in SIMULATION", country)
+		}
+	}
+}
+
+func TestDefaultAllowedCountries(t *testing.T) {
+	production := NewSDKConfig("key", EnvironmentProduction, nil, nil)
+	for _, country := range []Country{CountrySA, CountryMY} {
+		if err := validateCountryForEnvironment(country, production); err != nil {
+			t.Fatalf("expected %s to be allowed in PRODUCTION: %v", country, err)
+		}
+	}
+	if err := validateCountryForEnvironment(CountrySG, production); err == nil {
+		t.Fatalf("expected SG to be blocked in PRODUCTION by default")
+	}
+	if err := validateCountryForEnvironment(CountryMY, NewSDKConfig("key", EnvironmentSimulation, nil, nil)); err == nil {
+		t.Fatalf("expected MY to be blocked in SIMULATION by default")
+	}
+	if err := validateCountryForEnvironment(CountrySG, NewSDKConfig("key", EnvironmentDev, nil, nil)); err != nil {
+		t.Fatalf("expected DEV to allow every country: %v", err)
+	}
+}
+
+func TestCustomAllowedCountriesEnableAE(t *testing.T) {
+	simulation := NewSDKConfig("key", EnvironmentSimulation, nil, nil).
+		WithAllowedCountries(EnvironmentSimulation, CountrySA, CountryAE)
+	if err := validateCountryForEnvironment(CountryAE, simulation); err != nil {
+		t.Fatalf("expected AE to be allowed once configured: %v", err)
+	}
+	if err := validateCountryForEnvironment(CountryMY, simulation); err == nil {
+		t.Fatalf("expected MY to stay blocked")
+	}
+
+	production := NewSDKConfigBuilder().
+		Environment(EnvironmentProduction).
+		AllowedCountries(map[Environment][]Country{EnvironmentProduction: {CountryAE}}).
+		Build()
+	if err := validateCountryForEnvironment(CountryAE, production); err != nil {
+		t.Fatalf("expected AE to be allowed in PRODUCTION: %v", err)
+	}
+	if err := validateCountryForEnvironment(CountrySA, production); err == nil {
+		t.Fatalf("expected the override to replace the default PRODUCTION list")
+	}
+}
+
+func TestCountryRestrictionLogUsesAllowList(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  *SDKConfig
+		want    string
+		notWant string
+	}{
+		{"simulation default", NewSDKConfig("key", EnvironmentSimulation, nil, nil), "allowed: SA.", "MY"},
+		{"custom production", NewSDKConfig("key", EnvironmentProduction, nil, nil).
+			WithAllowedCountries(EnvironmentProduction, CountryAE, CountrySG), "allowed: AE, SG.", "SA"},
+		{"dev", NewSDKConfig("key", EnvironmentDev, nil, nil), "All countries are allowed", "Only"},
+	}
+	for _, tc := range cases {
+		logger := &recordingLogger{}
+		validateEnvironmentCountryRestrictions(tc.config, logger)
+		text := logger.text()
+		if !strings.Contains(text, tc.want) || strings.Contains(text, tc.notWant) {
+			t.Fatalf("%s: unexpected log %q", tc.name, text)
+		}
+	}
+}
+
+func TestValidateDocumentSendsNoDestinationsAndReturnsValidation(t *testing.T) {
+	var body map[string]interface{}
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.AutoGenerateTaxDestination = true
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"validation":{
+			"overall_success":false,
+			"methods":["schema"],
+			"errors":[{"method":"schema","message":"Seller VAT is required","code":"MISSING_FIELD","path":["invoice_data","seller_vat"]}]
+		}}}`))
+	})
+
+	validation, err := ValidateDocument(context.Background(), NewSource("src", "1", nil), LogicalDocTypeTaxInvoice, CountrySA, testInvoicePayload("INV-DRY"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body["purpose"] != string(PurposeValidation) {
+		t.Fatalf("expected validation purpose, got %v", body["purpose"])
+	}
+	if destinations, ok := body["destinations"].([]interface{}); ok && len(destinations) != 0 {
+		t.Fatalf("expected no destinations, got %v", destinations)
+	}
+	if validation.IsOverallSuccess() || len(validation.GetErrors()) != 1 {
+		t.Fatalf("unexpected validation result: %+v", validation)
+	}
+	if got := validation.GetErrors()[0].GetMessage(); got == nil || *got != "Seller VAT is required" {
+		t.Fatalf("unexpected validation message: %v", got)
+	}
+}
+
+func TestValidationAndSubmissionUseDifferentIdempotencyKeys(t *testing.T) {
+	var keys []string
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	configureTestSDK(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		_, _ = w.Write([]byte(`{"status":"success","data":{"validation":{"overall_success":true}}}`))
+	})
+
+	if _, err := ValidateDocument(context.Background(), NewSource("src", "1", nil), LogicalDocTypeTaxInvoice, CountrySA, testInvoicePayload("INV-KEY")); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	for _, logicalType := range []LogicalDocType{LogicalDocTypeTaxInvoice, LogicalDocTypeTaxInvoice, LogicalDocTypeCreditNote} {
+		payload := testInvoicePayload("INV-KEY")
+		if logicalType == LogicalDocTypeCreditNote {
+			payload["invoice_data"].(map[string]interface{})["original_invoice_number"] = "INV-KEY-0"
+		}
+		if _, err := PushToUnify("src", "1", logicalType, CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, payload, nil); err != nil {
+			t.Fatalf("unexpected push error: %v", err)
+		}
+	}
+	if len(keys) != 4 || keys[0] == "" {
+		t.Fatalf("expected four keyed requests, got %v", keys)
+	}
+	if keys[0] == keys[1] {
+		t.Fatalf("expected the validation and the submission to use different keys, got %s", keys[0])
+	}
+	if keys[1] != keys[2] {
+		t.Fatalf("expected resubmitting the same invoice to reuse its key, got %s and %s", keys[1], keys[2])
+	}
+	if keys[3] == keys[1] {
+		t.Fatalf("expected a credit note reusing the invoice number to get its own key")
+	}
+}
+
+func TestValidateDocumentRequiresValidationData(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"submission":{"status":"ACCEPTED"}}}`))
+	})
+
+	if _, err := ValidateDocument(context.Background(), NewSource("src", "1", nil), LogicalDocTypeTaxInvoice, CountrySA, testInvoicePayload("INV-DRY-2")); err == nil {
+		t.Fatalf("expected an error when the response has no validation data")
+	}
+}
+
+func TestConvertReturnsGetsDocumentWithoutSubmitting(t *testing.T) {
+	var body map[string]interface{}
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"conversion":{
+			"success":true,
+			"gets_document":{"invoice_number":"INV-CONV"},
+			"conversion_time":42
+		}}}`))
+	})
+
+	conversion, err := Convert(context.Background(), NewSource("src", "1", nil), LogicalDocTypeTaxInvoice, CountrySA, testInvoicePayload("INV-CONV"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["purpose"] != string(PurposeConversion) {
+		t.Fatalf("expected conversion purpose, got %v", body["purpose"])
+	}
+	if destinations, ok := body["destinations"].([]interface{}); ok && len(destinations) != 0 {
+		t.Fatalf("expected no destinations, got %v", destinations)
+	}
+	if conversion.GetGetsDocument()["invoice_number"] != "INV-CONV" {
+		t.Fatalf("unexpected GETS document %v", conversion.GetGetsDocument())
+	}
+	if conversion.GetConversionTime() == nil || *conversion.GetConversionTime() != 42 {
+		t.Fatalf("expected the conversion time, got %v", conversion.GetConversionTime())
+	}
+	if pending := GetDetailedQueueStatus().PendingCount; pending != 0 {
+		t.Fatalf("expected nothing to be queued, got %d", pending)
+	}
+}
+
+func TestConvertSurfacesConversionErrors(t *testing.T) {
+	configureTestSDK(t, NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig()), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"conversion":{
+			"success":false,
+			"errors":["invoice_data.seller_vat is required"]
+		}}}`))
+	})
+
+	conversion, err := Convert(context.Background(), NewSource("src", "1", nil), LogicalDocTypeTaxInvoice, CountrySA, testInvoicePayload("INV-CONV-2"))
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeConversionError {
+		t.Fatalf("expected a conversion error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "seller_vat is required") {
+		t.Fatalf("expected the conversion errors in the message, got %v", err)
+	}
+	if conversion == nil || conversion.IsSuccess() || len(conversion.GetErrors()) != 1 {
+		t.Fatalf("expected the failed conversion to be returned, got %+v", conversion)
+	}
+}
+
+func TestDefaultSourceIsUsedWhenSourceIsOmitted(t *testing.T) {
+	cfg := NewSDKConfig("test-key", EnvironmentSandbox, nil, NewNoRetryConfig())
+	cfg.SetQueueMode(QueueModeMemory)
+	sdk, err := NewSDK(cfg)
+	if err != nil {
+		t.Fatalf("NewSDK failed: %v", err)
+	}
+	build := func(sourceName, sourceVersion string) (*UnifyRequest, error) {
+		return sdk.buildUnifyRequestV2(sourceName, sourceVersion, MapLogicalDocTypeToGetsV2(LogicalDocTypeTaxInvoice),
+			CountrySA, OperationSingle, ModeDocuments, PurposeInvoicing, testInvoicePayload("INV-1"), nil)
+	}
+
+	_, err = build("", "")
+	if code, ok := GetErrorCode(err); !ok || code != ErrorCodeMissingField {
+		t.Fatalf("expected MISSING_FIELD without a source or default, got %v", err)
+	}
+
+	cfg.SetDefaultSource(NewSource("erp", "2.1", nil))
+	request, err := build("", "")
+	if err != nil {
+		t.Fatalf("expected the default source to be used, got %v", err)
+	}
+	if source := request.GetSource(); source == nil || source.GetName() != "erp" || source.GetVersion() != "2.1" {
+		t.Fatalf("expected source erp 2.1, got %+v", source)
+	}
+
+	request, err = build("pos", "1")
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if source := request.GetSource(); source == nil || source.GetName() != "pos" || source.GetVersion() != "1" {
+		t.Fatalf("expected the explicit source to override the default, got %+v", source)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ZATCA QR code decoding and rendering for Saudi e-invoices.
+*/
+package complyancesdk
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// ZATCA QR TLV tags
+const (
+	ZATCATagSellerName           byte = 1
+	ZATCATagVATNumber            byte = 2
+	ZATCATagTimestamp            byte = 3
+	ZATCATagInvoiceTotal         byte = 4
+	ZATCATagVATTotal             byte = 5
+	ZATCATagInvoiceHash          byte = 6
+	ZATCATagSignature            byte = 7
+	ZATCATagPublicKey            byte = 8
+	ZATCATagCertificateSignature byte = 9
+)
+
+// DefaultQRPNGSize is the PNG width and height used when RenderQRPNG is given no size
+const DefaultQRPNGSize = 256
+
+// ZATCATLV Fields of a ZATCA QR code. Tags 1-5 are present on every invoice;
+// the phase 2 cryptographic tags 6-9 are kept as raw bytes when present.
+type ZATCATLV struct {
+	SellerName           string
+	VATNumber            string
+	Timestamp            string
+	InvoiceTotal         string
+	VATTotal             string
+	InvoiceHash          []byte
+	Signature            []byte
+	PublicKey            []byte
+	CertificateSignature []byte
+	// Tags Raw value of every tag in the QR code, including ones not listed above
+	Tags map[byte][]byte
+}
+
+// DecodeZATCAQR Parse a base64 encoded ZATCA QR code, as returned in the
+// submission response's qr_code, into its TLV fields
+func DecodeZATCAQR(qr string) (*ZATCATLV, error) {
+	qr = strings.TrimSpace(qr)
+	if qr == "" {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"QR code is required",
+		))
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(qr)
+	if err != nil {
+		raw, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(qr, "="))
+	}
+	if err != nil {
+		detail := NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			fmt.Sprintf("QR code is not valid base64: %v", err),
+		).WithSuggestion("Pass the qr_code value from the submission response unchanged.")
+		return nil, NewSDKError(detail)
+	}
+
+	tlv := &ZATCATLV{Tags: make(map[byte][]byte)}
+	for offset := 0; offset < len(raw); {
+		if offset+2 > len(raw) {
+			return nil, invalidZATCAQRError(fmt.Sprintf("QR code is truncated at byte %d", offset))
+		}
+		tag, length := raw[offset], int(raw[offset+1])
+		offset += 2
+		if offset+length > len(raw) {
+			return nil, invalidZATCAQRError(fmt.Sprintf("QR code tag %d is truncated", tag))
+		}
+		tlv.Tags[tag] = raw[offset : offset+length]
+		offset += length
+	}
+
+	for tag := ZATCATagSellerName; tag <= ZATCATagVATTotal; tag++ {
+		value, ok := tlv.Tags[tag]
+		if !ok {
+			return nil, invalidZATCAQRError(fmt.Sprintf("QR code is missing tag %d", tag))
+		}
+		if !utf8.Valid(value) {
+			return nil, invalidZATCAQRError(fmt.Sprintf("QR code tag %d is not valid UTF-8", tag))
+		}
+	}
+	tlv.SellerName = string(tlv.Tags[ZATCATagSellerName])
+	tlv.VATNumber = string(tlv.Tags[ZATCATagVATNumber])
+	tlv.Timestamp = string(tlv.Tags[ZATCATagTimestamp])
+	tlv.InvoiceTotal = string(tlv.Tags[ZATCATagInvoiceTotal])
+	tlv.VATTotal = string(tlv.Tags[ZATCATagVATTotal])
+	tlv.InvoiceHash = tlv.Tags[ZATCATagInvoiceHash]
+	tlv.Signature = tlv.Tags[ZATCATagSignature]
+	tlv.PublicKey = tlv.Tags[ZATCATagPublicKey]
+	tlv.CertificateSignature = tlv.Tags[ZATCATagCertificateSignature]
+	return tlv, nil
+}
+
+// invalidZATCAQRError Error for a QR code whose TLV structure cannot be parsed
+func invalidZATCAQRError(message string) error {
+	return NewSDKError(NewErrorDetailWithCode(
+		ErrorCodeInvalidArgument,
+		message,
+	).WithSuggestion("Check that the value is a ZATCA QR code; QR codes from other authorities are not TLV encoded."))
+}
+
+// RenderQRPNG Render a QR code string as a square PNG of size pixels
+// (DefaultQRPNGSize when size is 0 or less). The string is encoded as-is, so a
+// ZATCA qr_code renders to the code printed on the invoice.
+func RenderQRPNG(qr string, size int) ([]byte, error) {
+	if strings.TrimSpace(qr) == "" {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"QR code is required",
+		))
+	}
+	if size <= 0 {
+		size = DefaultQRPNGSize
+	}
+
+	png, err := qrcode.Encode(qr, qrcode.Medium, size)
+	if err != nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeInvalidArgument,
+			fmt.Sprintf("Failed to render QR code: %v", err),
+		))
+	}
+	return png, nil
+}
+
+// GetZATCAQR Decode the QR code of a ZATCA submission
+func (s *SubmissionResponseData) GetZATCAQR() (*ZATCATLV, error) {
+	if s == nil || s.QRCode == nil {
+		return nil, NewSDKError(NewErrorDetailWithCode(
+			ErrorCodeMissingField,
+			"Submission response has no QR code",
+		).WithSuggestion("QR codes are available once the submission is cleared or reported."))
+	}
+	return DecodeZATCAQR(*s.QRCode)
+}
@@ -0,0 +1,82 @@
+package complyancesdk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"testing"
+)
+
+// zatcaQRFixture is the example QR code from the ZATCA e-invoicing guideline
+const zatcaQRFixture = "AQxGaXJveiBBc2hyYWYCCjEyMzQ1Njc4OTEDEzIwMjEtMTEtMTcgMDg6MzA6MDAEBjEwMC4wMAUFMTUuMDA="
+
+func TestDecodeZATCAQRParsesFields(t *testing.T) {
+	tlv, err := DecodeZATCAQR(zatcaQRFixture)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if tlv.SellerName != "Firoz Ashraf" || tlv.VATNumber != "1234567891" {
+		t.Fatalf("unexpected seller fields %q, %q", tlv.SellerName, tlv.VATNumber)
+	}
+	if tlv.Timestamp != "2021-11-17 08:30:00" || tlv.InvoiceTotal != "100.00" || tlv.VATTotal != "15.00" {
+		t.Fatalf("unexpected invoice fields %q, %q, %q", tlv.Timestamp, tlv.InvoiceTotal, tlv.VATTotal)
+	}
+	if tlv.InvoiceHash != nil || len(tlv.Tags) != 5 {
+		t.Fatalf("expected only the phase 1 tags, got %v", tlv.Tags)
+	}
+}
+
+func TestDecodeZATCAQRKeepsPhaseTwoTagsAndArabicNames(t *testing.T) {
+	fixture := "ARnYtNix2YPYqSDYp9mE2KfYrtiq2KjYp9ixAg8zMTAxMjIzOTM1MDAwMDMDFDIwMjItMDQtMjVUMTU6MzA6MDBaBAcxMTUwLjAwBQYxNTAuMDA="
+	raw, _ := base64.StdEncoding.DecodeString(fixture)
+	raw = append(raw, ZATCATagInvoiceHash, 3, 'a', 'b', 'c', ZATCATagPublicKey, 2, 0x30, 0x59)
+
+	tlv, err := DecodeZATCAQR(base64.StdEncoding.EncodeToString(raw))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if tlv.SellerName != "شركة الاختبار" || tlv.VATNumber != "310122393500003" {
+		t.Fatalf("unexpected seller fields %q, %q", tlv.SellerName, tlv.VATNumber)
+	}
+	if string(tlv.InvoiceHash) != "abc" || !bytes.Equal(tlv.PublicKey, []byte{0x30, 0x59}) || tlv.Signature != nil {
+		t.Fatalf("unexpected phase 2 tags %v", tlv.Tags)
+	}
+}
+
+func TestDecodeZATCAQRRejectsMalformedInput(t *testing.T) {
+	raw, _ := base64.StdEncoding.DecodeString(zatcaQRFixture)
+	cases := map[string]string{
+		"not base64":  "%%%",
+		"truncated":   base64.StdEncoding.EncodeToString(raw[:len(raw)-2]),
+		"missing tag": base64.StdEncoding.EncodeToString(raw[:len(raw)-7]),
+	}
+	for name, qr := range cases {
+		if _, err := DecodeZATCAQR(qr); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRenderQRPNG(t *testing.T) {
+	data, err := RenderQRPNG(zatcaQRFixture, 0)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	image, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a PNG: %v", err)
+	}
+	if bounds := image.Bounds(); bounds.Dx() != DefaultQRPNGSize || bounds.Dy() != DefaultQRPNGSize {
+		t.Fatalf("expected a %dpx image, got %v", DefaultQRPNGSize, bounds)
+	}
+
+	qr := zatcaQRFixture
+	response := &SubmissionResponseData{QRCode: &qr}
+	tlv, err := response.GetZATCAQR()
+	if err != nil || tlv.SellerName != "Firoz Ashraf" {
+		t.Fatalf("expected the response QR code to decode, got %v, %v", tlv, err)
+	}
+	if _, err := (&SubmissionResponseData{}).GetZATCAQR(); err == nil {
+		t.Fatalf("expected an error without a QR code")
+	}
+}